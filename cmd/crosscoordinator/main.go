@@ -0,0 +1,307 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	"github.com/hyperledger/fabric/msp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/common/crosscoord"
+	cb "github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var logger = flogging.MustGetLogger("crosscoordinator")
+
+// broadcaster sends confirmations to the ordering service, one at a time
+type broadcaster struct {
+	lock   sync.Mutex
+	conn   *grpc.ClientConn
+	client ab.AtomicBroadcast_BroadcastClient
+}
+
+func (b *broadcaster) Broadcast(env *cb.Envelope) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.client == nil {
+		client, err := ab.NewAtomicBroadcastClient(b.conn).Broadcast(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed connecting to the ordering service")
+		}
+		b.client = client
+	}
+	if err := b.client.Send(env); err != nil {
+		b.client = nil
+		return errors.Wrap(err, "failed sending to the ordering service")
+	}
+	resp, err := b.client.Recv()
+	if err != nil {
+		b.client = nil
+		return errors.Wrap(err, "failed receiving from the ordering service")
+	}
+	if resp.Status != cb.Status_SUCCESS {
+		return errors.Errorf("got unexpected status: %v - %s", resp.Status, resp.Info)
+	}
+	return nil
+}
+
+// deliverBlocks feeds the coordinator with the blocks committed on the channel, resuming
+// from the height recorded in the decision log, and reconnects to the peer on failure
+func deliverBlocks(conn *grpc.ClientConn, channelID string, signer crypto.LocalSigner, coord *crosscoord.Coordinator, retryInterval time.Duration) {
+	for {
+		if err := deliverFrom(conn, channelID, signer, coord); err != nil {
+			logger.Warningf("[%s] Delivery of blocks stopped, retrying in %s: %s", channelID, retryInterval, err)
+		}
+		time.Sleep(retryInterval)
+	}
+}
+
+func deliverFrom(conn *grpc.ClientConn, channelID string, signer crypto.LocalSigner, coord *crosscoord.Coordinator) error {
+	height, err := coord.Height(channelID)
+	if err != nil {
+		return err
+	}
+	client, err := pb.NewDeliverClient(conn).Deliver(context.Background())
+	if err != nil {
+		return err
+	}
+	defer client.CloseSend()
+	seek, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, channelID, signer, &ab.SeekInfo{
+		Start:    &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: height}}},
+		Stop:     &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: math.MaxUint64}}},
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+	}, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err := client.Send(seek); err != nil {
+		return err
+	}
+	logger.Infof("[%s] Processing committed blocks from block [%d]", channelID, height)
+
+	for {
+		msg, err := client.Recv()
+		if err != nil {
+			return err
+		}
+		switch t := msg.Type.(type) {
+		case *pb.DeliverResponse_Status:
+			return errors.Errorf("got status %v", t.Status)
+		case *pb.DeliverResponse_Block:
+			if err := coord.ProcessBlock(channelID, t.Block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// waitPeer is a peer whose endorsers report the lock conflicts of cross transactions
+type waitPeer struct {
+	addr string
+	conn *grpc.ClientConn
+}
+
+// reportLockWaits reports to the coordinator the lock waits of the channel, as known to the given peers, which
+// are merged as the endorsers of each peer only know the conflicts of the proposals they endorsed
+func reportLockWaits(peers []waitPeer, channelID string, signer msp.SigningIdentity, coord *crosscoord.Coordinator) error {
+	holders := make(map[string]map[string]struct{})
+	for _, peer := range peers {
+		waits, err := getLockWaits(peer.conn, channelID, signer)
+		if err != nil {
+			logger.Warningf("[%s] Failed getting lock waits from peer %s: %s", channelID, peer.addr, err)
+			continue
+		}
+		for _, w := range waits {
+			if holders[w.CrossTxId] == nil {
+				holders[w.CrossTxId] = make(map[string]struct{})
+			}
+			for _, holder := range w.Holders {
+				holders[w.CrossTxId][holder] = struct{}{}
+			}
+		}
+	}
+	var waits []*crosspb.CrossLockWait
+	for crossTxID, hs := range holders {
+		w := &crosspb.CrossLockWait{CrossTxId: crossTxID}
+		for holder := range hs {
+			w.Holders = append(w.Holders, holder)
+		}
+		waits = append(waits, w)
+	}
+	return coord.ReportWaits(channelID, waits)
+}
+
+// getLockWaits queries the lock waits of the channel from the qscc of a peer
+func getLockWaits(conn *grpc.ClientConn, channelID string, signer msp.SigningIdentity) ([]*crosspb.CrossLockWait, error) {
+	invocation := &pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_GOLANG,
+			ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+			Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(qscc.GetCrossLockWaits), []byte(channelID)}},
+		},
+	}
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot serialize the signer")
+	}
+	prop, _, err := utils.CreateProposalFromCIS(cb.HeaderType_ENDORSER_TRANSACTION, "", invocation, creator)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot create proposal")
+	}
+	signedProp, err := utils.GetSignedProposal(prop, signer)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot create signed proposal")
+	}
+	proposalResp, err := pb.NewEndorserClient(conn).ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed sending proposal")
+	}
+	if proposalResp.Response == nil || proposalResp.Response.Status != 200 {
+		return nil, errors.Errorf("received bad response, status %d: %s", proposalResp.Response.GetStatus(), proposalResp.Response.GetMessage())
+	}
+	waits := &crosspb.CrossLockWaits{}
+	if err := proto.Unmarshal(proposalResp.Response.Payload, waits); err != nil {
+		return nil, errors.Wrap(err, "cannot read qscc response")
+	}
+	return waits.Waits, nil
+}
+
+// parseLeases parses the leases of specific channels, given as channel=blocks/duration
+func parseLeases(leases string) (map[string]crosscoord.Lease, error) {
+	res := make(map[string]crosscoord.Lease)
+	if leases == "" {
+		return res, nil
+	}
+	for _, entry := range strings.Split(leases, ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("invalid lease [%s], expected channel=blocks/duration", entry)
+		}
+		bt := strings.SplitN(kv[1], "/", 2)
+		if len(bt) != 2 {
+			return nil, errors.Errorf("invalid lease [%s], expected channel=blocks/duration", entry)
+		}
+		blocks, err := strconv.ParseUint(bt[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid number of blocks in lease [%s]", entry)
+		}
+		duration, err := time.ParseDuration(bt[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid duration in lease [%s]", entry)
+		}
+		res[kv[0]] = crosscoord.Lease{Blocks: blocks, Time: duration}
+	}
+	return res, nil
+}
+
+func main() {
+	var peerAddr, ordererAddr, channels, mspDir, mspID, logDir, channelLeases, waitPeers string
+	var retryInterval, leaseTime time.Duration
+	var leaseBlocks uint64
+	flag.StringVar(&peerAddr, "peer", "127.0.0.1:7051", "The peer delivering the committed blocks of the participant channels.")
+	flag.StringVar(&ordererAddr, "orderer", "127.0.0.1:7050", "The orderer the confirmations are broadcast to.")
+	flag.StringVar(&channels, "channels", "", "Comma separated list of the channels whose cross transactions are coordinated.")
+	flag.StringVar(&mspDir, "mspDir", "", "The MSP directory of the coordinator identity.")
+	flag.StringVar(&mspID, "mspID", "", "The MSP ID of the coordinator identity.")
+	flag.StringVar(&logDir, "logDir", "/var/hyperledger/crosscoordinator", "The directory of the decision log.")
+	flag.DurationVar(&retryInterval, "retryInterval", 10*time.Second, "The interval at which undelivered confirmations are broadcast again, and the leases checked.")
+	flag.Uint64Var(&leaseBlocks, "leaseBlocks", 0, "The number of blocks committed on a channel after the preparation of a cross transaction, after which the transaction fails if votes are missing. 0 for no limit.")
+	flag.DurationVar(&leaseTime, "leaseTime", 0, "The time elapsed since the preparation of a cross transaction on a channel, after which the transaction fails if votes are missing. 0 for no limit.")
+	flag.StringVar(&channelLeases, "channelLeases", "", "Comma separated leases of specific channels overriding -leaseBlocks and -leaseTime, as channel=blocks/duration, e.g. ch1=100/10m.")
+	flag.StringVar(&waitPeers, "waitPeers", "", "Comma separated list of the peers whose endorsers report the lock conflicts of cross transactions, polled at -retryInterval for detecting deadlocks. The -peer if empty.")
+	flag.Parse()
+
+	if channels == "" {
+		fmt.Println("At least one channel must be specified.")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	leases, err := parseLeases(channelLeases)
+	if err != nil {
+		fmt.Println("Invalid channel leases:", err)
+		os.Exit(1)
+	}
+	if err := mspmgmt.LoadLocalMsp(mspDir, factory.GetDefaultOpts(), mspID); err != nil {
+		fmt.Println("Failed to initialize local MSP:", err)
+		os.Exit(1)
+	}
+	signer := localmsp.NewSigner()
+
+	peerConn, err := grpc.Dial(peerAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Println("Error connecting to the peer:", err)
+		os.Exit(1)
+	}
+	waitPeerList := []waitPeer{{addr: peerAddr, conn: peerConn}}
+	if waitPeers != "" {
+		waitPeerList = nil
+		for _, addr := range strings.Split(waitPeers, ",") {
+			addr = strings.TrimSpace(addr)
+			conn, err := grpc.Dial(addr, grpc.WithInsecure())
+			if err != nil {
+				fmt.Println("Error connecting to the peer:", err)
+				os.Exit(1)
+			}
+			waitPeerList = append(waitPeerList, waitPeer{addr: addr, conn: conn})
+		}
+	}
+	ordererConn, err := grpc.Dial(ordererAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Println("Error connecting to the orderer:", err)
+		os.Exit(1)
+	}
+
+	decisionLog := crosscoord.NewDecisionLog(logDir)
+	defer decisionLog.Close()
+	coord, err := crosscoord.New(decisionLog, signer, &broadcaster{conn: ordererConn})
+	if err != nil {
+		fmt.Println("Failed to recover the decision log:", err)
+		os.Exit(1)
+	}
+	coord.SetLease("", crosscoord.Lease{Blocks: leaseBlocks, Time: leaseTime})
+	for channelID, lease := range leases {
+		coord.SetLease(channelID, lease)
+	}
+
+	var channelIDs []string
+	for _, channelID := range strings.Split(channels, ",") {
+		channelIDs = append(channelIDs, strings.TrimSpace(channelID))
+		go deliverBlocks(peerConn, strings.TrimSpace(channelID), signer, coord, retryInterval)
+	}
+	identity := mspmgmt.GetLocalSigningIdentityOrPanic()
+	for range time.Tick(retryInterval) {
+		coord.ResendPending()
+		if err := coord.ExpireLeases(); err != nil {
+			logger.Errorf("Failed expiring the leases of cross transactions: %s", err)
+		}
+		for _, channelID := range channelIDs {
+			if err := reportLockWaits(waitPeerList, channelID, identity, coord); err != nil {
+				logger.Errorf("[%s] Failed aborting deadlocked cross transactions: %s", channelID, err)
+			}
+		}
+	}
+}
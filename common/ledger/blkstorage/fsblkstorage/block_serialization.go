@@ -17,7 +17,6 @@ limitations under the License.
 package fsblkstorage
 
 import (
-	"fmt"
 	"github.com/golang/protobuf/proto"
 	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
@@ -209,13 +208,6 @@ func extractTxID(txEnvelopBytes []byte) (string, error) {
 		return "", err
 	}
 
-	fmt.Println("common/ledger/blkstorage/fsblkstorage/block_serialization.go extractTxID() txEnvelope.CrossInfo = ", txEnvelope.CrossInfo)
-	//NEW add  confirmation
-	if string(txEnvelope.CrossInfo) != "singleCross" && string(txEnvelope.CrossInfo) != "multiCross" && string(txEnvelope.CrossInfo) != "local" {
-		fmt.Println("common/ledger/blkstorage/fsblkstorage/block_serialization.go extractTxID() confirmation  CrossInfo = ", txEnvelope.CrossInfo)
-		return "", nil
-	} //NEW end
-
 	txPayload, err := utils.GetPayload(txEnvelope)
 	if err != nil {
 		return "", nil
@@ -224,9 +216,5 @@ func extractTxID(txEnvelopBytes []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fmt.Println("common/ledger/blkstorage/fsblkstorage/block_serialization.go extractTxID() chdr=", chdr)
-/*	if chdr.TxId == nil{
-		return nil, nil
-	}*/
 	return chdr.TxId, nil
 }
@@ -22,8 +22,10 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/peer"
 	putil "github.com/hyperledger/fabric/protos/utils"
 )
@@ -245,3 +247,39 @@ func testBlockIndexSelectiveIndexing(t *testing.T, indexItems []blkstorage.Index
 		}
 	})
 }
+
+func TestBlockIndexConfirmations(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testledger")
+	defer blkfileMgrWrapper.close()
+	blockfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	conf := &cross.Confirmation{CrossTxId: "tx1", Channels: []string{"testledger"}, Outcome: cross.Outcome_SUCCESS}
+	confEnv, err := putil.CreateSignedConfirmation("testledger", mockcrypto.FakeLocalSigner, conf)
+	testutil.AssertNoError(t, err, "")
+	// a confirmation of the same transaction committed again is marked as a duplicate by the validator
+	conf.Outcome = cross.Outcome_FAILURE
+	replayEnv, err := putil.CreateSignedConfirmation("testledger", mockcrypto.FakeLocalSigner, conf)
+	testutil.AssertNoError(t, err, "")
+
+	blocks := testutil.ConstructTestBlocks(t, 2)
+	confBlock := testutil.NewBlock([]*common.Envelope{confEnv}, 2, blocks[1].Header.Hash())
+	replayBlock := testutil.NewBlock([]*common.Envelope{replayEnv}, 3, confBlock.Header.Hash())
+	replayBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = util.NewTxValidationFlagsSetValue(1, peer.TxValidationCode_DUPLICATE_TXID)
+	blkfileMgrWrapper.addBlocks(append(blocks, confBlock, replayBlock))
+
+	txid, err := extractTxID(confBlock.Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txid, putil.ComputeConfirmationTxID("tx1"))
+
+	txEnvelope, err := blockfileMgr.retrieveTransactionByID(txid)
+	testutil.AssertNoError(t, err, "Error while retrieving confirmation by id")
+	testutil.AssertEquals(t, txEnvelope, confEnv)
+	block, err := blockfileMgr.retrieveBlockByTxID(txid)
+	testutil.AssertNoError(t, err, "Error while retrieving block by confirmation id")
+	testutil.AssertEquals(t, block, confBlock)
+	code, err := blockfileMgr.retrieveTxValidationCodeByTxID(txid)
+	testutil.AssertNoError(t, err, "Error while retrieving confirmation validation code")
+	testutil.AssertEquals(t, code, peer.TxValidationCode_VALID)
+}
@@ -42,6 +42,10 @@ func (m *MockQueryExecutor) GetState(namespace string, key string) ([]byte, erro
 	return ns[key], nil
 }
 
+func (m *MockQueryExecutor) GetStateNoRSet(namespace string, key string) ([]byte, error) {
+	return m.GetState(namespace, key)
+}
+
 func (m *MockQueryExecutor) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
 	res, err := m.GetState(namespace, keys[0])
 	if err != nil {
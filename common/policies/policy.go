@@ -38,6 +38,9 @@ const (
 	// ChannelWriters is the label for the channel's writers policy (encompassing both orderer and application writers)
 	ChannelWriters = PathSeparator + ChannelPrefix + PathSeparator + "Writers"
 
+	// ChannelCrossCoordinators is the label for the channel's policy which authorizes the confirmations of cross transactions
+	ChannelCrossCoordinators = PathSeparator + ChannelPrefix + PathSeparator + "CrossCoordinators"
+
 	// ChannelApplicationReaders is the label for the channel's application readers policy
 	ChannelApplicationReaders = PathSeparator + ChannelPrefix + PathSeparator + ApplicationPrefix + PathSeparator + "Readers"
 
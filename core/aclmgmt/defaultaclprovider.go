@@ -74,6 +74,13 @@ func (d *defaultACLProvider) initialize() {
 	d.cResourcePolicyMap[resources.Qscc_GetBlockByHash] = CHANNELREADERS
 	d.cResourcePolicyMap[resources.Qscc_GetTransactionByID] = CHANNELREADERS
 	d.cResourcePolicyMap[resources.Qscc_GetBlockByTxID] = CHANNELREADERS
+	//NEW add
+	d.cResourcePolicyMap[resources.Qscc_GetCrossTxStatus] = CHANNELREADERS
+	d.cResourcePolicyMap[resources.Qscc_ListPendingCrossTxs] = CHANNELREADERS
+	d.cResourcePolicyMap[resources.Qscc_ListLockedKeys] = CHANNELREADERS
+	d.cResourcePolicyMap[resources.Qscc_GetConfirmationsByBlock] = CHANNELREADERS
+	d.cResourcePolicyMap[resources.Qscc_GetConfirmationByBlock] = CHANNELREADERS
+	d.cResourcePolicyMap[resources.Qscc_GetCrossLockWaits] = CHANNELREADERS
 
 	//--------------- CSCC resources -----------
 	//p resources (implemented by the chaincode currently)
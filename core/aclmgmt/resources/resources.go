@@ -27,6 +27,13 @@ const (
 	Qscc_GetBlockByHash     = "qscc/GetBlockByHash"
 	Qscc_GetTransactionByID = "qscc/GetTransactionByID"
 	Qscc_GetBlockByTxID     = "qscc/GetBlockByTxID"
+	//NEW add
+	Qscc_GetCrossTxStatus        = "qscc/GetCrossTxStatus"
+	Qscc_ListPendingCrossTxs     = "qscc/ListPendingCrossTxs"
+	Qscc_ListLockedKeys          = "qscc/ListLockedKeys"
+	Qscc_GetConfirmationsByBlock = "qscc/GetConfirmationsByBlock"
+	Qscc_GetConfirmationByBlock  = "qscc/GetConfirmationByBlock"
+	Qscc_GetCrossLockWaits       = "qscc/GetCrossLockWaits"
 
 	//Cscc resources
 	Cscc_JoinChain                = "cscc/JoinChain"
@@ -7,10 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package chaincode
 
 import (
-	"reflect"
 	"fmt"
 	//"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"io"
+	"strconv" //NEW add
 	"strings"
 	"sync"
 	"time"
@@ -27,7 +27,9 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
-	"github.com/hyperledger/fabric/peer/cross" //NEW add
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util" //NEW add
+	"github.com/hyperledger/fabric/peer/cross"                  //NEW add
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"   //NEW add
 //	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
 )
 
@@ -211,6 +213,8 @@ func (h *Handler) handleMessageReadyState(msg *pb.ChaincodeMessage) error {
 		go h.HandleTransaction(msg, h.HandleQueryStateNext)
 	case pb.ChaincodeMessage_QUERY_STATE_CLOSE:
 		go h.HandleTransaction(msg, h.HandleQueryStateClose)
+	case pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED: //NEW add
+		go h.HandleTransaction(msg, h.HandleIsKeyCrossLocked)
 
 	default:
 		return fmt.Errorf("[%s] Fabric side handler cannot handle message (%s) while in ready state", msg.Txid, msg.Type)
@@ -663,9 +667,10 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }*/
 //NEW add update
+// HandleGetState reads a key on behalf of the chaincode. A read of a key held in exclusive mode
+// by a committed cross transaction marks the simulation as cross locked. The keys read by a cross transaction
+// are locked when the transaction is committed, not here.
 func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
-	//加判断lockedKey
-	//同txid的相对不锁
 	key := string(msg.Payload)
 
 	getState := &pb.GetState{}
@@ -676,22 +681,14 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 
 	chaincodeName := h.ChaincodeName()
 	chaincodeLogger.Debugf("[%s] getting state for chaincode %s, key %s, channel %s", shorttxid(msg.Txid), chaincodeName, getState.Key, txContext.ChainID)
-	var res []byte
-	var isCross, isLocked bool
 
-	isCross, isLocked = cross.IsCrossTxLockedKey(msg.Txid, getState.Key)
-	fmt.Println("core/chaincode/handler.go HandleGetState() isCross, isLocked = ", isCross, isLocked)
-	fmt.Println("core/chaincode/handler.go HandleGetState() chaincodeName, getState.Key = ", chaincodeName, getState.Key)
-
-	if isLocked{//要读的key锁了
-		fmt.Println("core/chaincode/handler.go HandleGetState isLockedKey")
-		txContext.TXSimulator.SetCrossLocked(true) //New add 标记key被锁
-	}
+	markCrossLocked(msg.Txid, txContext, chaincodeName, getState.Collection, getState.Key, cross.SharedLock)
 
+	var res []byte
 	if isCollectionSet(getState.Collection) {
 		res, err = txContext.TXSimulator.GetPrivateData(chaincodeName, getState.Collection, getState.Key)
 	} else {
-		res, err = txContext.TXSimulator.GetState(chaincodeName, getState.Key)  // getState.Key string, res []byte
+		res, err = txContext.TXSimulator.GetState(chaincodeName, getState.Key)
 	}
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -700,23 +697,10 @@ func (h *Handler) HandleGetState(msg *pb.ChaincodeMessage, txContext *Transactio
 		chaincodeLogger.Debugf("[%s] No state associated with key: %s. Sending %s with an empty payload", shorttxid(msg.Txid), key, pb.ChaincodeMessage_RESPONSE)
 	}
 
-	/*if isCross{//没锁，是跨链
-		cross.PreCrossTxR(msg.Txid, getState.Key) // lock this key //NEW add is crosstx? maintain cross slice
-	}*/
-	if isCross{
-		switch chaincodeName {
-		case "lscc":
-			break
-		default:
-			cross.PreCrossTxR(msg.Txid, getState.Key) // lock this key //NEW add is crosstx? maintain cross slice
-		}
-	}
-
 	// Send response msg back to chaincode. GetState will not trigger event
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
-
 // Handles query to ledger to rage query state
 func (h *Handler) HandleGetStateByRange(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
 	getStateByRange := &pb.GetStateByRange{}
@@ -737,6 +721,9 @@ func (h *Handler) HandleGetStateByRange(msg *pb.ChaincodeMessage, txContext *Tra
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	//NEW add
+	rangeIter = &crossLockedIterator{ResultsIterator: rangeIter, chainID: txContext.ChainID, collection: getStateByRange.Collection,
+		txsim: txContext.TXSimulator} //NEW end
 
 	txContext.InitializeQueryContext(iterID, rangeIter)
 	payload, err := h.QueryResponseBuilder.BuildQueryResponse(txContext, rangeIter, iterID)
@@ -825,6 +812,9 @@ func (h *Handler) HandleGetQueryResult(msg *pb.ChaincodeMessage, txContext *Tran
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	//NEW add
+	executeIter = &crossLockedIterator{ResultsIterator: executeIter, chainID: txContext.ChainID, collection: getQueryResult.Collection,
+		txsim: txContext.TXSimulator} //NEW end
 
 	txContext.InitializeQueryContext(iterID, executeIter)
 
@@ -844,6 +834,70 @@ func (h *Handler) HandleGetQueryResult(msg *pb.ChaincodeMessage, txContext *Tran
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
+// NEW add
+// crossLockedIterator marks the simulation as cross locked when a query returns a key held in exclusive mode
+// by a committed cross transaction. The keys returned by a query are locked, along with the range of a range
+// query, when a cross transaction is committed, not here.
+type crossLockedIterator struct {
+	commonledger.ResultsIterator
+	chainID    string
+	collection string
+	txsim      ledger.TxSimulator
+}
+
+// Next implements method in interface commonledger.ResultsIterator
+func (it *crossLockedIterator) Next() (commonledger.QueryResult, error) {
+	res, err := it.ResultsIterator.Next()
+	if kv, ok := res.(*queryresult.KV); ok {
+		ns, key := crossLockKey(kv.Namespace, it.collection, kv.Key)
+		if cross.GetLockManager().IsLocked(it.chainID, ns, key, cross.SharedLock) {
+			chaincodeLogger.Debugf("key %s of chaincode %s returned by a query is locked by a cross transaction", kv.Key, kv.Namespace)
+			it.txsim.SetCrossLocked(true)
+		}
+	}
+	return res, err
+}
+
+// markCrossLocked marks the simulation as cross locked when the given key, of the given collection if set,
+// is held by a committed cross transaction in a mode conflicting with the given one
+func markCrossLocked(txid string, txContext *TransactionContext, ns, coll, key string, mode cross.LockMode) {
+	lockNs, lockKey := crossLockKey(ns, coll, key)
+	if cross.GetLockManager().IsLocked(txContext.ChainID, lockNs, lockKey, mode) {
+		chaincodeLogger.Debugf("[%s] key %s of chaincode %s is locked by a cross transaction", shorttxid(txid), key, ns)
+		txContext.TXSimulator.SetCrossLocked(true)
+	}
+}
+
+// crossLockKey returns the namespace and the key under which the lock table holds the given key,
+// of the given collection if set. The keys of a collection are held by their hash.
+func crossLockKey(ns, coll, key string) (string, string) {
+	if !isCollectionSet(coll) {
+		return ns, key
+	}
+	ck := cross.CollectionKey(ns, coll, ledgerutil.ComputeStringHash(key))
+	return ck.Namespace, ck.Key
+}
+
+// HandleIsKeyCrossLocked tells the chaincode whether a key, of a collection if set, is held by a committed
+// cross transaction, in which case writing the key would fail the proposal. The answer is a hint only, as
+// the key may be released or locked by the time the transaction is committed, and the simulation is not
+// marked as cross locked.
+func (h *Handler) HandleIsKeyCrossLocked(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
+	getState := &pb.GetState{}
+	err := proto.Unmarshal(msg.Payload, getState)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	lockNs, lockKey := crossLockKey(h.ChaincodeName(), getState.Collection, getState.Key)
+	locked := cross.GetLockManager().IsLocked(txContext.ChainID, lockNs, lockKey, cross.ExclusiveLock)
+	chaincodeLogger.Debugf("[%s] key %s of chaincode %s held by a cross transaction: %t", shorttxid(msg.Txid), getState.Key, h.ChaincodeName(), locked)
+
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: []byte(strconv.FormatBool(locked)), Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
+}
+
+//NEW end
+
 // Handles query to ledger history db
 func (h *Handler) HandleGetHistoryForKey(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
 	iterID := h.UUIDGenerator.New()
@@ -1030,74 +1084,28 @@ fmt.Println("HandlePutState!!!!!!  core/chaincode/handler.go HandlePutState()")
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }*/
 //NEW add update 与上版不同在于 locked key 不影响simulate过程，只标注TxSimulator.CrossLocked=true
+// HandlePutState writes a key on behalf of the chaincode. A write of a key held, in any mode, by a
+// committed cross transaction marks the simulation as cross locked. The keys written by a cross transaction
+// are locked, and their original values recorded, when the transaction is committed, not here.
 func (h *Handler) HandlePutState(msg *pb.ChaincodeMessage, txContext *TransactionContext) (*pb.ChaincodeMessage, error) {
-	fmt.Println("HandlePutState!!!!!!  core/chaincode/handler.go HandlePutState()")
 	putState := &pb.PutState{}
 	err := proto.Unmarshal(msg.Payload, putState)
 	if err != nil {
 		return nil, errors.Wrap(err, "unmarshal failed")
 	}
-	fmt.Println("HandlePutState!!!!! Unmarshal之后")
 
 	chaincodeName := h.ChaincodeName()
-	isCross, isLocked := cross.IsCrossTxLockedKey(msg.Txid, putState.Key)
-	fmt.Println("HandlePueState!!!!! if之前 isCross= ; isLocked=", isCross, isLocked)
-	fmt.Println("HandlePutState chaincodeName, putState.Key = ", chaincodeName, putState.Key)
+	markCrossLocked(msg.Txid, txContext, chaincodeName, putState.Collection, putState.Key, cross.ExclusiveLock)
 
-	if isLocked{ //lock 返回
-		fmt.Println("HandlePutState!!!!! isLocked")
-	//	txContext.CrossLocked = true
-		txContext.TXSimulator.SetCrossLocked(true) //NEW add
+	if isCollectionSet(putState.Collection) {
+		err = txContext.TXSimulator.SetPrivateData(chaincodeName, putState.Collection, putState.Key, putState.Value)
+	} else {
+		err = txContext.TXSimulator.SetState(chaincodeName, putState.Key, putState.Value)
 	}
-	if isCross{ //如果是跨链tx，存origVal
-		fmt.Println("core/chaincode/handler.go  HandlePutState() IsCross")
-		//origVal, _ := txContext.TXSimulator.GetStateNoRSet(chaincodeName, putState.Key) //origVal []byte,可以为nil  NEW add 此查询originalKey不计入读写集
-		fmt.Println("马上GetStateNoRSet chaincodeName, putState.Key = ", chaincodeName, putState.Key)
-		//origVal := &statedb.VersionedValue{Version: nil}
-		fmt.Println("Typeof putState = ", reflect.TypeOf(putState))
-		fmt.Println("Typeof putState.Key = ", reflect.TypeOf(putState.Key))
-		fmt.Println("Typeof txContext.CrossGetInterface = ", reflect.TypeOf(txContext.CrossGetInterface))
-		fmt.Println("txContext.CrossGetInterface = ", txContext.CrossGetInterface)
-		origValByte, _ := txContext.TXSimulator.GetStateNoRSet(chaincodeName, putState.Key) //origVal []byte,可以为nil  NEW add 此查询originalKey不计入读写集
-		//origValByte, _ := txContext.CrossGetInterface.GetStateNoRSet(chaincodeName, putState.Key) //origVal []byte,可以为nil  NEW add 此查询originalKey不计入读写集
-		fmt.Println("core/chaincode/handler.go HandlePutState().GetStateNoRSet()完成 origVal = ", origValByte)
-
-
-	/*	var origVal *statedb.VersionedValue
-		if origValByte != nil{
-			val, ver := stateleveldb.DecodeValue(origValByte)
-			origVal = &statedb.VersionedValue{Value: val, Version: ver}
-		}
-*/
-		if isCollectionSet(putState.Collection) {
-			err = txContext.TXSimulator.SetPrivateData(chaincodeName, putState.Collection, putState.Key, putState.Value)
-		} else {
-			err = txContext.TXSimulator.SetState(chaincodeName, putState.Key, putState.Value)
-		}
-		if err != nil {
-			fmt.Println("core/chiancode/handler.go HandlePutState() isCross SetState ERROR")
-			return nil, errors.WithStack(err)
-		}
-		//cross.PreCrossTxW(msg.Txid, chaincodeName, putState.Key, origVal)  // NEW add 跨链写的key:origVal存一下
-		switch chaincodeName {
-		case "lscc":
-			break
-		default:
-			cross.PreCrossTxW(msg.Txid, chaincodeName, putState.Key, origValByte)  // NEW add 跨链写的key:origVal存一下
-		}
-	}else{ //不是跨链tx
-		fmt.Println("core/chaincode/handler.go  HandlePutState() IsNotCross")
-		if isCollectionSet(putState.Collection) {
-			err = txContext.TXSimulator.SetPrivateData(chaincodeName, putState.Collection, putState.Key, putState.Value)
-		} else {
-			err = txContext.TXSimulator.SetState(chaincodeName, putState.Key, putState.Value)
-		}
-		if err != nil {
-			fmt.Println("core/chiancode/handler.go HandlePutState() NOTCross SetState ERROR")
-			return nil, errors.WithStack(err)
-		}
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
-	fmt.Println("HandlePutState!!!!! return之前")
+
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: msg.Txid, ChannelId: msg.ChannelId}, nil
 }
 
@@ -1109,6 +1117,7 @@ func (h *Handler) HandleDelState(msg *pb.ChaincodeMessage, txContext *Transactio
 	}
 
 	chaincodeName := h.ChaincodeName()
+	markCrossLocked(msg.Txid, txContext, chaincodeName, delState.Collection, delState.Key, cross.ExclusiveLock) //NEW add
 	if isCollectionSet(delState.Collection) {
 		err = txContext.TXSimulator.DeletePrivateData(chaincodeName, delState.Collection, delState.Key)
 	} else {
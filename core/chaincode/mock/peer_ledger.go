@@ -221,6 +221,17 @@ type PeerLedger struct {
 		result1 ledger.ConfigHistoryRetriever
 		result2 error
 	}
+	GetCrossTxQuerierStub        func() (ledger.CrossTxQuerier, error)
+	getCrossTxQuerierMutex       sync.RWMutex
+	getCrossTxQuerierArgsForCall []struct{}
+	getCrossTxQuerierReturns     struct {
+		result1 ledger.CrossTxQuerier
+		result2 error
+	}
+	getCrossTxQuerierReturnsOnCall map[int]struct {
+		result1 ledger.CrossTxQuerier
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -1066,6 +1077,49 @@ func (fake *PeerLedger) GetConfigHistoryRetrieverReturnsOnCall(i int, result1 le
 	}{result1, result2}
 }
 
+func (fake *PeerLedger) GetCrossTxQuerier() (ledger.CrossTxQuerier, error) {
+	fake.getCrossTxQuerierMutex.Lock()
+	ret, specificReturn := fake.getCrossTxQuerierReturnsOnCall[len(fake.getCrossTxQuerierArgsForCall)]
+	fake.getCrossTxQuerierArgsForCall = append(fake.getCrossTxQuerierArgsForCall, struct{}{})
+	fake.recordInvocation("GetCrossTxQuerier", []interface{}{})
+	fake.getCrossTxQuerierMutex.Unlock()
+	if fake.GetCrossTxQuerierStub != nil {
+		return fake.GetCrossTxQuerierStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getCrossTxQuerierReturns.result1, fake.getCrossTxQuerierReturns.result2
+}
+
+func (fake *PeerLedger) GetCrossTxQuerierCallCount() int {
+	fake.getCrossTxQuerierMutex.RLock()
+	defer fake.getCrossTxQuerierMutex.RUnlock()
+	return len(fake.getCrossTxQuerierArgsForCall)
+}
+
+func (fake *PeerLedger) GetCrossTxQuerierReturns(result1 ledger.CrossTxQuerier, result2 error) {
+	fake.GetCrossTxQuerierStub = nil
+	fake.getCrossTxQuerierReturns = struct {
+		result1 ledger.CrossTxQuerier
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PeerLedger) GetCrossTxQuerierReturnsOnCall(i int, result1 ledger.CrossTxQuerier, result2 error) {
+	fake.GetCrossTxQuerierStub = nil
+	if fake.getCrossTxQuerierReturnsOnCall == nil {
+		fake.getCrossTxQuerierReturnsOnCall = make(map[int]struct {
+			result1 ledger.CrossTxQuerier
+			result2 error
+		})
+	}
+	fake.getCrossTxQuerierReturnsOnCall[i] = struct {
+		result1 ledger.CrossTxQuerier
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -1105,6 +1159,8 @@ func (fake *PeerLedger) Invocations() map[string][][]interface{} {
 	defer fake.pruneMutex.RUnlock()
 	fake.getConfigHistoryRetrieverMutex.RLock()
 	defer fake.getConfigHistoryRetrieverMutex.RUnlock()
+	fake.getCrossTxQuerierMutex.RLock()
+	defer fake.getCrossTxQuerierMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
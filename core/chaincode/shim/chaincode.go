@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/protos/ledger/cross" // NEW add
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -65,6 +66,8 @@ type ChaincodeStub struct {
 	binding   []byte
 
 	decorations map[string][]byte
+
+	crossInfo *cross.CrossInfo // NEW add
 }
 
 // Peer address derived from command line or env var
@@ -375,6 +378,14 @@ func (stub *ChaincodeStub) init(handler *Handler, channelId string, txid string,
 		}
 	}
 
+	//NEW add
+	var err error
+	stub.crossInfo, err = utils.UnmarshalCrossInfo(signedProposal.GetCrossMsgBytes())
+	if err != nil {
+		return errors.WithMessage(err, "failed extracting cross info from signedProposal")
+	}
+	//NEW end
+
 	return nil
 }
 
@@ -392,6 +403,38 @@ func (stub *ChaincodeStub) GetDecorations() map[string][]byte {
 	return stub.decorations
 }
 
+// NEW add
+// GetCrossMode documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetCrossMode() cross.CrossMode {
+	return stub.crossInfo.GetMode()
+}
+
+// GetCrossTxID documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetCrossTxID() string {
+	return crossTxID(stub.crossInfo, stub.TxID)
+}
+
+// IsKeyCrossLocked documentation can be found in interfaces.go
+func (stub *ChaincodeStub) IsKeyCrossLocked(key string) (bool, error) {
+	if key == "" {
+		return false, errors.New("key must not be an empty string")
+	}
+	return stub.handler.handleIsKeyCrossLocked(key, stub.ChannelId, stub.TxID)
+}
+
+// crossTxID returns the global ID of a cross transaction, which is the ID of the transaction unless
+// the client set it, or an empty string for a transaction which is not a cross transaction
+func crossTxID(info *cross.CrossInfo, txid string) string {
+	mode := info.GetMode()
+	if mode != cross.CrossMode_SINGLE_CROSS && mode != cross.CrossMode_MULTI_CROSS {
+		return ""
+	}
+	if info.CrossTxId != "" {
+		return info.CrossTxId
+	}
+	return txid
+}
+
 // ------------- Call Chaincode functions ---------------
 
 // InvokeChaincode documentation can be found in interfaces.go
@@ -8,6 +8,7 @@ package shim
 
 import (
 	"fmt"
+	"strconv" // NEW add
 	"sync"
 
 	"github.com/golang/protobuf/proto"
@@ -330,6 +331,39 @@ func (handler *Handler) handleGetState(collection string, key string, channelId
 	return nil, errors.Errorf("[%s] incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
 }
 
+// NEW add
+// handleIsKeyCrossLocked asks the peer whether a key is held by a committed cross transaction.
+func (handler *Handler) handleIsKeyCrossLocked(key string, channelId string, txid string) (bool, error) {
+	payloadBytes, _ := proto.Marshal(&pb.GetState{Key: key})
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED, Payload: payloadBytes, Txid: txid, ChannelId: channelId}
+	chaincodeLogger.Debugf("[%s] Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED)
+
+	responseMsg, err := handler.callPeerWithChaincodeMsg(msg, channelId, txid)
+	if err != nil {
+		return false, errors.WithMessage(err, fmt.Sprintf("[%s] error sending %s", shorttxid(txid), pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s] IsKeyCrossLocked received payload %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+		locked, err := strconv.ParseBool(string(responseMsg.Payload))
+		if err != nil {
+			return false, errors.Wrapf(err, "[%s] unexpected payload received from peer", shorttxid(responseMsg.Txid))
+		}
+		return locked, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s] IsKeyCrossLocked received error %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return false, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("[%s] Incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return false, errors.Errorf("[%s] incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+}
+
 // TODO: Implement a method to set multiple keys at a time [FAB-1244]
 // handlePutState communicates with the peer to put state information into the ledger.
 func (handler *Handler) handlePutState(collection string, key string, value []byte, channelId string, txid string) error {
@@ -9,6 +9,7 @@ package shim
 import (
 	"github.com/golang/protobuf/ptypes/timestamp"
 
+	"github.com/hyperledger/fabric/protos/ledger/cross" // NEW add
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
@@ -260,6 +261,26 @@ type ChaincodeStubInterface interface {
 	// available within the transaction in the committed block regardless of the
 	// validity of the transaction.
 	SetEvent(name string, payload []byte) error
+
+	// NEW add
+	// GetCrossMode returns the cross-chain mode the client set on the signed
+	// proposal: LOCAL for a transaction of this channel only, SINGLE_CROSS or
+	// MULTI_CROSS for a cross transaction, whose keys stay locked from its
+	// commit until its confirmation.
+	GetCrossMode() cross.CrossMode
+
+	// GetCrossTxID returns the global ID of the cross transaction, which is the
+	// ID of the transaction on every participant channel, or an empty string if
+	// the proposal is not a cross transaction.
+	GetCrossTxID() string
+
+	// IsKeyCrossLocked returns true if the given key is held by a committed
+	// cross transaction which is not confirmed yet, in which case a proposal
+	// writing the key fails. The answer may be outdated by the time the
+	// transaction is committed, and checking a key does not add it to the
+	// read set of the transaction.
+	IsKeyCrossLocked(key string) (bool, error)
+	// NEW end
 }
 
 // CommonIteratorInterface allows a chaincode to check whether any more result
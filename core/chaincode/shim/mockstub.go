@@ -13,10 +13,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 	"github.com/pkg/errors"
 )
@@ -62,6 +65,9 @@ type MockStub struct {
 
 	// channel to store ChaincodeEvents
 	ChaincodeEventsChannel chan *pb.ChaincodeEvent
+
+	// CrossLockedKeys are the keys reported as held by cross transactions
+	CrossLockedKeys map[string]bool
 }
 
 func (stub *MockStub) GetTxID() string {
@@ -150,6 +156,44 @@ func (stub *MockStub) MockInvokeWithSignedProposal(uuid string, args [][]byte, s
 	return res
 }
 
+// Invoke this chaincode as a cross transaction described by the given cross info,
+// also starts and ends a transaction.
+func (stub *MockStub) MockCrossInvoke(uuid string, args [][]byte, info *cross.CrossInfo) pb.Response {
+	crossMsgBytes, err := proto.Marshal(info)
+	if err != nil {
+		return Error(fmt.Sprintf("failed to marshal cross info: %s", err))
+	}
+	return stub.MockInvokeWithSignedProposal(uuid, args, &pb.SignedProposal{CrossMsgBytes: crossMsgBytes})
+}
+
+// GetCrossMode returns the cross mode set on the mocked signedProposal, LOCAL if none is set
+func (stub *MockStub) GetCrossMode() cross.CrossMode {
+	return stub.crossInfo().GetMode()
+}
+
+// GetCrossTxID returns the cross transaction ID set on the mocked signedProposal, the
+// transaction ID if none is set, or an empty string if the transaction is not a cross transaction
+func (stub *MockStub) GetCrossTxID() string {
+	return crossTxID(stub.crossInfo(), stub.TxID)
+}
+
+// IsKeyCrossLocked returns true if the key is one of the CrossLockedKeys of the stub
+func (stub *MockStub) IsKeyCrossLocked(key string) (bool, error) {
+	if key == "" {
+		return false, errors.New("key must not be an empty string")
+	}
+	return stub.CrossLockedKeys[key], nil
+}
+
+func (stub *MockStub) crossInfo() *cross.CrossInfo {
+	info, err := utils.UnmarshalCrossInfo(stub.signedProposal.GetCrossMsgBytes())
+	if err != nil {
+		mockLogger.Errorf("Invalid cross info set on the signedProposal: %s", err)
+		return nil
+	}
+	return info
+}
+
 func (stub *MockStub) GetPrivateData(collection string, key string) ([]byte, error) {
 	m, in := stub.PvtState[collection]
 
@@ -380,6 +424,7 @@ func NewMockStub(name string, cc Chaincode) *MockStub {
 	s.PvtState = make(map[string]map[string][]byte)
 	s.Invokables = make(map[string]*MockStub)
 	s.Keys = list.New()
+	s.CrossLockedKeys = make(map[string]bool)
 	s.ChaincodeEventsChannel = make(chan *pb.ChaincodeEvent, 100) //define large capacity for non-blocking setEvent calls.
 
 	return s
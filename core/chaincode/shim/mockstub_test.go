@@ -23,7 +23,9 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMockStateRangeQueryIterator(t *testing.T) {
@@ -235,6 +237,24 @@ func TestGetTxTimestamp(t *testing.T) {
 	stub.MockTransactionEnd("init")
 }
 
+func TestMockCrossInvoke(t *testing.T) {
+	stub := NewMockStub("MockCrossInvoke", &shimTestCC{})
+	stub.CrossLockedKeys["A"] = true
+	args := [][]byte{[]byte("crossq"), []byte("A")}
+
+	res := stub.MockInvoke("tx1", args)
+	assert.Equal(t, `LOCAL "" true`, string(res.Payload))
+
+	res = stub.MockCrossInvoke("tx2", [][]byte{[]byte("crossq"), []byte("B")}, &cross.CrossInfo{Mode: cross.CrossMode_SINGLE_CROSS})
+	assert.Equal(t, `SINGLE_CROSS "tx2" false`, string(res.Payload))
+
+	res = stub.MockCrossInvoke("tx3", args, &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "tx3", Channels: []string{"ch1", "ch2"}})
+	assert.Equal(t, `MULTI_CROSS "tx3" true`, string(res.Payload))
+
+	res = stub.MockInvoke("tx4", [][]byte{[]byte("crossq"), []byte("")})
+	assert.Equal(t, int32(ERROR), res.Status)
+}
+
 //TestMockMock clearly cheating for coverage... but not. Mock should
 //be tucked away under common/mocks package which is not
 //included for coverage. Moving mockstub to another package
@@ -18,6 +18,7 @@ package shim
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -94,6 +95,8 @@ func (t *shimTestCC) Invoke(stub ChaincodeStubInterface) pb.Response {
 		return t.historyq(stub, args)
 	} else if function == "richq" {
 		return t.richq(stub, args)
+	} else if function == "crossq" {
+		return t.crossq(stub, args)
 	}
 
 	return Error("Invalid invoke function name. Expecting \"invoke\" \"delete\" \"query\"")
@@ -349,6 +352,20 @@ func (t *shimTestCC) historyq(stub ChaincodeStubInterface, args []string) pb.Res
 	return Success(buffer.Bytes())
 }
 
+// crossq checks whether a key is held by a cross transaction
+func (t *shimTestCC) crossq(stub ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) < 1 {
+		return Error("Incorrect number of arguments. Expecting 1")
+	}
+
+	locked, err := stub.IsKeyCrossLocked(args[0])
+	if err != nil {
+		return Error(err.Error())
+	}
+
+	return Success([]byte(fmt.Sprintf("%s %q %t", stub.GetCrossMode(), stub.GetCrossTxID(), locked)))
+}
+
 // Test Go shim functionality that can be tested outside of a real chaincode
 // context.
 
@@ -832,6 +849,32 @@ func TestInvoke(t *testing.T) {
 	//wait for done
 	processDone(t, done, false)
 
+	//cross locked key
+	respSet = &mockpeer.MockResponseSet{errorFunc, errorFunc, []*mockpeer.MockResponse{
+		{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED, Txid: "9", ChannelId: channelId}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: []byte("true"), Txid: "9", ChannelId: channelId}},
+		{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "9", ChannelId: channelId}, nil}}}
+	peerSide.SetResponses(respSet)
+
+	ci = &pb.ChaincodeInput{Args: [][]byte{[]byte("crossq"), []byte("A")}, Decorations: nil}
+	payload = utils.MarshalOrPanic(ci)
+	peerSide.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: payload, Txid: "9", ChannelId: channelId})
+
+	//wait for done
+	processDone(t, done, false)
+
+	//cross locked key error
+	respSet = &mockpeer.MockResponseSet{errorFunc, errorFunc, []*mockpeer.MockResponse{
+		{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_IS_KEY_CROSS_LOCKED, Txid: "9a", ChannelId: channelId}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: nil, Txid: "9a", ChannelId: channelId}},
+		{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "9a", ChannelId: channelId}, nil}}}
+	peerSide.SetResponses(respSet)
+
+	ci = &pb.ChaincodeInput{Args: [][]byte{[]byte("crossq"), []byte("A")}, Decorations: nil}
+	payload = utils.MarshalOrPanic(ci)
+	peerSide.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: payload, Txid: "9a", ChannelId: channelId})
+
+	//wait for done
+	processDone(t, done, false)
+
 	time.Sleep(1 * time.Second)
 	peerSide.Quit()
 }
@@ -40,7 +40,7 @@ func testValidationWithNTXes(t *testing.T, ledger ledger2.PeerLedger, gbHash []b
 	simulator.SetState("ns1", "key3", []byte("value3"))
 	simulator.Done()
 
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimulationResBytes, _ := simRes.GetPubSimulationBytes()
 	_, err := testutil.ConstructBytesProposalResponsePayload("v1", pubSimulationResBytes)
 	if err != nil {
@@ -114,7 +114,7 @@ func TestBlockValidationDuplicateTXId(t *testing.T) {
 	simulator.SetState("ns1", "key3", []byte("value3"))
 	simulator.Done()
 
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimulationResBytes, _ := simRes.GetPubSimulationBytes()
 	_, err := testutil.ConstructBytesProposalResponsePayload("v1", pubSimulationResBytes)
 	if err != nil {
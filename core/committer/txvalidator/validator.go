@@ -15,12 +15,14 @@ import (
 	"github.com/hyperledger/fabric/common/configtx"
 	commonerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/common/validation"
 	"github.com/hyperledger/fabric/core/ledger"
 	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -52,6 +54,9 @@ type Support interface {
 
 	// Capabilities defines the capabilities for the application portion of this channel
 	Capabilities() channelconfig.ApplicationCapabilities
+
+	// PolicyManager returns the policy manager of the channel, which holds the cross coordinators policy
+	PolicyManager() policies.Manager //NEW add
 }
 
 //Validator interface which defines API to validate block transactions
@@ -98,6 +103,7 @@ type blockValidationResult struct {
 	err                  error
 	txid                 string
 	CrossInfo            []byte
+	crossTxID            string //NEW add  the cross transaction confirmed by a confirmation
 }
 
 // NewTxValidator creates new transactions validator
@@ -138,7 +144,9 @@ func (v *TxValidator) chainExists(chain string) bool {
 func (v *TxValidator) Validate(block *common.Block) error {
 	var err error
 	var errPos int
-	var blkType string
+	var confirmations []int //NEW add  indexes of the confirmations of the block
+	//NEW add  cross transactions confirmed by the valid confirmations
+	confirmedTxIDs := make(map[int]string)
 
 	startValidation := time.Now() // timer to log Validate block duration
 	logger.Debugf("[%s] START Block Validation for block [%d]", v.ChainID, block.Header.Number)
@@ -173,7 +181,6 @@ func (v *TxValidator) Validate(block *common.Block) error {
 
 	logger.Debugf("expecting %d block validation responses", len(block.Data.Data))
 
-	isCross := false
 	// now we read responses in the order in which they come back
 	for i := 0; i < len(block.Data.Data); i++ {
 		res := <-results
@@ -189,77 +196,79 @@ func (v *TxValidator) Validate(block *common.Block) error {
 				errPos = res.tIdx
 			}
 		} else {
-			if string(res.CrossInfo) == "singleCross" || string(res.CrossInfo) == "multiCross" { //NEW add
-				fmt.Println("core/commiter/txvalidator/validator.go singleCross || multiCross类型")
-				isCross = true
-			} else if string(res.CrossInfo) == "local" {
-				fmt.Println("core/commiter/txvalidator/validator.go local类型")
-			} else if string(res.CrossInfo) == "confirmation"{ // confirmation类型 res.CrossInfo是confirmation信息  env.CrossInfo
-				fmt.Println("core/commiter/txvalidator/validator.go confirmation类型 res.CrossInfo = ", string(res.CrossInfo))
-			//	confirmation := res.CrossInfo
-			//	block.Data.Data[0] = confirmation
-				blkType = "ConfirmationBlock"
-			}
-			if blkType != "ConfirmationBlock" {  // NEW end
-				// if there was no error, we set the txsfltr and we set the
-				// txsChaincodeNames and txsUpgradedChaincodes maps
-				logger.Debugf("got result for idx %d, code %d", res.tIdx, res.validationCode)
+			crossInfo, _ := utils.UnmarshalCrossInfo(res.CrossInfo) //NEW add
+			if crossInfo.GetMode() == cross.CrossMode_CONFIRMATION {
+				confirmations = append(confirmations, res.tIdx)
+				if res.validationCode == peer.TxValidationCode_VALID {
+					confirmedTxIDs[res.tIdx] = res.crossTxID
+				}
+			} // NEW end
 
-				txsfltr.SetFlag(res.tIdx, res.validationCode)
+			// if there was no error, we set the txsfltr and we set the
+			// txsChaincodeNames and txsUpgradedChaincodes maps
+			logger.Debugf("got result for idx %d, code %d", res.tIdx, res.validationCode)
 
-				if res.validationCode == peer.TxValidationCode_VALID {
-					if res.txsChaincodeName != nil {
-						txsChaincodeNames[res.tIdx] = res.txsChaincodeName
-					}
-					if res.txsUpgradedChaincode != nil {
-						txsUpgradedChaincodes[res.tIdx] = res.txsUpgradedChaincode
-					}
-					txidArray[res.tIdx] = res.txid
+			txsfltr.SetFlag(res.tIdx, res.validationCode)
+
+			if res.validationCode == peer.TxValidationCode_VALID {
+				if res.txsChaincodeName != nil {
+					txsChaincodeNames[res.tIdx] = res.txsChaincodeName
+				}
+				if res.txsUpgradedChaincode != nil {
+					txsUpgradedChaincodes[res.tIdx] = res.txsUpgradedChaincode
 				}
+				txidArray[res.tIdx] = res.txid
 			}
 		}
+	}
 
+	// if we're here, all workers have completed the validation.
+	// If there was an error we return the error from the first
+	// tx in this block that returned an error
+	if err != nil {
+		return err
+	}
 
-		if blkType != "ConfirmationBlock" {// NEW add
-
-			// if we're here, all workers have completed the validation.
-			// If there was an error we return the error from the first
-			// tx in this block that returned an error
-			if err != nil {
-				return err
-			}
+	// if we operate with this capability, we mark invalid any transaction that has a txid
+	// which is equal to that of a previous tx in this block
+	if v.Support.Capabilities().ForbidDuplicateTXIdInBlock() {
+		markTXIdDuplicates(txidArray, txsfltr)
+	}
 
-			// if we operate with this capability, we mark invalid any transaction that has a txid
-			// which is equal to that of a previous tx in this block
-			if v.Support.Capabilities().ForbidDuplicateTXIdInBlock() {
-				markTXIdDuplicates(txidArray, txsfltr)
-			}
+	// if we're here, all workers have completed validation and
+	// no error was reported; we set the tx filter and return
+	// success
+	v.invalidTXsForUpgradeCC(txsChaincodeNames, txsUpgradedChaincodes, txsfltr)
 
-			// if we're here, all workers have completed validation and
-			// no error was reported; we set the tx filter and return
-			// success
-			v.invalidTXsForUpgradeCC(txsChaincodeNames, txsUpgradedChaincodes, txsfltr)
+	// make sure no transaction has skipped validation
+	err = v.allValidated(txsfltr, block)
+	if err != nil {
+		return err
+	}
 
-			// make sure no transaction has skipped validation
-			err = v.allValidated(txsfltr, block)
-			if err != nil {
-				return err
-			}
+	//NEW add
+	// the confirmations of a block are committed apart from the other transactions, hence, should the orderer
+	// mix them, which it never does, they are invalidated
+	isConfirmation := len(confirmations) > 0 && len(confirmations) == len(block.Data.Data)
+	if !isConfirmation {
+		for _, tIdx := range confirmations {
+			logger.Warningf("[%s] Invalidating confirmation %d of block [%d] mixed with other transactions", v.ChainID, tIdx, block.Header.Number)
+			txsfltr.SetFlag(tIdx, peer.TxValidationCode_INVALID_OTHER_REASON)
 		}
-	}
+	} else {
+		markConfirmationDuplicates(confirmedTxIDs, txsfltr)
+	} //NEW end
 
 	// Initialize metadata structure
 	utils.InitBlockMetadata(block)
 
 	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsfltr
-	if isCross{ //NEW add
-		block.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO] = []byte("crosstx")
-	}else {
-		block.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO] = []byte("normal")
-	}
-	if blkType == "ConfirmationBlock" {
-		block.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO] = []byte("confirmation")
-	}//NEW end
+	// NEW add
+	// The classification of the block is derived from its envelopes when committing it. Earlier versions wrote it
+	// to the metadata of the block, which is covered by no hash, and it is cleared when the block is validated again.
+	if utils.ClearLegacyCrossInfo(block) {
+		logger.Debugf("[%s] Cleared legacy cross info metadata of block [%d]", v.ChainID, block.Header.Number)
+	} //NEW end
 
 	elapsedValidation := time.Since(startValidation) / time.Millisecond // duration in ms
 	logger.Infof("[%s] Validated block [%d] in %dms", v.ChainID, block.Header.Number, elapsedValidation)
@@ -267,6 +276,169 @@ func (v *TxValidator) Validate(block *common.Block) error {
 	return nil
 }
 
+// NEW add
+// validateConfirmation checks that the txid of a confirmation is the one derived from its cross transaction, that
+// the confirmation is signed by its coordinator, and that the transaction is prepared on the channel. A confirmation of a transaction committed or aborted already,
+// whether a replay or a confirmation of the other outcome, is a no-op invalidated as a duplicate confirmation.
+// A failure confirmation of a transaction unknown to the channel is valid, and aborts the transaction in advance,
+// which is invalidated if it is committed later on, while a success confirmation of such a transaction is invalid.
+// Confirmations created before confirmations had a txid carry none, and are not checked for duplicate txids.
+func (v *TxValidator) validateConfirmation(tIdx int, env *common.Envelope) *blockValidationResult {
+	res := &blockValidationResult{tIdx: tIdx, CrossInfo: env.CrossInfo}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil {
+		logger.Warningf("[%s] Invalid payload of confirmation %d", v.ChainID, tIdx)
+		res.validationCode = peer.TxValidationCode_BAD_PAYLOAD
+		return res
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		logger.Warningf("[%s] Invalid channel header of confirmation %d: %s", v.ChainID, tIdx, err)
+		res.validationCode = peer.TxValidationCode_BAD_CHANNEL_HEADER
+		return res
+	}
+	conf, err := utils.UnmarshalConfirmation(payload.Data)
+	if err != nil {
+		logger.Warningf("[%s] Invalid confirmation %d: %s", v.ChainID, tIdx, err)
+		res.validationCode = peer.TxValidationCode_BAD_PAYLOAD
+		return res
+	}
+	if err := utils.CheckConfirmationTxID(chdr.TxId, conf); err != nil {
+		logger.Warningf("[%s] Invalid confirmation %d: %s", v.ChainID, tIdx, err)
+		res.validationCode = peer.TxValidationCode_BAD_PROPOSAL_TXID
+		return res
+	}
+	if err := v.checkCoordinatorSignature(conf); err != nil {
+		logger.Warningf("[%s] Invalid confirmation %d: %s", v.ChainID, tIdx, err)
+		res.validationCode = peer.TxValidationCode_BAD_CREATOR_SIGNATURE
+		return res
+	}
+	res.crossTxID = conf.CrossTxId
+
+	// the state of the cross transaction is derived from the committed blocks, so that every peer of the channel
+	// applies the same confirmations
+	querier, err := v.Support.Ledger().GetCrossTxQuerier()
+	if err != nil {
+		return &blockValidationResult{tIdx: tIdx, err: err}
+	}
+	status, err := querier.GetCrossTxStatus(conf.CrossTxId)
+	if err != nil {
+		logger.Errorf("Ledger failure while attempting to get the state of cross transaction [%s], err '%s'. Aborting", conf.CrossTxId, err)
+		return &blockValidationResult{tIdx: tIdx, err: err}
+	}
+	switch status.State {
+	case cross.CrossTxState_PREPARED:
+	case cross.CrossTxState_COMMITTED, cross.CrossTxState_ABORTED:
+		logger.Warningf("[%s] Confirmation %d of cross transaction [%s] already %s in block [%d], skipping",
+			v.ChainID, tIdx, conf.CrossTxId, status.State, status.ConfirmationBlock)
+		res.validationCode = peer.TxValidationCode_DUPLICATE_CONFIRMATION
+		return res
+	default:
+		// A failure confirmation of a cross transaction the channel never prepared is valid, and records the
+		// transaction as aborted, so that its prepare, if ordered later, is rejected as already aborted. This
+		// deviates from two-phase commit, where a participant only learns the outcome of a transaction it voted
+		// on: the coordinator may abort a transaction before its prepare reaches the channel, as when it times
+		// out, and the channel presumes the abort rather than wait for a prepare which must not commit.
+		if conf.Outcome != cross.Outcome_FAILURE {
+			logger.Warningf("[%s] Confirmation %d of cross transaction [%s] not prepared on the channel, skipping", v.ChainID, tIdx, conf.CrossTxId)
+			res.validationCode = peer.TxValidationCode_INVALID_OTHER_REASON
+			return res
+		}
+		logger.Infof("[%s] Cross transaction [%s] not prepared on the channel aborted by confirmation %d", v.ChainID, conf.CrossTxId, tIdx)
+	}
+	if chdr.TxId == "" {
+		res.validationCode = peer.TxValidationCode_VALID
+		return res
+	}
+
+	_, err = v.Support.Ledger().GetTransactionByID(chdr.TxId)
+	if err == nil {
+		logger.Warningf("[%s] Duplicate confirmation %s of cross transaction [%s], skipping", v.ChainID, chdr.TxId, conf.CrossTxId)
+		res.validationCode = peer.TxValidationCode_DUPLICATE_TXID
+		return res
+	}
+	if _, isNotFoundInIndexErrType := err.(ledger.NotFoundInIndexErr); !isNotFoundInIndexErrType {
+		logger.Errorf("Ledger failure while attempting to detect duplicate status for confirmation %s, err '%s'. Aborting", chdr.TxId, err)
+		return &blockValidationResult{tIdx: tIdx, err: err}
+	}
+	res.validationCode = peer.TxValidationCode_VALID
+	res.txid = chdr.TxId
+	return res
+}
+
+// validateCrossTx checks that the cross info of a transaction names the transaction itself, as the locks and the
+// state of a cross transaction are kept under its txid, which its confirmations carry. A cross transaction
+// committed or aborted already, as when a failure confirmation aborted it before it was ordered, is invalidated,
+// so that it takes no lock that no confirmation would release.
+func (v *TxValidator) validateCrossTx(env *common.Envelope, chdr *common.ChannelHeader) (peer.TxValidationCode, error) {
+	info, err := utils.UnmarshalCrossInfo(env.CrossInfo)
+	if err != nil {
+		logger.Warningf("[%s] Invalid cross info of transaction %s: %s", v.ChainID, chdr.TxId, err)
+		return peer.TxValidationCode_INVALID_OTHER_REASON, nil
+	}
+	if err := utils.CheckCrossTxID(chdr.TxId, info); err != nil {
+		logger.Warningf("[%s] Invalid cross transaction %s: %s", v.ChainID, chdr.TxId, err)
+		return peer.TxValidationCode_BAD_PROPOSAL_TXID, nil
+	}
+	if !utils.IsCrossTx(env) {
+		return peer.TxValidationCode_VALID, nil
+	}
+
+	querier, err := v.Support.Ledger().GetCrossTxQuerier()
+	if err != nil {
+		return peer.TxValidationCode_NOT_VALIDATED, err
+	}
+	status, err := querier.GetCrossTxStatus(chdr.TxId)
+	if err != nil {
+		logger.Errorf("Ledger failure while attempting to get the state of cross transaction [%s], err '%s'. Aborting", chdr.TxId, err)
+		return peer.TxValidationCode_NOT_VALIDATED, err
+	}
+	if state := status.GetState(); state == cross.CrossTxState_COMMITTED || state == cross.CrossTxState_ABORTED {
+		logger.Warningf("[%s] Cross transaction %s already %s in block [%d], skipping", v.ChainID, chdr.TxId, state, status.ConfirmationBlock)
+		return peer.TxValidationCode_INVALID_OTHER_REASON, nil
+	}
+	return peer.TxValidationCode_VALID, nil
+}
+
+// checkCoordinatorSignature checks the signature of a confirmation by its coordinator against the cross
+// coordinators policy of the channel, as the orderer does. A confirmation which is not signed by its coordinator
+// is rejected.
+func (v *TxValidator) checkCoordinatorSignature(conf *cross.Confirmation) error {
+	signedData, err := utils.ConfirmationAsSignedData(conf)
+	if err != nil {
+		return err
+	}
+	policy, ok := v.Support.PolicyManager().GetPolicy(policies.ChannelCrossCoordinators)
+	if !ok {
+		return errors.Errorf("could not find policy %s", policies.ChannelCrossCoordinators)
+	}
+	if err := policy.Evaluate(signedData); err != nil {
+		return errors.WithMessage(err, "invalid signature of the coordinator")
+	}
+	return nil
+}
+
+// markConfirmationDuplicates invalidates the confirmations of a block, found valid on their own, confirming a
+// cross transaction confirmed by a confirmation earlier in the block, which would be no-ops. They are marked as
+// duplicate confirmations even when their txid is marked as a duplicate already.
+func markConfirmationDuplicates(confirmedTxIDs map[int]string, txsfltr ledgerUtil.TxValidationFlags) {
+	confirmed := make(map[string]struct{})
+	for tIdx := range txsfltr {
+		crossTxID, ok := confirmedTxIDs[tIdx]
+		if !ok || crossTxID == "" {
+			continue
+		}
+		if _, in := confirmed[crossTxID]; in {
+			logger.Warningf("Duplicate confirmation %d of cross transaction [%s] in the block, skipping", tIdx, crossTxID)
+			txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_CONFIRMATION)
+			continue
+		}
+		confirmed[crossTxID] = struct{}{}
+	}
+}
+
+//NEW end
+
 // allValidated returns error if some of the validation flags have not been set
 // during validation
 func (v *TxValidator) allValidated(txsfltr ledgerUtil.TxValidationFlags, block *common.Block) error {
@@ -335,12 +507,8 @@ func (v *TxValidator) validateTx(req *blockValidationRequest, results chan<- *bl
 		//NEW add
 		//fmt.Println("core/commi/txva/validator.go validateTx() envelope = ", env)
 		crossInfo = env.CrossInfo  // crossInfo此时为confirmation内容
-		if string(crossInfo) == "confirmation"{
-			results <- &blockValidationResult{
-				tIdx:                 tIdx,
-				validationCode:       peer.TxValidationCode_VALID,
-				CrossInfo: crossInfo, //NEW add
-			}
+		if utils.IsConfirmation(env) {
+			results <- v.validateConfirmation(tIdx, env)
 			return
 		}
 		//New end
@@ -414,6 +582,22 @@ func (v *TxValidator) validateTx(req *blockValidationRequest, results chan<- *bl
 			}
 			// 3) err is of type blkstorage.NotFoundInIndexErr => there is no tx with the supplied id in the ledger
 
+			//NEW add
+			if code, err := v.validateCrossTx(env, chdr); err != nil {
+				results <- &blockValidationResult{
+					tIdx: tIdx,
+					err:  err,
+				}
+				return
+			} else if code != peer.TxValidationCode_VALID {
+				results <- &blockValidationResult{
+					tIdx:           tIdx,
+					validationCode: code,
+				}
+				return
+			}
+			//NEW end
+
 			// Validate tx with vscc and policy
 			logger.Debug("Validating transaction vscc tx validate")
 			err, cde := v.Vscc.VSCCValidateTx(tIdx, payload, d, block)
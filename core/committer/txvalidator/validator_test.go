@@ -18,7 +18,10 @@ import (
 	ledger2 "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/mocks/scc"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
 	"github.com/hyperledger/fabric/core/committer/txvalidator/mocks"
@@ -35,6 +38,7 @@ import (
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
@@ -82,7 +86,7 @@ func createRWset(t *testing.T, ccnames ...string) []byte {
 	for _, ccname := range ccnames {
 		rwsetBuilder.AddToWriteSet(ccname, "key", []byte("value"))
 	}
-	rwset, err := rwsetBuilder.GetTxSimulationResults()
+	rwset, err := rwsetBuilder.GetTxSimulationResults(nil)
 	assert.NoError(t, err)
 	rwsetBytes, err := rwset.GetPubSimulationBytes()
 	return rwsetBytes
@@ -139,7 +143,7 @@ func putCCInfoWithVSCCAndVer(theLedger ledger.PeerLedger, ccname, vscc, ver stri
 	simulator.SetState("lscc", ccname, cdbytes)
 	simulator.Done()
 
-	simRes, err := simulator.GetTxSimulationResults()
+	simRes, err := simulator.GetTxSimulationResults(nil)
 	assert.NoError(t, err)
 	pubSimulationBytes, err := simRes.GetPubSimulationBytes()
 	assert.NoError(t, err)
@@ -390,7 +394,7 @@ func TestInvokeOKPvtDataOnly(t *testing.T) {
 
 	rwsetBuilder := rwsetutil.NewRWSetBuilder()
 	rwsetBuilder.AddToPvtAndHashedWriteSet(ccID, "mycollection", "somekey", nil)
-	rwset, err := rwsetBuilder.GetTxSimulationResults()
+	rwset, err := rwsetBuilder.GetTxSimulationResults(nil)
 	assert.NoError(t, err)
 	rwsetBytes, err := rwset.GetPubSimulationBytes()
 	assert.NoError(t, err)
@@ -720,6 +724,12 @@ func (m *mockLedger) GetConfigHistoryRetriever() (ledger.ConfigHistoryRetriever,
 	return args.Get(0).(ledger.ConfigHistoryRetriever), nil
 }
 
+// GetCrossTxQuerier returns the CrossTxQuerier
+func (m *mockLedger) GetCrossTxQuerier() (ledger.CrossTxQuerier, error) {
+	args := m.Called()
+	return args.Get(0).(ledger.CrossTxQuerier), nil
+}
+
 // mockQueryExecutor mock of the query executor,
 // needed to simulate inability to access state db, e.g.
 // the case where due to db failure it's not possible to
@@ -736,6 +746,10 @@ func (exec *mockQueryExecutor) GetState(namespace string, key string) ([]byte, e
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (exec *mockQueryExecutor) GetStateNoRSet(namespace string, key string) ([]byte, error) {
+	return exec.GetState(namespace, key)
+}
+
 func (exec *mockQueryExecutor) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
 	args := exec.Called(namespace, keys)
 	return args.Get(0).([][]byte), args.Error(1)
@@ -1060,6 +1074,125 @@ func TestValidationPluginNotFound(t *testing.T) {
 	assert.Contains(t, executionErr.Error(), "plugin with name vscc wasn't found")
 }
 
+// mockCoordinatorsPolicy records the signed data it evaluates
+type mockCoordinatorsPolicy struct {
+	err       error
+	evaluated []*common.SignedData
+}
+
+func (p *mockCoordinatorsPolicy) Evaluate(signatureSet []*common.SignedData) error {
+	p.evaluated = signatureSet
+	return p.err
+}
+
+func TestValidateConfirmationCoordinatorPolicy(t *testing.T) {
+	support, l := createCustomSupportAndLedger(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+	policy := &mockCoordinatorsPolicy{}
+	support.PolicyManagerVal = &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{policies.ChannelCrossCoordinators: policy}}
+	vcs := struct {
+		*mocktxvalidator.Support
+		*semaphore.Weighted
+	}{support, semaphore.NewWeighted(10)}
+	v := txvalidator.NewTxValidator(util.GetTestChainID(), vcs, (&scc.MocksccProviderFactory{}).NewSystemChaincodeProvider(), &mocks.PluginMapper{})
+
+	validate := func(env *common.Envelope) *common.Block {
+		b := &common.Block{
+			Data:   &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(env)}},
+			Header: &common.BlockHeader{Number: 1},
+		}
+		assert.NoError(t, v.Validate(b))
+		return b
+	}
+
+	conf := &cross.Confirmation{CrossTxId: "crosstx", Channels: []string{util.GetTestChainID()}, Outcome: cross.Outcome_FAILURE}
+	env, err := utils.CreateSignedConfirmation(util.GetTestChainID(), mockcrypto.FakeLocalSigner, conf)
+	assert.NoError(t, err)
+
+	// the policy is evaluated against the signature of the confirmation by its coordinator
+	assertValid(validate(env), t)
+	signed, err := utils.GetConfirmation(env)
+	assert.NoError(t, err)
+	expected, err := utils.ConfirmationAsSignedData(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, policy.evaluated)
+
+	policy.err = errors.New("not a coordinator")
+	assertInvalid(validate(env), t, peer.TxValidationCode_BAD_CREATOR_SIGNATURE)
+	policy.err = nil
+
+	// a confirmation which is not signed by its coordinator is rejected
+	unsigned := *signed
+	unsigned.Coordinator, unsigned.CoordinatorSignature = nil, nil
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	assert.NoError(t, err)
+	payload.Data = utils.MarshalOrPanic(&unsigned)
+	unsignedEnv := &common.Envelope{Payload: utils.MarshalOrPanic(payload), Signature: env.Signature, CrossInfo: env.CrossInfo}
+	assertInvalid(validate(unsignedEnv), t, peer.TxValidationCode_BAD_CREATOR_SIGNATURE)
+
+	// a channel without a cross coordinators policy accepts no confirmation
+	support.PolicyManagerVal = &mockpolicies.Manager{}
+	assertInvalid(validate(env), t, peer.TxValidationCode_BAD_CREATOR_SIGNATURE)
+}
+
+func TestValidateConfirmationPresumedAbort(t *testing.T) {
+	support, l := createCustomSupportAndLedger(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+	support.PolicyManagerVal = &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{policies.ChannelCrossCoordinators: &mockCoordinatorsPolicy{}}}
+	vcs := struct {
+		*mocktxvalidator.Support
+		*semaphore.Weighted
+	}{support, semaphore.NewWeighted(10)}
+	v := txvalidator.NewTxValidator(util.GetTestChainID(), vcs, (&scc.MocksccProviderFactory{}).NewSystemChaincodeProvider(), &mocks.PluginMapper{})
+
+	newBlock := func(env *common.Envelope) *common.Block {
+		info, err := l.GetBlockchainInfo()
+		assert.NoError(t, err)
+		return &common.Block{
+			Data:   &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(env)}},
+			Header: &common.BlockHeader{Number: info.Height},
+		}
+	}
+	confirm := func(crossTxID string, outcome cross.Outcome) *common.Block {
+		conf := &cross.Confirmation{CrossTxId: crossTxID, Channels: []string{util.GetTestChainID()}, Outcome: outcome}
+		env, err := utils.CreateSignedConfirmation(util.GetTestChainID(), mockcrypto.FakeLocalSigner, conf)
+		assert.NoError(t, err)
+		b := newBlock(env)
+		assert.NoError(t, v.Validate(b))
+		return b
+	}
+
+	// the prepare of a cross transaction, ordered after the confirmation of its failure
+	prop, err := getProposalWithType("mycc", common.HeaderType_ENDORSER_TRANSACTION)
+	assert.NoError(t, err)
+	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, &peer.Response{Status: 200}, createRWset(t, "mycc"), nil, &peer.ChaincodeID{Name: "mycc", Version: ccVersion}, nil, signer)
+	assert.NoError(t, err)
+	crossTx, err := utils.CreateSignedCrossTx(prop, signer, &cross.CrossInfo{Mode: cross.CrossMode_SINGLE_CROSS}, presp)
+	assert.NoError(t, err)
+	chdr, err := utils.ChannelHeader(crossTx)
+	assert.NoError(t, err)
+
+	// a success confirmation of a cross transaction not prepared on the channel is rejected
+	assertInvalid(confirm(chdr.TxId, cross.Outcome_SUCCESS), t, peer.TxValidationCode_INVALID_OTHER_REASON)
+
+	// a failure confirmation of a cross transaction not prepared on the channel aborts it
+	b := confirm(chdr.TxId, cross.Outcome_FAILURE)
+	assertValid(b, t)
+	assert.NoError(t, l.CommitWithPvtData(&ledger.BlockAndPvtData{Block: b}))
+	querier, err := l.GetCrossTxQuerier()
+	assert.NoError(t, err)
+	status, err := querier.GetCrossTxStatus(chdr.TxId)
+	assert.NoError(t, err)
+	assert.Equal(t, cross.CrossTxState_ABORTED, status.State)
+
+	// so that its prepare is rejected
+	b = newBlock(crossTx)
+	assert.NoError(t, v.Validate(b))
+	assertInvalid(b, t, peer.TxValidationCode_INVALID_OTHER_REASON)
+}
+
 var signer msp.SigningIdentity
 
 var signerSerialized []byte
@@ -7,7 +7,6 @@ SPDX-License-Identifier: Apache-2.0
 package blocksprovider
 
 import (
-	"fmt"
 	"math"
 	"sync/atomic"
 	"time"
@@ -173,30 +172,9 @@ func (b *blocksProviderImpl) DeliverBlocks() {
 				logger.Errorf("[%s] Error serializing block with sequence number %d, due to %s", b.chainID, blockNum, err)
 				continue
 			}
-			/* 原
 			if err := b.mcs.VerifyBlock(gossipcommon.ChainID(b.chainID), blockNum, marshaledBlock); err != nil {
 				logger.Errorf("[%s] Error verifying block with sequnce number %d, due to %s", b.chainID, blockNum, err)
 				continue
-			}*/
-
-			//NEW add
-			envelope := &common.Envelope{}
-			if err = proto.Unmarshal(t.Block.Data.Data[0], envelope); err != nil {
-				fmt.Errorf("error reconstructing envelope(%s)", err)
-			}
-			fmt.Println("core/deliverservice/blocksprovider/blocksprovider.go DeliverBlocks() envelope.CrossInfo = ", envelope.CrossInfo)
-
-		/*	mtdt, err := utils.GetMetadataFromBlock(t.Block, 4)
-			fmt.Println("core/deliverservice/blocksprovider/blocksprovider.go DeliverBlocks() GetMetadata = ", mtdt)
-
-			fmt.Println("core/deliverservice/blocksprovider/blocksprovider.go DeliverBlocks() Metadata[4] = ", string(t.Block.Metadata.Metadata[4]))
-			fmt.Println("metadata=", t.Block.Metadata)*/
-			if string(envelope.CrossInfo) != "confirmation" { //NEW add
-fmt.Println("core/deliverservice/blocksprovider/blocksprovider.go DeliverBlocks() !confirmation")
-				if err := b.mcs.VerifyBlock(gossipcommon.ChainID(b.chainID), blockNum, marshaledBlock); err != nil {
-					logger.Errorf("[%s] Error verifying block with sequnce number %d, due to %s", b.chainID, blockNum, err)
-					continue
-				}
 			}
 			numberOfPeers := len(b.gossip.PeersOfChannel(gossipcommon.ChainID(b.chainID)))
 			// Create payload with a block received
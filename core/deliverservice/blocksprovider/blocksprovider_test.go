@@ -12,12 +12,15 @@ import (
 	"testing"
 	"time"
 
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/deliverservice/mocks"
 	"github.com/hyperledger/fabric/gossip/api"
 	common2 "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -443,3 +446,31 @@ func TestBlockVerificationFailure(t *testing.T) {
 	mcs.On("VerifyBlock", mock.Anything).Return(errors.New("Invalid signature"))
 	makeTestCase(uint64(0), mcs, false, rcvr)(t)
 }
+
+func TestConfirmationBlockVerification(t *testing.T) {
+	conf, err := utils.CreateSignedConfirmation("***TEST_CHAINID***", &mockcrypto.LocalSigner{Identity: []byte("coordinator")},
+		&cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE})
+	assert.NoError(t, err)
+	// the ordering service delivers confirmation blocks only
+	confirmationRcvr := func(mock *mocks.MockBlocksDeliverer) (*orderer.DeliverResponse, error) {
+		block := common.NewBlock(mock.Pos, nil)
+		mock.Pos++
+		block.Data.Data = [][]byte{utils.MarshalOrPanic(conf)}
+		block.Header.DataHash = block.Data.Hash()
+		return &orderer.DeliverResponse{
+			Type: &orderer.DeliverResponse_Block{Block: block},
+		}, nil
+	}
+
+	// a confirmation block is delivered once verified
+	mcs := &mockMCS{}
+	mcs.On("VerifyBlock", mock.Anything).Return(nil)
+	makeTestCase(uint64(0), mcs, true, confirmationRcvr)(t)
+	mcs.AssertCalled(t, "VerifyBlock")
+
+	// and not delivered if the signature of the orderer is missing or invalid
+	mcs = &mockMCS{}
+	mcs.On("VerifyBlock", mock.Anything).Return(errors.New("Invalid signature"))
+	makeTestCase(uint64(0), mcs, false, confirmationRcvr)(t)
+	mcs.AssertCalled(t, "VerifyBlock")
+}
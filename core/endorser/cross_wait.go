@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/peer/cross"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// crossLockedStatus is the status of the response to a proposal accessing keys held by cross transactions.
+// Being above shim.ERRORTHRESHOLD, the response is not endorsed.
+const crossLockedStatus = 401
+
+// CrossLockWait configures how the endorser handles the proposals accessing keys held by cross transactions
+type CrossLockWait struct {
+	// Timeout bounds the time a proposal waits for the release of the cross transactions holding the keys it
+	// accesses, before being simulated again. A zero timeout fails such a proposal right away.
+	Timeout time.Duration
+	// Lease is the time the coordinator of the cross transactions gives them to be confirmed once prepared,
+	// which tells the clients when a lock expires at the latest. A zero lease leaves the expiry unknown.
+	Lease time.Duration
+}
+
+// simulateCrossLocked handles a proposal whose simulation accessed keys held by cross transactions. In wait
+// mode, the proposal waits for the release of the transactions holding the keys, in the order of arrival of
+// the proposals waiting on the channel, and is simulated again, until it no longer accesses held keys or the
+// timeout expires. The response to a proposal still accessing held keys carries a CrossLockConflict.
+// The conflicts of a proposal of a cross transaction are reported to the lock manager, which makes them
+// available to the coordinator of the transactions for detecting deadlocks.
+func (e *Endorser) simulateCrossLocked(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cid *pb.ChaincodeID, cd ccprovider.ChaincodeDefinition, res *pb.Response, simRes []byte) (ccprovider.ChaincodeDefinition, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
+	var ccevent *pb.ChaincodeEvent
+	waitCtx, cancel := context.WithTimeout(ctx, e.CrossLockWait.Timeout)
+	defer cancel()
+	crossProp := isCrossProposal(signedProp)
+	for {
+		blockers, err := crossBlockers(chainID, txid, simRes)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		var txIDs []string
+		for _, b := range blockers {
+			txIDs = append(txIDs, b.TxID)
+		}
+		if crossProp {
+			cross.GetLockManager().ReportConflict(chainID, txid, txIDs)
+		}
+		if e.CrossLockWait.Timeout <= 0 {
+			return cd, e.crossLockedResponse(res, blockers), simRes, ccevent, nil
+		}
+		endorserLogger.Debugf("[%s][%s] Waiting for the release of cross transactions %v", chainID, shorttxid(txid), txIDs)
+		done, err := cross.GetLockManager().WaitRelease(waitCtx, chainID, txIDs)
+		if err != nil {
+			endorserLogger.Debugf("[%s][%s] %s", chainID, shorttxid(txid), err)
+			return cd, e.crossLockedResponse(res, blockers), simRes, ccevent, nil
+		}
+		cd, res, simRes, ccevent, err = e.simulateAgain(ctx, chainID, txid, signedProp, prop, cid)
+		done()
+		if err != nil || res.Status != crossLockedStatus {
+			return cd, res, simRes, ccevent, err
+		}
+	}
+}
+
+// simulateAgain simulates a proposal with a new tx simulator
+func (e *Endorser) simulateAgain(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cid *pb.ChaincodeID) (ccprovider.ChaincodeDefinition, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
+	txsim, err := e.s.GetTxSimulator(chainID, txid)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer txsim.Done()
+	return e.SimulateProposal(ctx, chainID, txid, signedProp, prop, cid, txsim)
+}
+
+// isCrossProposal returns true if the proposal is of a single or multi-chain cross transaction, whose txid is
+// the ID of the cross transaction on every participant channel
+func isCrossProposal(signedProp *pb.SignedProposal) bool {
+	info, err := putils.UnmarshalCrossInfo(signedProp.CrossMsgBytes)
+	return err == nil && (info.Mode == crosspb.CrossMode_SINGLE_CROSS || info.Mode == crosspb.CrossMode_MULTI_CROSS)
+}
+
+// crossBlockers returns the cross transactions holding the keys accessed by the given simulation results
+func crossBlockers(chainID, txid string, simRes []byte) ([]cross.Blocker, error) {
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err := txRWSet.FromProtoBytes(simRes); err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal the simulation results")
+	}
+	return cross.GetLockManager().Blockers(chainID, txid, txRWSet), nil
+}
+
+// crossLockedResponse returns the response to a proposal accessing keys held by the given cross transactions,
+// whose payload is a CrossLockConflict. The blocking transactions may have been released since the simulation,
+// or be unknown when the keys were accessed by rich queries, in which case the payload is left empty.
+func (e *Endorser) crossLockedResponse(res *pb.Response, blockers []cross.Blocker) *pb.Response {
+	res.Status = crossLockedStatus
+	res.Message = "proposal accesses keys held by cross transactions"
+	res.Payload = nil
+	if len(blockers) == 0 {
+		return res
+	}
+	conflict := &crosspb.CrossLockConflict{CrossTxId: blockers[0].TxID, PreparedBlock: blockers[0].PreparedBlock}
+	if e.CrossLockWait.Lease > 0 {
+		if expiry, err := ptypes.TimestampProto(blockers[0].Since.Add(e.CrossLockWait.Lease)); err == nil {
+			conflict.ExpectedExpiry = expiry
+		}
+	}
+	for _, b := range blockers[1:] {
+		conflict.OtherCrossTxIds = append(conflict.OtherCrossTxIds, b.TxID)
+	}
+	res.Message = fmt.Sprintf("proposal accesses keys held by cross transaction [%s]", conflict.CrossTxId)
+	res.Payload = putils.MarshalOrPanic(conflict)
+	return res
+}
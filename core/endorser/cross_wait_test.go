@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	mc "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/endorser"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	mockccprovider "github.com/hyperledger/fabric/core/mocks/ccprovider"
+	em "github.com/hyperledger/fabric/core/mocks/endorser"
+	"github.com/hyperledger/fabric/peer/cross"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newCrossLockedTxSim returns a tx simulator whose results read the key [ccid:key], reporting the key as held
+// by a cross transaction if so requested
+func newCrossLockedTxSim(t *testing.T, locked bool) *mockccprovider.MockTxSim {
+	b := rwsetutil.NewRWSetBuilder()
+	b.AddToReadSet("ccid", "key", nil)
+	simRes, err := b.GetTxSimulationResults(nil)
+	assert.NoError(t, err)
+	return &mockccprovider.MockTxSim{GetTxSimulationResultsRv: simRes, GetCrossLockedRv: locked}
+}
+
+// newCrossWaitEndorser returns an endorser whose tx simulators are the given ones, in turn
+func newCrossWaitEndorser(wait endorser.CrossLockWait, txsims ...*mockccprovider.MockTxSim) *endorser.Endorser {
+	m := &mock.Mock{}
+	m.On("Sign", mock.Anything).Return([]byte{1, 2, 3, 4, 5}, nil)
+	m.On("Serialize").Return([]byte{1, 1, 1}, nil)
+	for _, txsim := range txsims {
+		m.On("GetTxSimulator", mock.Anything, mock.Anything).Return(txsim, nil).Once()
+	}
+	support := &em.MockSupport{
+		Mock:                       m,
+		GetApplicationConfigBoolRv: true,
+		GetApplicationConfigRv:     &mc.MockApplication{CapabilitiesRv: &mc.MockApplicationCapabilities{}},
+		GetTransactionByIDErr:      errors.New(""),
+		ChaincodeDefinitionRv:      &ccprovider.ChaincodeData{Escc: "ESCC"},
+		ExecuteResp:                &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(&pb.ProposalResponse{Response: &pb.Response{}})},
+	}
+	attachPluginEndorser(support)
+	es := endorser.NewEndorserServer(pvtEmptyDistributor, &crossWaitSupport{support})
+	es.CrossLockWait = wait
+	return es
+}
+
+// crossWaitSupport executes chaincodes returning a new response every time, as the endorser marks the response
+// to a simulation accessing keys held by cross transactions
+type crossWaitSupport struct {
+	*em.MockSupport
+}
+
+func (s *crossWaitSupport) Execute(ctxt context.Context, cid, name, version, txid string, syscc bool, signedProp *pb.SignedProposal, prop *pb.Proposal, spec ccprovider.ChaincodeSpecGetter) (*pb.Response, *pb.ChaincodeEvent, error) {
+	res, event, err := s.MockSupport.Execute(ctxt, cid, name, version, txid, syscc, signedProp, prop, spec)
+	if res != nil {
+		res = proto.Clone(res).(*pb.Response)
+	}
+	return res, event, err
+}
+
+// prepareCrossHolder prepares a cross transaction holding the key [ccid:key] exclusively on the test channel
+func prepareCrossHolder(t *testing.T, txID string) {
+	keys := []cross.KeyLock{{CompositeKey: statedb.CompositeKey{Namespace: "ccid", Key: "key"}, Mode: cross.ExclusiveLock}}
+	err := cross.GetLockManager().PrepareTx(util.GetTestChainID(), txID, 3, keys, nil, nil)
+	assert.NoError(t, err)
+}
+
+func assertCrossLockConflict(t *testing.T, resp *pb.ProposalResponse, txID string) *crosspb.CrossLockConflict {
+	assert.EqualValues(t, 401, resp.Response.Status)
+	assert.Nil(t, resp.Endorsement)
+	conflict := &crosspb.CrossLockConflict{}
+	assert.NoError(t, proto.Unmarshal(resp.Response.Payload, conflict))
+	assert.Equal(t, txID, conflict.CrossTxId)
+	assert.EqualValues(t, 3, conflict.PreparedBlock)
+	return conflict
+}
+
+func TestCrossLockedNoWait(t *testing.T) {
+	prepareCrossHolder(t, "crossholder1")
+	defer cross.GetLockManager().Release(util.GetTestChainID(), "crossholder1")
+
+	es := newCrossWaitEndorser(endorser.CrossLockWait{}, newCrossLockedTxSim(t, true))
+	pResp, err := es.ProcessProposal(context.Background(), getSignedProp("ccid", "0", t))
+	assert.NoError(t, err)
+	conflict := assertCrossLockConflict(t, pResp, "crossholder1")
+	assert.Nil(t, conflict.ExpectedExpiry)
+	assert.Empty(t, conflict.OtherCrossTxIds)
+}
+
+func TestCrossLockedLease(t *testing.T) {
+	before := time.Now()
+	prepareCrossHolder(t, "crossholder2")
+	after := time.Now()
+	defer cross.GetLockManager().Release(util.GetTestChainID(), "crossholder2")
+
+	es := newCrossWaitEndorser(endorser.CrossLockWait{Lease: time.Minute}, newCrossLockedTxSim(t, true))
+	pResp, err := es.ProcessProposal(context.Background(), getSignedProp("ccid", "0", t))
+	assert.NoError(t, err)
+	conflict := assertCrossLockConflict(t, pResp, "crossholder2")
+	expiry, err := ptypes.Timestamp(conflict.ExpectedExpiry)
+	assert.NoError(t, err)
+	assert.False(t, expiry.Before(before.Add(time.Minute)))
+	assert.False(t, expiry.After(after.Add(time.Minute)))
+}
+
+func TestCrossLockedWaitReleased(t *testing.T) {
+	prepareCrossHolder(t, "crossholder3")
+	defer cross.GetLockManager().Release(util.GetTestChainID(), "crossholder3")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cross.GetLockManager().Release(util.GetTestChainID(), "crossholder3")
+	}()
+
+	es := newCrossWaitEndorser(endorser.CrossLockWait{Timeout: 10 * time.Second}, newCrossLockedTxSim(t, true), newCrossLockedTxSim(t, false))
+	pResp, err := es.ProcessProposal(context.Background(), getSignedProp("ccid", "0", t))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 200, pResp.Response.Status)
+	assert.NotNil(t, pResp.Endorsement)
+}
+
+func TestCrossLockedWaitTimeout(t *testing.T) {
+	prepareCrossHolder(t, "crossholder4")
+	defer cross.GetLockManager().Release(util.GetTestChainID(), "crossholder4")
+
+	es := newCrossWaitEndorser(endorser.CrossLockWait{Timeout: 200 * time.Millisecond}, newCrossLockedTxSim(t, true))
+	start := time.Now()
+	pResp, err := es.ProcessProposal(context.Background(), getSignedProp("ccid", "0", t))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+	assertCrossLockConflict(t, pResp, "crossholder4")
+}
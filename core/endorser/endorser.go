@@ -19,7 +19,6 @@ import (
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/validation"
 	"github.com/hyperledger/fabric/core/ledger"
-	"github.com/hyperledger/fabric/peer/cross"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/transientstore"
@@ -98,6 +97,7 @@ type Endorser struct {
 	distributePrivateData privateDataDistributor
 	s                     Support
 	PvtRWSetAssembler
+	CrossLockWait CrossLockWait //NEW add
 }
 
 // validateResult provides the result of endorseProposal verification
@@ -297,6 +297,18 @@ func (e *Endorser) SimulateProposal(ctx context.Context, chainID string, txid st
 			return nil, nil, nil, nil, err
 		}
 
+		//NEW add
+		// the private data of a proposal accessing keys held by cross transactions is not distributed, the
+		// proposal failing or being simulated again
+		if txsim.GetCrossLocked() {
+			res.Status = crossLockedStatus
+			txsim.Done()
+			if pubSimResBytes, err = simResult.GetPubSimulationBytes(); err != nil {
+				return nil, nil, nil, nil, err
+			}
+			return cdLedger, res, pubSimResBytes, ccevent, nil
+		} //NEW end
+
 		if simResult.PvtSimulationResults != nil {
 			if cid.Name == "lscc" {
 				// TODO: remove once we can store collection configuration outside of LSCC
@@ -326,10 +338,6 @@ func (e *Endorser) SimulateProposal(ctx context.Context, chainID string, txid st
 			}
 		}
 
-		if txsim.GetCrossLocked(){ //NEW add
-			res.Status = 401  //如果用到的Key被CrossLocked 改response状态码为401  后面peer会因为>400而认为error
-		}
-
 		txsim.Done()
 		if pubSimResBytes, err = simResult.GetPubSimulationBytes(); err != nil {
 			return nil, nil, nil, nil, err
@@ -464,16 +472,6 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	addr := util.ExtractRemoteAddress(ctx)
 	endorserLogger.Debug("Entering: request from", addr)
 	defer endorserLogger.Debug("Exit: request from", addr)
-//NEW add print
-	fmt.Println("这里是fabric/core/endorser/endorser.go ProcessProposal()")
-//	fmt.Println("ProcessProposal() signedProposal=", signedProp)
-//	fmt.Println("ProcessProposal() signedProposal.CrossMsgBytes=", signedProp.CrossMsgBytes)
-	fmt.Println("core/endorser/endorser.go printslice CrossTxID ", cross.CrossTxID)
-	fmt.Println("core/endorser/endorser.go printslice LockedKeys ", cross.LockedKeys)
-	fmt.Println("core/endorser/endorser.go printmap CrossTxWriteKeyMap", cross.CrossTxWriteKeyMap)
-
-	fmt.Println()
-
 	// 0 -- check and validate
 	vr, err := e.preProcess(signedProp)
 	if err != nil {
@@ -483,15 +481,6 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 
 	prop, hdrExt, chainID, txid := vr.prop, vr.hdrExt, vr.chainID, vr.txid
 
-//NEW add
-	if string(signedProp.CrossMsgBytes) != "local"{   /////////////////////////// 要改回来呀！！！测试用==，正式用!=////
-		fmt.Println("core/endorser/endorser.go  ProcessProposal.Is Not Local")
-		fmt.Println("IsNotLocal.txid = ", txid)
-		cross.AppendCrossTxID(txid)
-		fmt.Println("IsNotLocal.cross.CrossTxID = ", cross.CrossTxID)
-	}
-//NEW end
-
 	// obtaining once the tx simulator for this proposal. This will be nil
 	// for chainless proposals
 	// Also obtain a history query executor for history queries, since tx simulator does not cover history
@@ -528,6 +517,10 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 
 	// 1 -- simulate
 	cd, res, simulationResult, ccevent, err := e.SimulateProposal(ctx, chainID, txid, signedProp, prop, hdrExt.ChaincodeId, txsim)
+	//NEW add
+	if err == nil && res != nil && res.Status == crossLockedStatus {
+		cd, res, simulationResult, ccevent, err = e.simulateCrossLocked(ctx, chainID, txid, signedProp, prop, hdrExt.ChaincodeId, cd, res, simulationResult)
+	} //NEW end
 	if err != nil {
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, nil
 	}
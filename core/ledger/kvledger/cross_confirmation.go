@@ -0,0 +1,429 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// crossRollbackKeyPrefix prefixes the keys under which the rollback caused by a failure confirmation
+// is kept in the crossdb, by block and transaction number. The rollback is persisted before being applied,
+// so that a recovery or a rebuild of the state and history databases reproduces the same state.
+var crossRollbackKeyPrefix = []byte("rollback~")
+
+// crossPvtRollbackKeyPrefix prefixes the keys under which the part of a rollback restoring the keys of private
+// data collections is kept in the crossdb. This part depends on the private data held by the peer.
+var crossPvtRollbackKeyPrefix = []byte("pvtrollback~")
+
+// crossBlockKeysEnd ends the range of the per-block entries of the crossdb, whose keys are big-endian
+// block numbers, before the keys starting with a printable prefix
+var crossBlockKeysEnd = []byte{0x01}
+
+// crossConfirmation is the outcome of a cross transaction carried by a confirmation block
+type crossConfirmation struct {
+	// txNum is the position of the confirmation transaction in the block
+	txNum   uint64
+	txID    string
+	success bool
+	// reason is the reason of a failure given by the coordinator, such as the expiry of the lease of the transaction
+	reason string
+	// raw is the payload of the confirmation transaction
+	raw []byte
+}
+
+func isCrossConfirmationBlock(block *common.Block) bool {
+	return utils.IsConfirmationBlock(block)
+}
+
+// extractCrossConfirmations decodes the confirmations carried by the valid transactions of a confirmation block,
+// in the order of the block. Only the first confirmation of a cross transaction within the block is kept.
+func extractCrossConfirmations(block *common.Block) ([]*crossConfirmation, error) {
+	if len(block.Data.Data) == 0 {
+		return nil, errors.Errorf("confirmation block [%d] carries no transaction", block.Header.Number)
+	}
+	txsFilter := lutil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	var confs []*crossConfirmation
+	confirmed := make(map[string]struct{})
+	for txNum, envBytes := range block.Data.Data {
+		// earlier versions left the confirmations not validated
+		if txNum < len(txsFilter) && txsFilter.IsInvalid(txNum) && !txsFilter.IsSetTo(txNum, peer.TxValidationCode_NOT_VALIDATED) {
+			logger.Debugf("Skipping invalid confirmation [%d] of block [%d]", txNum, block.Header.Number)
+			continue
+		}
+		env, err := utils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid confirmation [%d] in block [%d]", txNum, block.Header.Number))
+		}
+		payload, err := utils.GetPayload(env)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid confirmation [%d] in block [%d]", txNum, block.Header.Number))
+		}
+		conf, err := utils.UnmarshalConfirmation(payload.Data)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid confirmation [%d] in block [%d]", txNum, block.Header.Number))
+		}
+		if _, ok := confirmed[conf.CrossTxId]; ok {
+			logger.Warningf("Skipping confirmation [%d] of block [%d], cross transaction [%s] being confirmed earlier in the block",
+				txNum, block.Header.Number, conf.CrossTxId)
+			continue
+		}
+		confirmed[conf.CrossTxId] = struct{}{}
+		confs = append(confs, &crossConfirmation{
+			txNum:   uint64(txNum),
+			txID:    conf.CrossTxId,
+			success: conf.Outcome == cross.Outcome_SUCCESS,
+			reason:  conf.Reason,
+			raw:     payload.Data,
+		})
+	}
+	return confs, nil
+}
+
+// commitCrossConfirmation commits a confirmation block. On failure, the keys written by a cross transaction
+// are restored to their original values by a rollback applied at the height of its confirmation, which is
+// recorded in the history database as modifications made by the cross transaction. The rollbacks are written
+// to the crossdb along with the confirmations, in a single batch. The locks held by the confirmed cross
+// transactions are released in any case. The confirmations of cross transactions confirmed by an earlier
+// block are no-ops.
+func (l *kvLedger) commitCrossConfirmation(pvtdataAndBlock *ledger.BlockAndPvtData) error {
+	block := pvtdataAndBlock.Block
+	blockNo := block.Header.Number
+	confs, err := extractCrossConfirmations(block)
+	if err != nil {
+		return err
+	}
+	if confs, err = l.applicableCrossConfirmations(blockNo, confs); err != nil {
+		return err
+	}
+
+	startCommitBlockStorage := time.Now()
+	logger.Debugf("[%s] Committing confirmation block [%d] to storage", l.ledgerID, blockNo)
+	l.blockAPIsRWLock.Lock()
+	defer l.blockAPIsRWLock.Unlock()
+	if err = l.blockStore.CommitWithPvtData(pvtdataAndBlock); err != nil {
+		return err
+	}
+
+	crossBatch := crossdb.NewUpdateBatch()
+	rollbacks, err := l.crossRollbacks(crossBatch, blockNo, confs)
+	if err != nil {
+		panic(fmt.Errorf(`Error during building cross rollbacks:%s`, err))
+	}
+	if err = l.recordCrossConfirmations(crossBatch, blockNo, confs); err != nil {
+		panic(fmt.Errorf(`Error during recording cross confirmations:%s`, err))
+	}
+	if err = l.commitCrossRecords(crossBatch, block); err != nil {
+		panic(fmt.Errorf(`Error during commit to crossdb:%s`, err))
+	}
+	if err = l.txtmgmt.CommitCrossConfirmations(block, rollbacks); err != nil {
+		panic(fmt.Errorf(`Error during commit to txmgr:%s`, err))
+	}
+	if ledgerconfig.IsHistoryDBEnabled() {
+		if err = l.historyDB.CommitCrossConfirmations(block, rollbacks); err != nil {
+			panic(fmt.Errorf(`Error during commit to history db:%s`, err))
+		}
+	}
+	if err = l.releaseCrossTxs(confs); err != nil {
+		return err
+	}
+
+	elapsedCommitBlockStorage := time.Since(startCommitBlockStorage) / time.Millisecond // duration in ms
+	for _, conf := range confs {
+		if !conf.success && conf.reason != "" {
+			logger.Warningf("[%s] Rolled back cross transaction [%s] confirmed as failed in block [%d]: %s", l.ledgerID, conf.txID, blockNo, conf.reason)
+		}
+		logger.Debugf("[%s] Confirmed cross transaction [%s] in block [%d] (success=%t)", l.ledgerID, conf.txID, blockNo, conf.success)
+	}
+	logger.Infof("[%s] Committed confirmation block [%d] with %d confirmation(s) and %d rollback(s) in %dms",
+		l.ledgerID, blockNo, len(confs), len(rollbacks), elapsedCommitBlockStorage)
+	return nil
+}
+
+// recommitCrossConfirmation recommits a confirmation block to the given recoverables
+func (l *kvLedger) recommitCrossConfirmation(block *common.Block, recoverables ...recoverable) error {
+	confs, err := extractCrossConfirmations(block)
+	if err != nil {
+		return err
+	}
+	if confs, err = l.applicableCrossConfirmations(block.Header.Number, confs); err != nil {
+		return err
+	}
+	crossBatch := crossdb.NewUpdateBatch()
+	rollbacks, err := l.crossRollbacks(crossBatch, block.Header.Number, confs)
+	if err != nil {
+		return err
+	}
+	if err := l.recordCrossConfirmations(crossBatch, block.Header.Number, confs); err != nil {
+		return err
+	}
+	if err := l.commitCrossRecords(crossBatch, block); err != nil {
+		return err
+	}
+	for _, r := range recoverables {
+		if err := r.CommitCrossConfirmations(block, rollbacks); err != nil {
+			return err
+		}
+	}
+	// the peer may have stopped before releasing the locks
+	return l.releaseCrossTxs(confs)
+}
+
+// recordCrossConfirmations adds the confirmations and the final states of the confirmed cross transactions
+// to the given batch of the crossdb
+func (l *kvLedger) recordCrossConfirmations(batch *crossdb.UpdateBatch, blockNum uint64, confs []*crossConfirmation) error {
+	for _, conf := range confs {
+		batch.Put(constructCrossConfirmationKey(blockNum, conf.txNum), conf.raw)
+		if err := l.confirmCrossTxState(batch, blockNum, conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseCrossTxs releases the locks held by the confirmed cross transactions, in the order of the block
+func (l *kvLedger) releaseCrossTxs(confs []*crossConfirmation) error {
+	for _, conf := range confs {
+		if err := l.releaseCrossTx(conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *kvLedger) releaseCrossTx(conf *crossConfirmation) error {
+	if conf.success {
+		return crossLockMgr.CrossConfirmSucc(l.ledgerID, conf.txID)
+	}
+	return crossLockMgr.CrossConfirmFail(l.ledgerID, conf.txID)
+}
+
+// crossRollbacks returns the rollbacks caused by the failure confirmations among the given ones, adding those
+// built for the first time to the given batch of the crossdb
+func (l *kvLedger) crossRollbacks(batch *crossdb.UpdateBatch, blockNum uint64, confs []*crossConfirmation) ([]*rwsetutil.CrossRollback, error) {
+	var rollbacks []*rwsetutil.CrossRollback
+	for _, conf := range confs {
+		rollback, err := l.crossRollback(batch, blockNum, conf)
+		if err != nil {
+			return nil, err
+		}
+		if rollback != nil {
+			rollbacks = append(rollbacks, rollback)
+		}
+	}
+	return rollbacks, nil
+}
+
+// crossRollback returns the rollback caused by the given confirmation, nil for a success confirmation.
+// The first time, the rollback is built from the undo log of the cross transaction and added to the given
+// batch, which persists it along with the confirmation.
+func (l *kvLedger) crossRollback(batch *crossdb.UpdateBatch, blockNum uint64, conf *crossConfirmation) (*rwsetutil.CrossRollback, error) {
+	if conf.success {
+		return nil, nil
+	}
+	key, pvtKey := constructCrossRollbackKey(blockNum, conf.txNum), constructCrossPvtRollbackKey(blockNum, conf.txNum)
+	rollbackBytes, err := l.crossDB.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if rollbackBytes == nil && conf.txNum == 0 {
+		// earlier versions kept the rollback of the single confirmation of a block by block number only
+		if rollbackBytes, err = l.crossDB.Get(constructLegacyCrossRollbackKey(blockNum)); err != nil {
+			return nil, err
+		}
+	}
+	rollback := &rwsetutil.CrossRollback{TxNum: conf.txNum, CrossTxID: conf.txID, RwSet: &rwsetutil.TxRwSet{}}
+	if rollbackBytes != nil {
+		if err := rollback.RwSet.FromProtoBytes(rollbackBytes); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal the rollback of confirmation [%d] of block [%d]", conf.txNum, blockNum)
+		}
+		pvtRollbackBytes, err := l.crossDB.Get(pvtKey)
+		if err != nil || pvtRollbackBytes == nil {
+			return rollback, err
+		}
+		rollback.PvtRwSet = &rwsetutil.TxPvtRwSet{}
+		if err := rollback.PvtRwSet.FromProtoBytes(pvtRollbackBytes); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal the private rollback of confirmation [%d] of block [%d]", conf.txNum, blockNum)
+		}
+		return rollback, nil
+	}
+
+	rollback.RwSet, rollback.PvtRwSet = buildCrossRollback(crossLockMgr.RollbackKVs(l.ledgerID, conf.txID))
+	if rollback.PvtRwSet != nil {
+		pvtRollbackBytes, err := rollback.PvtRwSet.ToProtoBytes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal the private rollback of confirmation [%d] of block [%d]", conf.txNum, blockNum)
+		}
+		batch.Put(pvtKey, pvtRollbackBytes)
+	}
+	if rollbackBytes, err = rollback.RwSet.ToProtoBytes(); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the rollback of confirmation [%d] of block [%d]", conf.txNum, blockNum)
+	}
+	batch.Put(key, rollbackBytes)
+	return rollback, nil
+}
+
+// buildCrossRollback builds the writes that restore the original values recorded in the undo log of a cross
+// transaction: the public keys and the hashes of the keys of collections, and the keys of collections held by the
+// peer, if any. A restored key of a collection keeps the version of its original value, recorded as a read of
+// the key, so that the value expires as it would have had the transaction not been committed. Namespaces,
+// collections and keys are sorted so that the rollback is deterministic.
+func buildCrossRollback(undo []statedb.KeyOrigVal) (*rwsetutil.TxRwSet, *rwsetutil.TxPvtRwSet) {
+	writes := make(map[string][]*kvrwset.KVWrite)
+	hashedRWSets := make(map[string]map[string]*kvrwset.HashedRWSet)
+	pvtRWSets := make(map[string]map[string]*kvrwset.KVRWSet)
+	// hashedColls and pvtColls hold the collections of every namespace of hashedRWSets and pvtRWSets
+	hashedColls, pvtColls := make(map[string][]string), make(map[string][]string)
+	for _, kv := range undo {
+		var orig *statedb.VersionedValue
+		if kv.OriginalVersionedValue != nil {
+			orig = statedb.DecodeOrigValue(kv.OriginalVersionedValue)
+		}
+		switch {
+		case kv.Collection == "":
+			w := &kvrwset.KVWrite{Key: kv.Key, IsDelete: orig == nil}
+			if orig != nil {
+				w.Value = orig.Value
+			}
+			writes[kv.Namespace] = append(writes[kv.Namespace], w)
+		case kv.KeyHash != nil:
+			if hashedRWSets[kv.Namespace] == nil {
+				hashedRWSets[kv.Namespace] = make(map[string]*kvrwset.HashedRWSet)
+			}
+			hashedRWSet := hashedRWSets[kv.Namespace][kv.Collection]
+			if hashedRWSet == nil {
+				hashedRWSet = &kvrwset.HashedRWSet{}
+				hashedRWSets[kv.Namespace][kv.Collection] = hashedRWSet
+				hashedColls[kv.Namespace] = append(hashedColls[kv.Namespace], kv.Collection)
+			}
+			w := &kvrwset.KVWriteHash{KeyHash: kv.KeyHash, IsDelete: orig == nil}
+			if orig != nil {
+				w.ValueHash = orig.Value
+				hashedRWSet.HashedReads = append(hashedRWSet.HashedReads,
+					&kvrwset.KVReadHash{KeyHash: kv.KeyHash, Version: &kvrwset.Version{BlockNum: orig.Version.BlockNum, TxNum: orig.Version.TxNum}})
+			}
+			hashedRWSet.HashedWrites = append(hashedRWSet.HashedWrites, w)
+		default:
+			if pvtRWSets[kv.Namespace] == nil {
+				pvtRWSets[kv.Namespace] = make(map[string]*kvrwset.KVRWSet)
+			}
+			kvRWSet := pvtRWSets[kv.Namespace][kv.Collection]
+			if kvRWSet == nil {
+				kvRWSet = &kvrwset.KVRWSet{}
+				pvtRWSets[kv.Namespace][kv.Collection] = kvRWSet
+				pvtColls[kv.Namespace] = append(pvtColls[kv.Namespace], kv.Collection)
+			}
+			w := &kvrwset.KVWrite{Key: kv.Key, IsDelete: orig == nil}
+			if orig != nil {
+				w.Value = orig.Value
+				kvRWSet.Reads = append(kvRWSet.Reads, rwsetutil.NewKVRead(kv.Key, orig.Version))
+			}
+			kvRWSet.Writes = append(kvRWSet.Writes, w)
+		}
+	}
+
+	rollback := &rwsetutil.TxRwSet{}
+	var namespaces []string
+	for ns := range writes {
+		namespaces = append(namespaces, ns)
+	}
+	for ns := range hashedRWSets {
+		if _, ok := writes[ns]; !ok {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		nsWrites := writes[ns]
+		sort.Slice(nsWrites, func(i, j int) bool { return nsWrites[i].Key < nsWrites[j].Key })
+		nsRWSet := &rwsetutil.NsRwSet{NameSpace: ns, KvRwSet: &kvrwset.KVRWSet{Writes: nsWrites}}
+		sort.Strings(hashedColls[ns])
+		for _, coll := range hashedColls[ns] {
+			hashedRWSet := hashedRWSets[ns][coll]
+			sort.Slice(hashedRWSet.HashedReads, func(i, j int) bool {
+				return bytes.Compare(hashedRWSet.HashedReads[i].KeyHash, hashedRWSet.HashedReads[j].KeyHash) < 0
+			})
+			sort.Slice(hashedRWSet.HashedWrites, func(i, j int) bool {
+				return bytes.Compare(hashedRWSet.HashedWrites[i].KeyHash, hashedRWSet.HashedWrites[j].KeyHash) < 0
+			})
+			nsRWSet.CollHashedRwSets = append(nsRWSet.CollHashedRwSets, &rwsetutil.CollHashedRwSet{CollectionName: coll, HashedRwSet: hashedRWSet})
+		}
+		rollback.NsRwSets = append(rollback.NsRwSets, nsRWSet)
+	}
+	if len(pvtRWSets) == 0 {
+		return rollback, nil
+	}
+
+	pvtRollback := &rwsetutil.TxPvtRwSet{}
+	var pvtNamespaces []string
+	for ns := range pvtRWSets {
+		pvtNamespaces = append(pvtNamespaces, ns)
+	}
+	sort.Strings(pvtNamespaces)
+	for _, ns := range pvtNamespaces {
+		nsPvtRWSet := &rwsetutil.NsPvtRwSet{NameSpace: ns}
+		sort.Strings(pvtColls[ns])
+		for _, coll := range pvtColls[ns] {
+			kvRWSet := pvtRWSets[ns][coll]
+			sort.Slice(kvRWSet.Reads, func(i, j int) bool { return kvRWSet.Reads[i].Key < kvRWSet.Reads[j].Key })
+			sort.Slice(kvRWSet.Writes, func(i, j int) bool { return kvRWSet.Writes[i].Key < kvRWSet.Writes[j].Key })
+			nsPvtRWSet.CollPvtRwSets = append(nsPvtRWSet.CollPvtRwSets, &rwsetutil.CollPvtRwSet{CollectionName: coll, KvRwSet: kvRWSet})
+		}
+		pvtRollback.NsPvtRwSet = append(pvtRollback.NsPvtRwSet, nsPvtRWSet)
+	}
+	return rollback, pvtRollback
+}
+
+func constructCrossRollbackKey(blockNum, txNum uint64) []byte {
+	return append(append([]byte{}, crossRollbackKeyPrefix...), constructCrossConfirmationKey(blockNum, txNum)...)
+}
+
+func constructCrossPvtRollbackKey(blockNum, txNum uint64) []byte {
+	return append(append([]byte{}, crossPvtRollbackKeyPrefix...), constructCrossConfirmationKey(blockNum, txNum)...)
+}
+
+func constructLegacyCrossRollbackKey(blockNum uint64) []byte {
+	return append(append([]byte{}, crossRollbackKeyPrefix...), constructCrossBlockKey(blockNum)...)
+}
+
+// constructCrossBlockKey returns the key of the per-block entry of the crossdb, which records a block
+// carrying cross transactions, or the single confirmation of a block committed by earlier versions
+func constructCrossBlockKey(blockNum uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, blockNum)
+	return key
+}
+
+// constructCrossConfirmationKey returns the key of the entry of the crossdb recording the confirmation
+// at position txNum of a block. It follows the per-block entry of the block in the key order.
+func constructCrossConfirmationKey(blockNum, txNum uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, blockNum)
+	binary.BigEndian.PutUint64(key[8:], txNum)
+	return key
+}
+
+// decodeCrossBlockKey returns the block number of a per-block or a confirmation entry of the crossdb
+func decodeCrossBlockKey(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key)
+}
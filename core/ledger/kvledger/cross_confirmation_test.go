@@ -0,0 +1,393 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCrossConfirmations(t *testing.T) {
+	bg, _ := testutil.NewBlockGenerator(t, "testLedger", false)
+	prev := bg.NextBlock(nil)
+
+	confs, err := extractCrossConfirmations(constructConfirmationBlock(t, "testLedger", prev, "tx_1_succ"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*crossConfirmation{{txID: "tx_1", success: true, raw: []byte("tx_1_succ")}}, confs)
+
+	confs, err = extractCrossConfirmations(constructConfirmationBlock(t, "testLedger", prev, "tx1_fail"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*crossConfirmation{{txID: "tx1", success: false, raw: []byte("tx1_fail")}}, confs)
+
+	for _, invalid := range []string{"tx1", "_fail", "tx1_unknown", ""} {
+		_, err = extractCrossConfirmations(constructConfirmationBlock(t, "testLedger", prev, "tx0_succ", invalid))
+		assert.Error(t, err, "confirmation [%s] should be rejected", invalid)
+	}
+	typed := putils.MarshalOrPanic(&crosspb.Confirmation{CrossTxId: "tx_1_succ", Outcome: crosspb.Outcome_FAILURE})
+	confs, err = extractCrossConfirmations(constructConfirmationBlock(t, "testLedger", prev, string(typed)))
+	assert.NoError(t, err)
+	assert.Equal(t, []*crossConfirmation{{txID: "tx_1_succ", success: false, raw: typed}}, confs)
+
+	// the confirmations are kept in the order of the block, skipping the invalid ones and the repeated ones
+	block := constructConfirmationBlock(t, "testLedger", prev, "tx1_succ", "tx2_fail", "tx3_succ", "tx1_fail", "tx4_fail")
+	txsFilter := lutil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsFilter.SetFlag(2, peer.TxValidationCode_INVALID_OTHER_REASON)
+	// as earlier versions did
+	txsFilter.SetFlag(4, peer.TxValidationCode_NOT_VALIDATED)
+	confs, err = extractCrossConfirmations(block)
+	assert.NoError(t, err)
+	assert.Equal(t, []*crossConfirmation{
+		{txNum: 0, txID: "tx1", success: true, raw: []byte("tx1_succ")},
+		{txNum: 1, txID: "tx2", success: false, raw: []byte("tx2_fail")},
+		{txNum: 4, txID: "tx4", success: false, raw: []byte("tx4_fail")},
+	}, confs)
+
+	_, err = extractCrossConfirmations(&common.Block{Header: &common.BlockHeader{}, Data: &common.BlockData{}})
+	assert.Error(t, err)
+}
+
+func TestCrossRollbackVersionsAndRebuild(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	// block 1 sets the values the cross transaction is going to overwrite
+	txid1 := util.GenerateUUID()
+	simulator, _ := ledger.NewTxSimulator(txid1)
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetState("ns1", "key2", []byte("value2"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	block1 := bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid1})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+
+	// block 2 commits the writes of the cross transaction, which locks its keys
+	crossTxID := util.GenerateUUID()
+	block2 := constructCrossTxBlock(block1, constructCrossTxEnv(t, crossTxID, simulateTx(t, ledger, crossTxID, func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1-cross"))
+		s.SetState("ns1", "key3", []byte("value3-cross"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+
+	// block 3 confirms the failure of the cross transaction
+	block3 := constructConfirmationBlock(t, "testLedger", block2, crossTxID+"_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+	assert.Empty(t, crossLockMgr.LockedKeys("testLedger"))
+
+	verifyRolledBack := func(ledger lgr.PeerLedger) {
+		kvl := ledger.(*kvLedger)
+		// the rolled back keys carry the height of the confirmation block
+		assertCommittedVersion(t, ledger, "ns1", "key1", []byte("value1"), version.NewHeight(3, 0))
+		assertCommittedVersion(t, ledger, "ns1", "key2", []byte("value2"), version.NewHeight(1, 0))
+		assertCommittedVersion(t, ledger, "ns1", "key3", nil, nil)
+
+		// and the savepoints moved to the confirmation block
+		sp, err := kvl.txtmgmt.GetLastSavepoint()
+		assert.NoError(t, err)
+		assert.Equal(t, version.NewHeight(3, 0), sp)
+		sp, err = kvl.historyDB.GetLastSavepoint()
+		assert.NoError(t, err)
+		assert.Equal(t, version.NewHeight(3, 1), sp)
+
+		// the rollback shows in the history as a modification made by the cross transaction
+		assertHistory(t, ledger, "ns1", "key1", []*queryresult.KeyModification{
+			{TxId: txid1, Value: []byte("value1")},
+			{TxId: crossTxID, Value: []byte("value1-cross")},
+			{TxId: crossTxID, Value: []byte("value1")},
+		})
+		assertHistory(t, ledger, "ns1", "key3", []*queryresult.KeyModification{
+			{TxId: crossTxID, Value: []byte("value3-cross")},
+			{TxId: crossTxID, IsDelete: true},
+		})
+		assertHistory(t, ledger, "ns1", "key2", []*queryresult.KeyModification{
+			{TxId: txid1, Value: []byte("value2")},
+		})
+	}
+	verifyRolledBack(ledger)
+
+	// rebuild the state and history databases from the block store
+	ledger.Close()
+	provider.Close()
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetStateLevelDBPath()))
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetHistoryLevelDBPath()))
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer ledger.Close()
+	verifyRolledBack(ledger)
+	assert.Empty(t, crossLockMgr.PendingTxs("testLedger"))
+
+	// a transaction simulated on the rolled back state commits as valid
+	simulator, _ = ledger.NewTxSimulator(util.GenerateUUID())
+	_, err = simulator.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	simulator.SetState("ns1", "key1", []byte("value1-new"))
+	simulator.Done()
+	simRes, _ = simulator.GetTxSimulationResults(nil)
+	pubSimBytes, _ = simRes.GetPubSimulationBytes()
+	block4 := testutil.ConstructBlock(t, 4, block3.Header.Hash(), [][]byte{pubSimBytes}, false)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+	assertCommittedVersion(t, ledger, "ns1", "key1", []byte("value1-new"), version.NewHeight(4, 0))
+}
+
+func TestCrossSuccessConfirmation(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	crossTxID := util.GenerateUUID()
+	block1 := constructCrossTxBlock(gb, constructCrossTxEnv(t, crossTxID, simulateTx(t, ledger, crossTxID, func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1-cross"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+	assert.True(t, crossLockMgr.IsPrepared("testLedger", crossTxID))
+
+	block2 := constructConfirmationBlock(t, "testLedger", block1, crossTxID+"_succ")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	assert.False(t, crossLockMgr.IsCrossTx("testLedger", crossTxID))
+	assertCommittedVersion(t, ledger, "ns1", "key1", []byte("value1-cross"), version.NewHeight(1, 0))
+	sp, err := ledger.(*kvLedger).txtmgmt.GetLastSavepoint()
+	assert.NoError(t, err)
+	assert.Equal(t, version.NewHeight(2, 0), sp)
+	rollbackBytes, err := ledger.(*kvLedger).crossDB.Get(constructCrossRollbackKey(2, 0))
+	assert.NoError(t, err)
+	assert.Nil(t, rollbackBytes)
+}
+
+func TestCrossFailureConfirmationBeforePrepare(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	kvl := ledger.(*kvLedger)
+
+	// the failure confirmation of a cross transaction not prepared yet aborts it
+	crossTxID := util.GenerateUUID()
+	block1 := constructConfirmationBlock(t, "testLedger", gb, crossTxID+"_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+	status, err := kvl.getCrossTxState(crossTxID)
+	assert.NoError(t, err)
+	assert.Equal(t, crosspb.CrossTxState_ABORTED, status.GetState())
+	assert.Equal(t, uint64(1), status.GetConfirmationBlock())
+
+	// the transaction, were it committed afterwards, takes no lock and stays aborted
+	block2 := constructCrossTxBlock(block1, constructCrossTxEnv(t, crossTxID, simulateTx(t, ledger, crossTxID, func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1-cross"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	assert.False(t, crossLockMgr.IsCrossTx("testLedger", crossTxID))
+	assert.Empty(t, crossLockMgr.LockedKeys("testLedger"))
+	status, err = kvl.getCrossTxState(crossTxID)
+	assert.NoError(t, err)
+	assert.Equal(t, crosspb.CrossTxState_ABORTED, status.GetState())
+	assert.Equal(t, uint64(1), status.GetConfirmationBlock())
+}
+
+func TestCrossConfirmationsInOneBlock(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	block1 := bg.NextBlockWithTxid([][]byte{simulateTx(t, ledger, "tx1", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1"))
+		s.SetState("ns1", "key2", []byte("value2"))
+	})}, []string{"tx1"})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+	// block 2 carries the cross transactions along with a regular transaction
+	block2 := constructCrossTxBlock(block1,
+		constructTxEnv(t, "tx2", simulateTx(t, ledger, "tx2", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key0", []byte("value0"))
+		})),
+		constructCrossTxEnv(t, "cross1", simulateTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key1", []byte("value1-cross1"))
+		})),
+		constructCrossTxEnv(t, "cross2", simulateTx(t, ledger, "cross2", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key2", []byte("value2-cross2"))
+		})),
+		constructCrossTxEnv(t, "cross3", simulateTx(t, ledger, "cross3", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key3", []byte("value3-cross3"))
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	assertCommittedVersion(t, ledger, "ns1", "key0", []byte("value0"), version.NewHeight(2, 0))
+	assert.Len(t, crossLockMgr.PendingTxs("testLedger"), 3)
+
+	// block 3 confirms the three cross transactions, every failure rolled back at the height of its confirmation
+	block3 := constructConfirmationBlock(t, "testLedger", block2, "cross1_succ", "cross2_fail", "cross3_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+	assert.Empty(t, crossLockMgr.PendingTxs("testLedger"))
+
+	verifyConfirmed := func(ledger lgr.PeerLedger) {
+		assertCommittedVersion(t, ledger, "ns1", "key1", []byte("value1-cross1"), version.NewHeight(2, 1))
+		assertCommittedVersion(t, ledger, "ns1", "key2", []byte("value2"), version.NewHeight(3, 1))
+		assertCommittedVersion(t, ledger, "ns1", "key3", nil, nil)
+		assertHistory(t, ledger, "ns1", "key2", []*queryresult.KeyModification{
+			{TxId: "tx1", Value: []byte("value2")},
+			{TxId: "cross2", Value: []byte("value2-cross2")},
+			{TxId: "cross2", Value: []byte("value2")},
+		})
+		assertHistory(t, ledger, "ns1", "key3", []*queryresult.KeyModification{
+			{TxId: "cross3", Value: []byte("value3-cross3")},
+			{TxId: "cross3", IsDelete: true},
+		})
+
+		querier, err := ledger.GetCrossTxQuerier()
+		assert.NoError(t, err)
+		confs, err := querier.GetConfirmationsByBlock(3)
+		assert.NoError(t, err)
+		assert.Equal(t, []*crosspb.Confirmation{
+			{CrossTxId: "cross1", Outcome: crosspb.Outcome_SUCCESS},
+			{CrossTxId: "cross2", Outcome: crosspb.Outcome_FAILURE},
+			{CrossTxId: "cross3", Outcome: crosspb.Outcome_FAILURE},
+		}, confs)
+		status, err := querier.GetCrossTxStatus("cross3")
+		assert.NoError(t, err)
+		assert.Equal(t, crosspb.CrossTxState_ABORTED, status.State)
+		assert.Equal(t, uint64(3), status.ConfirmationBlock)
+	}
+	verifyConfirmed(ledger)
+
+	// rebuild the state and history databases from the block store
+	ledger.Close()
+	provider.Close()
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetStateLevelDBPath()))
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetHistoryLevelDBPath()))
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer ledger.Close()
+	verifyConfirmed(ledger)
+	assert.Empty(t, crossLockMgr.PendingTxs("testLedger"))
+}
+
+func TestCrossRollbackLegacyKey(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	kvl := ledger.(*kvLedger)
+
+	// earlier versions kept the rollback of a block by block number only
+	rollback, pvtRollback := buildCrossRollback([]statedb.KeyOrigVal{{Namespace: "ns1", Key: "key1"}})
+	assert.Nil(t, pvtRollback)
+	rollbackBytes, err := rollback.ToProtoBytes()
+	assert.NoError(t, err)
+	assert.NoError(t, kvl.crossDB.Put(constructLegacyCrossRollbackKey(5), rollbackBytes, true))
+
+	batch := crossdb.NewUpdateBatch()
+	actual, err := kvl.crossRollback(batch, 5, &crossConfirmation{txNum: 0, txID: "cross1"})
+	assert.NoError(t, err)
+	assert.Equal(t, &rwsetutil.CrossRollback{TxNum: 0, CrossTxID: "cross1", RwSet: rollback}, actual)
+	assert.Equal(t, 0, batch.Len())
+
+	// a rollback built for the first time is added to the batch, and persisted only when the batch is written
+	actual, err = kvl.crossRollback(batch, 5, &crossConfirmation{txNum: 1, txID: "cross2"})
+	assert.NoError(t, err)
+	assert.Empty(t, actual.RwSet.NsRwSets)
+	rollbackBytes, ok := batch.Get(constructCrossRollbackKey(5, 1))
+	assert.True(t, ok)
+	expected, err := actual.RwSet.ToProtoBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, rollbackBytes)
+	persisted, err := kvl.crossDB.Get(constructCrossRollbackKey(5, 1))
+	assert.NoError(t, err)
+	assert.Nil(t, persisted)
+}
+
+// assertCommittedVersion checks the committed value of a key and the version read by a simulation
+func assertCommittedVersion(t *testing.T, ledger lgr.PeerLedger, ns, key string, expectedValue []byte, expectedVersion *version.Height) {
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	val, err := simulator.GetState(ns, key)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedValue, val)
+	simulator.Done()
+	simRes, err := simulator.GetTxSimulationResults(nil)
+	assert.NoError(t, err)
+	txRWSet, err := rwsetutil.TxRwSetFromProtoMsg(simRes.PubSimulationResults)
+	assert.NoError(t, err)
+	read := txRWSet.NsRwSets[0].KvRwSet.Reads[0]
+	if expectedVersion == nil {
+		assert.Nil(t, read.Version)
+		return
+	}
+	assert.Equal(t, expectedVersion, version.NewHeight(read.Version.BlockNum, read.Version.TxNum))
+}
+
+func assertHistory(t *testing.T, ledger lgr.PeerLedger, ns, key string, expected []*queryresult.KeyModification) {
+	qhistory, err := ledger.NewHistoryQueryExecutor()
+	assert.NoError(t, err)
+	itr, err := qhistory.GetHistoryForKey(ns, key)
+	assert.NoError(t, err)
+	defer itr.Close()
+	var actual []*queryresult.KeyModification
+	for {
+		res, err := itr.Next()
+		assert.NoError(t, err)
+		if res == nil {
+			break
+		}
+		mod := res.(*queryresult.KeyModification)
+		actual = append(actual, &queryresult.KeyModification{TxId: mod.TxId, Value: mod.Value, IsDelete: mod.IsDelete})
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -1,15 +1,15 @@
 package kvledger
 
-import "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
-
 // NEW File
 // everything is new
 
-type RollbackInterface interface {
-	CrossRollbackOrigVal(kov *[]statedb.KeyOrigVal)
-}
-
 type CrossGetStateInterface interface {
 	// GetStateNoRSet(namespace string, key string) (*statedb.VersionedValue, error)
 	GetStateNoRSet(namespace string, key string) ([]byte, error)
+	// GetValueHashNoRSet returns the hash of the value of a key of a collection, read by the hash of the key,
+	// in the form returned by GetStateNoRSet
+	GetValueHashNoRSet(namespace, collection string, keyHash []byte) ([]byte, error)
+	// GetPrivateDataNoRSet returns the value of a key of a collection, in the form returned by GetStateNoRSet.
+	// A peer not member of the collection returns nil.
+	GetPrivateDataNoRSet(namespace, collection, key string) ([]byte, error)
 }
@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/peer/cross"
+)
+
+// crossLockKeyPrefix prefixes the keys under which the records of the in-flight cross
+// transactions are kept in the crossdb. The keys of the per-block entries are 8 bytes
+// big-endian block numbers, which never start with this prefix for any realistic height.
+var crossLockKeyPrefix = []byte("lock~")
+
+// crossLockStore implements cross.Store on top of the crossdb of a ledger
+type crossLockStore struct {
+	db crossdb.CrossDB
+}
+
+func newCrossLockStore(db crossdb.CrossDB) *crossLockStore {
+	return &crossLockStore{db}
+}
+
+// PutTxRecord implements method in interface `cross.Store`
+func (s *crossLockStore) PutTxRecord(rec *cross.TxRecord) error {
+	b, err := cross.MarshalTxRecord(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(constructCrossLockKey(rec.TxID), b, true)
+}
+
+// DeleteTxRecord implements method in interface `cross.Store`
+func (s *crossLockStore) DeleteTxRecord(txID string) error {
+	return s.db.Delete(constructCrossLockKey(txID), true)
+}
+
+// GetTxRecords implements method in interface `cross.Store`
+func (s *crossLockStore) GetTxRecords() ([]*cross.TxRecord, error) {
+	itr := s.db.GetIterator(crossLockKeyPrefix, crossLockRangeEnd())
+	defer itr.Release()
+	var recs []*cross.TxRecord
+	for itr.Next() {
+		rec, err := cross.UnmarshalTxRecord(itr.Value())
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func constructCrossLockKey(txID string) []byte {
+	return append(append([]byte{}, crossLockKeyPrefix...), []byte(txID)...)
+}
+
+func crossLockRangeEnd() []byte {
+	end := append([]byte{}, crossLockKeyPrefix...)
+	end[len(end)-1]++
+	return end
+}
@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossLockStateRecovery(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	// block 1 sets the values the cross transaction is going to overwrite
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetState("ns1", "key2", []byte("value2"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+
+	block1 := bg.NextBlock([][]byte{pubSimBytes})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+
+	// block 2 commits the cross transaction, which locks its keys and records their original values
+	crossTxID := util.GenerateUUID()
+	block2 := constructCrossTxBlock(block1, constructCrossTxEnv(t, crossTxID, simulateTx(t, ledger, crossTxID, func(s lgr.TxSimulator) {
+		s.GetState("ns1", "key2")
+		s.SetState("ns1", "key1", []byte("value1-cross"))
+		s.SetState("ns1", "key3", []byte("value3-cross"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	expectedUndo := []statedb.KeyOrigVal{
+		{Namespace: "ns1", Key: "key1", OriginalVersionedValue: statedb.EncodeOrigValue(
+			&statedb.VersionedValue{Value: []byte("value1"), Version: version.NewHeight(1, 0)})},
+		{Namespace: "ns1", Key: "key3"},
+	}
+	assert.Equal(t, expectedUndo, crossLockMgr.RollbackKVs("testLedger", crossTxID))
+
+	// the peer goes down before the confirmation arrives and comes back with an empty lock table
+	ledger.Close()
+	provider.Close()
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	assert.True(t, crossLockMgr.IsCrossTx("testLedger", crossTxID))
+	assert.Equal(t, []statedb.CompositeKey{
+		{Namespace: "ns1", Key: "key1"},
+		{Namespace: "ns1", Key: "key2"},
+		{Namespace: "ns1", Key: "key3"},
+	}, crossLockMgr.LockedKeys("testLedger"))
+	assert.Equal(t, expectedUndo, crossLockMgr.RollbackKVs("testLedger", crossTxID))
+
+	// the recovered undo log is used to roll back the transaction once it is confirmed as failed
+	block3 := constructConfirmationBlock(t, "testLedger", block2, crossTxID+"_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+
+	qe, _ := ledger.NewQueryExecutor()
+	defer qe.Done()
+	val, err := qe.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+	val, err = qe.GetState("ns1", "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value2"), val)
+	val, err = qe.GetState("ns1", "key3")
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	assert.False(t, crossLockMgr.IsCrossTx("testLedger", crossTxID))
+	assert.Empty(t, crossLockMgr.LockedKeys("testLedger"))
+	recs, err := newCrossLockStore(ledger.(*kvLedger).crossDB).GetTxRecords()
+	assert.NoError(t, err)
+	assert.Empty(t, recs)
+}
+
+func constructConfirmationBlock(t *testing.T, chainID string, prevBlock *common.Block, confirmations ...string) *common.Block {
+	var envs []*common.Envelope
+	for _, confirmation := range confirmations {
+		chdr := putils.MakeChannelHeader(common.HeaderType_ENDORSER_TRANSACTION, 0, chainID, 0)
+		payload := &common.Payload{
+			Header: putils.MakePayloadHeader(chdr, &common.SignatureHeader{}),
+			Data:   []byte(confirmation),
+		}
+		payloadBytes, err := proto.Marshal(payload)
+		assert.NoError(t, err)
+		envs = append(envs, &common.Envelope{Payload: payloadBytes, CrossInfo: []byte("confirmation")})
+	}
+	return testutil.NewBlock(envs, prevBlock.Header.Number+1, prevBlock.Header.Hash())
+}
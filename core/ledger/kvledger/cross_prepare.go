@@ -0,0 +1,268 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// crossLockChecker exposes the lock table of the ledger to the validation of the blocks
+type crossLockChecker struct {
+	ledgerID string
+}
+
+// IsLockedByOther implements method in interface `validator.CrossLockChecker`
+func (c *crossLockChecker) IsLockedByOther(txID, ns, key string, mode cross.LockMode) bool {
+	_, isLocked := crossLockMgr.CheckKey(c.ledgerID, txID, ns, key, mode)
+	return isLocked
+}
+
+// IsRangeLockedByOther implements method in interface `validator.CrossLockChecker`
+func (c *crossLockChecker) IsRangeLockedByOther(txID string, r cross.RangeLock) bool {
+	return crossLockMgr.CheckRange(c.ledgerID, txID, r)
+}
+
+func isCrossTxBlock(block *common.Block) bool {
+	return utils.IsCrossTxBlock(block)
+}
+
+// prepareCrossTxs locks the keys accessed by the valid cross transactions of the given block until their
+// confirmation, and records the original value of the keys they wrote. The lock table is thereby derived
+// from the committed blocks only, so that every peer of the channel, endorsing or not, holds the same locks
+// and is able to roll back a failed transaction. The keys of private data collections are locked by their hash,
+// and the original values of their hashes are recorded on every peer, while the original values of the keys
+// themselves are recorded by the members of the collections, from the private data of the block. It is to be
+// called after the validation of the block and before its writes are applied to the state, which provides the
+// original values. The states of the prepared transactions and the per-block entry of the block are added to
+// the given batch of the crossdb. A transaction committed or aborted already, as when a block preceding its
+// confirmation is committed again, takes no lock, since no confirmation would release it.
+func (l *kvLedger) prepareCrossTxs(pvtdataAndBlock *ledger.BlockAndPvtData, batch *crossdb.UpdateBatch) error {
+	block := pvtdataAndBlock.Block
+	if !isCrossTxBlock(block) {
+		return nil
+	}
+	batch.Put(constructCrossBlockKey(block.Header.Number), []byte("crosstx"))
+	qe, err := l.txtmgmt.NewQueryExecutor(util.GenerateUUID())
+	if err != nil {
+		return err
+	}
+	defer qe.Done()
+	reader, ok := qe.(CrossGetStateInterface)
+	if !ok {
+		return errors.Errorf("query executor of ledger [%s] does not read the original values of cross transactions", l.ledgerID)
+	}
+
+	// blockWrites holds the values written by the preceding valid transactions of the block,
+	// in the form of the undo log
+	blockWrites := make(map[crossUndoKey][]byte)
+	txsFilter := lutil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	for txIndex, envBytes := range block.Data.Data {
+		if txsFilter.IsInvalid(txIndex) {
+			continue
+		}
+		env, err := utils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("invalid transaction [%d] in block [%d]", txIndex, block.Header.Number))
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("invalid transaction [%d] in block [%d]", txIndex, block.Header.Number))
+		}
+		if common.HeaderType(chdr.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+		respPayload, err := utils.GetActionFromEnvelope(envBytes)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("invalid transaction [%d] in block [%d]", txIndex, block.Header.Number))
+		}
+		txRWSet := &rwsetutil.TxRwSet{}
+		if err := txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("invalid transaction [%d] in block [%d]", txIndex, block.Header.Number))
+		}
+		var txPvtRWSet *rwsetutil.TxPvtRwSet
+		if pvtData, ok := pvtdataAndBlock.BlockPvtData[uint64(txIndex)]; ok && pvtData.WriteSet != nil {
+			if txPvtRWSet, err = rwsetutil.TxPvtRwSetFromProtoMsg(pvtData.WriteSet); err != nil {
+				return errors.WithMessage(err, fmt.Sprintf("invalid private data of transaction [%d] in block [%d]", txIndex, block.Header.Number))
+			}
+		}
+
+		if utils.IsCrossTx(env) {
+			err := l.prepareCrossTx(batch, reader, blockWrites, block.Header.Number, chdr.TxId, txRWSet, txPvtRWSet)
+			if err != nil {
+				return err
+			}
+		}
+
+		recordCrossBlockWrites(blockWrites, version.NewHeight(block.Header.Number, uint64(txIndex)), txRWSet, txPvtRWSet)
+	}
+	return nil
+}
+
+// prepareCrossTx locks the keys accessed by a cross transaction of the given block and records their original
+// values, unless the transaction is committed or aborted already
+func (l *kvLedger) prepareCrossTx(batch *crossdb.UpdateBatch, reader CrossGetStateInterface, blockWrites map[crossUndoKey][]byte,
+	blockNum uint64, txID string, txRWSet *rwsetutil.TxRwSet, txPvtRWSet *rwsetutil.TxPvtRwSet) error {
+	status, err := l.crossRecords.GetUpdatedRecord(batch, txID)
+	if err != nil {
+		return err
+	}
+	if isTerminalCrossTxState(status) {
+		logger.Warningf("[%s] Cross transaction [%s] of block [%d] already %s in block [%d], not preparing it",
+			l.ledgerID, txID, blockNum, status.State, status.ConfirmationBlock)
+		return nil
+	}
+	keys, ranges := cross.LockKeys(txRWSet), cross.LockRanges(txRWSet)
+	undo, err := crossUndo(reader, txRWSet, txPvtRWSet, blockWrites)
+	if err != nil {
+		return err
+	}
+	if err := crossLockMgr.PrepareTx(l.ledgerID, txID, blockNum, keys, ranges, undo); err != nil {
+		return err
+	}
+	if err := l.prepareCrossTxState(batch, txID, blockNum); err != nil {
+		return err
+	}
+	logger.Debugf("[%s] Cross transaction [%s] prepared in block [%d], holding %d key(s) and %d range(s) until its confirmation",
+		l.ledgerID, txID, blockNum, len(keys), len(ranges))
+	return nil
+}
+
+// crossUndoKey identifies a key in the undo log: a public key, the hash of a key of a collection, or a key of
+// a collection
+type crossUndoKey struct {
+	ns, coll, key string
+	hashed        bool
+}
+
+// recordCrossBlockWrites records the writes of a valid transaction of a block, committed at the given height,
+// in the form of the undo log
+func recordCrossBlockWrites(blockWrites map[crossUndoKey][]byte, height *version.Height,
+	txRWSet *rwsetutil.TxRwSet, txPvtRWSet *rwsetutil.TxPvtRwSet) {
+	origVal := func(isDelete bool, value []byte) []byte {
+		if isDelete {
+			return nil
+		}
+		return statedb.EncodeOrigValue(&statedb.VersionedValue{Value: value, Version: height})
+	}
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		ns := nsRWSet.NameSpace
+		if nsRWSet.KvRwSet != nil {
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				blockWrites[crossUndoKey{ns: ns, key: kvWrite.Key}] = origVal(kvWrite.IsDelete, kvWrite.Value)
+			}
+		}
+		for _, collHashedRWSet := range nsRWSet.CollHashedRwSets {
+			if collHashedRWSet.HashedRwSet == nil {
+				continue
+			}
+			for _, hashedWrite := range collHashedRWSet.HashedRwSet.HashedWrites {
+				k := crossUndoKey{ns: ns, coll: collHashedRWSet.CollectionName, key: string(hashedWrite.KeyHash), hashed: true}
+				blockWrites[k] = origVal(hashedWrite.IsDelete, hashedWrite.ValueHash)
+			}
+		}
+	}
+	if txPvtRWSet == nil {
+		return
+	}
+	for _, nsPvtRWSet := range txPvtRWSet.NsPvtRwSet {
+		for _, collPvtRWSet := range nsPvtRWSet.CollPvtRwSets {
+			if collPvtRWSet.KvRwSet == nil {
+				continue
+			}
+			for _, kvWrite := range collPvtRWSet.KvRwSet.Writes {
+				k := crossUndoKey{ns: nsPvtRWSet.NameSpace, coll: collPvtRWSet.CollectionName, key: kvWrite.Key}
+				blockWrites[k] = origVal(kvWrite.IsDelete, kvWrite.Value)
+			}
+		}
+	}
+}
+
+// crossUndo returns the original values of the keys written by a cross transaction, in the order of its write-set:
+// the public keys, then the hashes of the keys of collections, then the keys of collections given by the private
+// data of the transaction, if the peer holds it. The original values are the values written by the preceding
+// transactions of the block or else the committed values.
+func crossUndo(reader CrossGetStateInterface, txRWSet *rwsetutil.TxRwSet, txPvtRWSet *rwsetutil.TxPvtRwSet,
+	blockWrites map[crossUndoKey][]byte) ([]statedb.KeyOrigVal, error) {
+	var undo []statedb.KeyOrigVal
+	recorded := make(map[crossUndoKey]struct{})
+	record := func(k crossUndoKey, read func() ([]byte, error)) error {
+		if _, ok := recorded[k]; ok {
+			return nil
+		}
+		recorded[k] = struct{}{}
+		origVal, ok := blockWrites[k]
+		if !ok {
+			var err error
+			if origVal, err = read(); err != nil {
+				return err
+			}
+		}
+		kv := statedb.KeyOrigVal{Namespace: k.ns, Collection: k.coll, Key: k.key, OriginalVersionedValue: origVal}
+		if k.hashed {
+			kv.Key, kv.KeyHash = "", []byte(k.key)
+		}
+		undo = append(undo, kv)
+		return nil
+	}
+
+	var hashed []crossUndoKey
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		ns := nsRWSet.NameSpace
+		// the chaincode definitions are never locked, hence never restored
+		if nsRWSet.KvRwSet != nil && ns != "lscc" {
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				key := kvWrite.Key
+				if err := record(crossUndoKey{ns: ns, key: key}, func() ([]byte, error) { return reader.GetStateNoRSet(ns, key) }); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, collHashedRWSet := range nsRWSet.CollHashedRwSets {
+			if collHashedRWSet.HashedRwSet == nil {
+				continue
+			}
+			for _, hashedWrite := range collHashedRWSet.HashedRwSet.HashedWrites {
+				hashed = append(hashed, crossUndoKey{ns: ns, coll: collHashedRWSet.CollectionName, key: string(hashedWrite.KeyHash), hashed: true})
+			}
+		}
+	}
+	for _, k := range hashed {
+		k := k
+		if err := record(k, func() ([]byte, error) { return reader.GetValueHashNoRSet(k.ns, k.coll, []byte(k.key)) }); err != nil {
+			return nil, err
+		}
+	}
+	if txPvtRWSet == nil {
+		return undo, nil
+	}
+	for _, nsPvtRWSet := range txPvtRWSet.NsPvtRwSet {
+		for _, collPvtRWSet := range nsPvtRWSet.CollPvtRwSets {
+			if collPvtRWSet.KvRwSet == nil {
+				continue
+			}
+			for _, kvWrite := range collPvtRWSet.KvRwSet.Writes {
+				k := crossUndoKey{ns: nsPvtRWSet.NameSpace, coll: collPvtRWSet.CollectionName, key: kvWrite.Key}
+				if err := record(k, func() ([]byte, error) { return reader.GetPrivateDataNoRSet(k.ns, k.coll, k.key) }); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return undo, nil
+}
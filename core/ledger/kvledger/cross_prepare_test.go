@@ -0,0 +1,276 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossLocksFromCommittedBlocks(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	block1 := bg.NextBlock([][]byte{simulateTx(t, ledger, "tx1", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1"))
+		s.SetState("ns1", "key2", []byte("value2"))
+	})})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+
+	// the simulation of a cross transaction locks nothing, its commit does
+	crossTx1 := simulateTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+		s.GetState("ns1", "key2")
+		s.SetState("ns1", "key1", []byte("value1-cross1"))
+		s.GetState("lscc", "ns1")
+	})
+	assert.Empty(t, crossLockMgr.PendingTxs("testLedger"))
+	block2 := constructCrossTxBlock(block1, constructCrossTxEnv(t, "cross1", crossTx1))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	assert.True(t, crossLockMgr.IsPrepared("testLedger", "cross1"))
+	assert.Equal(t, []statedb.CompositeKey{{Namespace: "ns1", Key: "key1"}, {Namespace: "ns1", Key: "key2"}},
+		crossLockMgr.LockedKeys("testLedger"))
+	assert.Equal(t, []statedb.KeyOrigVal{
+		{Namespace: "ns1", Key: "key1", OriginalVersionedValue: statedb.EncodeOrigValue(
+			&statedb.VersionedValue{Value: []byte("value1"), Version: version.NewHeight(1, 0)})},
+	}, crossLockMgr.RollbackKVs("testLedger", "cross1"))
+
+	// a transaction reading a key only read by a cross transaction is valid. A transaction accessing
+	// a key written by a cross transaction is invalid, and so is one accessing a key locked by a
+	// preceding cross transaction of the same block. The undo log of a cross transaction records
+	// the values written by the preceding transactions of the block.
+	block3 := constructCrossTxBlock(block2,
+		constructTxEnv(t, "reader", simulateTx(t, ledger, "reader", func(s lgr.TxSimulator) {
+			s.GetState("ns1", "key2")
+		})),
+		constructTxEnv(t, "writer", simulateTx(t, ledger, "writer", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key3", []byte("value3"))
+		})),
+		constructCrossTxEnv(t, "cross2", simulateTx(t, ledger, "cross2", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key3", []byte("value3-cross2"))
+			s.SetState("ns1", "key4", []byte("value4-cross2"))
+		})),
+		constructTxEnv(t, "late-writer", simulateTx(t, ledger, "late-writer", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key4", []byte("value4"))
+		})),
+		constructCrossTxEnv(t, "cross3", simulateTx(t, ledger, "cross3", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key1", []byte("value1-cross3"))
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+	txsFilter := lutil.TxValidationFlags(block3.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(0))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(1))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(2))
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(3))
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(4))
+
+	assert.Equal(t, []string{"cross1", "cross2"}, crossLockMgr.PendingTxs("testLedger"))
+	assert.Equal(t, []statedb.KeyOrigVal{
+		{Namespace: "ns1", Key: "key3", OriginalVersionedValue: statedb.EncodeOrigValue(
+			&statedb.VersionedValue{Value: []byte("value3"), Version: version.NewHeight(3, 1)})},
+		{Namespace: "ns1", Key: "key4"},
+	}, crossLockMgr.RollbackKVs("testLedger", "cross2"))
+	assertCommittedVersion(t, ledger, "ns1", "key4", []byte("value4-cross2"), version.NewHeight(3, 2))
+
+	// cross transactions reading the same key share it, whereas a transaction writing it is invalid
+	block4 := constructCrossTxBlock(block3,
+		constructCrossTxEnv(t, "cross4", simulateTx(t, ledger, "cross4", func(s lgr.TxSimulator) {
+			s.GetState("ns1", "key2")
+			s.SetState("ns1", "key5", []byte("value5-cross4"))
+		})),
+		constructCrossTxEnv(t, "cross5", simulateTx(t, ledger, "cross5", func(s lgr.TxSimulator) {
+			s.GetState("ns1", "key2")
+			s.SetState("ns1", "key5", []byte("value5-cross5"))
+		})),
+		constructTxEnv(t, "key2-writer", simulateTx(t, ledger, "key2-writer", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key2", []byte("value2-writer"))
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+	txsFilter = lutil.TxValidationFlags(block4.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(0))
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(1))
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(2))
+	assert.Equal(t, []string{"cross1", "cross2", "cross4"}, crossLockMgr.PendingTxs("testLedger"))
+	// only the written key is to be rolled back
+	assert.Equal(t, []statedb.KeyOrigVal{{Namespace: "ns1", Key: "key5"}}, crossLockMgr.RollbackKVs("testLedger", "cross4"))
+	block5 := constructCrossTxBlock(block4,
+		constructCrossTxEnv(t, "cross6", simulateTx(t, ledger, "cross6", func(s lgr.TxSimulator) {
+			s.GetState("ns1", "key2")
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block5}))
+	assert.True(t, crossLockMgr.IsPrepared("testLedger", "cross6"))
+
+	// the confirmation of a cross transaction releases its locks, a key shared with others remaining locked
+	block6 := constructConfirmationBlock(t, "testLedger", block5, "cross1_succ", "cross4_succ")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block6}))
+	block7 := constructCrossTxBlock(block6,
+		constructTxEnv(t, "key2-writer2", simulateTx(t, ledger, "key2-writer2", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key2", []byte("value2-writer2"))
+		})),
+		constructTxEnv(t, "key5-writer", simulateTx(t, ledger, "key5-writer", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key5", []byte("value5-writer"))
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block7}))
+	txsFilter = lutil.TxValidationFlags(block7.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(0))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(1))
+
+	block8 := constructConfirmationBlock(t, "testLedger", block7, "cross6_succ")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block8}))
+	block9 := constructCrossTxBlock(block8, constructTxEnv(t, "key2-writer3", simulateTx(t, ledger, "key2-writer3", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key2", []byte("value2-writer3"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block9}))
+	txsFilter = lutil.TxValidationFlags(block9.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(0))
+	assert.Equal(t, []string{"cross2"}, crossLockMgr.PendingTxs("testLedger"))
+}
+
+func TestCrossRangeLocksAndDeletes(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	querier, err := ledger.GetCrossTxQuerier()
+	assert.NoError(t, err)
+
+	block1 := bg.NextBlockWithTxid([][]byte{simulateTx(t, ledger, "tx1", func(s lgr.TxSimulator) {
+		for _, key := range []string{"key1", "key2", "key4", "key9"} {
+			s.SetState("ns1", key, []byte("value-"+key))
+		}
+	})}, []string{"tx1"})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+
+	// a cross transaction locks the range it scanned, another one deletes a key
+	block2 := constructCrossTxBlock(block1,
+		constructCrossTxEnv(t, "cross1", simulateTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+			scanRange(t, s, "ns1", "key1", "key5")
+		})),
+		constructCrossTxEnv(t, "cross2", simulateTx(t, ledger, "cross2", func(s lgr.TxSimulator) {
+			s.DeleteState("ns1", "key9")
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	status, err := querier.GetCrossTxStatus("cross1")
+	assert.NoError(t, err)
+	assert.Empty(t, status.LockedKeys)
+	assert.Equal(t, []*crosspb.LockedRange{{Namespace: "ns1", StartKey: "key1", EndKey: "key5", CrossTxId: "cross1"}}, status.LockedRanges)
+	assert.Equal(t, []statedb.KeyOrigVal{
+		{Namespace: "ns1", Key: "key9", OriginalVersionedValue: statedb.EncodeOrigValue(
+			&statedb.VersionedValue{Value: []byte("value-key9"), Version: version.NewHeight(1, 0)})},
+	}, crossLockMgr.RollbackKVs("testLedger", "cross2"))
+	assertCommittedVersion(t, ledger, "ns1", "key9", nil, nil)
+
+	// no transaction may insert a key in a locked range, nor scan a range holding a deleted key
+	block3 := constructCrossTxBlock(block2,
+		constructTxEnv(t, "inserter", simulateTx(t, ledger, "inserter", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key3", []byte("value-key3"))
+		})),
+		constructTxEnv(t, "outside-writer", simulateTx(t, ledger, "outside-writer", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key7", []byte("value-key7"))
+		})),
+		constructTxEnv(t, "range-reader", simulateTx(t, ledger, "range-reader", func(s lgr.TxSimulator) {
+			scanRange(t, s, "ns1", "key0", "key3")
+		})),
+		constructTxEnv(t, "scanner", simulateTx(t, ledger, "scanner", func(s lgr.TxSimulator) {
+			scanRange(t, s, "ns1", "key8", "")
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+	txsFilter := lutil.TxValidationFlags(block3.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(0))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(1))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(2))
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(3))
+
+	// the failure of the deleting transaction restores the key, and the range is released
+	block4 := constructConfirmationBlock(t, "testLedger", block3, "cross1_succ", "cross2_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+	assertCommittedVersion(t, ledger, "ns1", "key9", []byte("value-key9"), version.NewHeight(4, 1))
+	block5 := constructCrossTxBlock(block4, constructTxEnv(t, "inserter2", simulateTx(t, ledger, "inserter2", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key3", []byte("value-key3"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block5}))
+	txsFilter = lutil.TxValidationFlags(block5.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(0))
+	assert.Empty(t, crossLockMgr.PendingTxs("testLedger"))
+}
+
+// scanRange reads all the keys of the given range through a range query
+func scanRange(t *testing.T, s lgr.TxSimulator, ns, startKey, endKey string) {
+	itr, err := s.GetStateRangeScanIterator(ns, startKey, endKey)
+	assert.NoError(t, err)
+	defer itr.Close()
+	for {
+		res, err := itr.Next()
+		assert.NoError(t, err)
+		if res == nil {
+			return
+		}
+	}
+}
+
+// simulateTx returns the public simulation results of a transaction running the given function
+func simulateTx(t *testing.T, ledger lgr.PeerLedger, txID string, f func(s lgr.TxSimulator)) []byte {
+	simulator, err := ledger.NewTxSimulator(txID)
+	assert.NoError(t, err)
+	f(simulator)
+	simulator.Done()
+	simRes, err := simulator.GetTxSimulationResults(nil)
+	assert.NoError(t, err)
+	pubSimBytes, err := simRes.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	return pubSimBytes
+}
+
+func constructTxEnv(t *testing.T, txID string, pubSimBytes []byte) *common.Envelope {
+	env, _, err := testutil.ConstructTransaction(t, pubSimBytes, txID, false)
+	assert.NoError(t, err)
+	return env
+}
+
+// constructCrossTxEnv constructs a multi-chain cross transaction
+func constructCrossTxEnv(t *testing.T, txID string, pubSimBytes []byte) *common.Envelope {
+	env := constructTxEnv(t, txID, pubSimBytes)
+	env.CrossInfo = putils.MarshalOrPanic(&crosspb.CrossInfo{Mode: crosspb.CrossMode_MULTI_CROSS, CrossTxId: txID, Channels: []string{"testLedger", "other"}})
+	return env
+}
+
+// constructCrossTxBlock constructs the block following the given one
+func constructCrossTxBlock(prevBlock *common.Block, envs ...*common.Envelope) *common.Block {
+	return testutil.NewBlock(envs, prevBlock.Header.Number+1, prevBlock.Header.Hash())
+}
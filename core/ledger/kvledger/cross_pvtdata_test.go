@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossRollbackOfPrivateData(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	// block 1 defines a collection never expiring and a collection whose data expires with block [committing block + 4]
+	assert.NoError(t, ledger.CommitWithPvtData(prepareNextBlockForTestCollectionConfigs(t, ledger, bg, "collConfig", "ns",
+		map[string]uint64{"coll": 0, "collbtl": 3})))
+
+	// block 2 sets the original values, the ones of collbtl expiring with block 6
+	pub, pvt := simulatePvtTx(t, ledger, "tx1", func(s lgr.TxSimulator) {
+		s.SetPrivateData("ns", "coll", "key1", []byte("value1"))
+		s.SetPrivateData("ns", "coll", "key5", []byte("value5"))
+		s.SetPrivateData("ns", "collbtl", "key2", []byte("value2"))
+		s.SetPrivateData("ns", "collbtl", "key3", []byte("value3"))
+		s.SetPrivateData("ns", "collbtl", "key4", []byte("value4"))
+	})
+	block2 := bg.NextBlock([][]byte{pub})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2,
+		BlockPvtData: map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: pvt}}}))
+
+	// block 3 commits three cross transactions. The peer misses the private data of the third one,
+	// as would a peer not member of the collection.
+	pub1, pvt1 := simulatePvtTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+		s.SetPrivateData("ns", "coll", "key1", []byte("value1-cross1"))
+		s.SetPrivateData("ns", "collbtl", "key2", []byte("value2-cross1"))
+		s.SetPrivateData("ns", "collbtl", "key6", []byte("value6-cross1"))
+	})
+	pub2, pvt2 := simulatePvtTx(t, ledger, "cross2", func(s lgr.TxSimulator) {
+		s.SetPrivateData("ns", "collbtl", "key4", []byte("value4-cross2"))
+	})
+	pub3, _ := simulatePvtTx(t, ledger, "cross3", func(s lgr.TxSimulator) {
+		s.SetPrivateData("ns", "coll", "key5", []byte("value5-cross3"))
+	})
+	block3 := constructCrossTxBlock(block2, constructCrossTxEnv(t, "cross1", pub1), constructCrossTxEnv(t, "cross2", pub2),
+		constructCrossTxEnv(t, "cross3", pub3))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3, BlockPvtData: map[uint64]*lgr.TxPvtData{
+		0: {SeqInBlock: 0, WriteSet: pvt1}, 1: {SeqInBlock: 1, WriteSet: pvt2}}}))
+	txsFilter := lutil.TxValidationFlags(block3.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(0))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(1))
+	assert.Equal(t, peer.TxValidationCode_VALID, txsFilter.Flag(2))
+
+	// the keys of the collections are locked by their hash, and their undo logs hold the original hashes
+	// along with the original values held by the peer
+	key1Hash := lutil.ComputeStringHash("key1")
+	assert.Contains(t, crossLockMgr.LockedKeys("testLedger"), cross.CollectionKey("ns", "coll", key1Hash))
+	assert.Equal(t, []statedb.KeyOrigVal{{Namespace: "ns", Collection: "coll", KeyHash: lutil.ComputeStringHash("key5"),
+		OriginalVersionedValue: statedb.EncodeOrigValue(&statedb.VersionedValue{Value: util.ComputeSHA256([]byte("value5")),
+			Version: version.NewHeight(2, 0)})}}, crossLockMgr.RollbackKVs("testLedger", "cross3"))
+	// a transaction writing a locked key of a collection is invalid
+	block4 := constructCrossTxBlock(block3, constructTxEnv(t, "writer", simulateTx(t, ledger, "writer", func(s lgr.TxSimulator) {
+		s.SetPrivateData("ns", "coll", "key1", []byte("value1-writer"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+	txsFilter = lutil.TxValidationFlags(block4.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.Equal(t, peer.TxValidationCode_CROSS_LOCK_CONFLICT, txsFilter.Flag(0))
+
+	// block 5 confirms the failure of cross1 and cross3. The keys get their original values back, at their
+	// original versions, and the peer missing the private data of cross3 restores the hash only.
+	block5 := constructConfirmationBlock(t, "testLedger", block4, "cross1_fail", "cross3_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block5}))
+	assertCollectionState(t, ledger, "ns", "coll", "key1", []byte("value1"), version.NewHeight(2, 0))
+	assertCollectionState(t, ledger, "ns", "collbtl", "key2", []byte("value2"), version.NewHeight(2, 0))
+	assertCollectionState(t, ledger, "ns", "collbtl", "key6", nil, nil)
+	assertCollectionState(t, ledger, "ns", "coll", "key5", []byte("value5"), version.NewHeight(2, 0))
+
+	// block 6 expires the values of collbtl committed by block 2, the restored one included. The other keys
+	// of the block keep their expiry schedule.
+	block6 := testutil.ConstructBlock(t, 6, block5.Header.Hash(), [][]byte{simulateTx(t, ledger, "tx6", func(s lgr.TxSimulator) {
+		s.SetState("ns", "pubkey", []byte("pubvalue"))
+	})}, false)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block6}))
+	assertCollectionState(t, ledger, "ns", "collbtl", "key2", nil, nil)
+	assertCollectionState(t, ledger, "ns", "collbtl", "key3", nil, nil)
+	assertCollectionState(t, ledger, "ns", "collbtl", "key4", []byte("value4-cross2"), version.NewHeight(3, 1))
+	assertCollectionState(t, ledger, "ns", "coll", "key1", []byte("value1"), version.NewHeight(2, 0))
+
+	// the original value of a key expired before the confirmation is not resurrected by the rollback
+	block7 := constructConfirmationBlock(t, "testLedger", block6, "cross2_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block7}))
+	assertCollectionState(t, ledger, "ns", "collbtl", "key4", nil, nil)
+	assert.Empty(t, crossLockMgr.LockedKeys("testLedger"))
+}
+
+// simulatePvtTx returns the public and the private simulation results of a transaction running the given function
+func simulatePvtTx(t *testing.T, ledger lgr.PeerLedger, txID string, f func(s lgr.TxSimulator)) ([]byte, *rwset.TxPvtReadWriteSet) {
+	simulator, err := ledger.NewTxSimulator(txID)
+	assert.NoError(t, err)
+	f(simulator)
+	simulator.Done()
+	simRes, err := simulator.GetTxSimulationResults(nil)
+	assert.NoError(t, err)
+	pubSimBytes, err := simRes.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	return pubSimBytes, simRes.PvtSimulationResults
+}
+
+// assertCollectionState checks the committed value of a key of a collection, along with the hash of the value,
+// both at the expected version
+func assertCollectionState(t *testing.T, ledger lgr.PeerLedger, ns, coll, key string, expectedValue []byte, expectedVersion *version.Height) {
+	qe, err := ledger.(*kvLedger).txtmgmt.NewQueryExecutor(util.GenerateUUID())
+	assert.NoError(t, err)
+	defer qe.Done()
+	reader := qe.(CrossGetStateInterface)
+	pvtVal, err := reader.GetPrivateDataNoRSet(ns, coll, key)
+	assert.NoError(t, err)
+	hashVal, err := reader.GetValueHashNoRSet(ns, coll, lutil.ComputeStringHash(key))
+	assert.NoError(t, err)
+	if expectedValue == nil {
+		assert.Nil(t, pvtVal, "key [%s:%s:%s] is expected to be deleted", ns, coll, key)
+		assert.Nil(t, hashVal, "hash of key [%s:%s:%s] is expected to be deleted", ns, coll, key)
+		return
+	}
+	assert.Equal(t, &statedb.VersionedValue{Value: expectedValue, Version: expectedVersion}, statedb.DecodeOrigValue(pvtVal))
+	assert.Equal(t, &statedb.VersionedValue{Value: util.ComputeSHA256(expectedValue), Version: expectedVersion}, statedb.DecodeOrigValue(hashVal))
+}
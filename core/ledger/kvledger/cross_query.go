@@ -0,0 +1,205 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/peer/cross"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// crossTxQuerier implements the ledger.CrossTxQuerier of a ledger. The in-flight cross transactions are read from
+// the lock table and the confirmed ones from their states and the per-block entries of the crossdb. Queries hold the read lock of the
+// block APIs, so that they never observe a block half-committed.
+type crossTxQuerier struct {
+	l *kvLedger
+}
+
+// GetCrossTxQuerier returns the CrossTxQuerier of the ledger
+func (l *kvLedger) GetCrossTxQuerier() (ledger.CrossTxQuerier, error) {
+	return &crossTxQuerier{l}, nil
+}
+
+// GetCrossTxStatus implements method in interface `ledger.CrossTxQuerier`. A prepared transaction is described
+// by the lock table, along with the keys it holds, and a confirmed one by its state recorded in the crossdb.
+func (q *crossTxQuerier) GetCrossTxStatus(txID string) (*crosspb.CrossTxStatus, error) {
+	q.l.blockAPIsRWLock.RLock()
+	defer q.l.blockAPIsRWLock.RUnlock()
+	if info, ok := crossLockMgr.GetTxInfo(q.l.ledgerID, txID); ok && info.Prepared {
+		return preparedCrossTxStatus(info), nil
+	}
+	status, err := q.l.getCrossTxState(txID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return &crosspb.CrossTxStatus{CrossTxId: txID, State: crosspb.CrossTxState_NOT_FOUND}, nil
+	}
+	return status, nil
+}
+
+// ListPendingCrossTxs implements method in interface `ledger.CrossTxQuerier`
+func (q *crossTxQuerier) ListPendingCrossTxs() ([]*crosspb.CrossTxStatus, error) {
+	q.l.blockAPIsRWLock.RLock()
+	defer q.l.blockAPIsRWLock.RUnlock()
+	var statuses []*crosspb.CrossTxStatus
+	for _, info := range crossLockMgr.GetTxInfos(q.l.ledgerID) {
+		if info.Prepared {
+			statuses = append(statuses, preparedCrossTxStatus(info))
+		}
+	}
+	return statuses, nil
+}
+
+// ListLockedKeys implements method in interface `ledger.CrossTxQuerier`
+func (q *crossTxQuerier) ListLockedKeys(namespace string) ([]*crosspb.LockedKey, error) {
+	q.l.blockAPIsRWLock.RLock()
+	defer q.l.blockAPIsRWLock.RUnlock()
+	var keys []*crosspb.LockedKey
+	for _, info := range crossLockMgr.GetTxInfos(q.l.ledgerID) {
+		for _, kl := range info.Keys {
+			if namespace == "" || kl.Namespace == namespace {
+				keys = append(keys, lockedKey(info.TxID, kl))
+			}
+		}
+	}
+	sortLockedKeys(keys)
+	return keys, nil
+}
+
+// ListLockWaits implements method in interface `ledger.CrossTxQuerier`
+func (q *crossTxQuerier) ListLockWaits() ([]*crosspb.CrossLockWait, error) {
+	var waits []*crosspb.CrossLockWait
+	for _, w := range crossLockMgr.LockWaits(q.l.ledgerID) {
+		since, err := ptypes.TimestampProto(w.Since)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid time of lock wait")
+		}
+		waits = append(waits, &crosspb.CrossLockWait{CrossTxId: w.TxID, Holders: w.Holders, Since: since})
+	}
+	return waits, nil
+}
+
+// GetConfirmationsByBlock implements method in interface `ledger.CrossTxQuerier`
+func (q *crossTxQuerier) GetConfirmationsByBlock(blockNum uint64) ([]*crosspb.Confirmation, error) {
+	q.l.blockAPIsRWLock.RLock()
+	defer q.l.blockAPIsRWLock.RUnlock()
+	var confs []*crosspb.Confirmation
+	itr := q.l.crossDB.GetIterator(constructCrossBlockKey(blockNum), constructCrossBlockKey(blockNum+1))
+	defer itr.Release()
+	for itr.Next() {
+		if string(itr.Value()) == "crosstx" {
+			continue
+		}
+		conf, err := utils.UnmarshalConfirmation(itr.Value())
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid confirmation recorded in the crossdb")
+		}
+		confs = append(confs, conf)
+	}
+	return confs, nil
+}
+
+// GetCrossOutcomesByBlock implements method in interface `ledger.CrossTxQuerier`. The keys rolled back are read
+// from the rollbacks persisted in the crossdb when the failure confirmations were committed.
+func (q *crossTxQuerier) GetCrossOutcomesByBlock(blockNum uint64) ([]*crosspb.CrossOutcome, error) {
+	q.l.blockAPIsRWLock.RLock()
+	defer q.l.blockAPIsRWLock.RUnlock()
+	var outcomes []*crosspb.CrossOutcome
+	itr := q.l.crossDB.GetIterator(constructCrossBlockKey(blockNum), constructCrossBlockKey(blockNum+1))
+	defer itr.Release()
+	for itr.Next() {
+		if string(itr.Value()) == "crosstx" {
+			continue
+		}
+		conf, err := utils.UnmarshalConfirmation(itr.Value())
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid confirmation recorded in the crossdb")
+		}
+		outcome := &crosspb.CrossOutcome{CrossTxId: conf.CrossTxId, ChannelId: q.l.ledgerID, Outcome: conf.Outcome, Reason: conf.Reason}
+		if conf.Outcome != crosspb.Outcome_SUCCESS {
+			// the single confirmation of a block committed by earlier versions is recorded by block number only
+			rollbackKey := constructLegacyCrossRollbackKey(blockNum)
+			if key := itr.Key(); len(key) > len(constructCrossBlockKey(blockNum)) {
+				rollbackKey = constructCrossRollbackKey(blockNum, binary.BigEndian.Uint64(key[8:]))
+			}
+			if outcome.RolledBackKeys, err = q.rolledBackKeys(rollbackKey); err != nil {
+				return nil, err
+			}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
+// rolledBackKeys returns the keys restored by the rollback persisted under the given key, by namespace
+func (q *crossTxQuerier) rolledBackKeys(rollbackKey []byte) ([]*crosspb.RolledBackKeys, error) {
+	rollbackBytes, err := q.l.crossDB.Get(rollbackKey)
+	if err != nil || rollbackBytes == nil {
+		return nil, err
+	}
+	rollback := &rwsetutil.TxRwSet{}
+	if err := rollback.FromProtoBytes(rollbackBytes); err != nil {
+		return nil, errors.Wrap(err, "invalid rollback recorded in the crossdb")
+	}
+	var keys []*crosspb.RolledBackKeys
+	for _, nsRWSet := range rollback.NsRwSets {
+		nsKeys := &crosspb.RolledBackKeys{Namespace: nsRWSet.NameSpace}
+		for _, w := range nsRWSet.KvRwSet.GetWrites() {
+			nsKeys.Keys = append(nsKeys.Keys, w.Key)
+		}
+		for _, coll := range nsRWSet.CollHashedRwSets {
+			nsKeys.Collections = append(nsKeys.Collections, coll.CollectionName)
+		}
+		keys = append(keys, nsKeys)
+	}
+	return keys, nil
+}
+
+func preparedCrossTxStatus(info *cross.TxInfo) *crosspb.CrossTxStatus {
+	status := &crosspb.CrossTxStatus{
+		CrossTxId:     info.TxID,
+		State:         crosspb.CrossTxState_PREPARED,
+		PreparedBlock: info.PreparedBlock,
+	}
+	for _, kl := range info.Keys {
+		status.LockedKeys = append(status.LockedKeys, lockedKey(info.TxID, kl))
+	}
+	for _, r := range info.Ranges {
+		status.LockedRanges = append(status.LockedRanges, &crosspb.LockedRange{Namespace: r.Namespace, StartKey: r.StartKey,
+			EndKey: r.EndKey, EndInclusive: r.EndInclusive, CrossTxId: info.TxID})
+	}
+	return status
+}
+
+func lockedKey(txID string, kl cross.KeyLock) *crosspb.LockedKey {
+	mode := crosspb.LockMode_EXCLUSIVE
+	if kl.Mode == cross.SharedLock {
+		mode = crosspb.LockMode_SHARED
+	}
+	return &crosspb.LockedKey{Namespace: kl.Namespace, Key: kl.Key, CrossTxId: txID, Mode: mode}
+}
+
+func sortLockedKeys(keys []*crosspb.LockedKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		if keys[i].Key != keys[j].Key {
+			return keys[i].Key < keys[j].Key
+		}
+		// a key held in shared mode is listed once per holder
+		return keys[i].CrossTxId < keys[j].CrossTxId
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/peer/cross"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossTxQuerier(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	querier, err := ledger.GetCrossTxQuerier()
+	assert.NoError(t, err)
+
+	block1 := bg.NextBlock([][]byte{simulateTx(t, ledger, "tx1", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1"))
+	})})
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+	block2 := constructCrossTxBlock(block1,
+		constructCrossTxEnv(t, "cross1", simulateTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key1", []byte("value1-cross1"))
+			s.GetState("ns2", "key2")
+		})),
+		constructCrossTxEnv(t, "cross2", simulateTx(t, ledger, "cross2", func(s lgr.TxSimulator) {
+			s.SetState("ns1", "key0", []byte("value0-cross2"))
+		})),
+	)
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+
+	status, err := querier.GetCrossTxStatus("cross1")
+	assert.NoError(t, err)
+	cross1 := &crosspb.CrossTxStatus{CrossTxId: "cross1", State: crosspb.CrossTxState_PREPARED, PreparedBlock: 2,
+		LockedKeys: []*crosspb.LockedKey{
+			{Namespace: "ns1", Key: "key1", CrossTxId: "cross1"},
+			{Namespace: "ns2", Key: "key2", CrossTxId: "cross1", Mode: crosspb.LockMode_SHARED},
+		}}
+	assert.True(t, proto.Equal(cross1, status), "unexpected status %s", status)
+	statuses, err := querier.ListPendingCrossTxs()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.True(t, proto.Equal(cross1, statuses[0]))
+	assert.Equal(t, "cross2", statuses[1].CrossTxId)
+
+	keys, err := querier.ListLockedKeys("ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, []*crosspb.LockedKey{
+		{Namespace: "ns1", Key: "key0", CrossTxId: "cross2"},
+		{Namespace: "ns1", Key: "key1", CrossTxId: "cross1"},
+	}, keys)
+	keys, err = querier.ListLockedKeys("")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 3)
+	keys, err = querier.ListLockedKeys("ns3")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	crossLockMgr.ReportConflict("testLedger", "cross3", []string{"cross2", "cross1"})
+	waits, err := querier.ListLockWaits()
+	assert.NoError(t, err)
+	assert.Len(t, waits, 1)
+	assert.Equal(t, "cross3", waits[0].CrossTxId)
+	assert.Equal(t, []string{"cross1", "cross2"}, waits[0].Holders)
+	assert.NotNil(t, waits[0].Since)
+
+	// a legacy success confirmation, then a typed failure confirmation
+	block3 := constructConfirmationBlock(t, "testLedger", block2, "cross1_succ")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+	block4 := constructConfirmationBlock(t, "testLedger", block3, string(putils.MarshalOrPanic(&crosspb.Confirmation{
+		CrossTxId: "cross2", Outcome: crosspb.Outcome_FAILURE, Reason: "lease expired"})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+
+	status, err = querier.GetCrossTxStatus("cross1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&crosspb.CrossTxStatus{CrossTxId: "cross1", State: crosspb.CrossTxState_COMMITTED, PreparedBlock: 2, ConfirmationBlock: 3}, status))
+	status, err = querier.GetCrossTxStatus("cross2")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&crosspb.CrossTxStatus{CrossTxId: "cross2", State: crosspb.CrossTxState_ABORTED,
+		PreparedBlock: 2, ConfirmationBlock: 4, Reason: "lease expired"}, status))
+	status, err = querier.GetCrossTxStatus("unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, crosspb.CrossTxState_NOT_FOUND, status.State)
+	statuses, err = querier.ListPendingCrossTxs()
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+	keys, err = querier.ListLockedKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	// the conflicts with the confirmed transactions are gone
+	waits, err = querier.ListLockWaits()
+	assert.NoError(t, err)
+	assert.Empty(t, waits)
+
+	confs, err := querier.GetConfirmationsByBlock(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*crosspb.Confirmation{{CrossTxId: "cross1", Outcome: crosspb.Outcome_SUCCESS}}, confs)
+	confs, err = querier.GetConfirmationsByBlock(4)
+	assert.NoError(t, err)
+	assert.Len(t, confs, 1)
+	assert.Equal(t, "lease expired", confs[0].Reason)
+	for _, blockNum := range []uint64{1, 2, 5} {
+		confs, err = querier.GetConfirmationsByBlock(blockNum)
+		assert.NoError(t, err)
+		assert.Empty(t, confs, "block [%d] carries no confirmation", blockNum)
+	}
+
+	outcomes, err := querier.GetCrossOutcomesByBlock(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*crosspb.CrossOutcome{{CrossTxId: "cross1", ChannelId: "testLedger", Outcome: crosspb.Outcome_SUCCESS}}, outcomes)
+	outcomes, err = querier.GetCrossOutcomesByBlock(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []*crosspb.CrossOutcome{{CrossTxId: "cross2", ChannelId: "testLedger", Outcome: crosspb.Outcome_FAILURE,
+		Reason: "lease expired", RolledBackKeys: []*crosspb.RolledBackKeys{{Namespace: "ns1", Keys: []string{"key0"}}}}}, outcomes)
+	outcomes, err = querier.GetCrossOutcomesByBlock(2)
+	assert.NoError(t, err)
+	assert.Empty(t, outcomes)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// crossStatesIndexedKey marks a crossdb whose confirmations committed by earlier versions, which kept no state,
+// are indexed in the states of the cross transactions
+var crossStatesIndexedKey = []byte("indexed~states")
+
+// getCrossTxState returns the state recorded for the given cross transaction, nil if it was never prepared.
+// The state of the cross transactions prepared on the channel is kept in the records of the crossdb. A transaction
+// is prepared when it is committed, then committed or aborted by its confirmation for good: its state never
+// changes afterwards, so that a confirmation committed again or a confirmation of the other outcome is a no-op.
+func (l *kvLedger) getCrossTxState(txID string) (*crosspb.CrossTxStatus, error) {
+	return l.crossRecords.GetRecord(txID)
+}
+
+// putCrossTxState adds the record of the state of a cross transaction to the batch. The keys locked by
+// a prepared transaction are kept by the lock table and are not recorded.
+func (l *kvLedger) putCrossTxState(batch *crossdb.UpdateBatch, status *crosspb.CrossTxStatus) error {
+	return l.crossRecords.PutRecord(batch, &crosspb.CrossTxStatus{
+		CrossTxId:         status.CrossTxId,
+		State:             status.State,
+		PreparedBlock:     status.PreparedBlock,
+		ConfirmationBlock: status.ConfirmationBlock,
+		Reason:            status.Reason,
+	})
+}
+
+// prepareCrossTxState records a cross transaction as prepared in the given block, unless it is confirmed
+// already, as when a block preceding its confirmation is committed again to the history database
+func (l *kvLedger) prepareCrossTxState(batch *crossdb.UpdateBatch, txID string, blockNum uint64) error {
+	status, err := l.crossRecords.GetUpdatedRecord(batch, txID)
+	if err != nil {
+		return err
+	}
+	if isTerminalCrossTxState(status) {
+		return nil
+	}
+	return l.putCrossTxState(batch, &crosspb.CrossTxStatus{CrossTxId: txID, State: crosspb.CrossTxState_PREPARED, PreparedBlock: blockNum})
+}
+
+// confirmCrossTxState records a cross transaction as committed or aborted by the confirmation of the given block.
+// The state of a transaction confirmed already is kept, so that committing a block again leaves it unchanged.
+func (l *kvLedger) confirmCrossTxState(batch *crossdb.UpdateBatch, blockNum uint64, conf *crossConfirmation) error {
+	status, err := l.crossRecords.GetUpdatedRecord(batch, conf.txID)
+	if err != nil {
+		return err
+	}
+	if isTerminalCrossTxState(status) {
+		return nil
+	}
+	if status == nil {
+		status = &crosspb.CrossTxStatus{CrossTxId: conf.txID}
+	}
+	status.State = crosspb.CrossTxState_COMMITTED
+	if !conf.success {
+		status.State = crosspb.CrossTxState_ABORTED
+	}
+	status.ConfirmationBlock = blockNum
+	status.Reason = conf.reason
+	return l.putCrossTxState(batch, status)
+}
+
+// commitCrossRecords writes the updates of the crossdb made by the given block in a single batch, along with
+// the savepoint of the crossdb. It is called before the state database records the block as its savepoint:
+// the updates being idempotent, a crash in between has the recovery of the state database make them again.
+// The savepoint never moves backwards, as when a block is committed again to the history database only.
+func (l *kvLedger) commitCrossRecords(batch *crossdb.UpdateBatch, block *common.Block) error {
+	savepoint, err := l.crossRecords.GetLastSavepoint()
+	if err != nil {
+		return err
+	}
+	height := version.NewHeight(block.Header.Number, 0)
+	if len(block.Data.Data) > 0 {
+		height.TxNum = uint64(len(block.Data.Data) - 1)
+	}
+	if savepoint == nil || height.Compare(savepoint) > 0 {
+		l.crossRecords.PutSavepoint(batch, height)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return l.crossRecords.Commit(batch)
+}
+
+// applicableCrossConfirmations returns the given confirmations of a block, but those of the cross transactions
+// confirmed by an earlier block, which are no-ops. The validation of the block invalidates such confirmations,
+// but for the blocks committed by earlier versions. The confirmations of a block committed again are kept.
+func (l *kvLedger) applicableCrossConfirmations(blockNum uint64, confs []*crossConfirmation) ([]*crossConfirmation, error) {
+	var applicable []*crossConfirmation
+	for _, conf := range confs {
+		status, err := l.getCrossTxState(conf.txID)
+		if err != nil {
+			return nil, err
+		}
+		if isTerminalCrossTxState(status) && status.ConfirmationBlock != blockNum {
+			logger.Warningf("[%s] Skipping confirmation [%d] of block [%d], cross transaction [%s] being %s in block [%d]",
+				l.ledgerID, conf.txNum, blockNum, conf.txID, status.State, status.ConfirmationBlock)
+			continue
+		}
+		applicable = append(applicable, conf)
+	}
+	return applicable, nil
+}
+
+// indexCrossTxStates records the states of the cross transactions prepared or confirmed before the states
+// were recorded, once: the in-flight transactions of the lock table, and the transactions confirmed by
+// the confirmations recorded in the crossdb, the first confirmation of a transaction being the one applied.
+func (l *kvLedger) indexCrossTxStates() error {
+	indexed, err := l.crossDB.Get(crossStatesIndexedKey)
+	if err != nil || indexed != nil {
+		return err
+	}
+	batch := crossdb.NewUpdateBatch()
+	for _, info := range crossLockMgr.GetTxInfos(l.ledgerID) {
+		if info.Prepared {
+			if err := l.prepareCrossTxState(batch, info.TxID, info.PreparedBlock); err != nil {
+				return err
+			}
+		}
+	}
+
+	itr := l.crossDB.GetIterator(constructCrossBlockKey(0), crossBlockKeysEnd)
+	defer itr.Release()
+	count := 0
+	for itr.Next() {
+		if string(itr.Value()) == "crosstx" {
+			continue
+		}
+		conf, err := utils.UnmarshalConfirmation(itr.Value())
+		if err != nil {
+			return errors.WithMessage(err, "invalid confirmation recorded in the crossdb")
+		}
+		err = l.confirmCrossTxState(batch, decodeCrossBlockKey(itr.Key()), &crossConfirmation{
+			txID:    conf.CrossTxId,
+			success: conf.Outcome == crosspb.Outcome_SUCCESS,
+			reason:  conf.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	logger.Infof("[%s] Indexed the states of the cross transactions of %d confirmation(s)", l.ledgerID, count)
+	batch.Put(crossStatesIndexedKey, []byte{1})
+	return l.crossRecords.Commit(batch)
+}
+
+func isTerminalCrossTxState(status *crosspb.CrossTxStatus) bool {
+	state := status.GetState()
+	return state == crosspb.CrossTxState_COMMITTED || state == crosspb.CrossTxState_ABORTED
+}
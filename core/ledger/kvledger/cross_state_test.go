@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/peer/cross"
+	"github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossTxStates(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	kvl := ledger.(*kvLedger)
+
+	block1 := constructCrossTxBlock(gb, constructCrossTxEnv(t, "cross1", simulateTx(t, ledger, "cross1", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1-cross1"))
+	})))
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}))
+	status, err := kvl.getCrossTxState("cross1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&crosspb.CrossTxStatus{CrossTxId: "cross1", State: crosspb.CrossTxState_PREPARED, PreparedBlock: 1}, status))
+	status, err = kvl.getCrossTxState("unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	block2 := constructConfirmationBlock(t, "testLedger", block1, "cross1_fail")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	aborted := &crosspb.CrossTxStatus{CrossTxId: "cross1", State: crosspb.CrossTxState_ABORTED, PreparedBlock: 1, ConfirmationBlock: 2}
+	status, err = kvl.getCrossTxState("cross1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(aborted, status), "unexpected state %s", status)
+	assertCommittedVersion(t, ledger, "ns1", "key1", nil, nil)
+
+	block3 := testutil.NewBlock([]*common.Envelope{constructTxEnv(t, "tx3", simulateTx(t, ledger, "tx3", func(s lgr.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1-tx3"))
+	}))}, 3, block2.Header.Hash())
+	// the classification written by earlier versions to the metadata of the block is ignored
+	block3.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO] = []byte("confirmation")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3}))
+
+	// a replayed confirmation and a confirmation of the other outcome, which the validation of the block would
+	// have invalidated, are no-ops
+	block4 := constructConfirmationBlock(t, "testLedger", block3, "cross1_fail", "cross1_succ")
+	assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block4}))
+
+	verifyStates := func(ledger lgr.PeerLedger) {
+		assertCommittedVersion(t, ledger, "ns1", "key1", []byte("value1-tx3"), version.NewHeight(3, 0))
+		querier, err := ledger.GetCrossTxQuerier()
+		assert.NoError(t, err)
+		status, err := querier.GetCrossTxStatus("cross1")
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(aborted, status), "unexpected status %s", status)
+		confs, err := querier.GetConfirmationsByBlock(4)
+		assert.NoError(t, err)
+		assert.Empty(t, confs)
+	}
+	verifyStates(ledger)
+
+	// the records are indexed by block and by state, and committed along with the savepoint of the crossdb
+	itr := kvl.crossRecords.GetRecordsByBlock(2, 5)
+	rec, err := itr.Next()
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(aborted, rec), "unexpected record %s", rec)
+	rec, err = itr.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+	itr.Release()
+	itr = kvl.crossRecords.GetRecordsByState(crosspb.CrossTxState_PREPARED)
+	rec, err = itr.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+	itr.Release()
+	savepoint, err := kvl.crossRecords.GetLastSavepoint()
+	assert.NoError(t, err)
+	assert.Equal(t, version.NewHeight(4, 1), savepoint)
+
+	// rebuild the state and history databases from the block store
+	ledger.Close()
+	provider.Close()
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetStateLevelDBPath()))
+	assert.NoError(t, os.RemoveAll(ledgerconfig.GetHistoryLevelDBPath()))
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer ledger.Close()
+	verifyStates(ledger)
+}
+
+func TestIndexCrossTxStates(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer func(m *cross.LockManager) { crossLockMgr = m }(crossLockMgr)
+	crossLockMgr = cross.NewLockManager()
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	kvl := ledger.(*kvLedger)
+	indexed, err := kvl.crossDB.Get(crossStatesIndexedKey)
+	assert.NoError(t, err)
+	assert.NotNil(t, indexed)
+
+	// a crossdb of an earlier version records the confirmations only
+	assert.NoError(t, crossLockMgr.PrepareTx("testLedger", "cross3", 6, nil, nil, nil))
+	assert.NoError(t, kvl.crossDB.Delete(crossStatesIndexedKey, true))
+	assert.NoError(t, kvl.crossDB.Put(constructCrossBlockKey(2), []byte("crosstx"), false))
+	assert.NoError(t, kvl.crossDB.Put(constructCrossBlockKey(3), []byte("cross1_succ"), false))
+	for txNum, conf := range []*crosspb.Confirmation{
+		{CrossTxId: "cross2", Outcome: crosspb.Outcome_FAILURE, Reason: "lease expired"},
+		{CrossTxId: "cross1", Outcome: crosspb.Outcome_FAILURE},
+	} {
+		assert.NoError(t, kvl.crossDB.Put(constructCrossConfirmationKey(4, uint64(txNum)), putils.MarshalOrPanic(conf), false))
+	}
+	assert.NoError(t, kvl.indexCrossTxStates())
+
+	for _, expected := range []*crosspb.CrossTxStatus{
+		{CrossTxId: "cross1", State: crosspb.CrossTxState_COMMITTED, ConfirmationBlock: 3},
+		{CrossTxId: "cross2", State: crosspb.CrossTxState_ABORTED, ConfirmationBlock: 4, Reason: "lease expired"},
+		{CrossTxId: "cross3", State: crosspb.CrossTxState_PREPARED, PreparedBlock: 6},
+	} {
+		status, err := kvl.getCrossTxState(expected.CrossTxId)
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(expected, status), "unexpected state %s", status)
+	}
+
+	// the states are indexed once
+	batch := crossdb.NewUpdateBatch()
+	assert.NoError(t, kvl.crossRecords.DeleteRecord(batch, "cross1"))
+	assert.NoError(t, kvl.crossRecords.Commit(batch))
+	assert.NoError(t, kvl.indexCrossTxStates())
+	status, err := kvl.getCrossTxState("cross1")
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
@@ -0,0 +1,335 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscouchdb
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("crosscouchdb")
+
+// crossDBNamespace is the namespace of the database holding the crossdb of a channel, next to the databases of
+// its state. The databases of the chaincodes and of their collections never end with it.
+const crossDBNamespace = "$$cross"
+
+// journalID is the id of the document journaling the batch being written, which is not the hex encoding of a key
+const journalID = "~journal"
+
+// CrossDBProvider implements interface crossdb.CrossDBProvider on top of the CouchDB instance of the state database
+type CrossDBProvider struct {
+	couchInstance *couchdb.CouchInstance
+}
+
+// NewCrossDBProvider instantiates CrossDBProvider
+func NewCrossDBProvider() (*CrossDBProvider, error) {
+	logger.Debugf("constructing CouchDB CrossDBProvider")
+	couchDBDef := couchdb.GetCouchDBDefinition()
+	couchInstance, err := couchdb.CreateCouchInstance(couchDBDef.URL, couchDBDef.Username, couchDBDef.Password,
+		couchDBDef.MaxRetries, couchDBDef.MaxRetriesOnStartup, couchDBDef.RequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &CrossDBProvider{couchInstance}, nil
+}
+
+// GetDBHandler gets the handle to the crossdb of a channel. A batch whose write was interrupted is written again.
+func (provider *CrossDBProvider) GetDBHandler(dbName string) (crossdb.CrossDB, error) {
+	db, err := couchdb.CreateCouchDatabase(provider.couchInstance, couchdb.ConstructNamespaceDBName(dbName, crossDBNamespace))
+	if err != nil {
+		return nil, err
+	}
+	crossDB := &crossDB{db: db}
+	if err := crossDB.recoverBatch(); err != nil {
+		return nil, err
+	}
+	return crossDB, nil
+}
+
+// HasCrossDB returns true if the CouchDB instance of the state database holds a crossdb with records of one of
+// the given ledgers
+func HasCrossDB(ledgerIDs []string) (bool, error) {
+	provider, err := NewCrossDBProvider()
+	if err != nil {
+		return false, err
+	}
+	for _, ledgerID := range ledgerIDs {
+		// the name of the database as mapped by CreateCouchDatabase, without creating it
+		dbName := strings.Replace(couchdb.ConstructNamespaceDBName(ledgerID, crossDBNamespace), ".", "$", -1)
+		db := &couchdb.CouchDatabase{CouchInstance: provider.couchInstance, DBName: dbName}
+		info, couchDBReturn, err := db.GetDatabaseInfo()
+		if couchDBReturn != nil && couchDBReturn.StatusCode == 404 {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if info.DocCount > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Close closes the underlying db instance
+func (provider *CrossDBProvider) Close() {
+	// No close needed on Couch
+}
+
+// crossDB keeps a key-value as a document whose id is the hex encoding of the key, which sorts the documents
+// in the order of the keys, and whose value field is the value encoded in base64. The reads wait for the
+// writes in progress, so that a batch is seen whole or not at all.
+type crossDB struct {
+	db   *couchdb.CouchDatabase
+	lock sync.RWMutex
+}
+
+type crossDoc struct {
+	ID      string `json:"_id"`
+	Rev     string `json:"_rev,omitempty"`
+	Value   []byte `json:"value,omitempty"`
+	Deleted bool   `json:"_deleted,omitempty"`
+}
+
+// journalDoc is the document journaling a batch, holding its updates by document id, a deletion having a null value
+type journalDoc struct {
+	ID      string            `json:"_id"`
+	Updates map[string][]byte `json:"updates"`
+}
+
+func (crossDB *crossDB) Test() {
+	logger.Infof("[CrossDB]Testing")
+}
+
+// Get returns the value of the given key, nil if it does not exist
+func (crossDB *crossDB) Get(key []byte) ([]byte, error) {
+	crossDB.lock.RLock()
+	defer crossDB.lock.RUnlock()
+	couchDoc, _, err := crossDB.db.ReadDoc(encodeKey(key))
+	if err != nil || couchDoc == nil {
+		return nil, err
+	}
+	doc := &crossDoc{}
+	if err := json.Unmarshal(couchDoc.JSONValue, doc); err != nil {
+		return nil, errors.Wrapf(err, "invalid document of key [%x] in crossdb", key)
+	}
+	if doc.Value == nil {
+		return []byte{}, nil
+	}
+	return doc.Value, nil
+}
+
+// Put saves the value of the given key. The sync flag is ignored, CouchDB committing the document before
+// responding.
+func (crossDB *crossDB) Put(key []byte, value []byte, sync bool) error {
+	crossDB.lock.Lock()
+	defer crossDB.lock.Unlock()
+	docJSON, err := json.Marshal(&crossDoc{ID: encodeKey(key), Value: value})
+	if err != nil {
+		return err
+	}
+	_, err = crossDB.db.SaveDoc(encodeKey(key), "", &couchdb.CouchDoc{JSONValue: docJSON})
+	return err
+}
+
+// Delete removes the given key
+func (crossDB *crossDB) Delete(key []byte, sync bool) error {
+	crossDB.lock.Lock()
+	defer crossDB.lock.Unlock()
+	return crossDB.db.DeleteDoc(encodeKey(key), "")
+}
+
+// GetIterator returns an iterator over the keys in the range [startKey, endKey), which reads the documents
+// by pages of the query limit of the state database
+func (crossDB *crossDB) GetIterator(startKey []byte, endKey []byte) crossdb.ResultsIterator {
+	return &docsItr{crossDB: crossDB, startID: encodeKey(startKey), endID: encodeKey(endKey), limit: ledgerconfig.GetQueryLimit()}
+}
+
+// WriteBatch writes the updates of the batch atomically. CouchDB updating every document of a bulk update on its
+// own, the batch is first saved in a single journal document, then written with bulk updates of at most the max
+// batch update size of the state database, and the journal is deleted. A write interrupted by a crash is
+// completed from the journal when the crossdb is opened again.
+func (crossDB *crossDB) WriteBatch(batch *crossdb.UpdateBatch, sync bool) error {
+	if len(batch.KVs) == 0 {
+		return nil
+	}
+	updates := make(map[string][]byte)
+	for k, v := range batch.KVs {
+		updates[encodeKey([]byte(k))] = v
+	}
+	crossDB.lock.Lock()
+	defer crossDB.lock.Unlock()
+	journalJSON, err := json.Marshal(&journalDoc{ID: journalID, Updates: updates})
+	if err != nil {
+		return err
+	}
+	if _, err := crossDB.db.SaveDoc(journalID, "", &couchdb.CouchDoc{JSONValue: journalJSON}); err != nil {
+		return errors.WithMessage(err, "failed to journal the batch of the crossdb")
+	}
+	return crossDB.applyBatch(updates, sync)
+}
+
+// recoverBatch completes the write of the batch left in the journal, if any
+func (crossDB *crossDB) recoverBatch() error {
+	couchDoc, _, err := crossDB.db.ReadDoc(journalID)
+	if err != nil || couchDoc == nil {
+		return err
+	}
+	journal := &journalDoc{}
+	if err := json.Unmarshal(couchDoc.JSONValue, journal); err != nil {
+		return errors.Wrap(err, "invalid journal of crossdb")
+	}
+	logger.Infof("Completing the interrupted write of a batch of %d update(s) to crossdb [%s]", len(journal.Updates), crossDB.db.DBName)
+	crossDB.lock.Lock()
+	defer crossDB.lock.Unlock()
+	return crossDB.applyBatch(journal.Updates, true)
+}
+
+// applyBatch writes the journaled updates, by document id, then deletes the journal
+func (crossDB *crossDB) applyBatch(updates map[string][]byte, sync bool) error {
+	var ids []string
+	for id := range updates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	maxBatchSize := ledgerconfig.GetMaxBatchUpdateSize()
+	for start := 0; start < len(ids); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := crossDB.writeDocs(updates, ids[start:end]); err != nil {
+			return err
+		}
+	}
+	if sync {
+		if _, err := crossDB.db.EnsureFullCommit(); err != nil {
+			return errors.WithMessage(err, "failed to commit the crossdb")
+		}
+	}
+	if err := crossDB.db.DeleteDoc(journalID, ""); err != nil {
+		return errors.WithMessage(err, "failed to delete the journal of the crossdb")
+	}
+	return nil
+}
+
+func (crossDB *crossDB) writeDocs(updates map[string][]byte, ids []string) error {
+	metadata, err := crossDB.db.BatchRetrieveDocumentMetadata(ids)
+	if err != nil {
+		return err
+	}
+	revs := make(map[string]string)
+	for _, m := range metadata {
+		revs[m.ID] = m.Rev
+	}
+	var docs []*couchdb.CouchDoc
+	for _, id := range ids {
+		doc := &crossDoc{ID: id, Rev: revs[id], Value: updates[id]}
+		if doc.Value == nil {
+			if doc.Rev == "" {
+				continue
+			}
+			doc.Deleted = true
+		}
+		docJSON, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, &couchdb.CouchDoc{JSONValue: docJSON})
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	resps, err := crossDB.db.BatchUpdateDocuments(docs)
+	if err != nil {
+		return err
+	}
+	for _, resp := range resps {
+		if !resp.Ok {
+			return errors.Errorf("failed to write document [%s] of crossdb: %s: %s", resp.ID, resp.Error, resp.Reason)
+		}
+	}
+	return nil
+}
+
+type docsItr struct {
+	crossDB        *crossDB
+	startID, endID string
+	limit          int
+	// skip is the number of documents to skip at startID, the last document of the previous page
+	skip      int
+	results   []couchdb.QueryResult
+	exhausted bool
+	key       []byte
+	value     []byte
+}
+
+// Next moves to the next document, reading the next page of documents when the current one is consumed.
+// A failure to read a page ends the iteration. The journal is skipped.
+func (itr *docsItr) Next() bool {
+	if len(itr.results) == 0 {
+		if itr.exhausted {
+			return false
+		}
+		itr.crossDB.lock.RLock()
+		results, err := itr.crossDB.db.ReadDocRange(itr.startID, itr.endID, itr.limit, itr.skip)
+		itr.crossDB.lock.RUnlock()
+		if err != nil {
+			logger.Errorf("Failed to read the documents of crossdb from [%s]: %s", itr.startID, err)
+			return false
+		}
+		itr.results = *results
+		itr.exhausted = len(itr.results) < itr.limit
+		if len(itr.results) == 0 {
+			return false
+		}
+		itr.startID, itr.skip = itr.results[len(itr.results)-1].ID, 1
+	}
+	result := itr.results[0]
+	itr.results = itr.results[1:]
+	if result.ID == journalID {
+		return itr.Next()
+	}
+	doc := &crossDoc{}
+	if err := json.Unmarshal(result.Value, doc); err != nil {
+		logger.Errorf("Invalid document [%s] of crossdb: %s", result.ID, err)
+		return false
+	}
+	itr.key, _ = hex.DecodeString(doc.ID)
+	itr.value = doc.Value
+	if itr.value == nil {
+		itr.value = []byte{}
+	}
+	return true
+}
+
+func (itr *docsItr) Key() []byte {
+	return itr.key
+}
+
+func (itr *docsItr) Value() []byte {
+	return itr.value
+}
+
+func (itr *docsItr) Release() {
+	itr.results = nil
+	itr.exhausted = true
+}
+
+// encodeKey returns the id of the document of a key, an empty key giving an unbounded end of range
+func encodeKey(key []byte) string {
+	return hex.EncodeToString(key)
+}
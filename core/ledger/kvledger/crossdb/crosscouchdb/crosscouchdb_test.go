@@ -0,0 +1,167 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscouchdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+	"github.com/hyperledger/fabric/integration/runner"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testMain(m))
+}
+
+func testMain(m *testing.M) int {
+	// Read the core.yaml file for default config.
+	ledgertestutil.SetupCoreYAMLConfig()
+
+	couchAddress, cleanup := couchDBSetup()
+	defer cleanup()
+	viper.Set("ledger.state.couchDBConfig.couchDBAddress", couchAddress)
+	// Replace with correct username/password such as
+	// admin/admin if user security is enabled on couchdb.
+	viper.Set("ledger.state.couchDBConfig.username", "")
+	viper.Set("ledger.state.couchDBConfig.password", "")
+	viper.Set("ledger.state.couchDBConfig.maxRetries", 3)
+	viper.Set("ledger.state.couchDBConfig.maxRetriesOnStartup", 10)
+	viper.Set("ledger.state.couchDBConfig.requestTimeout", time.Second*35)
+	return m.Run()
+}
+
+func couchDBSetup() (addr string, cleanup func()) {
+	externalCouch, set := os.LookupEnv("COUCHDB_ADDR")
+	if set {
+		return externalCouch, func() {}
+	}
+
+	couchDB := &runner.CouchDB{}
+	if err := couchDB.Start(); err != nil {
+		err := fmt.Errorf("failed to start couchDB: %s", err)
+		panic(err)
+	}
+	return couchDB.Address(), func() { couchDB.Stop() }
+}
+
+func TestCrossDB(t *testing.T) {
+	// small pages and bulk updates
+	viper.Set("ledger.state.couchDBConfig.queryLimit", 2)
+	viper.Set("ledger.state.couchDBConfig.maxBatchUpdateSize", 2)
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	defer viper.Set("ledger.state.couchDBConfig.maxBatchUpdateSize", 1000)
+
+	provider, err := NewCrossDBProvider()
+	assert.NoError(t, err)
+	defer provider.Close()
+	db, err := provider.GetDBHandler("testcrossdb")
+	assert.NoError(t, err)
+	defer db.(*crossDB).db.DropDatabase()
+
+	assert.NoError(t, db.Put([]byte("key1"), []byte("value1"), false))
+	assert.NoError(t, db.Put([]byte("key1"), []byte("value1-updated"), false))
+	assert.NoError(t, db.Put([]byte{0, 0, 0, 1}, []byte{}, false))
+	value, err := db.Get([]byte("key1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1-updated"), value)
+	value, err = db.Get([]byte{0, 0, 0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, value)
+	value, err = db.Get([]byte("unknown"))
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	batch := crossdb.NewUpdateBatch()
+	for _, k := range []string{"key2", "key3", "key4", "key5"} {
+		batch.Put([]byte(k), []byte("value-"+k))
+	}
+	batch.Put([]byte("key1"), []byte("value1-batch"))
+	batch.Delete([]byte{0, 0, 0, 1})
+	batch.Delete([]byte("unknown"))
+	assert.NoError(t, db.WriteBatch(batch, true))
+	assert.NoError(t, db.Delete([]byte("key5"), false))
+	value, err = db.Get([]byte{0, 0, 0, 1})
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	// the keys are iterated in their order, over several pages
+	itr := db.GetIterator([]byte("key1"), []byte("key9"))
+	defer itr.Release()
+	var keys, values []string
+	for itr.Next() {
+		keys, values = append(keys, string(itr.Key())), append(values, string(itr.Value()))
+	}
+	assert.Equal(t, []string{"key1", "key2", "key3", "key4"}, keys)
+	assert.Equal(t, []string{"value1-batch", "value-key2", "value-key3", "value-key4"}, values)
+
+	// the records of cross transactions are kept as in any crossdb
+	s := crossdb.NewRecordStore(db)
+	assert.NoError(t, s.UpgradeSchema())
+	assert.NoError(t, s.UpgradeSchema())
+}
+
+func TestCrossDBInterruptedBatch(t *testing.T) {
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	provider, err := NewCrossDBProvider()
+	assert.NoError(t, err)
+	defer provider.Close()
+	db, err := provider.GetDBHandler("testcrossdbjournal")
+	assert.NoError(t, err)
+	defer db.(*crossDB).db.DropDatabase()
+	assert.NoError(t, db.Put([]byte("key1"), []byte("value1"), false))
+	assert.NoError(t, db.Put([]byte{0, 0, 0, 1}, []byte("value2"), false))
+
+	has, err := HasCrossDB([]string{"unknownledger", "testcrossdbjournal"})
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasCrossDB([]string{"unknownledger"})
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// a batch journaled but not written, as when the peer crashes, is written when the crossdb is opened again
+	journal := &journalDoc{ID: journalID, Updates: map[string][]byte{
+		encodeKey([]byte("key1")):     []byte("value1-batch"),
+		encodeKey([]byte{0, 0, 0, 1}): nil,
+		encodeKey([]byte{0, 0, 0, 2}): {},
+		encodeKey([]byte("key3")):     []byte("value3"),
+	}}
+	journalJSON, err := json.Marshal(journal)
+	assert.NoError(t, err)
+	_, err = db.(*crossDB).db.SaveDoc(journalID, "", &couchdb.CouchDoc{JSONValue: journalJSON})
+	assert.NoError(t, err)
+
+	db, err = provider.GetDBHandler("testcrossdbjournal")
+	assert.NoError(t, err)
+	value, err := db.Get([]byte("key1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1-batch"), value)
+	value, err = db.Get([]byte{0, 0, 0, 1})
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = db.Get([]byte{0, 0, 0, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, value)
+	couchDoc, _, err := db.(*crossDB).db.ReadDoc(journalID)
+	assert.NoError(t, err)
+	assert.Nil(t, couchDoc)
+
+	itr := db.GetIterator(nil, nil)
+	defer itr.Release()
+	var keys []string
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+	assert.Equal(t, []string{string([]byte{0, 0, 0, 2}), "key1", "key3"}, keys)
+}
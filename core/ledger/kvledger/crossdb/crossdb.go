@@ -9,4 +9,53 @@ type CrossDB interface {
 	Test()
 	Get(key []byte) ([]byte, error)
 	Put(key []byte, value []byte, sync bool) error
-}
\ No newline at end of file
+	// Delete removes the given key
+	Delete(key []byte, sync bool) error
+	// GetIterator returns an iterator over the keys in the range [startKey, endKey).
+	// A nil endKey iterates till the last key of the db
+	GetIterator(startKey []byte, endKey []byte) ResultsIterator
+	// WriteBatch writes the updates of the given batch atomically
+	WriteBatch(batch *UpdateBatch, sync bool) error
+}
+
+// ResultsIterator iterates over the key-values of a CrossDB
+type ResultsIterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Release()
+}
+
+// UpdateBatch holds the updates written to a CrossDB in a single WriteBatch
+type UpdateBatch struct {
+	KVs map[string][]byte
+}
+
+// NewUpdateBatch constructs an instance of a Batch
+func NewUpdateBatch() *UpdateBatch {
+	return &UpdateBatch{make(map[string][]byte)}
+}
+
+// Put adds a KV
+func (batch *UpdateBatch) Put(key []byte, value []byte) {
+	if value == nil {
+		panic("Nil value not allowed")
+	}
+	batch.KVs[string(key)] = value
+}
+
+// Delete deletes a Key and associated value
+func (batch *UpdateBatch) Delete(key []byte) {
+	batch.KVs[string(key)] = nil
+}
+
+// Get returns the update of the given key held by the batch, if any: its new value, or nil for a delete
+func (batch *UpdateBatch) Get(key []byte) ([]byte, bool) {
+	value, ok := batch.KVs[string(key)]
+	return value, ok
+}
+
+// Len returns the number of updates held by the batch
+func (batch *UpdateBatch) Len() int {
+	return len(batch.KVs)
+}
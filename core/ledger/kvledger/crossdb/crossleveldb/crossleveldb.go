@@ -1,6 +1,8 @@
 package crossleveldb
 
 import (
+	"os"
+
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
@@ -28,6 +30,24 @@ func NewCrossDBProvider() *CrossDBProvider{
 	return &CrossDBProvider{dbProvider}
 }
 
+// IsCrossDBEmpty returns true if the leveldb crossdb holds no key, as when it was never used.
+// It is not to be called while a CrossDBProvider is open.
+func IsCrossDBEmpty() (bool, error) {
+	dbPath := ledgerconfig.GetCrossLevelDBPath()
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	db := leveldbhelper.CreateDB(&leveldbhelper.Conf{DBPath: dbPath})
+	db.Open()
+	defer db.Close()
+	itr := db.GetIterator(nil, nil)
+	defer itr.Release()
+	return !itr.Next(), itr.Error()
+}
+
 func (provider *CrossDBProvider) GetDBHandler(dbName string) (crossdb.CrossDB, error){
 	return newCrossDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
@@ -53,6 +73,27 @@ func (crossDB *crossDB) Put(key []byte, value []byte, sync bool) error {
 	return crossDB.db.Put(key, value, sync)
 }
 
+func (crossDB *crossDB) Delete(key []byte, sync bool) error {
+	return crossDB.db.Delete(key, sync)
+}
+
+func (crossDB *crossDB) GetIterator(startKey []byte, endKey []byte) crossdb.ResultsIterator {
+	return crossDB.db.GetIterator(startKey, endKey)
+}
+
+// WriteBatch writes the updates of the batch in a single leveldb batch
+func (crossDB *crossDB) WriteBatch(batch *crossdb.UpdateBatch, sync bool) error {
+	levelBatch := leveldbhelper.NewUpdateBatch()
+	for k, v := range batch.KVs {
+		if v == nil {
+			levelBatch.Delete([]byte(k))
+		} else {
+			levelBatch.Put([]byte(k), v)
+		}
+	}
+	return crossDB.db.WriteBatch(levelBatch, sync)
+}
+
 
 func (crossDB *crossDB) Test(){
 	logger.Infof("[CrossDB]Testing")
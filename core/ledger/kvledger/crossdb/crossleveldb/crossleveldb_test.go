@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crossleveldb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crossleveldb")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer viper.Reset()
+	viper.Set("peer.fileSystemPath", dir)
+
+	provider := NewCrossDBProvider()
+	defer provider.Close()
+	db1, err := provider.GetDBHandler("ledger1")
+	assert.NoError(t, err)
+	db2, err := provider.GetDBHandler("ledger2")
+	assert.NoError(t, err)
+
+	assert.NoError(t, db1.Put([]byte("key1"), []byte("value1"), false))
+	assert.NoError(t, db1.Put([]byte("key2"), []byte("value2"), false))
+	batch := crossdb.NewUpdateBatch()
+	batch.Put([]byte("key3"), []byte("value3"))
+	batch.Put([]byte("key4"), []byte("value4"))
+	batch.Delete([]byte("key1"))
+	assert.NoError(t, db1.WriteBatch(batch, true))
+	assert.NoError(t, db1.Delete([]byte("key4"), false))
+
+	value, err := db1.Get([]byte("key1"))
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = db1.Get([]byte("key3"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value3"), value)
+	// the dbs of the ledgers are isolated
+	value, err = db2.Get([]byte("key3"))
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	itr := db1.GetIterator([]byte("key2"), nil)
+	defer itr.Release()
+	var keys, values []string
+	for itr.Next() {
+		keys, values = append(keys, string(itr.Key())), append(values, string(itr.Value()))
+	}
+	assert.Equal(t, []string{"key2", "key3"}, keys)
+	assert.Equal(t, []string{"value2", "value3"}, values)
+}
+
+func TestIsCrossDBEmpty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crossleveldb")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer viper.Reset()
+	viper.Set("peer.fileSystemPath", dir)
+
+	empty, err := IsCrossDBEmpty()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+
+	// a crossdb opened but never written is empty
+	provider := NewCrossDBProvider()
+	db, err := provider.GetDBHandler("ledger1")
+	assert.NoError(t, err)
+	provider.Close()
+	empty, err = IsCrossDBEmpty()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+
+	provider = NewCrossDBProvider()
+	db, _ = provider.GetDBHandler("ledger1")
+	assert.NoError(t, db.Put([]byte("key1"), []byte("value1"), true))
+	provider.Close()
+	empty, err = IsCrossDBEmpty()
+	assert.NoError(t, err)
+	assert.False(t, empty)
+}
@@ -0,0 +1,310 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crossdb
+
+import (
+	"encoding/binary"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the version of the format of the records and of their indexes. A record is prefixed with
+// the version it was written with, so that the format can evolve. Records written before the format was
+// versioned carry no version: a marshaled record starts with the tag of a field, which is never below 0x08.
+const SchemaVersion byte = 1
+
+var (
+	// recordKeyPrefix prefixes the keys of the records, by cross txid
+	recordKeyPrefix = []byte("state~")
+	// blockIndexKeyPrefix prefixes the index entries of the records by the blocks preparing and confirming
+	// the transactions, made of the big-endian block number followed by the cross txid
+	blockIndexKeyPrefix = []byte("idx~block~")
+	// stateIndexKeyPrefix prefixes the index entries of the records by state, made of the big-endian
+	// state followed by the cross txid
+	stateIndexKeyPrefix = []byte("idx~state~")
+	schemaVersionKey    = []byte("schema~version")
+	savepointKey        = []byte("savepoint~")
+	emptyValue          = []byte{}
+)
+
+// RecordStore keeps the records of the cross transactions of a channel in a CrossDB, indexed by cross txid,
+// by the blocks preparing and confirming the transactions, and by state. The records and their index entries
+// are updated through batches, so that the updates of a block are written atomically along with its savepoint.
+type RecordStore struct {
+	db CrossDB
+}
+
+// RecordsIterator iterates over records
+type RecordsIterator interface {
+	// Next returns the next record, nil when the iterator is exhausted
+	Next() (*cross.CrossTxStatus, error)
+	// Release releases the iterator
+	Release()
+}
+
+// NewRecordStore constructs a RecordStore on top of the given CrossDB
+func NewRecordStore(db CrossDB) *RecordStore {
+	return &RecordStore{db}
+}
+
+// GetRecord returns the record of the given cross transaction, nil if there is none
+func (s *RecordStore) GetRecord(txID string) (*cross.CrossTxStatus, error) {
+	return s.GetUpdatedRecord(nil, txID)
+}
+
+// GetUpdatedRecord returns the record of the given cross transaction as updated by the given batch,
+// nil if there is none
+func (s *RecordStore) GetUpdatedRecord(batch *UpdateBatch, txID string) (*cross.CrossTxStatus, error) {
+	key := constructRecordKey(txID)
+	var b []byte
+	var ok bool
+	if batch != nil {
+		b, ok = batch.Get(key)
+	}
+	if !ok {
+		var err error
+		if b, err = s.db.Get(key); err != nil {
+			return nil, err
+		}
+	}
+	if b == nil {
+		return nil, nil
+	}
+	rec, err := decodeRecord(b)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid record of cross transaction ["+txID+"]")
+	}
+	return rec, nil
+}
+
+// PutRecord adds the record of a cross transaction to the batch, along with the updates of its index entries
+func (s *RecordStore) PutRecord(batch *UpdateBatch, rec *cross.CrossTxStatus) error {
+	if rec.CrossTxId == "" {
+		return errors.New("record of cross transaction without txid")
+	}
+	prev, err := s.GetUpdatedRecord(batch, rec.CrossTxId)
+	if err != nil {
+		return err
+	}
+	b, err := proto.Marshal(rec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the record of cross transaction [%s]", rec.CrossTxId)
+	}
+	deleteIndexEntries(batch, prev)
+	batch.Put(constructRecordKey(rec.CrossTxId), append([]byte{SchemaVersion}, b...))
+	for _, key := range indexKeys(rec) {
+		batch.Put(key, emptyValue)
+	}
+	return nil
+}
+
+// DeleteRecord adds the delete of the record of a cross transaction and of its index entries to the batch
+func (s *RecordStore) DeleteRecord(batch *UpdateBatch, txID string) error {
+	prev, err := s.GetUpdatedRecord(batch, txID)
+	if err != nil || prev == nil {
+		return err
+	}
+	deleteIndexEntries(batch, prev)
+	batch.Delete(constructRecordKey(txID))
+	return nil
+}
+
+// GetRecords returns an iterator over the records of the cross transactions whose txid is in the range
+// [startTxID, endTxID). An empty endTxID iterates till the last record.
+func (s *RecordStore) GetRecords(startTxID, endTxID string) RecordsIterator {
+	endKey := prefixEnd(recordKeyPrefix)
+	if endTxID != "" {
+		endKey = constructRecordKey(endTxID)
+	}
+	return &recordsItr{itr: s.db.GetIterator(constructRecordKey(startTxID), endKey)}
+}
+
+// GetRecordsByBlock returns an iterator over the records of the cross transactions prepared or confirmed in
+// the blocks [startBlock, endBlock), in the order of the blocks. A transaction prepared and confirmed within
+// the range is returned for both blocks.
+func (s *RecordStore) GetRecordsByBlock(startBlock, endBlock uint64) RecordsIterator {
+	return &indexItr{s: s, itr: s.db.GetIterator(constructBlockIndexPrefix(startBlock), constructBlockIndexPrefix(endBlock)),
+		prefixLen: len(blockIndexKeyPrefix) + 8}
+}
+
+// GetRecordsByState returns an iterator over the records of the cross transactions in the given state,
+// in the order of their txids
+func (s *RecordStore) GetRecordsByState(state cross.CrossTxState) RecordsIterator {
+	prefix := constructStateIndexPrefix(state)
+	return &indexItr{s: s, itr: s.db.GetIterator(prefix, prefixEnd(prefix)), prefixLen: len(prefix)}
+}
+
+// PutSavepoint adds the savepoint of the store to the batch, the height of the last transaction of the block
+// whose updates the batch holds
+func (s *RecordStore) PutSavepoint(batch *UpdateBatch, height *version.Height) {
+	batch.Put(savepointKey, height.ToBytes())
+}
+
+// GetLastSavepoint returns the savepoint of the store, nil if none was recorded
+func (s *RecordStore) GetLastSavepoint() (*version.Height, error) {
+	b, err := s.db.Get(savepointKey)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	height, _ := version.NewHeightFromBytes(b)
+	return height, nil
+}
+
+// Commit writes the updates of the batch atomically, synced to disk
+func (s *RecordStore) Commit(batch *UpdateBatch) error {
+	return s.db.WriteBatch(batch, true)
+}
+
+// UpgradeSchema rewrites the records written with an earlier schema version along with their index entries,
+// once, and records the current schema version. It fails on a store written with a later schema version.
+func (s *RecordStore) UpgradeSchema() error {
+	b, err := s.db.Get(schemaVersionKey)
+	if err != nil {
+		return err
+	}
+	if len(b) > 0 && b[0] >= SchemaVersion {
+		if b[0] > SchemaVersion {
+			return errors.Errorf("crossdb has schema version %d, only versions up to %d are supported", b[0], SchemaVersion)
+		}
+		return nil
+	}
+	batch := NewUpdateBatch()
+	itr := s.GetRecords("", "")
+	defer itr.Release()
+	for {
+		rec, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			break
+		}
+		if err := s.PutRecord(batch, rec); err != nil {
+			return err
+		}
+	}
+	batch.Put(schemaVersionKey, []byte{SchemaVersion})
+	return s.Commit(batch)
+}
+
+type recordsItr struct {
+	itr ResultsIterator
+}
+
+func (i *recordsItr) Next() (*cross.CrossTxStatus, error) {
+	if !i.itr.Next() {
+		return nil, nil
+	}
+	rec, err := decodeRecord(i.itr.Value())
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid record of cross transaction ["+string(i.itr.Key()[len(recordKeyPrefix):])+"]")
+	}
+	return rec, nil
+}
+
+func (i *recordsItr) Release() {
+	i.itr.Release()
+}
+
+// indexItr iterates over index entries, whose keys end with the cross txid of the record they point to
+type indexItr struct {
+	s         *RecordStore
+	itr       ResultsIterator
+	prefixLen int
+}
+
+func (i *indexItr) Next() (*cross.CrossTxStatus, error) {
+	for i.itr.Next() {
+		txID := string(i.itr.Key()[i.prefixLen:])
+		rec, err := i.s.GetRecord(txID)
+		if err != nil {
+			return nil, err
+		}
+		// the index entries are written along with their record
+		if rec != nil {
+			return rec, nil
+		}
+	}
+	return nil, nil
+}
+
+func (i *indexItr) Release() {
+	i.itr.Release()
+}
+
+func decodeRecord(b []byte) (*cross.CrossTxStatus, error) {
+	schemaVersion := byte(0)
+	if len(b) > 0 && b[0] < 0x08 {
+		schemaVersion, b = b[0], b[1:]
+	}
+	if schemaVersion > SchemaVersion {
+		return nil, errors.Errorf("unsupported schema version %d", schemaVersion)
+	}
+	rec := &cross.CrossTxStatus{}
+	if err := proto.Unmarshal(b, rec); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling record")
+	}
+	return rec, nil
+}
+
+func deleteIndexEntries(batch *UpdateBatch, rec *cross.CrossTxStatus) {
+	if rec == nil {
+		return
+	}
+	for _, key := range indexKeys(rec) {
+		batch.Delete(key)
+	}
+}
+
+// indexKeys returns the keys of the index entries of a record. The genesis block carrying no transaction
+// to prepare nor confirm, a zero block number is unset.
+func indexKeys(rec *cross.CrossTxStatus) [][]byte {
+	keys := [][]byte{constructStateIndexKey(rec.State, rec.CrossTxId)}
+	if rec.PreparedBlock != 0 {
+		keys = append(keys, constructBlockIndexKey(rec.PreparedBlock, rec.CrossTxId))
+	}
+	if rec.ConfirmationBlock != 0 {
+		keys = append(keys, constructBlockIndexKey(rec.ConfirmationBlock, rec.CrossTxId))
+	}
+	return keys
+}
+
+func constructRecordKey(txID string) []byte {
+	return append(append([]byte{}, recordKeyPrefix...), []byte(txID)...)
+}
+
+func constructBlockIndexPrefix(blockNum uint64) []byte {
+	key := make([]byte, len(blockIndexKeyPrefix)+8)
+	copy(key, blockIndexKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(blockIndexKeyPrefix):], blockNum)
+	return key
+}
+
+func constructBlockIndexKey(blockNum uint64, txID string) []byte {
+	return append(constructBlockIndexPrefix(blockNum), []byte(txID)...)
+}
+
+func constructStateIndexPrefix(state cross.CrossTxState) []byte {
+	key := make([]byte, len(stateIndexKeyPrefix)+4)
+	copy(key, stateIndexKeyPrefix)
+	binary.BigEndian.PutUint32(key[len(stateIndexKeyPrefix):], uint32(state))
+	return key
+}
+
+func constructStateIndexKey(state cross.CrossTxState, txID string) []byte {
+	return append(constructStateIndexPrefix(state), []byte(txID)...)
+}
+
+// prefixEnd returns the first key following the keys starting with the given prefix
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	end[len(end)-1]++
+	return end
+}
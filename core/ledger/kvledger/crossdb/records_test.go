@@ -0,0 +1,198 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crossdb
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordStore(t *testing.T) {
+	s := NewRecordStore(newMemCrossDB())
+	rec, err := s.GetRecord("tx1")
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+
+	batch := NewUpdateBatch()
+	prepared := &cross.CrossTxStatus{CrossTxId: "tx1", State: cross.CrossTxState_PREPARED, PreparedBlock: 2}
+	assert.NoError(t, s.PutRecord(batch, prepared))
+	assert.NoError(t, s.PutRecord(batch, &cross.CrossTxStatus{CrossTxId: "tx2", State: cross.CrossTxState_PREPARED, PreparedBlock: 2}))
+	assert.Error(t, s.PutRecord(batch, &cross.CrossTxStatus{State: cross.CrossTxState_PREPARED}))
+	// the updates are visible through the batch only until it is committed
+	rec, err = s.GetUpdatedRecord(batch, "tx1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(prepared, rec))
+	rec, err = s.GetRecord("tx1")
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+	assert.NoError(t, s.Commit(batch))
+	rec, err = s.GetRecord("tx1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(prepared, rec))
+
+	batch = NewUpdateBatch()
+	aborted := &cross.CrossTxStatus{CrossTxId: "tx1", State: cross.CrossTxState_ABORTED, PreparedBlock: 2, ConfirmationBlock: 4, Reason: "timeout"}
+	assert.NoError(t, s.PutRecord(batch, aborted))
+	committed := &cross.CrossTxStatus{CrossTxId: "tx3", State: cross.CrossTxState_COMMITTED, ConfirmationBlock: 3}
+	assert.NoError(t, s.PutRecord(batch, committed))
+	assert.NoError(t, s.Commit(batch))
+
+	assertRecords(t, s.GetRecords("", ""), "tx1", "tx2", "tx3")
+	assertRecords(t, s.GetRecords("tx2", ""), "tx2", "tx3")
+	assertRecords(t, s.GetRecords("tx1", "tx3"), "tx1", "tx2")
+	assertRecords(t, s.GetRecordsByBlock(0, 10), "tx1", "tx2", "tx3", "tx1")
+	assertRecords(t, s.GetRecordsByBlock(3, 4), "tx3")
+	assertRecords(t, s.GetRecordsByBlock(5, 10))
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_PREPARED), "tx2")
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_ABORTED), "tx1")
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_COMMITTED), "tx3")
+
+	// deleting a record deletes its index entries
+	batch = NewUpdateBatch()
+	assert.NoError(t, s.DeleteRecord(batch, "tx1"))
+	assert.NoError(t, s.DeleteRecord(batch, "unknown"))
+	assert.NoError(t, s.Commit(batch))
+	rec, err = s.GetRecord("tx1")
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+	assertRecords(t, s.GetRecordsByBlock(0, 10), "tx2", "tx3")
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_ABORTED))
+	assert.Len(t, s.db.(*memCrossDB).kvs, 6)
+}
+
+func TestRecordStoreSavepoint(t *testing.T) {
+	s := NewRecordStore(newMemCrossDB())
+	savepoint, err := s.GetLastSavepoint()
+	assert.NoError(t, err)
+	assert.Nil(t, savepoint)
+
+	batch := NewUpdateBatch()
+	s.PutSavepoint(batch, version.NewHeight(5, 2))
+	assert.NoError(t, s.Commit(batch))
+	savepoint, err = s.GetLastSavepoint()
+	assert.NoError(t, err)
+	assert.Equal(t, version.NewHeight(5, 2), savepoint)
+}
+
+func TestRecordStoreUpgradeSchema(t *testing.T) {
+	db := newMemCrossDB()
+	s := NewRecordStore(db)
+	// records written before the format was versioned carry no version nor index entries
+	legacy := &cross.CrossTxStatus{CrossTxId: "tx1", State: cross.CrossTxState_COMMITTED, ConfirmationBlock: 3}
+	legacyBytes, err := proto.Marshal(legacy)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(constructRecordKey("tx1"), legacyBytes, false))
+	rec, err := s.GetRecord("tx1")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(legacy, rec))
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_COMMITTED))
+
+	assert.NoError(t, s.UpgradeSchema())
+	assert.Equal(t, SchemaVersion, db.kvs[string(constructRecordKey("tx1"))][0])
+	assertRecords(t, s.GetRecordsByState(cross.CrossTxState_COMMITTED), "tx1")
+	assertRecords(t, s.GetRecordsByBlock(3, 4), "tx1")
+	// the schema is upgraded once
+	assert.NoError(t, db.Put(constructRecordKey("tx2"), legacyBytes, false))
+	assert.NoError(t, s.UpgradeSchema())
+	assert.Equal(t, legacyBytes, db.kvs[string(constructRecordKey("tx2"))])
+
+	// a store or a record written with a later version is rejected
+	assert.NoError(t, db.Put(constructRecordKey("tx3"), append([]byte{SchemaVersion + 1}, legacyBytes...), false))
+	_, err = s.GetRecord("tx3")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported schema version")
+	assert.NoError(t, db.Put(schemaVersionKey, []byte{SchemaVersion + 1}, false))
+	assert.Error(t, s.UpgradeSchema())
+}
+
+func assertRecords(t *testing.T, itr RecordsIterator, txIDs ...string) {
+	defer itr.Release()
+	var actual []string
+	for {
+		rec, err := itr.Next()
+		assert.NoError(t, err)
+		if rec == nil {
+			break
+		}
+		actual = append(actual, rec.CrossTxId)
+	}
+	assert.Equal(t, txIDs, actual)
+}
+
+// memCrossDB is a CrossDB held in memory
+type memCrossDB struct {
+	kvs map[string][]byte
+}
+
+func newMemCrossDB() *memCrossDB {
+	return &memCrossDB{make(map[string][]byte)}
+}
+
+func (db *memCrossDB) Test() {}
+
+func (db *memCrossDB) Get(key []byte) ([]byte, error) {
+	return db.kvs[string(key)], nil
+}
+
+func (db *memCrossDB) Put(key []byte, value []byte, sync bool) error {
+	db.kvs[string(key)] = value
+	return nil
+}
+
+func (db *memCrossDB) Delete(key []byte, sync bool) error {
+	delete(db.kvs, string(key))
+	return nil
+}
+
+func (db *memCrossDB) GetIterator(startKey []byte, endKey []byte) ResultsIterator {
+	itr := &memItr{db: db, pos: -1}
+	for k := range db.kvs {
+		if bytes.Compare([]byte(k), startKey) >= 0 && (endKey == nil || bytes.Compare([]byte(k), endKey) < 0) {
+			itr.keys = append(itr.keys, k)
+		}
+	}
+	sort.Strings(itr.keys)
+	return itr
+}
+
+func (db *memCrossDB) WriteBatch(batch *UpdateBatch, sync bool) error {
+	for k, v := range batch.KVs {
+		if v == nil {
+			delete(db.kvs, k)
+		} else {
+			db.kvs[k] = v
+		}
+	}
+	return nil
+}
+
+type memItr struct {
+	db   *memCrossDB
+	keys []string
+	pos  int
+}
+
+func (itr *memItr) Next() bool {
+	itr.pos++
+	return itr.pos < len(itr.keys)
+}
+
+func (itr *memItr) Key() []byte {
+	return []byte(itr.keys[itr.pos])
+}
+
+func (itr *memItr) Value() []byte {
+	return itr.db.kvs[itr.keys[itr.pos]]
+}
+
+func (itr *memItr) Release() {}
@@ -19,6 +19,7 @@ package historydb
 import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/protos/common"
 )
@@ -38,4 +39,7 @@ type HistoryDB interface {
 	GetLastSavepoint() (*version.Height, error)
 	ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error)
 	CommitLostBlock(blockAndPvtdata *ledger.BlockAndPvtData) error
+	// CommitCrossConfirmations commits a cross confirmation block. The writes of the given rollbacks are recorded
+	// as modifications of the keys made by the rolled back cross transactions, at the height of their confirmation
+	CommitCrossConfirmations(block *common.Block, rollbacks []*rwsetutil.CrossRollback) error
 }
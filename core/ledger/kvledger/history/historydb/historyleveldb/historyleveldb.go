@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package historyleveldb
 
 import (
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -17,7 +18,9 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
 )
 
 var logger historydbLogger = flogging.MustGetLogger("historyleveldb")
@@ -173,6 +176,59 @@ func (historyDB *historyDB) Commit(block *common.Block) error {
 	return nil
 }
 
+// CommitCrossConfirmations implements method in HistoryDB interface.
+// A confirmation transaction carries no write-set, hence, unlike the other history records, the
+// records of the rolled back keys hold the modification itself, wrapped in a KV that identifies the key.
+func (historyDB *historyDB) CommitCrossConfirmations(block *common.Block, rollbacks []*rwsetutil.CrossRollback) error {
+	blockNo := block.Header.Number
+	dbBatch := leveldbhelper.NewUpdateBatch()
+
+	for _, rollback := range rollbacks {
+		if rollback.TxNum >= uint64(len(block.Data.Data)) {
+			return errors.Errorf("no confirmation [%d] in block [%d]", rollback.TxNum, blockNo)
+		}
+		env, err := putils.GetEnvelopeFromBlock(block.Data.Data[rollback.TxNum])
+		if err != nil {
+			return err
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			return err
+		}
+		chdr, err := putils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil {
+			return err
+		}
+		for _, nsRWSet := range rollback.RwSet.NsRwSets {
+			ns := nsRWSet.NameSpace
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				record, err := constructCrossRollbackRecord(ns, kvWrite.Key, &queryresult.KeyModification{TxId: rollback.CrossTxID,
+					Value: kvWrite.Value, Timestamp: chdr.Timestamp, IsDelete: kvWrite.IsDelete})
+				if err != nil {
+					return err
+				}
+				dbBatch.Put(historydb.ConstructCompositeHistoryKey(ns, kvWrite.Key, blockNo, rollback.TxNum), record)
+			}
+		}
+	}
+
+	height := version.NewHeight(blockNo, uint64(len(block.Data.Data)))
+	dbBatch.Put(savePointKey, height.ToBytes())
+	if err := historyDB.db.WriteBatch(dbBatch, true); err != nil {
+		return err
+	}
+	logger.Debugf("Channel [%s]: Cross confirmation committed to history database for blockNo [%v]", historyDB.dbName, blockNo)
+	return nil
+}
+
+func constructCrossRollbackRecord(ns, key string, mod *queryresult.KeyModification) ([]byte, error) {
+	modBytes, err := proto.Marshal(mod)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&queryresult.KV{Namespace: ns, Key: key, Value: modBytes})
+}
+
 // NewHistoryQueryExecutor implements method in HistoryDB interface
 func (historyDB *historyDB) NewHistoryQueryExecutor(blockStore blkstorage.BlockStore) (ledger.HistoryQueryExecutor, error) {
 	return &LevelHistoryDBQueryExecutor{historyDB, blockStore}, nil
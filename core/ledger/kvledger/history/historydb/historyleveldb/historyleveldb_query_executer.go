@@ -17,6 +17,7 @@ limitations under the License.
 package historyleveldb
 
 import (
+	"github.com/golang/protobuf/proto"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
@@ -114,6 +115,20 @@ func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
 		logger.Debugf("Found history record for namespace:%s key:%s at blockNumTranNum %v:%v\n",
 			scanner.namespace, scanner.key, blockNum, tranNum)
 
+		// the records of the keys rolled back by a cross confirmation hold the modification itself
+		if record := scanner.dbItr.Value(); len(record) > 0 {
+			queryResult, err := getKeyModificationFromCrossRollbackRecord(record, scanner.namespace, scanner.key)
+			if err != nil {
+				return nil, err
+			}
+			if queryResult == nil {
+				logger.Warningf("Some other key [%#v] found in the range while scanning history for key [%#v]. Skipping (rollback of another key)",
+					historyKey, scanner.key)
+				continue
+			}
+			return queryResult, nil
+		}
+
 		// Get the transaction from block storage that is associated with this history record
 		tranEnvelope, err := scanner.blockStore.RetrieveTxByBlockNumTranNum(blockNum, tranNum)
 		if err == blkstorage.ErrNotFoundInIndex {
@@ -149,6 +164,23 @@ func (scanner *historyScanner) Close() {
 	scanner.dbItr.Release()
 }
 
+// getKeyModificationFromCrossRollbackRecord decodes a record written by CommitCrossConfirmations.
+// It returns nil if the record belongs to another key.
+func getKeyModificationFromCrossRollbackRecord(record []byte, namespace string, key string) (commonledger.QueryResult, error) {
+	kv := &queryresult.KV{}
+	if err := proto.Unmarshal(record, kv); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling cross rollback history record")
+	}
+	if kv.Namespace != namespace || kv.Key != key {
+		return nil, nil
+	}
+	mod := &queryresult.KeyModification{}
+	if err := proto.Unmarshal(kv.Value, mod); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling cross rollback history record")
+	}
+	return mod, nil
+}
+
 // getTxIDandKeyWriteValueFromTran inspects a transaction for writes to a given key
 func getKeyModificationFromTran(tranEnvelope *common.Envelope, namespace string, key string) (commonledger.QueryResult, error) {
 	logger.Debugf("Entering getKeyModificationFromTran()\n", namespace, key)
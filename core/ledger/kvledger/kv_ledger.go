@@ -7,14 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package kvledger
 
 import (
-	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/peer/cross"
-	"github.com/hyperledger/fabric/protos/utils"
 
 	"sync"
 	"time"
@@ -40,6 +36,9 @@ import (
 
 var logger = flogging.MustGetLogger("kvledger")
 
+// crossLockMgr holds the locks and the undo log of the in-flight cross transactions
+var crossLockMgr = cross.GetLockManager()
+
 // KVLedger provides an implementation of `ledger.PeerLedger`.
 // This implementation provides a key-value based data model
 type kvLedger struct {
@@ -47,7 +46,8 @@ type kvLedger struct {
 	blockStore             *ledgerstorage.Store
 	txtmgmt                txmgr.TxMgr
 	historyDB              historydb.HistoryDB
-	crossDB 			   crossdb.CrossDB //NEW add
+	crossDB                crossdb.CrossDB      //NEW add
+	crossRecords           *crossdb.RecordStore //NEW add
 	configHistoryRetriever ledger.ConfigHistoryRetriever
 	blockAPIsRWLock        *sync.RWMutex
 }
@@ -69,7 +69,7 @@ func newKVLedger(
 	// id store, blockstore, txmgr (state database), history database
 	//l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, historyDB: historyDB, blockAPIsRWLock: &sync.RWMutex{}}
 	//NEW update
-	l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, historyDB: historyDB, crossDB: crossDB, blockAPIsRWLock: &sync.RWMutex{}}
+	l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, historyDB: historyDB, crossDB: crossDB, crossRecords: crossdb.NewRecordStore(crossDB), blockAPIsRWLock: &sync.RWMutex{}}
 
 	// TODO Move the function `GetChaincodeEventListener` to ledger interface and
 	// this functionality of regiserting for events to ledgermgmt package so that this
@@ -84,6 +84,17 @@ func newKVLedger(
 		return nil, err
 	}
 	l.initBlockStore(btlPolicy)
+	//NEW add
+	// Recover the locks and the undo log of the cross transactions that were in-flight when the peer stopped
+	if err := crossLockMgr.SetStore(ledgerID, newCrossLockStore(crossDB)); err != nil {
+		return nil, err
+	}
+	if err := l.crossRecords.UpgradeSchema(); err != nil {
+		return nil, err
+	}
+	if err := l.indexCrossTxStates(); err != nil {
+		return nil, err
+	}
 	//Recover both state DB and history DB if they are out of sync with block storage
 	if err := l.recoverDBs(); err != nil {
 		panic(fmt.Errorf(`Error during state DB recovery:%s`, err))
@@ -95,7 +106,8 @@ func newKVLedger(
 func (l *kvLedger) initTxMgr(versionedDB privacyenabledstate.DB, stateListeners []ledger.StateListener,
 	btlPolicy pvtdatapolicy.BTLPolicy, bookkeeperProvider bookkeeping.Provider) error {
 	var err error
-	l.txtmgmt, err = lockbasedtxmgr.NewLockBasedTxMgr(l.ledgerID, versionedDB, stateListeners, btlPolicy, bookkeeperProvider)
+	l.txtmgmt, err = lockbasedtxmgr.NewLockBasedTxMgr(l.ledgerID, versionedDB, stateListeners, btlPolicy, bookkeeperProvider,
+		&crossLockChecker{l.ledgerID}) //NEW add crossLockChecker
 	return err
 }
 
@@ -158,6 +170,25 @@ func (l *kvLedger) recommitLostBlocks(firstBlockNum uint64, lastBlockNum uint64,
 		if blockAndPvtdata, err = l.GetPvtDataAndBlockByNum(blockNumber, nil); err != nil {
 			return err
 		}
+		//NEW add
+		if isCrossConfirmationBlock(blockAndPvtdata.Block) {
+			if err := l.recommitCrossConfirmation(blockAndPvtdata.Block, recoverables...); err != nil {
+				return err
+			}
+			continue
+		}
+		// the locks are taken from the state preceding the block, they are already taken if the state is up to date
+		for _, r := range recoverables {
+			if r == l.txtmgmt {
+				crossBatch := crossdb.NewUpdateBatch()
+				if err := l.prepareCrossTxs(blockAndPvtdata, crossBatch); err != nil {
+					return err
+				}
+				if err := l.commitCrossRecords(crossBatch, blockAndPvtdata.Block); err != nil {
+					return err
+				}
+			}
+		} //NEW end
 		for _, r := range recoverables {
 			if err := r.CommitLostBlock(blockAndPvtdata); err != nil {
 				return err
@@ -258,77 +289,21 @@ func (l *kvLedger) NewQueryExecutor() (ledger.QueryExecutor, error) {
 func (l *kvLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error) {
 	return l.historyDB.NewHistoryQueryExecutor(l.blockStore)
 }
-func SplitCompositeKey(bytesToSplit []byte, separator []byte) ([]byte, []byte) {  //NEW add
-	split := bytes.SplitN(bytesToSplit, separator, 2)
-	return split[0], split[1]
-}
+
 // CommitWithPvtData commits the block and the corresponding pvt data in an atomic operation
 func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) error {
 	var err error
 	block := pvtdataAndBlock.Block
 	blockNo := pvtdataAndBlock.Block.Header.Number
-	//var CompositeKeySep = []byte{0x00} //NEW add
-	var CompositeKeySep = []byte("_") //NEW add
 
 	//NEW add
-	if string(block.Metadata.Metadata[4]) == "confirmation" {
-		fmt.Println("kv_ledger.go CommitWithPvtData() block.Metadata.Metadata[4] == confirmation")
-		blkHN := make([]byte, 8) //block.Head.Number
-		binary.BigEndian.PutUint64(blkHN, pvtdataAndBlock.Block.Header.Number)
-
-		startCommitBlockStorage := time.Now()
-		logger.Debugf("[%s] Committing block [%d] to storage", l.ledgerID, blockNo)
-		l.blockAPIsRWLock.Lock()
-		defer l.blockAPIsRWLock.Unlock()
-		fmt.Println("kv_ledger.go CommitWithPvtData() confirmation开始commit")
-		if err = l.blockStore.CommitWithPvtData(pvtdataAndBlock); err != nil {
-			return err
-		}
-		fmt.Println("kv_ledger.go CommitWithPvtData() confirmation完成commit")
-
-		envByte := block.Data.Data[0]  //第一个envelope
-		env, _ := utils.GetEnvelopeFromBlock(envByte)
-		payload := utils.GetPayloadFromBytes(env.Payload)
-		confirmation := payload.Data  //confirmation具体内容
-		txidByte, sf := SplitCompositeKey(confirmation, CompositeKeySep)  //txid & succ/fail
-		fmt.Printf("core/ledger/kvledger/kv_ledger.go CommitWithPvtData() confirmation=%s, txid=%s, sf=%s", confirmation, txidByte, sf)
-		txid := string(txidByte)
-
-		if string(sf) == "fail"{ //rollback & unlock
-			fmt.Printf("core/ledger/kvledger/kv_ledger.go CommitWithPvtData() fail ledgerID = %s, txid = %s, sf = %s ", l.ledgerID, txid, string(sf))
-
-			kv := cross.GetRollbackKV(txid)
-			l.txtmgmt.CrossRollbackOrigVal(kv)
-			cross.CrossConfirmFail(txid)
-			/*crsH := &crossinterface.CrossHandler{}
-			crsH.CrossConfirmFail(l.ledgerID, txid)
-			crsH.Itfc.CrossConfirmFail(l.ledgerID, txid)
-			// 错 crossinterface.CrossInterface.CrossConfirmFail(l.ledgerID, txid)*/
-
-			l.crossDB.Put(blkHN, confirmation, false)
-		}else { // succ unlock
-			fmt.Printf("core/ledger/kvledger/kv_ledger.go CommitWithPvtData() succ ledgerID = %s, txid = %s, sf = %s ", l.ledgerID, string(txid), string(sf))
-
-			/*crsH := &crossinterface.CrossHandler{}
-		//	if err = crsH.CrossConfirmSucc(string(txid)); err!= nil {
-			if err = crsH.Itfc.CrossConfirmSucc(txid); err != nil {
-				fmt.Println("kv_ledger.CrossConfirmSucc ERROR")
-			}*/
-
-			cross.CrossConfirmSucc(txid)
-			l.crossDB.Put(blkHN, confirmation, false)
-		}
-
-		elapsedCommitBlockStorage := time.Since(startCommitBlockStorage) / time.Millisecond // duration in ms
-		logger.Infof("[%s] Committed ConfirmationBlock [%d] txid:%s is %s in %dms ",
-			l.ledgerID, block.Header.Number, string(txid), string(sf), elapsedCommitBlockStorage)
-
-		return nil
+	if isCrossConfirmationBlock(block) {
+		return l.commitCrossConfirmation(pvtdataAndBlock)
 	} //NEW end
 
 	startStateValidation := time.Now()
 	logger.Debugf("[%s] Validating state for block [%d]", l.ledgerID, blockNo)
-	err = l.txtmgmt.ValidateAndPrepare(pvtdataAndBlock, true)  //MVCC
+	err = l.txtmgmt.ValidateAndPrepare(pvtdataAndBlock, true) //MVCC
 	if err != nil {
 		return err
 	}
@@ -343,6 +318,16 @@ func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) er
 	}
 	elapsedCommitBlockStorage := time.Since(startCommitBlockStorage) / time.Millisecond // duration in ms
 
+	//NEW add
+	// the locks are persisted before the state is committed, the recovery of the state takes them again otherwise
+	crossBatch := crossdb.NewUpdateBatch()
+	if err = l.prepareCrossTxs(pvtdataAndBlock, crossBatch); err != nil {
+		panic(fmt.Errorf(`Error during preparing cross transactions:%s`, err))
+	}
+	if err = l.commitCrossRecords(crossBatch, block); err != nil {
+		panic(fmt.Errorf(`Error during commit to crossdb:%s`, err))
+	} //NEW end
+
 	startCommitState := time.Now()
 	logger.Debugf("[%s] Committing block [%d] transactions to state database", l.ledgerID, blockNo)
 	if err = l.txtmgmt.Commit(); err != nil {
@@ -361,20 +346,6 @@ func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) er
 
 	elapsedCommitWithPvtData := time.Since(startStateValidation) / time.Millisecond // total duration in ms
 
-	// NEW add
-	crossinfo := string(pvtdataAndBlock.Block.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO])
-	blkHN := make([]byte, 8) //block.Head.Number
-	binary.BigEndian.PutUint64(blkHN, pvtdataAndBlock.Block.Header.Number)
-	if crossinfo == "crosstx"{
-		fmt.Println("core/ledger/kvledger/kv_ledger.go CommitWithPvtData() crossinfo == crosstx")
-		l.crossDB.Put(blkHN, []byte(crossinfo), false)
-
-		//print看看
-		cDBV, _ := l.crossDB.Get(blkHN)
-		fmt.Println("core/ledger/kvledger/kv_ledger.go CommitWithPvtData() crossDB.Key  crossDB.Value", blkHN, cDBV)
-	}
-	//NEW end
-
 	logger.Infof("[%s] Committed block [%d] with %d transaction(s) in %dms (state_validation=%dms block_commit=%dms state_commit=%dms)",
 		l.ledgerID, block.Header.Number, len(block.Data.Data), elapsedCommitWithPvtData,
 		elapsedStateValidation, elapsedCommitBlockStorage, elapsedCommitState)
@@ -422,13 +393,6 @@ func (l *kvLedger) Close() {
 	l.txtmgmt.Shutdown()
 }
 
-
-// NEW add
-// 实现 kvledger.RollbackInterface  在core/ledger/kvledger/cross_rollback_interface.go
-func (l *kvLedger) CrossRollbackOrigVal(kov *[]statedb.KeyOrigVal){
-	l.txtmgmt.CrossRollbackOrigVal(kov)
-}
-
 type blocksItr struct {
 	blockAPIsRWLock *sync.RWMutex
 	blocksItr       commonledger.ResultsIterator
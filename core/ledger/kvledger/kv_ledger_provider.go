@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb/crosscouchdb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/crossdb/crossleveldb"
 
 	"github.com/hyperledger/fabric/core/ledger/confighistory"
@@ -62,6 +63,13 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	// Initialize the ID store (inventory of chainIds/ledgerIds)
 	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
 
+	//NEW add
+	crossdbProvider, err := newCrossDBProvider(idStore)
+	if err != nil {
+		idStore.close()
+		return nil, err
+	} //NEW end
+
 	ledgerStoreProvider := ledgerstorage.NewProvider()
 
 	// Initialize the versioned database (state database)
@@ -74,8 +82,6 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	historydbProvider := historyleveldb.NewHistoryDBProvider()
 	bookkeepingProvider := bookkeeping.NewProvider()
 
-	crossdbProvider := crossleveldb.NewCrossDBProvider() //NEW add
-
 	// Initialize config history mgr
 	configHistoryMgr := confighistory.NewMgr()
 	logger.Info("ledger provider Initialized")
@@ -85,6 +91,42 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	return provider, nil
 }
 
+// NEW add
+// newCrossDBProvider constructs the provider of the crossdb selected by the configuration. As the crossdb holds the
+// locks and the states of the cross transactions, which are not rebuilt from the blocks, the peer refuses to start
+// when the crossdb of the other type holds records. The CouchDB crossdb is kept in the CouchDB instance of the
+// state database, hence is available with a CouchDB state database only.
+func newCrossDBProvider(idStore *idStore) (crossdb.CrossDBProvider, error) {
+	if !ledgerconfig.IsCrossCouchDBEnabled() {
+		if ledgerconfig.IsCouchDBEnabled() {
+			ledgerIDs, err := idStore.getAllLedgerIds()
+			if err != nil {
+				return nil, err
+			}
+			hasCouchCrossDB, err := crosscouchdb.HasCrossDB(ledgerIDs)
+			if err != nil {
+				return nil, err
+			}
+			if hasCouchCrossDB {
+				return nil, errors.New("a CouchDB crossdb holds the records of cross transactions, while ledger.cross.crossDatabase is goleveldb")
+			}
+		}
+		return crossleveldb.NewCrossDBProvider(), nil
+	}
+
+	if !ledgerconfig.IsCouchDBEnabled() {
+		return nil, errors.New("the CouchDB crossdb requires CouchDB as ledger.state.stateDatabase")
+	}
+	empty, err := crossleveldb.IsCrossDBEmpty()
+	if err != nil {
+		return nil, err
+	}
+	if !empty {
+		return nil, errors.New("a goleveldb crossdb holds the records of cross transactions, while ledger.cross.crossDatabase is CouchDB")
+	}
+	return crosscouchdb.NewCrossDBProvider()
+}
+
 // Initialize implements the corresponding method from interface ledger.PeerLedgerProvider
 func (provider *Provider) Initialize(stateListeners []ledger.StateListener) {
 	provider.stateListeners = stateListeners
@@ -196,6 +238,7 @@ func (provider *Provider) Close() {
 	provider.historydbProvider.Close()
 	provider.bookkeepingProvider.Close()
 	provider.configHistoryMgr.Close()
+	provider.crossdbProvider.Close()
 }
 
 // recoverUnderConstructionLedger checks whether the under construction flag is set - this would be the case
@@ -139,7 +139,7 @@ func TestMultipleLedgerBasicRW(t *testing.T) {
 		err = s.SetState("ns", "testKey", []byte(fmt.Sprintf("testValue_%d", i)))
 		s.Done()
 		testutil.AssertNoError(t, err, "")
-		res, err := s.GetTxSimulationResults()
+		res, err := s.GetTxSimulationResults(nil)
 		testutil.AssertNoError(t, err, "")
 		pubSimBytes, _ := res.GetPubSimulationBytes()
 		b := bg.NextBlock([][]byte{pubSimBytes})
@@ -188,7 +188,7 @@ func TestLedgerBackup(t *testing.T) {
 	simulator.SetState("ns1", "key2", []byte("value2"))
 	simulator.SetState("ns1", "key3", []byte("value3"))
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block1 := bg.NextBlock([][]byte{pubSimBytes})
 	ledger.CommitWithPvtData(&ledgerproto.BlockAndPvtData{Block: block1})
@@ -199,7 +199,7 @@ func TestLedgerBackup(t *testing.T) {
 	simulator.SetState("ns1", "key2", []byte("value5"))
 	simulator.SetState("ns1", "key3", []byte("value6"))
 	simulator.Done()
-	simRes, _ = simulator.GetTxSimulationResults()
+	simRes, _ = simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ = simRes.GetPubSimulationBytes()
 	block2 := bg.NextBlock([][]byte{pubSimBytes})
 	ledger.CommitWithPvtData(&ledgerproto.BlockAndPvtData{Block: block2})
@@ -285,6 +285,39 @@ func TestLedgerBackup(t *testing.T) {
 	testutil.AssertEquals(t, result2.(*queryresult.KeyModification).Value, []byte("value4"))
 }
 
+func TestCrossDBProviderSelection(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.state.stateDatabase", "goleveldb")
+	defer viper.Set("ledger.cross.crossDatabase", "goleveldb")
+
+	// the CouchDB crossdb is kept in the CouchDB state database
+	viper.Set("ledger.cross.crossDatabase", "CouchDB")
+	_, err := NewProvider()
+	testutil.AssertError(t, err, "CouchDB crossdb without CouchDB state database")
+
+	// a goleveldb crossdb holding records prevents switching to CouchDB
+	viper.Set("ledger.cross.crossDatabase", "goleveldb")
+	provider, err := NewProvider()
+	testutil.AssertNoError(t, err, "")
+	crossDB, err := provider.(*Provider).crossdbProvider.GetDBHandler("ledger1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, crossDB.Put([]byte("key1"), []byte("value1"), true), "")
+	provider.Close()
+
+	viper.Set("ledger.state.stateDatabase", "CouchDB")
+	viper.Set("ledger.cross.crossDatabase", "CouchDB")
+	_, err = NewProvider()
+	testutil.AssertError(t, err, "non-empty goleveldb crossdb")
+
+	// the peer starts again with the crossdb holding the records
+	viper.Set("ledger.state.stateDatabase", "goleveldb")
+	viper.Set("ledger.cross.crossDatabase", "goleveldb")
+	provider, err = NewProvider()
+	testutil.AssertNoError(t, err, "")
+	provider.Close()
+}
+
 func constructTestLedgerID(i int) string {
 	return fmt.Sprintf("ledger_%06d", i)
 }
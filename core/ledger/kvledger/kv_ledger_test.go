@@ -58,7 +58,7 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	simulator.SetState("ns1", "key2", []byte("value2"))
 	simulator.SetState("ns1", "key3", []byte("value3"))
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block1 := bg.NextBlock([][]byte{pubSimBytes})
 	ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1})
@@ -74,7 +74,7 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	simulator.SetState("ns1", "key2", []byte("value5"))
 	simulator.SetState("ns1", "key3", []byte("value6"))
 	simulator.Done()
-	simRes, _ = simulator.GetTxSimulationResults()
+	simRes, _ = simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ = simRes.GetPubSimulationBytes()
 	block2 := bg.NextBlock([][]byte{pubSimBytes})
 	ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2})
@@ -141,7 +141,7 @@ func TestKVLedgerBlockStorageWithPvtdata(t *testing.T) {
 	simulator.SetPrivateData("ns1", "coll1", "key2", []byte("value2"))
 	simulator.SetPrivateData("ns1", "coll2", "key2", []byte("value3"))
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block1 := bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})
 	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}), "")
@@ -157,7 +157,7 @@ func TestKVLedgerBlockStorageWithPvtdata(t *testing.T) {
 	simulator.SetState("ns1", "key2", []byte("value5"))
 	simulator.SetState("ns1", "key3", []byte("value6"))
 	simulator.Done()
-	simRes, _ = simulator.GetTxSimulationResults()
+	simRes, _ = simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ = simRes.GetPubSimulationBytes()
 	block2 := bg.NextBlock([][]byte{pubSimBytes})
 	ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2})
@@ -397,7 +397,7 @@ func TestLedgerWithCouchDbEnabledWithBinaryAndJSONData(t *testing.T) {
 	simulator.SetState("ns1", "key6", []byte("{\"shipmentID\":\"161003PKC7300\",\"customsInvoice\":{\"methodOfTransport\":\"GROUND\",\"invoiceNumber\":\"00091622\"},\"weightUnitOfMeasure\":\"KGM\",\"volumeUnitOfMeasure\": \"CO\",\"dimensionUnitOfMeasure\":\"CM\",\"currency\":\"USD\"}"))
 	simulator.SetState("ns1", "key7", []byte("{\"shipmentID\":\"161003PKC7600\",\"customsInvoice\":{\"methodOfTransport\":\"AIR MAYBE\",\"invoiceNumber\":\"00091624\"},\"weightUnitOfMeasure\":\"KGM\",\"volumeUnitOfMeasure\": \"CO\",\"dimensionUnitOfMeasure\":\"CM\",\"currency\":\"USD\"}"))
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block1 := bg.NextBlock([][]byte{pubSimBytes})
 
@@ -417,7 +417,7 @@ func TestLedgerWithCouchDbEnabledWithBinaryAndJSONData(t *testing.T) {
 	simulator.SetState("ns1", "key7", []byte("{\"shipmentID\":\"161003PKC7600\",\"customsInvoice\":{\"methodOfTransport\":\"GROUND\",\"invoiceNumber\":\"00091624\"},\"weightUnitOfMeasure\":\"KGM\",\"volumeUnitOfMeasure\": \"CO\",\"dimensionUnitOfMeasure\":\"CM\",\"currency\":\"USD\"}"))
 	simulator.SetState("ns1", "key8", []byte("{\"shipmentID\":\"161003PKC7700\",\"customsInvoice\":{\"methodOfTransport\":\"SHIP\",\"invoiceNumber\":\"00091625\"},\"weightUnitOfMeasure\":\"KGM\",\"volumeUnitOfMeasure\": \"CO\",\"dimensionUnitOfMeasure\":\"CM\",\"currency\":\"USD\"}"))
 	simulator.Done()
-	simRes, _ = simulator.GetTxSimulationResults()
+	simRes, _ = simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ = simRes.GetPubSimulationBytes()
 	simulationResults = append(simulationResults, pubSimBytes)
 	//add a 2nd transaction
@@ -427,7 +427,7 @@ func TestLedgerWithCouchDbEnabledWithBinaryAndJSONData(t *testing.T) {
 	simulator2.SetState("ns1", "key9", []byte("value5"))
 	simulator2.SetState("ns1", "key10", []byte("{\"shipmentID\":\"261003PKC8000\",\"customsInvoice\":{\"methodOfTransport\":\"DONKEY\",\"invoiceNumber\":\"00091626\"},\"weightUnitOfMeasure\":\"KGM\",\"volumeUnitOfMeasure\": \"CO\",\"dimensionUnitOfMeasure\":\"CM\",\"currency\":\"USD\"}"))
 	simulator2.Done()
-	simRes2, _ := simulator2.GetTxSimulationResults()
+	simRes2, _ := simulator2.GetTxSimulationResults(nil)
 	pubSimBytes2, _ := simRes2.GetPubSimulationBytes()
 	simulationResults = append(simulationResults, pubSimBytes2)
 
@@ -495,7 +495,7 @@ func prepareNextBlockForTest(t *testing.T, l lgr.PeerLedger, bg *testutil.BlockG
 		simulator.SetPrivateData("ns", "coll", k, []byte(v))
 	}
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block := bg.NextBlock([][]byte{pubSimBytes})
 	return &lgr.BlockAndPvtData{Block: block,
@@ -584,7 +584,7 @@ func prepareNextBlockForTestCollectionConfigs(t *testing.T, l lgr.PeerLedger, bg
 	testutil.AssertNoError(t, err, "")
 	simulator.SetState("lscc", key, value)
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block := bg.NextBlock([][]byte{pubSimBytes})
 	return &lgr.BlockAndPvtData{Block: block}
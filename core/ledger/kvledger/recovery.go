@@ -16,7 +16,11 @@ limitations under the License.
 
 package kvledger
 
-import "github.com/hyperledger/fabric/core/ledger"
+import (
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/protos/common"
+)
 
 type recoverable interface {
 	// ShouldRecover return whether recovery is need.
@@ -25,6 +29,8 @@ type recoverable interface {
 	ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error)
 	// CommitLostBlock recommits the block
 	CommitLostBlock(block *ledger.BlockAndPvtData) error
+	// CommitCrossConfirmations recommits a cross confirmation block along with the rollbacks it caused
+	CommitCrossConfirmations(block *common.Block, rollbacks []*rwsetutil.CrossRollback) error
 }
 
 type recoverer struct {
@@ -55,7 +55,7 @@ func TestStateListener(t *testing.T) {
 
 	// commit tx1 and this should cause mock listener to recieve the state changes made by tx1
 	mockListener.reset()
-	sim1Res, _ := sim1.GetTxSimulationResults()
+	sim1Res, _ := sim1.GetTxSimulationResults(nil)
 	sim1ResBytes, _ := sim1Res.GetPubSimulationBytes()
 	assert.NoError(t, err)
 	blk1 := bg.NextBlock([][]byte{sim1ResBytes})
@@ -66,7 +66,7 @@ func TestStateListener(t *testing.T) {
 	// commit tx2 and this should not cause mock listener to recieve the state changes made by tx2
 	// (because, tx2 should be found as invalid)
 	mockListener.reset()
-	sim2Res, _ := sim2.GetTxSimulationResults()
+	sim2Res, _ := sim2.GetTxSimulationResults(nil)
 	sim2ResBytes, _ := sim2Res.GetPubSimulationBytes()
 	assert.NoError(t, err)
 	blk2 := bg.NextBlock([][]byte{sim2ResBytes})
@@ -76,7 +76,7 @@ func TestStateListener(t *testing.T) {
 
 	// commit tx3 and thsi should cause mock listener to recieve changes made by tx3
 	mockListener.reset()
-	sim3Res, _ := sim3.GetTxSimulationResults()
+	sim3Res, _ := sim3.GetTxSimulationResults(nil)
 	sim3ResBytes, _ := sim3Res.GetPubSimulationBytes()
 	assert.NoError(t, err)
 	blk3 := bg.NextBlock([][]byte{sim3ResBytes})
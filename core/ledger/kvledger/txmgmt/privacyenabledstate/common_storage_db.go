@@ -195,11 +195,6 @@ func (s *CommonStorageDB) ApplyPrivacyAwareUpdates(updates *UpdateBatch, height
 	return s.VersionedDB.ApplyUpdates(updates.PubUpdates.UpdateBatch, height)
 }
 
-// NEW add
-func (s *CommonStorageDB) ApplyCrossOrigVal(kov *[]statedb.KeyOrigVal){
-	s.VersionedDB.ApplyCrossOrigVal(kov)
-}
-
 // HandleChaincodeDeploy initializes database artifacts for the database associated with the namespace
 // This function delibrately suppresses the errors that occur during the creation of the indexes on couchdb.
 // This is because, in the present code, we do not differentiate between the errors because of couchdb interaction
@@ -253,6 +248,17 @@ func (s *CommonStorageDB) ChaincodeDeployDone(succeeded bool) {
 	// NOOP
 }
 
+// NEW add
+// DerivePvtDataNs returns the namespace under which the private data of a collection is held in the state
+func DerivePvtDataNs(namespace, collection string) string {
+	return derivePvtDataNs(namespace, collection)
+}
+
+// DeriveHashedDataNs returns the namespace under which the hashed data of a collection is held in the state
+func DeriveHashedDataNs(namespace, collection string) string {
+	return deriveHashedDataNs(namespace, collection)
+}
+
 func derivePvtDataNs(namespace, collection string) string {
 	return namespace + nsJoiner + pvtDataPrefix + collection
 }
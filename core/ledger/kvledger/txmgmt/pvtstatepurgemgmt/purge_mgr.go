@@ -100,6 +100,8 @@ func (p *purgeMgr) DeleteExpiredAndUpdateBookkeeping(
 	if err != nil {
 		return err
 	}
+	//NEW add
+	listExpiryInfo = withoutRestoredValues(listExpiryInfo, p.workingset.expiringBlk) //NEW end
 
 	// For each key selected for purging, check if the key is not getting updated in the current block,
 	// add its deletion in the update batches for pvt and hashed updates
@@ -129,6 +131,23 @@ func (p *purgeMgr) DeleteExpiredAndUpdateBookkeeping(
 	return p.expKeeper.updateBookkeeping(listExpiryInfo, nil)
 }
 
+// NEW add
+// withoutRestoredValues removes the entries of the values not committed by the committing block. Such values are
+// the original values restored by the rollback of a cross transaction, at the version they were committed at,
+// whose expiry was scheduled by the block that committed them. Scheduling it again would replace the entry of
+// that block, and thereby drop the other keys it schedules.
+func withoutRestoredValues(listExpiryInfo []*expiryInfo, committingBlk uint64) []*expiryInfo {
+	var filtered []*expiryInfo
+	for _, expinfo := range listExpiryInfo {
+		if expinfo.expiryInfoKey.committingBlk != committingBlk {
+			logger.Debugf("Skipping expiry schedule of values restored from block [%d]", expinfo.expiryInfoKey.committingBlk)
+			continue
+		}
+		filtered = append(filtered, expinfo)
+	}
+	return filtered
+}
+
 // BlockCommitDone implements function in the interface 'PurgeMgr'
 // These orphan entries for purge-schedule can be cleared off in bulk in a separate background routine as well
 // If we maintian the following logic (i.e., clear off entries just after block commit), we need a TODO -
@@ -47,6 +47,17 @@ type CollHashedRwSet struct {
 	PvtRwSetHash   []byte
 }
 
+// NEW add
+// CrossRollback holds the writes restoring the keys written by a cross transaction, caused by the failure
+// confirmation at position TxNum of a confirmation block and applied at that height
+type CrossRollback struct {
+	TxNum     uint64
+	CrossTxID string
+	RwSet     *TxRwSet
+	// PvtRwSet holds the writes restoring the keys of private data collections held by the peer, if any
+	PvtRwSet *TxPvtRwSet
+}
+
 /////////////////////////////////////////////////////////////////
 // Messages related to PRIVATE read-write set
 /////////////////////////////////////////////////////////////////
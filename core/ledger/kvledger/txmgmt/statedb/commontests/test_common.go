@@ -553,6 +553,46 @@ func TestGetVersion(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	}
 }
 
+// TestGetStateByte tests that the original value returned by GetStateByte decodes to the committed value and version
+func TestGetStateByte(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db, err := dbProvider.GetDBHandle("testgetstatebyte")
+	testutil.AssertNoError(t, err, "")
+	db.Open()
+	defer db.Close()
+
+	batch := statedb.NewUpdateBatch()
+	jsonValue := `{"asset_name":"marble1","color":"blue","size":35,"owner":"tom"}`
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 2))
+	batch.Put("ns2", "key3", []byte(jsonValue), version.NewHeight(1, 3))
+	err = db.ApplyUpdates(batch, version.NewHeight(1, 3))
+	testutil.AssertNoError(t, err, "")
+
+	batch = statedb.NewUpdateBatch()
+	batch.Delete("ns1", "key2", version.NewHeight(2, 1))
+	err = db.ApplyUpdates(batch, version.NewHeight(2, 1))
+	testutil.AssertNoError(t, err, "")
+
+	for _, key := range []statedb.CompositeKey{{Namespace: "ns1", Key: "key1"}, {Namespace: "ns2", Key: "key3"}} {
+		vv, err := db.GetState(key.Namespace, key.Key)
+		testutil.AssertNoError(t, err, "")
+		origVal, err := db.GetStateByte(key.Namespace, key.Key)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, statedb.DecodeOrigValue(origVal), vv)
+	}
+	origVal, err := db.GetStateByte("ns2", "key3")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, statedb.DecodeOrigValue(origVal).Version, version.NewHeight(1, 3))
+
+	// a deleted key and a key never written have no original value
+	origVal, err = db.GetStateByte("ns1", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, origVal)
+	origVal, err = db.GetStateByte("ns1", "key4")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, origVal)
+}
+
 // TestSmallBatchSize tests multiple update batches
 func TestSmallBatchSize(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	db, err := dbProvider.GetDBHandle("testsmallbatchsize")
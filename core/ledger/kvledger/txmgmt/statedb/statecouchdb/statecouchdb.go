@@ -77,8 +77,14 @@ type VersionedDB struct {
 	mux                sync.RWMutex
 }
 
+// GetStateByte returns the committed value of the given key along with its version, encoded as an
+// original value of the undo log of a cross transaction. It returns nil if the key does not exist.
 func (vdb *VersionedDB) GetStateByte(namespace string, key string) ([]byte, error) {
-	return nil, nil
+	vv, err := vdb.GetState(namespace, key)
+	if err != nil || vv == nil {
+		return nil, err
+	}
+	return statedb.EncodeOrigValue(vv), nil
 }
 
 // newVersionedDB constructs an instance of VersionedDB
@@ -394,11 +400,6 @@ func (vdb *VersionedDB) GetLatestSavePoint() (*version.Height, error) {
 	return decodeSavepoint(couchDoc)
 }
 
-// NEW add
-func (vdb *VersionedDB) ApplyCrossOrigVal(kov *[]statedb.KeyOrigVal) {
-
-}
-
 // applyAdditionalQueryOptions will add additional fields to the query required for query processing
 func applyAdditionalQueryOptions(queryString string, queryLimit, querySkip int) (string, error) {
 	const jsonQueryFields = "fields"
@@ -157,6 +157,17 @@ func TestGetVersion(t *testing.T) {
 	commontests.TestGetVersion(t, env.DBProvider)
 }
 
+func TestGetStateByte(t *testing.T) {
+	env := NewTestVDBEnv(t)
+	env.Cleanup("testgetstatebyte_")
+	env.Cleanup("testgetstatebyte_ns1")
+	env.Cleanup("testgetstatebyte_ns2")
+	defer env.Cleanup("testgetstatebyte_")
+	defer env.Cleanup("testgetstatebyte_ns1")
+	defer env.Cleanup("testgetstatebyte_ns2")
+	commontests.TestGetStateByte(t, env.DBProvider)
+}
+
 func TestSmallBatchSize(t *testing.T) {
 	viper.Set("ledger.state.couchDBConfig.maxBatchUpdateSize", 2)
 	env := NewTestVDBEnv(t)
@@ -63,10 +63,6 @@ type VersionedDB interface {
 
 	// NEW add
 //	GetConstructLevelKey(namespace string, key string) []byte
-
-	// NEW add
-	ApplyCrossOrigVal(kov *[]KeyOrigVal)
-	//ApplyCrossOrigVal(kov *[]cross.KeyOrigVal)
 }
 
 //BulkOptimizable interface provides additional functions for
@@ -99,10 +95,27 @@ type VersionedValue struct {
 // NEW add  最初在peer/cross/cross.go
 type KeyOrigVal struct{
 	Namespace              string
+	// Collection is set for a key of a private data collection. The entry then restores either the hash of
+	// the key, when KeyHash is set, which every peer holds, or the key itself, which only the members of the
+	// collection hold.
+	Collection             string `json:",omitempty"`
+	KeyHash                []byte `json:",omitempty"`
 	Key                    string
 	OriginalVersionedValue []byte
 }
 
+// EncodeOrigValue encodes a committed value and its version in the form recorded in
+// KeyOrigVal.OriginalVersionedValue, which is the form returned by VersionedDB.GetStateByte
+func EncodeOrigValue(vv *VersionedValue) []byte {
+	return append(vv.Version.ToBytes(), vv.Value...)
+}
+
+// DecodeOrigValue decodes a value encoded by EncodeOrigValue
+func DecodeOrigValue(b []byte) *VersionedValue {
+	ver, n := version.NewHeightFromBytes(b)
+	return &VersionedValue{Value: append([]byte{}, b[n:]...), Version: ver}
+}
+
 // VersionedKV encloses key and corresponding VersionedValue
 type VersionedKV struct {
 	CompositeKey
@@ -203,28 +203,6 @@ func (vdb *versionedDB) GetLatestSavePoint() (*version.Height, error) {
 }
 
 
-// NEW add
-/*func (vdb *versionedDB) ApplyCrossOrigVal(kov *[]statedb.KeyOrigVal) {
-	dbBatch := leveldbhelper.NewUpdateBatch() // 一个空map
-	for _, v := range *kov{
-		compositeKey := constructCompositeKey(v.Namespace, v.Key) // ns: ccName
-		dbBatch.Put(compositeKey, EncodeValue(v.OriginalVersionedValue.Value, v.OriginalVersionedValue.Version))
-	}
-	vdb.db.WriteBatch(dbBatch, true)
-}*/
-//NEW add
-func (vdb *versionedDB) ApplyCrossOrigVal(kov *[]statedb.KeyOrigVal) {
-	dbBatch := leveldbhelper.NewUpdateBatch() // 一个空map
-	for _, v := range *kov{
-		compositeKey := constructCompositeKey(v.Namespace, v.Key) // ns: ccName
-		if v.OriginalVersionedValue == nil{
-			dbBatch.Delete(compositeKey)
-			continue
-		}
-		dbBatch.Put(compositeKey, v.OriginalVersionedValue)
-	}
-	vdb.db.WriteBatch(dbBatch, true)
-}
 func constructCompositeKey(ns string, key string) []byte {
 	return append(append([]byte(ns), compositeKeySep...), []byte(key)...)
 }
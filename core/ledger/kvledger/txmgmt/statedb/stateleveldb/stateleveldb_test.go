@@ -116,6 +116,12 @@ func TestGetVersion(t *testing.T) {
 	commontests.TestGetVersion(t, env.DBProvider)
 }
 
+func TestGetStateByte(t *testing.T) {
+	env := NewTestVDBEnv(t)
+	defer env.Cleanup()
+	commontests.TestGetStateByte(t, env.DBProvider)
+}
+
 func TestUtilityFunctions(t *testing.T) {
 	env := NewTestVDBEnv(t)
 	defer env.Cleanup()
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	btltestutil "github.com/hyperledger/fabric/core/ledger/pvtdatapolicy/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+)
+
+func TestCommitCrossConfirmations(t *testing.T) {
+	// run the tests for each environment configured in pkg_test.go
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testcommitcrossconfirmations"
+		cs := btltestutil.NewMockCollectionStore()
+		cs.SetBTL("ns", "coll", 0)
+		testEnv.init(t, testLedgerID, pvtdatapolicy.ConstructBTLPolicy(cs))
+		testCommitCrossConfirmations(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testCommitCrossConfirmations(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	db := env.getVDB()
+	populateCollConfigForTest(t, txMgr.(*LockBasedTxMgr), []collConfigkey{{"ns", "coll"}}, version.NewHeight(1, 1))
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+
+	// block 1 commits the original values
+	blkAndPvtdata := prepareNextBlockForTest(t, txMgr, txMgrHelper.bg, "txid-1",
+		map[string]string{"key1": "value1", "key2": "value2"}, map[string]string{"pvtkey1": "pvt-value1"})
+	testutil.AssertNoError(t, txMgr.ValidateAndPrepare(blkAndPvtdata, true), "")
+	testutil.AssertNoError(t, txMgr.Commit(), "")
+
+	// the undo log of the cross transaction records the original values of the keys it writes
+	pvtKeyHash := util.ComputeStringHash("pvtkey1")
+	origVals := make(map[string][]byte)
+	for _, key := range []string{"key1", "key2", "key3"} {
+		origVal, err := db.GetStateByte("ns", key)
+		testutil.AssertNoError(t, err, "")
+		origVals[key] = origVal
+	}
+	testutil.AssertNil(t, origVals["key3"])
+	origPvtVal, err := db.GetPrivateData("ns", "coll", "pvtkey1")
+	testutil.AssertNoError(t, err, "")
+	origHashedVal, err := db.GetValueHash("ns", "coll", pvtKeyHash)
+	testutil.AssertNoError(t, err, "")
+
+	// block 2 commits the cross transaction
+	s, _ := txMgr.NewTxSimulator("txid-2")
+	s.SetState("ns", "key1", []byte("value1-cross"))
+	s.DeleteState("ns", "key2")
+	s.SetState("ns", "key3", []byte("value3-cross"))
+	s.SetPrivateData("ns", "coll", "pvtkey1", []byte("pvt-value1-cross"))
+	s.Done()
+	simRes, _ := s.GetTxSimulationResults(nil)
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	blkAndPvtdata = &ledger.BlockAndPvtData{Block: txMgrHelper.bg.NextBlock([][]byte{pubSimBytes}),
+		BlockPvtData: map[uint64]*ledger.TxPvtData{0: {SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}},
+	}
+	testutil.AssertNoError(t, txMgr.ValidateAndPrepare(blkAndPvtdata, true), "")
+	testutil.AssertNoError(t, txMgr.Commit(), "")
+
+	// a transaction simulated on the writes of the cross transaction
+	s, _ = txMgr.NewTxSimulator("txid-stale")
+	s.GetState("ns", "key1")
+	s.SetState("ns", "key4", []byte("value4"))
+	s.Done()
+	staleSimRes, _ := s.GetTxSimulationResults(nil)
+
+	// block 3 confirms the failure of the cross transaction and rolls back its writes
+	key1Orig, key2Orig := statedb.DecodeOrigValue(origVals["key1"]), statedb.DecodeOrigValue(origVals["key2"])
+	rollback := &rwsetutil.CrossRollback{
+		TxNum:     0,
+		CrossTxID: "txid-2",
+		RwSet: &rwsetutil.TxRwSet{NsRwSets: []*rwsetutil.NsRwSet{{
+			NameSpace: "ns",
+			KvRwSet: &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{
+				{Key: "key1", Value: key1Orig.Value},
+				{Key: "key2", Value: key2Orig.Value},
+				{Key: "key3", IsDelete: true},
+			}},
+			CollHashedRwSets: []*rwsetutil.CollHashedRwSet{{
+				CollectionName: "coll",
+				HashedRwSet: &kvrwset.HashedRWSet{
+					HashedReads: []*kvrwset.KVReadHash{{KeyHash: pvtKeyHash,
+						Version: &kvrwset.Version{BlockNum: origHashedVal.Version.BlockNum, TxNum: origHashedVal.Version.TxNum}}},
+					HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: pvtKeyHash, ValueHash: origHashedVal.Value}},
+				},
+			}},
+		}}},
+		PvtRwSet: &rwsetutil.TxPvtRwSet{NsPvtRwSet: []*rwsetutil.NsPvtRwSet{{
+			NameSpace: "ns",
+			CollPvtRwSets: []*rwsetutil.CollPvtRwSet{{
+				CollectionName: "coll",
+				KvRwSet: &kvrwset.KVRWSet{
+					Reads:  []*kvrwset.KVRead{rwsetutil.NewKVRead("pvtkey1", origPvtVal.Version)},
+					Writes: []*kvrwset.KVWrite{{Key: "pvtkey1", Value: origPvtVal.Value}},
+				},
+			}},
+		}}},
+	}
+	confBlock := txMgrHelper.bg.NextBlock([][]byte{[]byte("confirmation")})
+	testutil.AssertNoError(t, txMgr.CommitCrossConfirmations(confBlock, []*rwsetutil.CrossRollback{rollback}), "")
+
+	// the public keys get back their original values at the height of the confirmation
+	confHeight := version.NewHeight(confBlock.Header.Number, 0)
+	vv, err := db.GetState("ns", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv, &statedb.VersionedValue{Value: key1Orig.Value, Version: confHeight})
+	vv, err = db.GetState("ns", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv, &statedb.VersionedValue{Value: key2Orig.Value, Version: confHeight})
+	vv, err = db.GetState("ns", "key3")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, vv)
+
+	// the keys of the collection get back their original values and versions
+	vv, err = db.GetPrivateData("ns", "coll", "pvtkey1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv, origPvtVal)
+	vv, err = db.GetValueHash("ns", "coll", pvtKeyHash)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv, origHashedVal)
+
+	savepoint, err := txMgr.GetLastSavepoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, savepoint, confHeight)
+
+	// a transaction that read a rolled back write is invalid, one that reads the restored value is valid
+	txMgrHelper.checkRWsetInvalid(staleSimRes.PubSimulationResults)
+	s, _ = txMgr.NewTxSimulator("txid-3")
+	val, err := s.GetState("ns", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, val, []byte("value1"))
+	s.SetState("ns", "key4", []byte("value4"))
+	s.Done()
+	simRes, _ = s.GetTxSimulationResults(nil)
+	txMgrHelper.validateAndCommitRWSet(simRes.PubSimulationResults)
+}
@@ -82,6 +82,33 @@ func (h *queryHelper) getStateNoRSet(ns string, key string) ([]byte, error) {
 
 	return dbVal, nil
 }
+// NEW add
+// getValueHashNoRSet returns the hash of the value of a key of a collection, along with its version, in the
+// form returned by getStateNoRSet. It is not recorded in the read-set.
+func (h *queryHelper) getValueHashNoRSet(ns, coll string, keyHash []byte) ([]byte, error) {
+	if err := h.checkDone(); err != nil {
+		return nil, err
+	}
+	versionedValue, err := h.txmgr.db.GetValueHash(ns, coll, keyHash)
+	if err != nil || versionedValue == nil {
+		return nil, err
+	}
+	return statedb.EncodeOrigValue(versionedValue), nil
+}
+
+// getPrivateDataNoRSet returns the value of a key of a collection, along with its version, in the form
+// returned by getStateNoRSet. It is not recorded in the read-set.
+func (h *queryHelper) getPrivateDataNoRSet(ns, coll, key string) ([]byte, error) {
+	if err := h.checkDone(); err != nil {
+		return nil, err
+	}
+	versionedValue, err := h.txmgr.db.GetPrivateData(ns, coll, key)
+	if err != nil || versionedValue == nil {
+		return nil, err
+	}
+	return statedb.EncodeOrigValue(versionedValue), nil
+}
+
 // NEW add
 /*func (h *queryHelper) getStateNoRSet(ns string, key string) (*statedb.VersionedValue, error) {
 	fmt.Println("这里是fabric/core/ledger/kvledger/txmgmt/txmgr/lockbasedtxmgr/helper.go getStateNoRSet()")
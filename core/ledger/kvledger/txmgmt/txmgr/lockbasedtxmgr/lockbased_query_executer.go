@@ -60,6 +60,17 @@ func (q *lockBasedQueryExecutor) GetStateNoRSet(ns string, key string) ([]byte,
 	return q.helper.getStateNoRSet(ns, key) //如果没找到返回nil, nil
 }
 
+// NEW add
+// GetValueHashNoRSet returns the hash of the value of a key of a collection, which is not recorded in the read-set
+func (q *lockBasedQueryExecutor) GetValueHashNoRSet(ns, coll string, keyHash []byte) ([]byte, error) {
+	return q.helper.getValueHashNoRSet(ns, coll, keyHash)
+}
+
+// GetPrivateDataNoRSet returns the value of a key of a collection, which is not recorded in the read-set
+func (q *lockBasedQueryExecutor) GetPrivateDataNoRSet(ns, coll, key string) ([]byte, error) {
+	return q.helper.getPrivateDataNoRSet(ns, coll, key)
+}
+
 // GetStateMetadata implements method in interface `ledger.QueryExecutor`
 func (q *lockBasedQueryExecutor) GetStateMetadata(namespace, key string) (map[string][]byte, error) {
 	return nil, errors.New("not implemented")
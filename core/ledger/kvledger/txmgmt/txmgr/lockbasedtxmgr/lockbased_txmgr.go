@@ -6,7 +6,6 @@ SPDX-License-Identifier: Apache-2.0
 package lockbasedtxmgr
 
 import (
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"sync"
 
 	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
@@ -16,6 +15,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/bookkeeping"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valimpl"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
@@ -35,6 +35,7 @@ type LockBasedTxMgr struct {
 	stateListeners  []ledger.StateListener
 	commitRWLock    sync.RWMutex
 	current         *current
+	btlPolicy       pvtdatapolicy.BTLPolicy //NEW add
 }
 
 type current struct {
@@ -51,17 +52,18 @@ func (c *current) maxTxNumber() uint64 {
 	return uint64(len(c.block.Data.Data)) - 1
 }
 
-// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr
+// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr.
+// crossLocks may be nil, in which case the validation ignores the locks of cross transactions
 func NewLockBasedTxMgr(ledgerid string, db privacyenabledstate.DB, stateListeners []ledger.StateListener,
-	btlPolicy pvtdatapolicy.BTLPolicy, bookkeepingProvider bookkeeping.Provider) (*LockBasedTxMgr, error) {
+	btlPolicy pvtdatapolicy.BTLPolicy, bookkeepingProvider bookkeeping.Provider, crossLocks validator.CrossLockChecker) (*LockBasedTxMgr, error) {
 	db.Open()
-	txmgr := &LockBasedTxMgr{ledgerid: ledgerid, db: db, stateListeners: stateListeners}
+	txmgr := &LockBasedTxMgr{ledgerid: ledgerid, db: db, stateListeners: stateListeners, btlPolicy: btlPolicy}
 	pvtstatePurgeMgr, err := pvtstatepurgemgmt.InstantiatePurgeMgr(ledgerid, db, btlPolicy, bookkeepingProvider)
 	if err != nil {
 		return nil, err
 	}
 	txmgr.pvtdataPurgeMgr = &pvtdataPurgeMgr{pvtstatePurgeMgr, false}
-	txmgr.validator = valimpl.NewStatebasedValidator(txmgr, db)
+	txmgr.validator = valimpl.NewStatebasedValidator(txmgr, db, crossLocks)
 	return txmgr, nil
 }
 
@@ -175,8 +177,106 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 
 
 // NEW add
-func (txmgr *LockBasedTxMgr) CrossRollbackOrigVal(kov *[]statedb.KeyOrigVal){
-	txmgr.db.ApplyCrossOrigVal(kov)
+// CommitCrossConfirmations implements method in interface `txmgmt.TxMgr`.
+// The confirmation block goes through the same commit path as a regular block so that the
+// version cache, the purge manager and the state listeners see it as any other block.
+func (txmgr *LockBasedTxMgr) CommitCrossConfirmations(block *common.Block, rollbacks []*rwsetutil.CrossRollback) error {
+	logger.Debugf("Committing %d cross rollback(s) of confirmation block %d", len(rollbacks), block.Header.Number)
+	logger.Debugf("Waiting for purge mgr to finish the background job of computing expirying keys for the block")
+	txmgr.pvtdataPurgeMgr.WaitForPrepareToFinish()
+	batch := privacyenabledstate.NewUpdateBatch()
+	for _, rollback := range rollbacks {
+		if err := txmgr.addCrossRollback(batch, block.Header.Number, rollback); err != nil {
+			return err
+		}
+	}
+	txmgr.current = &current{block: block, batch: batch}
+	if err := txmgr.invokeNamespaceListeners(); err != nil {
+		txmgr.reset()
+		return err
+	}
+	return txmgr.Commit()
+}
+
+// addCrossRollback adds the writes of a rollback to the update batch of a confirmation block. The public keys are
+// restored at the height of the confirmation. The keys of private data collections, and their hashes, are restored
+// at the version of their original values, which the purge manager scheduled for expiry when they were committed,
+// unless the original values are expired by the confirmation block, in which case they are deleted instead, as
+// the purge manager would have done had the cross transaction not been committed.
+func (txmgr *LockBasedTxMgr) addCrossRollback(batch *privacyenabledstate.UpdateBatch, blockNum uint64, rollback *rwsetutil.CrossRollback) error {
+	height := version.NewHeight(blockNum, rollback.TxNum)
+	// restoredVersion returns the version of the original value restored by a write of a key of a collection,
+	// or nil if the key is to be deleted
+	restoredVersion := func(ns, coll string, isDelete bool, ver *kvrwset.Version) (*version.Height, error) {
+		if isDelete || ver == nil {
+			return nil, nil
+		}
+		expiringBlk, err := txmgr.btlPolicy.GetExpiringBlock(ns, coll, ver.BlockNum)
+		if err != nil {
+			return nil, err
+		}
+		if expiringBlk <= blockNum {
+			logger.Debugf("Original value of a key of collection [%s:%s] committed in block [%d] expired in block [%d], deleting it", ns, coll, ver.BlockNum, expiringBlk)
+			return nil, nil
+		}
+		return version.NewHeight(ver.BlockNum, ver.TxNum), nil
+	}
+
+	for _, nsRWSet := range rollback.RwSet.NsRwSets {
+		ns := nsRWSet.NameSpace
+		if nsRWSet.KvRwSet != nil {
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				if kvWrite.IsDelete {
+					batch.PubUpdates.Delete(ns, kvWrite.Key, height)
+				} else {
+					batch.PubUpdates.Put(ns, kvWrite.Key, kvWrite.Value, height)
+				}
+			}
+		}
+		for _, collHashedRWSet := range nsRWSet.CollHashedRwSets {
+			coll := collHashedRWSet.CollectionName
+			versions := make(map[string]*kvrwset.Version)
+			for _, hashedRead := range collHashedRWSet.HashedRwSet.HashedReads {
+				versions[string(hashedRead.KeyHash)] = hashedRead.Version
+			}
+			for _, hashedWrite := range collHashedRWSet.HashedRwSet.HashedWrites {
+				ver, err := restoredVersion(ns, coll, hashedWrite.IsDelete, versions[string(hashedWrite.KeyHash)])
+				if err != nil {
+					return err
+				}
+				if ver == nil {
+					batch.HashUpdates.Delete(ns, coll, hashedWrite.KeyHash, height)
+				} else {
+					batch.HashUpdates.Put(ns, coll, hashedWrite.KeyHash, hashedWrite.ValueHash, ver)
+				}
+			}
+		}
+	}
+	if rollback.PvtRwSet == nil {
+		return nil
+	}
+	for _, nsPvtRWSet := range rollback.PvtRwSet.NsPvtRwSet {
+		ns := nsPvtRWSet.NameSpace
+		for _, collPvtRWSet := range nsPvtRWSet.CollPvtRwSets {
+			coll := collPvtRWSet.CollectionName
+			versions := make(map[string]*kvrwset.Version)
+			for _, kvRead := range collPvtRWSet.KvRwSet.Reads {
+				versions[kvRead.Key] = kvRead.Version
+			}
+			for _, kvWrite := range collPvtRWSet.KvRwSet.Writes {
+				ver, err := restoredVersion(ns, coll, kvWrite.IsDelete, versions[kvWrite.Key])
+				if err != nil {
+					return err
+				}
+				if ver == nil {
+					batch.PvtUpdates.Delete(ns, coll, kvWrite.Key, height)
+				} else {
+					batch.PvtUpdates.Put(ns, coll, kvWrite.Key, kvWrite.Value, ver)
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // Rollback implements method in interface `txmgmt.TxMgr`
@@ -69,7 +69,7 @@ func testTxSimulatorWithNoExistingData(t *testing.T, env testEnv) {
 	value, _ = s.GetState("ns2", "key3")
 	testutil.AssertNil(t, value)
 
-	simulationResults, err := s.GetTxSimulationResults()
+	simulationResults, err := s.GetTxSimulationResults(nil)
 	assert.NoError(t, err)
 	assert.Nil(t, simulationResults.PvtSimulationResults)
 }
@@ -98,7 +98,7 @@ func TestTxSimulatorGetResults(t *testing.T) {
 	assert.NoError(t, err)
 	simulator.SetState("ns1", "key1", []byte("value1"))
 	// get simulation results and verify that this contains rwset only for one namespace
-	simulationResults1, err := simulator.GetTxSimulationResults()
+	simulationResults1, err := simulator.GetTxSimulationResults(nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(simulationResults1.PubSimulationResults.NsRwset))
 	// clone freeze simulationResults1
@@ -112,7 +112,7 @@ func TestTxSimulatorGetResults(t *testing.T) {
 	simulator.GetPrivateData("ns2", "coll2", "key2")
 	simulator.SetState("ns2", "key2", []byte("value2"))
 	// get simulation results and verify that an error is raised when obtaining the simulation results more than once
-	_, err = simulator.GetTxSimulationResults()
+	_, err = simulator.GetTxSimulationResults(nil)
 	assert.Error(t, err) // calling 'GetTxSimulationResults()' more than once should raise error
 	// Now, verify that the simulator operations did not have an effect on privously obtained results
 	assert.Equal(t, frozenSimulationResults1, simulationResults1)
@@ -151,7 +151,7 @@ func testTxSimulatorWithExistingData(t *testing.T, env testEnv) {
 	s1.SetState("ns2", "key4", []byte("value4"))
 	s1.Done()
 	// validate and commit RWset
-	txRWSet1, _ := s1.GetTxSimulationResults()
+	txRWSet1, _ := s1.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
 	// simulate tx2 that make changes to existing data
@@ -164,7 +164,7 @@ func testTxSimulatorWithExistingData(t *testing.T, env testEnv) {
 	testutil.AssertEquals(t, value, []byte("value1"))
 	s2.Done()
 	// validate and commit RWset for tx2
-	txRWSet2, _ := s2.GetTxSimulationResults()
+	txRWSet2, _ := s2.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
 
 	// simulate tx3
@@ -203,7 +203,7 @@ func testTxValidation(t *testing.T, env testEnv) {
 	s1.SetState("ns2", "key4", []byte("value4"))
 	s1.Done()
 	// validate and commit RWset
-	txRWSet1, _ := s1.GetTxSimulationResults()
+	txRWSet1, _ := s1.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
 	// simulate tx2 that make changes to existing data.
@@ -251,21 +251,21 @@ func testTxValidation(t *testing.T, env testEnv) {
 	// tx6: Update ns1:new_key
 
 	// validate and commit RWset for tx2
-	txRWSet2, _ := s2.GetTxSimulationResults()
+	txRWSet2, _ := s2.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
 
 	//RWSet for tx3 and tx4 and tx5 should be invalid now due to read conflicts
-	txRWSet3, _ := s3.GetTxSimulationResults()
+	txRWSet3, _ := s3.GetTxSimulationResults(nil)
 	txMgrHelper.checkRWsetInvalid(txRWSet3.PubSimulationResults)
 
-	txRWSet4, _ := s4.GetTxSimulationResults()
+	txRWSet4, _ := s4.GetTxSimulationResults(nil)
 	txMgrHelper.checkRWsetInvalid(txRWSet4.PubSimulationResults)
 
-	txRWSet5, _ := s5.GetTxSimulationResults()
+	txRWSet5, _ := s5.GetTxSimulationResults(nil)
 	txMgrHelper.checkRWsetInvalid(txRWSet5.PubSimulationResults)
 
 	// tx6 should still be valid as it only writes a new key
-	txRWSet6, _ := s6.GetTxSimulationResults()
+	txRWSet6, _ := s6.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet6.PubSimulationResults)
 }
 
@@ -291,7 +291,7 @@ func testTxPhantomValidation(t *testing.T, env testEnv) {
 	s1.SetState("ns", "key5", []byte("value5"))
 	s1.SetState("ns", "key6", []byte("value6"))
 	// validate and commit RWset
-	txRWSet1, _ := s1.GetTxSimulationResults()
+	txRWSet1, _ := s1.GetTxSimulationResults(nil)
 	s1.Done() // explicitly calling done after obtaining the results to verify FAB-10788
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
@@ -304,7 +304,7 @@ func testTxPhantomValidation(t *testing.T, env testEnv) {
 		}
 	}
 	s2.DeleteState("ns", "key3")
-	txRWSet2, _ := s2.GetTxSimulationResults()
+	txRWSet2, _ := s2.GetTxSimulationResults(nil)
 	s2.Done()
 
 	// simulate tx3
@@ -316,7 +316,7 @@ func testTxPhantomValidation(t *testing.T, env testEnv) {
 		}
 	}
 	s3.SetState("ns", "key3", []byte("value3_new"))
-	txRWSet3, _ := s3.GetTxSimulationResults()
+	txRWSet3, _ := s3.GetTxSimulationResults(nil)
 	s3.Done()
 	// simulate tx4
 	s4, _ := txMgr.NewTxSimulator("test_tx4")
@@ -327,7 +327,7 @@ func testTxPhantomValidation(t *testing.T, env testEnv) {
 		}
 	}
 	s4.SetState("ns", "key3", []byte("value3_new"))
-	txRWSet4, _ := s4.GetTxSimulationResults()
+	txRWSet4, _ := s4.GetTxSimulationResults(nil)
 	s4.Done()
 
 	// txRWSet2 should be valid
@@ -382,7 +382,7 @@ func testIterator(t *testing.T, env testEnv, numKeys int, startKeyNum int, endKe
 	}
 	s.Done()
 	// validate and commit RWset
-	txRWSet, _ := s.GetTxSimulationResults()
+	txRWSet, _ := s.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
 
 	var startKey string
@@ -450,14 +450,14 @@ func testIteratorWithDeletes(t *testing.T, env testEnv) {
 	}
 	s.Done()
 	// validate and commit RWset
-	txRWSet1, _ := s.GetTxSimulationResults()
+	txRWSet1, _ := s.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
 	s, _ = txMgr.NewTxSimulator("test_tx2")
 	s.DeleteState(cID, createTestKey(4))
 	s.Done()
 	// validate and commit RWset
-	txRWSet2, _ := s.GetTxSimulationResults()
+	txRWSet2, _ := s.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
 
 	queryExecuter, _ := txMgr.NewQueryExecutor("test_tx3")
@@ -494,7 +494,7 @@ func testTxValidationWithItr(t *testing.T, env testEnv) {
 	}
 	s1.Done()
 	// validate and commit RWset
-	txRWSet1, _ := s1.GetTxSimulationResults()
+	txRWSet1, _ := s1.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
 	// simulate tx2 that reads key_001 and key_002
@@ -521,15 +521,15 @@ func testTxValidationWithItr(t *testing.T, env testEnv) {
 	s4.Done()
 
 	// validate and commit RWset for tx4
-	txRWSet4, _ := s4.GetTxSimulationResults()
+	txRWSet4, _ := s4.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet4.PubSimulationResults)
 
 	//RWSet tx3 should be invalid now
-	txRWSet3, _ := s3.GetTxSimulationResults()
+	txRWSet3, _ := s3.GetTxSimulationResults(nil)
 	txMgrHelper.checkRWsetInvalid(txRWSet3.PubSimulationResults)
 
 	// tx2 should still be valid
-	txRWSet2, _ := s2.GetTxSimulationResults()
+	txRWSet2, _ := s2.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
 
 }
@@ -559,7 +559,7 @@ func testGetSetMultipeKeys(t *testing.T, env testEnv) {
 	s1.SetStateMultipleKeys(cID, multipleKeyMap)
 	s1.Done()
 	// validate and commit RWset
-	txRWSet, _ := s1.GetTxSimulationResults()
+	txRWSet, _ := s1.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
 	qe, _ := txMgr.NewQueryExecutor("test_tx2")
 	defer qe.Done()
@@ -643,7 +643,7 @@ func testExecuteQuery(t *testing.T, env testEnv) {
 	s1.Done()
 
 	// validate and commit RWset
-	txRWSet, _ := s1.GetTxSimulationResults()
+	txRWSet, _ := s1.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
 
 	queryExecuter, _ := txMgr.NewQueryExecutor("test_tx2")
@@ -787,7 +787,7 @@ func TestDeleteOnCursor(t *testing.T) {
 		s.SetState(cID, k, v)
 	}
 	s.Done()
-	txRWSet1, _ := s.GetTxSimulationResults()
+	txRWSet1, _ := s.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
 
 	// simulate and commit tx2 that reads keys key_001 through key_004 and deletes them one by one (in a loop - itr.Next() followed by Delete())
@@ -802,7 +802,7 @@ func TestDeleteOnCursor(t *testing.T) {
 	}
 	itr2.Close()
 	s2.Done()
-	txRWSet2, _ := s2.GetTxSimulationResults()
+	txRWSet2, _ := s2.GetTxSimulationResults(nil)
 	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
 
 	// simulate tx3 to verify that the keys key_001 through key_004 got deleted
@@ -874,7 +874,7 @@ func prepareNextBlockForTest(t *testing.T, txMgr txmgr.TxMgr, bg *testutil.Block
 		simulator.SetPrivateData("ns", "coll", k, []byte(v))
 	}
 	simulator.Done()
-	simRes, _ := simulator.GetTxSimulationResults()
+	simRes, _ := simulator.GetTxSimulationResults(nil)
 	pubSimBytes, _ := simRes.GetPubSimulationBytes()
 	block := bg.NextBlock([][]byte{pubSimBytes})
 	return &ledger.BlockAndPvtData{Block: block,
@@ -18,8 +18,9 @@ package txmgr
 
 import (
 	"github.com/hyperledger/fabric/core/ledger"
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
 )
 
 // TxMgr - an interface that a transaction manager should implement
@@ -35,7 +36,9 @@ type TxMgr interface {
 	Shutdown()
 
 	// NEW add
-	CrossRollbackOrigVal(kov *[]statedb.KeyOrigVal)
+	// CommitCrossConfirmations commits a cross confirmation block. The writes of the given rollbacks, in the order
+	// of the confirmations of the block, are applied at their height, and the block becomes the savepoint of the state database
+	CommitCrossConfirmations(block *common.Block, rollbacks []*rwsetutil.CrossRollback) error
 }
 
 // ErrUnsupportedTransaction is expected to be thrown if a unsupported query is performed in an update transaction
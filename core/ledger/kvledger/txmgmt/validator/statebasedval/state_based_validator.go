@@ -10,8 +10,10 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valinternal"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/peer/cross"
 	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric/protos/peer"
 )
@@ -21,12 +23,13 @@ var logger = flogging.MustGetLogger("statebasedval")
 // Validator validates a tx against the latest committed state
 // and preceding valid transactions with in the same block
 type Validator struct {
-	db privacyenabledstate.DB
+	db         privacyenabledstate.DB
+	crossLocks validator.CrossLockChecker //NEW add
 }
 
-// NewValidator constructs StateValidator
-func NewValidator(db privacyenabledstate.DB) *Validator {
-	return &Validator{db}
+// NewValidator constructs StateValidator. crossLocks may be nil, in which case the locks of cross transactions are ignored
+func NewValidator(db privacyenabledstate.DB, crossLocks validator.CrossLockChecker) *Validator {
+	return &Validator{db, crossLocks}
 }
 
 // preLoadCommittedVersionOfRSet loads committed version of all keys in each
@@ -98,12 +101,17 @@ func (v *Validator) ValidateAndPrepareBatch(block *valinternal.Block, doMVCCVali
 	}
 
 	updates := valinternal.NewPubAndHashUpdates()
+	blockCrossLocks := cross.NewLockTable() //NEW add
 	for _, tx := range block.Txs {
 		var validationCode peer.TxValidationCode
 		var err error
 		if validationCode, err = v.validateEndorserTX(tx.RWSet, doMVCCValidation, updates); err != nil {
 			return nil, err
 		}
+		//NEW add
+		if validationCode == peer.TxValidationCode_VALID && doMVCCValidation && v.crossLocks != nil {
+			validationCode = v.validateCrossLocks(tx, blockCrossLocks)
+		} //NEW end
 
 		tx.ValidationCode = validationCode
 		if validationCode == peer.TxValidationCode_VALID {
@@ -118,6 +126,46 @@ func (v *Validator) ValidateAndPrepareBatch(block *valinternal.Block, doMVCCVali
 	return updates, nil
 }
 
+// NEW add
+// validateCrossLocks checks that none of the keys accessed by the transaction is locked in a conflicting mode by
+// another cross transaction, either committed in a previous block or valid and preceding the transaction in the
+// current block. A key read is only in conflict with a key held in exclusive mode, so that transactions reading
+// the same keys do not block each other, whereas a key written is also in conflict with a range of keys read by a
+// cross transaction. Likewise, a range of keys read is in conflict with a key of the range held in exclusive mode.
+// A valid cross transaction locks the keys and ranges it accessed for the transactions following it in the block.
+// As the lock table only changes upon the commit of a block, every peer of the channel reaches the same decision.
+func (v *Validator) validateCrossLocks(tx *valinternal.Transaction, blockCrossLocks *cross.LockTable) peer.TxValidationCode {
+	keys := cross.LockKeys(tx.RWSet)
+	for _, kl := range keys {
+		_, locked := blockCrossLocks.Conflicts(tx.ID, kl.CompositeKey, kl.Mode)
+		if locked || v.crossLocks.IsLockedByOther(tx.ID, kl.Namespace, kl.Key, kl.Mode) {
+			logger.Debugf("Key [%s:%s] accessed in %s mode by transaction [%s] is locked by a cross transaction",
+				kl.Namespace, kl.Key, kl.Mode, tx.ID)
+			return peer.TxValidationCode_CROSS_LOCK_CONFLICT
+		}
+	}
+	ranges := cross.LockRanges(tx.RWSet)
+	for _, r := range ranges {
+		_, locked := blockCrossLocks.ConflictsRange(tx.ID, r)
+		if locked || v.crossLocks.IsRangeLockedByOther(tx.ID, r) {
+			logger.Debugf("Range [%s:%s-%s] read by transaction [%s] holds a key locked by a cross transaction",
+				r.Namespace, r.StartKey, r.EndKey, tx.ID)
+			return peer.TxValidationCode_CROSS_LOCK_CONFLICT
+		}
+	}
+	if tx.Cross {
+		for _, kl := range keys {
+			blockCrossLocks.Grant(tx.ID, kl.CompositeKey, kl.Mode)
+		}
+		for _, r := range ranges {
+			blockCrossLocks.GrantRange(tx.ID, r)
+		}
+	}
+	return peer.TxValidationCode_VALID
+}
+
+//NEW end
+
 // validateEndorserTX validates endorser transaction
 func (v *Validator) validateEndorserTX(
 	txRWSet *rwsetutil.TxRwSet,
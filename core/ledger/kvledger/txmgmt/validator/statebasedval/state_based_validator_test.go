@@ -57,7 +57,7 @@ func TestValidatorBulkLoadingOfCache(t *testing.T) {
 	defer testDBEnv.Cleanup()
 	db := testDBEnv.GetDBHandle("testdb")
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	//populate db with initial data
 	batch := privacyenabledstate.NewUpdateBatch()
@@ -193,7 +193,7 @@ func TestValidator(t *testing.T) {
 	batch.PubUpdates.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 4))
 	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 4))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	//rwset1 should be valid
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
@@ -236,7 +236,7 @@ func TestPhantomValidation(t *testing.T) {
 	batch.PubUpdates.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 4))
 	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 4))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	//rwset1 should be valid
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
@@ -311,7 +311,7 @@ func TestPhantomHashBasedValidation(t *testing.T) {
 	batch.PubUpdates.Put("ns1", "key9", []byte("value9"), version.NewHeight(1, 8))
 	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 8))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
 	rqi1 := &kvrwset.RangeQueryInfo{StartKey: "key2", EndKey: "key9", ItrExhausted: true}
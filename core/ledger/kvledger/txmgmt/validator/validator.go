@@ -19,6 +19,7 @@ package validator
 import (
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/peer/cross"
 )
 
 // Validator validates the transactions present in a block and returns a batch that should be used to update the state
@@ -26,6 +27,18 @@ type Validator interface {
 	ValidateAndPrepareBatch(blockAndPvtdata *ledger.BlockAndPvtData, doMVCCValidation bool) (*privacyenabledstate.UpdateBatch, error)
 }
 
+// NEW add
+// CrossLockChecker tells whether a key of the state is locked by a cross transaction committed in a previous
+// block and not yet confirmed, in a mode which does not allow accessing the key in the given mode, and whether
+// a range of keys holds a key locked in exclusive mode. A transaction accessing such a key, or reading such a
+// range, is invalidated, unless it is the cross transaction holding the key.
+type CrossLockChecker interface {
+	IsLockedByOther(txID, ns, key string, mode cross.LockMode) bool
+	IsRangeLockedByOther(txID string, r cross.RangeLock) bool
+}
+
+//NEW end
+
 // ErrPvtdataHashMissmatch is to be thrown if the hash of a collection present in the public read-write set
 // does not match with the corresponding pvt data  supplied with the block for validation
 type ErrPvtdataHashMissmatch struct {
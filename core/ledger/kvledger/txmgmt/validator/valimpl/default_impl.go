@@ -29,9 +29,10 @@ type DefaultImpl struct {
 }
 
 // NewStatebasedValidator constructs a validator that internally manages statebased validator and in addition
-// handles the tasks that are agnostic to a particular validation scheme such as parsing the block and handling the pvt data
-func NewStatebasedValidator(txmgr txmgr.TxMgr, db privacyenabledstate.DB) validator.Validator {
-	return &DefaultImpl{txmgr, db, statebasedval.NewValidator(db)}
+// handles the tasks that are agnostic to a particular validation scheme such as parsing the block and handling the pvt data.
+// crossLocks, when not nil, invalidates the transactions accessing the keys locked by cross transactions
+func NewStatebasedValidator(txmgr txmgr.TxMgr, db privacyenabledstate.DB, crossLocks validator.CrossLockChecker) validator.Validator {
+	return &DefaultImpl{txmgr, db, statebasedval.NewValidator(db, crossLocks)}
 }
 
 // ValidateAndPrepareBatch implements the function in interface validator.Validator
@@ -33,6 +33,8 @@ type Transaction struct {
 	ID             string
 	RWSet          *rwsetutil.TxRwSet
 	ValidationCode peer.TxValidationCode
+	// Cross is true for a single or multi-chain cross transaction //NEW add
+	Cross bool
 }
 
 // PubAndHashUpdates encapsulates public and hash updates. The intended use of this to hold the updates
@@ -11,6 +11,7 @@ import (
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	//	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"  //import cycle
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/hyperledger/fabric/protos/peer"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -82,6 +83,29 @@ type PeerLedger interface {
 	// NEW add
 //	CrossRollbackOrigVal(kov *[]statedb.KeyOrigVal)  //import statedb导致import cycle // 实现在core/ledger/kvledger/kv_ledger.go
 	//CrossRollbackOrigVal(kov *[]cross.KeyOrigVal)
+	// GetCrossTxQuerier returns the CrossTxQuerier
+	GetCrossTxQuerier() (CrossTxQuerier, error)
+}
+
+// NEW add
+// CrossTxQuerier queries the cross transactions known to a ledger, which are the ones holding keys locked
+// until their confirmation and the ones whose confirmation was committed on the channel
+type CrossTxQuerier interface {
+	// GetCrossTxStatus returns the status of the given cross transaction, in state NOT_FOUND if it is unknown
+	GetCrossTxStatus(txID string) (*cross.CrossTxStatus, error)
+	// ListPendingCrossTxs returns the status of the prepared cross transactions waiting for their confirmation, sorted by ID
+	ListPendingCrossTxs() ([]*cross.CrossTxStatus, error)
+	// ListLockedKeys returns the keys of the given namespace held locked by cross transactions, sorted by key.
+	// An empty namespace lists the locked keys of every namespace, sorted by namespace and key.
+	ListLockedKeys(namespace string) ([]*cross.LockedKey, error)
+	// GetConfirmationsByBlock returns the confirmations committed in the given block, in the order of the block
+	GetConfirmationsByBlock(blockNum uint64) ([]*cross.Confirmation, error)
+	// GetCrossOutcomesByBlock returns the outcomes of the cross transactions confirmed by the given block, along with the
+	// keys rolled back by the failure confirmations, in the order of the block
+	GetCrossOutcomesByBlock(blockNum uint64) ([]*cross.CrossOutcome, error)
+	// ListLockWaits returns the cross transactions whose proposals were recently refused or delayed by keys held by
+	// other cross transactions, sorted by ID
+	ListLockWaits() ([]*cross.CrossLockWait, error)
 }
 
 // ValidatedLedger represents the 'final ledger' after filtering out invalid transactions from PeerLedger.
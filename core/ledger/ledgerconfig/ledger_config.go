@@ -38,6 +38,7 @@ const confAutoWarmIndexes = "ledger.state.couchDBConfig.autoWarmIndexes"
 const confWarmIndexesAfterNBlocks = "ledger.state.couchDBConfig.warmIndexesAfterNBlocks"
 //NEW add
 const confCrossLeveldb = "crossLeveldb"
+const confCrossDatabase = "ledger.cross.crossDatabase"
 
 // GetRootPath returns the filesystem path.
 // All ledger related contents are expected to be stored under this path
@@ -66,6 +67,11 @@ func GetCrossLevelDBPath() string {
 	return filepath.Join(GetRootPath(), confCrossLeveldb)
 }
 
+// IsCrossCouchDBEnabled returns true if the crossdb is kept in the CouchDB instance of the state database
+func IsCrossCouchDBEnabled() bool {
+	return viper.GetString(confCrossDatabase) == "CouchDB"
+}
+
 // GetBlockStorePath returns the filesystem path that is used for the chain block stores
 func GetBlockStorePath() string {
 	return filepath.Join(GetRootPath(), confChains)
@@ -46,6 +46,14 @@ func TestIsCouchDBEnabled(t *testing.T) {
 	testutil.AssertEquals(t, updatedValue, true) //test config returns true
 }
 
+func TestIsCrossCouchDBEnabled(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, IsCrossCouchDBEnabled(), false) //test default config is false
+	viper.Set("ledger.cross.crossDatabase", "CouchDB")
+	testutil.AssertEquals(t, IsCrossCouchDBEnabled(), true)
+}
+
 func TestLedgerConfigPathDefault(t *testing.T) {
 	setUpCoreYAMLConfig()
 	testutil.AssertEquals(t,
@@ -81,6 +81,7 @@ func ResetConfigToDefaultValues() {
 	//reset to defaults
 	viper.Set("ledger.state.couchDBConfig.queryLimit", 10000)
 	viper.Set("ledger.state.stateDatabase", "goleveldb")
+	viper.Set("ledger.cross.crossDatabase", "goleveldb")
 	viper.Set("ledger.history.enableHistoryDatabase", false)
 	viper.Set("ledger.state.couchDBConfig.autoWarmIndexes", true)
 	viper.Set("ledger.state.couchDBConfig.warmIndexesAfterNBlocks", 1)
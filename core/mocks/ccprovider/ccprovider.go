@@ -39,12 +39,17 @@ type MockCcProviderImpl struct {
 
 type MockTxSim struct {
 	GetTxSimulationResultsRv *ledger.TxSimulationResults
+	GetCrossLockedRv         bool
 }
 
 func (m *MockTxSim) GetState(namespace string, key string) ([]byte, error) {
 	return nil, nil
 }
 
+func (m *MockTxSim) GetStateNoRSet(namespace string, key string) ([]byte, error) {
+	return nil, nil
+}
+
 func (m *MockTxSim) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
 	return nil, nil
 }
@@ -76,10 +81,18 @@ func (m *MockTxSim) ExecuteUpdate(query string) error {
 	return nil
 }
 
-func (m *MockTxSim) GetTxSimulationResults() (*ledger.TxSimulationResults, error) {
+func (m *MockTxSim) GetTxSimulationResults(*peer.Response) (*ledger.TxSimulationResults, error) {
 	return m.GetTxSimulationResultsRv, nil
 }
 
+func (m *MockTxSim) SetCrossLocked(b bool) {
+	m.GetCrossLockedRv = b
+}
+
+func (m *MockTxSim) GetCrossLocked() bool {
+	return m.GetCrossLockedRv
+}
+
 func (m *MockTxSim) DeletePrivateData(namespace, collection, key string) error {
 	return nil
 }
@@ -32,6 +32,7 @@ type Support struct {
 	ACVal                   channelconfig.ApplicationCapabilities
 	CapabilitiesInvokeCount int
 	MSPManagerInvokeCount   int
+	PolicyManagerVal        policies.Manager
 }
 
 func (ms *Support) Capabilities() channelconfig.ApplicationCapabilities {
@@ -55,7 +56,11 @@ func (ms *Support) Apply(configtx *common.ConfigEnvelope) error {
 	return ms.ApplyVal
 }
 
+// PolicyManager returns PolicyManagerVal if set, and an empty policy manager otherwise
 func (ms *Support) PolicyManager() policies.Manager {
+	if ms.PolicyManagerVal != nil {
+		return ms.PolicyManagerVal
+	}
 	return &mockpolicies.Manager{}
 }
 
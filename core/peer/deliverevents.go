@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/core/aclmgmt/resources"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross" //NEW add
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
@@ -50,9 +51,20 @@ type server struct {
 	policyCheckerProvider PolicyCheckerProvider
 }
 
+// NEW add
+// CrossOutcomeRetriever is implemented by the chains able to tell the outcomes of the cross
+// transactions confirmed by their blocks
+type CrossOutcomeRetriever interface {
+	// GetCrossOutcomesByBlock returns the outcomes of the cross transactions confirmed by the given block
+	GetCrossOutcomesByBlock(blockNum uint64) ([]*cross.CrossOutcome, error)
+}
+
+//NEW end
+
 // blockResponseSender structure used to send block responses
 type blockResponseSender struct {
 	peer.Deliver_DeliverServer
+	chainManager deliver.ChainManager //NEW add
 }
 
 // SendStatusResponse generates status reply proto message
@@ -68,12 +80,33 @@ func (brs *blockResponseSender) SendBlockResponse(block *common.Block) error {
 	response := &peer.DeliverResponse{
 		Type: &peer.DeliverResponse_Block{Block: block},
 	}
-	return brs.Send(response)
+	//NEW update
+	if err := brs.Send(response); err != nil {
+		return err
+	}
+	// a block carrying confirmations is followed by the outcomes of the cross transactions it confirms
+	channelID, outcomes, err := crossOutcomes(brs.chainManager, block)
+	if err != nil {
+		logger.Warningf("Failed to retrieve the outcomes of the cross transactions confirmed by block [%d] due to: %s", block.Header.Number, err)
+		return brs.SendStatusResponse(common.Status_INTERNAL_SERVER_ERROR)
+	}
+	if len(outcomes) == 0 {
+		return nil
+	}
+	return brs.Send(&peer.DeliverResponse{
+		Type: &peer.DeliverResponse_CrossOutcomeEvent{CrossOutcomeEvent: &peer.CrossOutcomeEvent{
+			ChannelId:     channelID,
+			Number:        block.Header.Number,
+			CrossOutcomes: outcomes,
+		}},
+	})
+	//NEW end
 }
 
 // filteredBlockResponseSender structure used to send filtered block responses
 type filteredBlockResponseSender struct {
 	peer.Deliver_DeliverFilteredServer
+	chainManager deliver.ChainManager //NEW add
 }
 
 func (fbrs *filteredBlockResponseSender) SendStatusResponse(status common.Status) error {
@@ -92,6 +125,14 @@ func (fbrs *filteredBlockResponseSender) SendBlockResponse(block *common.Block)
 		logger.Warningf("Failed to generate filtered block due to: %s", err)
 		return fbrs.SendStatusResponse(common.Status_BAD_REQUEST)
 	}
+	//NEW add
+	_, outcomes, err := crossOutcomes(fbrs.chainManager, block)
+	if err != nil {
+		logger.Warningf("Failed to retrieve the outcomes of the cross transactions confirmed by block [%d] due to: %s", block.Header.Number, err)
+		return fbrs.SendStatusResponse(common.Status_INTERNAL_SERVER_ERROR)
+	}
+	addCrossOutcomes(filteredBlock, outcomes)
+	//NEW end
 	response := &peer.DeliverResponse{
 		Type: &peer.DeliverResponse_FilteredBlock{FilteredBlock: filteredBlock},
 	}
@@ -115,6 +156,7 @@ func (s *server) DeliverFiltered(srv peer.Deliver_DeliverFilteredServer) error {
 		PolicyChecker: s.policyCheckerProvider(resources.Event_FilteredBlock),
 		ResponseSender: &filteredBlockResponseSender{
 			Deliver_DeliverFilteredServer: srv,
+			chainManager:                  s.dh.ChainManager, //NEW add
 		},
 	}
 	return s.dh.Handle(srv.Context(), deliverServer)
@@ -130,6 +172,7 @@ func (s *server) Deliver(srv peer.Deliver_DeliverServer) (err error) {
 		Receiver:      srv,
 		ResponseSender: &blockResponseSender{
 			Deliver_DeliverServer: srv,
+			chainManager:          s.dh.ChainManager, //NEW add
 		},
 	}
 	return s.dh.Handle(srv.Context(), deliverServer)
@@ -207,7 +250,8 @@ func (block *blockEvent) toFilteredBlock() (*peer.FilteredBlock, error) {
 			TxValidationCode: txsFltr.Flag(txIndex),
 		}
 
-		if filteredTransaction.Type == common.HeaderType_ENDORSER_TRANSACTION {
+		// the outcome of a confirmation is added once the filtered block is built
+		if filteredTransaction.Type == common.HeaderType_ENDORSER_TRANSACTION && !utils.IsConfirmation(env) { //NEW update
 			tx, err := utils.GetTransaction(payload.Data)
 			if err != nil {
 				return nil, errors.WithMessage(err, "error unmarshal transaction payload for block event")
@@ -269,6 +313,53 @@ func (ta transactionActions) toFilteredActions() (*peer.FilteredTransaction_Tran
 	}, nil
 }
 
+// NEW add
+// crossOutcomes returns the channel of a block carrying confirmations and the outcomes of the cross
+// transactions it confirms, retrieved from the chain of the block. It returns no outcome for the
+// other blocks, or if the chain does not tell the outcomes of cross transactions.
+func crossOutcomes(chainManager deliver.ChainManager, block *common.Block) (string, []*cross.CrossOutcome, error) {
+	if !utils.IsConfirmationBlock(block) {
+		return "", nil, nil
+	}
+	channelID, err := utils.GetChainIDFromBlock(block)
+	if err != nil {
+		return "", nil, err
+	}
+	chain, ok := chainManager.GetChain(channelID)
+	if !ok {
+		return "", nil, errors.Errorf("channel %s not found", channelID)
+	}
+	retriever, ok := chain.(CrossOutcomeRetriever)
+	if !ok {
+		return channelID, nil, nil
+	}
+	outcomes, err := retriever.GetCrossOutcomesByBlock(block.Header.Number)
+	return channelID, outcomes, err
+}
+
+// addCrossOutcomes adds the given outcomes to the confirmations of a filtered block, in the order of the block.
+// The confirmation of a cross transaction is found by its txid, derived from the ID of the cross transaction.
+// As the payloads of chaincode events, the keys rolled back are left out, along with the reason of a failure,
+// while the namespaces and the collections rolled back are kept.
+func addCrossOutcomes(filteredBlock *peer.FilteredBlock, outcomes []*cross.CrossOutcome) {
+	for _, outcome := range outcomes {
+		confTxID := utils.ComputeConfirmationTxID(outcome.CrossTxId)
+		for _, tx := range filteredBlock.FilteredTransactions {
+			if tx.Data != nil || tx.Type != common.HeaderType_ENDORSER_TRANSACTION || tx.Txid != confTxID {
+				continue
+			}
+			filtered := &cross.CrossOutcome{CrossTxId: outcome.CrossTxId, ChannelId: outcome.ChannelId, Outcome: outcome.Outcome}
+			for _, nsKeys := range outcome.RolledBackKeys {
+				filtered.RolledBackKeys = append(filtered.RolledBackKeys, &cross.RolledBackKeys{Namespace: nsKeys.Namespace, Collections: nsKeys.Collections})
+			}
+			tx.Data = &peer.FilteredTransaction_CrossOutcome{CrossOutcome: filtered}
+			break
+		}
+	}
+}
+
+//NEW end
+
 func dumpStacktraceOnPanic() {
 	func() {
 		if r := recover(); r != nil {
@@ -14,9 +14,11 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/deliver"
 	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -510,3 +512,93 @@ func createTestBlock(data []*common.Envelope) (*common.Block, error) {
 	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = make([]byte, len(data))
 	return block, nil
 }
+
+// mockCrossChainSupport mock chain telling the outcomes of the cross transactions confirmed by its blocks
+type mockCrossChainSupport struct {
+	*mockChainSupport
+	outcomes map[uint64][]*cross.CrossOutcome
+}
+
+func (m *mockCrossChainSupport) GetCrossOutcomesByBlock(blockNum uint64) ([]*cross.CrossOutcome, error) {
+	return m.outcomes[blockNum], nil
+}
+
+func TestEventsServer_CrossOutcomes(t *testing.T) {
+	viper.Set("peer.authentication.timewindow", "1s")
+	// a confirmation block confirming the success of cross2, whose outcome is not told, and the failure of cross1
+	var confs []*common.Envelope
+	for _, conf := range []*cross.Confirmation{
+		{CrossTxId: "cross2", Outcome: cross.Outcome_SUCCESS},
+		{CrossTxId: "cross1", Outcome: cross.Outcome_FAILURE, Reason: "lease expired"},
+	} {
+		env, err := utils.CreateSignedConfirmation("testChainID", mockcrypto.FakeLocalSigner, conf)
+		assert.NoError(t, err)
+		confs = append(confs, env)
+	}
+	block, err := createTestBlock(confs)
+	assert.NoError(t, err)
+	assert.True(t, utils.IsConfirmationBlock(block))
+	outcome := &cross.CrossOutcome{CrossTxId: "cross1", ChannelId: "testChainID", Outcome: cross.Outcome_FAILURE, Reason: "lease expired",
+		RolledBackKeys: []*cross.RolledBackKeys{{Namespace: "mycc", Keys: []string{"a", "b"}, Collections: []string{"coll"}}}}
+
+	deliverBlock := func(deliver func(server peer.DeliverServer, srv *mockDeliverServer) error) []*peer.DeliverResponse {
+		iter := &mockIterator{}
+		iter.On("Next").Return(block, common.Status_SUCCESS)
+		reader := &mockReader{}
+		reader.On("Iterator", mock.Anything).Return(iter, uint64(0))
+		reader.On("Height").Return(uint64(1))
+		chain := &mockCrossChainSupport{mockChainSupport: &mockChainSupport{}, outcomes: map[uint64][]*cross.CrossOutcome{0: {outcome}}}
+		chain.On("Sequence").Return(uint64(0))
+		chain.On("Reader").Return(reader)
+		chainManager := &mockChainManager{}
+		chainManager.On("GetChain", "testChainID").Return(chain, true)
+
+		srv := &mockDeliverServer{}
+		srv.On("Context").Return(peer2.NewContext(context.TODO(), &peer2.Peer{}))
+		srv.On("Recv").Return(&common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+					ChannelId: "testChainID",
+					Timestamp: util.CreateUtcTimestamp(),
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&common.SignatureHeader{}),
+			},
+			Data: utils.MarshalOrPanic(&orderer.SeekInfo{
+				Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Oldest{Oldest: &orderer.SeekOldest{}}},
+				Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Newest{Newest: &orderer.SeekNewest{}}},
+				Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+			}),
+		})}, nil).Once()
+		srv.On("Recv").Return(nil, io.EOF)
+		var responses []*peer.DeliverResponse
+		srv.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			responses = append(responses, args.Get(0).(*peer.DeliverResponse))
+		}).Return(nil)
+
+		server := NewDeliverEventsServer(false, defaultPolicyCheckerProvider, chainManager)
+		assert.NoError(t, deliver(server, srv))
+		return responses
+	}
+
+	// the block is followed by the outcomes of the cross transactions it confirms
+	responses := deliverBlock(func(server peer.DeliverServer, srv *mockDeliverServer) error { return server.Deliver(srv) })
+	assert.Len(t, responses, 3)
+	assert.Equal(t, block, responses[0].GetBlock())
+	assert.True(t, proto.Equal(&peer.CrossOutcomeEvent{ChannelId: "testChainID", CrossOutcomes: []*cross.CrossOutcome{outcome}},
+		responses[1].GetCrossOutcomeEvent()))
+	assert.Equal(t, common.Status_SUCCESS, responses[2].GetStatus())
+
+	// the confirmation of a filtered block carries the outcome, without the keys rolled back nor the reason
+	responses = deliverBlock(func(server peer.DeliverServer, srv *mockDeliverServer) error { return server.DeliverFiltered(srv) })
+	assert.Len(t, responses, 2)
+	filteredBlock := responses[0].GetFilteredBlock()
+	assert.NotNil(t, filteredBlock)
+	assert.Len(t, filteredBlock.FilteredTransactions, 2)
+	assert.Equal(t, utils.ComputeConfirmationTxID("cross2"), filteredBlock.FilteredTransactions[0].Txid)
+	assert.Nil(t, filteredBlock.FilteredTransactions[0].Data)
+	assert.Equal(t, utils.ComputeConfirmationTxID("cross1"), filteredBlock.FilteredTransactions[1].Txid)
+	assert.True(t, proto.Equal(&cross.CrossOutcome{CrossTxId: "cross1", ChannelId: "testChainID", Outcome: cross.Outcome_FAILURE,
+		RolledBackKeys: []*cross.RolledBackKeys{{Namespace: "mycc", Collections: []string{"coll"}}}},
+		filteredBlock.FilteredTransactions[1].GetCrossOutcome()))
+	assert.Equal(t, common.Status_SUCCESS, responses[1].GetStatus())
+}
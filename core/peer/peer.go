@@ -8,7 +8,6 @@ package peer
 
 import (
 	"fmt"
-	"github.com/hyperledger/fabric/core/ledger/kvledger"  // NEW add
 	"net"
 	"runtime"
 	"sync"
@@ -37,6 +36,7 @@ import (
 	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross" //NEW add
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/pkg/errors"
@@ -68,8 +68,6 @@ type chainSupport struct {
 	channelconfig.Application
 	ledger     ledger.PeerLedger
 	fileLedger *fileledger.FileLedger
-
-	rollbackInterface kvledger.RollbackInterface  // NEW add
 }
 
 var TransientStoreFactory = &storeProvider{stores: make(map[string]transientstore.Store)}
@@ -161,6 +159,18 @@ func (cs *chainSupport) Errored() <-chan struct{} {
 	return nil
 }
 
+// NEW add
+// GetCrossOutcomesByBlock returns the outcomes of the cross transactions confirmed by the given block
+func (cs *chainSupport) GetCrossOutcomesByBlock(blockNum uint64) ([]*crosspb.CrossOutcome, error) {
+	querier, err := cs.ledger.GetCrossTxQuerier()
+	if err != nil {
+		return nil, err
+	}
+	return querier.GetCrossOutcomesByBlock(blockNum)
+}
+
+//NEW end
+
 // chain is a local struct to manage objects in a chain
 type chain struct {
 	cs        *chainSupport
@@ -438,16 +448,6 @@ func GetLedger(cid string) ledger.PeerLedger {
 }
 
 
-// NEW add
-func GetLedgerForRollback(cid string) kvledger.RollbackInterface{
-	chains.RLock()
-	defer chains.RUnlock()
-	if c, ok := chains.list[cid]; ok {
-		return c.cs.rollbackInterface   // 这里不一样
-	}
-	return nil
-}
-
 // GetStableChannelConfig returns the stable channel configuration of the chain with channel ID.
 // Note that this call returns nil if chain cid has not been created.
 func GetStableChannelConfig(cid string) channelconfig.Resources {
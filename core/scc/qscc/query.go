@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -33,6 +34,12 @@ func New(aclProvider aclmgmt.ACLProvider) *LedgerQuerier {
 // - GetBlockByNumber returns a block
 // - GetBlockByHash returns a block
 // - GetTransactionByID returns a transaction
+// - GetCrossTxStatus returns the status of a cross transaction
+// - ListPendingCrossTxs returns the cross transactions waiting for their confirmation
+// - ListLockedKeys returns the keys locked by cross transactions
+// - GetConfirmationsByBlock returns the confirmations of cross transactions carried by a block
+// - GetConfirmationByBlock returns the first confirmation carried by a block, as in earlier versions
+// - GetCrossLockWaits returns the cross transactions waiting for keys held by other cross transactions
 type LedgerQuerier struct {
 	aclProvider aclmgmt.ACLProvider
 }
@@ -46,6 +53,13 @@ const (
 	GetBlockByHash     string = "GetBlockByHash"
 	GetTransactionByID string = "GetTransactionByID"
 	GetBlockByTxID     string = "GetBlockByTxID"
+	//NEW add
+	GetCrossTxStatus        string = "GetCrossTxStatus"
+	ListPendingCrossTxs     string = "ListPendingCrossTxs"
+	ListLockedKeys          string = "ListLockedKeys"
+	GetConfirmationsByBlock string = "GetConfirmationsByBlock"
+	GetConfirmationByBlock  string = "GetConfirmationByBlock"
+	GetCrossLockWaits       string = "GetCrossLockWaits"
 )
 
 // Init is called once per chain when the chain is created.
@@ -64,6 +78,12 @@ func (e *LedgerQuerier) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // # GetBlockByNumber: Return the block specified by block number in args[2]
 // # GetBlockByHash: Return the block specified by block hash in args[2]
 // # GetTransactionByID: Return the transaction specified by ID in args[2]
+// # GetCrossTxStatus: Return the CrossTxStatus of the cross transaction specified by ID in args[2]
+// # ListPendingCrossTxs: Return the CrossTxStatuses of the cross transactions waiting for their confirmation
+// # ListLockedKeys: Return the LockedKeys of the namespace in the optional args[2], of every namespace if omitted
+// # GetConfirmationsByBlock: Return the Confirmations carried by the block specified by block number in args[2]
+// # GetConfirmationByBlock: Return the first Confirmation carried by the block specified by block number in args[2]
+// # GetCrossLockWaits: Return the CrossLockWaits of the cross transactions recently refused or delayed by held keys
 func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 
@@ -73,7 +93,8 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	fname := string(args[0])
 	cid := string(args[1])
 
-	if fname != GetChainInfo && len(args) < 3 {
+	//NEW add ListPendingCrossTxs, ListLockedKeys and GetCrossLockWaits, which take no 3rd argument
+	if fname != GetChainInfo && fname != ListPendingCrossTxs && fname != ListLockedKeys && fname != GetCrossLockWaits && len(args) < 3 {
 		return shim.Error(fmt.Sprintf("missing 3rd argument for %s", fname))
 	}
 
@@ -108,6 +129,23 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return getChainInfo(targetLedger)
 	case GetBlockByTxID:
 		return getBlockByTxID(targetLedger, args[2])
+	//NEW add
+	case GetCrossTxStatus:
+		return getCrossTxStatus(targetLedger, args[2])
+	case ListPendingCrossTxs:
+		return listPendingCrossTxs(targetLedger)
+	case ListLockedKeys:
+		var namespace []byte
+		if len(args) > 2 {
+			namespace = args[2]
+		}
+		return listLockedKeys(targetLedger, namespace)
+	case GetConfirmationsByBlock:
+		return getConfirmationsByBlock(targetLedger, args[2])
+	case GetConfirmationByBlock:
+		return getConfirmationByBlock(targetLedger, args[2])
+	case GetCrossLockWaits:
+		return getCrossLockWaits(targetLedger)
 	}
 
 	return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
@@ -207,6 +245,128 @@ func getBlockByTxID(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
 	return shim.Success(bytes)
 }
 
+// NEW add
+func getCrossTxStatus(vledger ledger.PeerLedger, txID []byte) pb.Response {
+	if len(txID) == 0 {
+		return shim.Error("Cross transaction ID must not be empty.")
+	}
+	querier, err := vledger.GetCrossTxQuerier()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get cross transaction querier with error %s", err))
+	}
+	status, err := querier.GetCrossTxStatus(string(txID))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get status of cross transaction %s, error %s", string(txID), err))
+	}
+	bytes, err := utils.Marshal(status)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+func listPendingCrossTxs(vledger ledger.PeerLedger) pb.Response {
+	querier, err := vledger.GetCrossTxQuerier()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get cross transaction querier with error %s", err))
+	}
+	statuses, err := querier.ListPendingCrossTxs()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to list pending cross transactions with error %s", err))
+	}
+	bytes, err := utils.Marshal(&cross.CrossTxStatuses{Statuses: statuses})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+func listLockedKeys(vledger ledger.PeerLedger, namespace []byte) pb.Response {
+	querier, err := vledger.GetCrossTxQuerier()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get cross transaction querier with error %s", err))
+	}
+	keys, err := querier.ListLockedKeys(string(namespace))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to list keys locked in namespace %s, error %s", string(namespace), err))
+	}
+	bytes, err := utils.Marshal(&cross.LockedKeys{Keys: keys})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+func getConfirmationsByBlock(vledger ledger.PeerLedger, number []byte) pb.Response {
+	_, confs, err := confirmationsByBlock(vledger, number)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	bytes, err := utils.Marshal(&cross.Confirmations{Confirmations: confs})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+// getConfirmationByBlock returns the first confirmation of a block, for the clients of the versions committing
+// a single confirmation per block
+func getConfirmationByBlock(vledger ledger.PeerLedger, number []byte) pb.Response {
+	bnum, confs, err := confirmationsByBlock(vledger, number)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if len(confs) == 0 {
+		return shim.Error(fmt.Sprintf("Block number %d carries no confirmation", bnum))
+	}
+	bytes, err := utils.Marshal(confs[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+func confirmationsByBlock(vledger ledger.PeerLedger, number []byte) (uint64, []*cross.Confirmation, error) {
+	if number == nil {
+		return 0, nil, fmt.Errorf("Block number must not be nil.")
+	}
+	bnum, err := strconv.ParseUint(string(number), 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to parse block number with error %s", err)
+	}
+	querier, err := vledger.GetCrossTxQuerier()
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to get cross transaction querier with error %s", err)
+	}
+	confs, err := querier.GetConfirmationsByBlock(bnum)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to get confirmations of block number %d, error %s", bnum, err)
+	}
+	return bnum, confs, nil
+}
+
+func getCrossLockWaits(vledger ledger.PeerLedger) pb.Response {
+	querier, err := vledger.GetCrossTxQuerier()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get cross transaction querier with error %s", err))
+	}
+	waits, err := querier.ListLockWaits()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to list cross lock waits with error %s", err))
+	}
+	bytes, err := utils.Marshal(&cross.CrossLockWaits{Waits: waits})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
 func getACLResource(fname string) string {
 	return "qscc/" + fname
 }
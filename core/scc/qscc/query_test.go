@@ -21,6 +21,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/aclmgmt/mocks"
@@ -29,6 +30,7 @@ import (
 	ledger2 "github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	peer2 "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/pkg/errors"
@@ -252,6 +254,111 @@ func TestFailingAccessControl(t *testing.T) {
 	assert.Contains(t, res.Message, "Failed access control")
 	// assert that the expectations were met
 	mockAclProvider.AssertExpectations(t)
+
+	// GetCrossTxStatus
+	args = [][]byte{[]byte(GetCrossTxStatus), []byte(chainid), []byte("1")}
+	sProp, _ = utils.MockSignedEndorserProposalOrPanic(chainid, &peer2.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
+	sProp.Signature = sProp.ProposalBytes
+	// Set the ACLProvider to have a failure
+	resetProvider(resources.Qscc_GetCrossTxStatus, chainid, sProp, errors.New("Failed access control"))
+	res = stub.MockInvokeWithSignedProposal("2", args, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetCrossTxStatus must fail: %s", res.Message)
+	assert.Contains(t, res.Message, "Failed access control")
+	// assert that the expectations were met
+	mockAclProvider.AssertExpectations(t)
+
+	// ListLockedKeys
+	args = [][]byte{[]byte(ListLockedKeys), []byte(chainid)}
+	sProp, _ = utils.MockSignedEndorserProposalOrPanic(chainid, &peer2.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
+	sProp.Signature = sProp.ProposalBytes
+	// Set the ACLProvider to have a failure
+	resetProvider(resources.Qscc_ListLockedKeys, chainid, sProp, errors.New("Failed access control"))
+	res = stub.MockInvokeWithSignedProposal("2", args, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "ListLockedKeys must fail: %s", res.Message)
+	assert.Contains(t, res.Message, "Failed access control")
+	// assert that the expectations were met
+	mockAclProvider.AssertExpectations(t)
+}
+
+func TestQueryCrossTxs(t *testing.T) {
+	chainid := "mytestchainid9"
+	path := tempDir(t, "test9")
+	defer os.RemoveAll(path)
+
+	stub, err := setupTestLedger(chainid, path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	args := [][]byte{[]byte(GetCrossTxStatus), []byte(chainid), []byte("crosstx1")}
+	prop := resetProvider(resources.Qscc_GetCrossTxStatus, chainid, &peer2.SignedProposal{}, nil)
+	res := stub.MockInvokeWithSignedProposal("1", args, prop)
+	assert.Equal(t, int32(shim.OK), res.Status, "GetCrossTxStatus failed with err: %s", res.Message)
+	status := &cross.CrossTxStatus{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, status))
+	assert.Equal(t, "crosstx1", status.CrossTxId)
+	assert.Equal(t, cross.CrossTxState_NOT_FOUND, status.State)
+
+	args = [][]byte{[]byte(GetCrossTxStatus), []byte(chainid), []byte("")}
+	res = stub.MockInvokeWithSignedProposal("2", args, prop)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetCrossTxStatus should have failed with blank txId.")
+
+	args = [][]byte{[]byte(ListPendingCrossTxs), []byte(chainid)}
+	prop = resetProvider(resources.Qscc_ListPendingCrossTxs, chainid, &peer2.SignedProposal{}, nil)
+	res = stub.MockInvokeWithSignedProposal("3", args, prop)
+	assert.Equal(t, int32(shim.OK), res.Status, "ListPendingCrossTxs failed with err: %s", res.Message)
+	statuses := &cross.CrossTxStatuses{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, statuses))
+	assert.Empty(t, statuses.Statuses)
+
+	for _, args := range [][][]byte{
+		{[]byte(ListLockedKeys), []byte(chainid)},
+		{[]byte(ListLockedKeys), []byte(chainid), []byte("ns1")},
+	} {
+		prop = resetProvider(resources.Qscc_ListLockedKeys, chainid, &peer2.SignedProposal{}, nil)
+		res = stub.MockInvokeWithSignedProposal("4", args, prop)
+		assert.Equal(t, int32(shim.OK), res.Status, "ListLockedKeys failed with err: %s", res.Message)
+		keys := &cross.LockedKeys{}
+		assert.NoError(t, proto.Unmarshal(res.Payload, keys))
+		assert.Empty(t, keys.Keys)
+	}
+
+	// the genesis block carries no confirmation
+	args = [][]byte{[]byte(GetConfirmationsByBlock), []byte(chainid), []byte("0")}
+	prop = resetProvider(resources.Qscc_GetConfirmationsByBlock, chainid, &peer2.SignedProposal{}, nil)
+	res = stub.MockInvokeWithSignedProposal("5", args, prop)
+	assert.Equal(t, int32(shim.OK), res.Status, "GetConfirmationsByBlock failed with err: %s", res.Message)
+	confs := &cross.Confirmations{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, confs))
+	assert.Empty(t, confs.Confirmations)
+
+	args = [][]byte{[]byte(GetConfirmationsByBlock), []byte(chainid), []byte("zero")}
+	res = stub.MockInvokeWithSignedProposal("6", args, prop)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetConfirmationsByBlock should have failed with an invalid block number")
+
+	// Test with wrong number of parameters
+	args = [][]byte{[]byte(GetConfirmationsByBlock), []byte(chainid)}
+	res = stub.MockInvokeWithSignedProposal("7", args, prop)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetConfirmationsByBlock should have failed due to incorrect number of arguments")
+
+	// as in earlier versions, the first confirmation of a block, under its own ACL resource
+	args = [][]byte{[]byte(GetConfirmationByBlock), []byte(chainid), []byte("0")}
+	prop = resetProvider(resources.Qscc_GetConfirmationByBlock, chainid, &peer2.SignedProposal{}, nil)
+	res = stub.MockInvokeWithSignedProposal("9", args, prop)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetConfirmationByBlock should have failed for a block without confirmation")
+	assert.Contains(t, res.Message, "carries no confirmation")
+
+	args = [][]byte{[]byte(GetConfirmationByBlock), []byte(chainid), []byte("zero")}
+	res = stub.MockInvokeWithSignedProposal("10", args, prop)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetConfirmationByBlock should have failed with an invalid block number")
+
+	args = [][]byte{[]byte(GetCrossLockWaits), []byte(chainid)}
+	prop = resetProvider(resources.Qscc_GetCrossLockWaits, chainid, &peer2.SignedProposal{}, nil)
+	res = stub.MockInvokeWithSignedProposal("8", args, prop)
+	assert.Equal(t, int32(shim.OK), res.Status, "GetCrossLockWaits failed with err: %s", res.Message)
+	waits := &cross.CrossLockWaits{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, waits))
+	assert.Empty(t, waits.Waits)
 }
 
 func TestQueryNonexistentFunction(t *testing.T) {
@@ -339,7 +446,7 @@ func addBlockForTesting(t *testing.T, chainid string) *common.Block {
 	simulator.SetState("ns1", "key2", []byte("value2"))
 	simulator.SetState("ns1", "key3", []byte("value3"))
 	simulator.Done()
-	simRes1, _ := simulator.GetTxSimulationResults()
+	simRes1, _ := simulator.GetTxSimulationResults(nil)
 	pubSimResBytes1, _ := simRes1.GetPubSimulationBytes()
 
 	txid2 := util.GenerateUUID()
@@ -348,7 +455,7 @@ func addBlockForTesting(t *testing.T, chainid string) *common.Block {
 	simulator.SetState("ns2", "key5", []byte("value5"))
 	simulator.SetState("ns2", "key6", []byte("value6"))
 	simulator.Done()
-	simRes2, _ := simulator.GetTxSimulationResults()
+	simRes2, _ := simulator.GetTxSimulationResults(nil)
 	pubSimResBytes2, _ := simRes2.GetPubSimulationBytes()
 
 	block1 := bg.NextBlock([][]byte{pubSimResBytes1, pubSimResBytes2})
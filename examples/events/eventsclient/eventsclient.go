@@ -131,6 +131,16 @@ func (r *eventsClient) readEventsStream() {
 			} else {
 				logger.Info("Received filtered block: ", t.FilteredBlock.Number)
 			}
+		case *peer.DeliverResponse_CrossOutcomeEvent:
+			if !quiet {
+				logger.Info("Received cross transaction outcomes: ")
+				err := protolator.DeepMarshalJSON(os.Stdout, t.CrossOutcomeEvent)
+				if err != nil {
+					fmt.Printf("  Error pretty printing cross transaction outcomes: %s", err)
+				}
+			} else {
+				logger.Info("Received cross transaction outcomes of block: ", t.CrossOutcomeEvent.Number)
+			}
 		}
 	}
 }
@@ -162,21 +162,19 @@ func (c *coordinator) StoreBlock(block *common.Block, privateDataSets util.PvtDa
 	logger.Infof("[%s] Received block [%d] from buffer", c.ChainID, block.Header.Number)
 
 	logger.Debugf("[%s] Validating block [%d]", c.ChainID, block.Header.Number)
-	err := c.Validator.Validate(block)  //检查 标记metadata normal crosstx confirmation
+	err := c.Validator.Validate(block)
 	if err != nil {
 		logger.Errorf("Validation failed: %+v", err)
 		return err
 	}
-	fmt.Println("gossip/privdata/coordinator.go StoreBlock() block.Metadata.(CROSSINFO)=", string(block.Metadata.Metadata[common.BlockMetadataIndex_CROSSINFO])) //NEW add
 
 	blockAndPvtData := &ledger.BlockAndPvtData{
 		Block:        block,
 		BlockPvtData: make(map[uint64]*ledger.TxPvtData),
 	}
 
-	if string(block.Metadata.Metadata[4]) == "confirmation" {// NEW add
-		fmt.Println("gossip/privdata/coordinator.go StoreBlock block.Metadata.Metadata[4] == confirmation", string(block.Metadata.Metadata[4]))
-		err = c.CommitWithPvtData(blockAndPvtData)  //metadata = normal crosstx confirmation
+	if utils.IsConfirmationBlock(block) { // NEW add
+		err = c.CommitWithPvtData(blockAndPvtData)
 		if err != nil {
 			return errors.Wrap(err, "commit failed")
 		}
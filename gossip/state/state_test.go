@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/configtx/test"
 	errors2 "github.com/hyperledger/fabric/common/errors"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
@@ -37,8 +38,10 @@ import (
 	gutil "github.com/hyperledger/fabric/gossip/util"
 	pcomm "github.com/hyperledger/fabric/protos/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	transientstore2 "github.com/hyperledger/fabric/protos/transientstore"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -1684,3 +1687,73 @@ func defaultBackend() logging.LeveledBackend {
 	be.SetLevel(logging.WARNING, "")
 	return be
 }
+
+// dataHashCryptoServiceMock rejects the blocks whose header does not match their data
+type dataHashCryptoServiceMock struct {
+	cryptoServiceMock
+}
+
+func (*dataHashCryptoServiceMock) VerifyBlock(chainID common.ChainID, seqNum uint64, signedBlock []byte) error {
+	block := &pcomm.Block{}
+	if err := pb.Unmarshal(signedBlock, block); err != nil {
+		return err
+	}
+	if !bytes.Equal(block.Data.Hash(), block.Header.DataHash) {
+		return errors.New("Header.DataHash is different from Hash(block.Data)")
+	}
+	return nil
+}
+
+func TestStateResponseConfirmationVerification(t *testing.T) {
+	t.Parallel()
+	chainID := "testChainID"
+	g := &mocks.GossipMock{}
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	g.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
+	g.On("Close")
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(5), nil)
+	coord.On("Close")
+
+	servicesAdapater := &ServicesMediator{GossipAdapter: g, MCSAdapter: &dataHashCryptoServiceMock{cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}}}
+	st := NewGossipStateProvider(chainID, servicesAdapater, coord).(*GossipStateProviderImpl)
+	defer st.Stop()
+
+	confirmationBlock := func(seqNum uint64, outcome cross.Outcome) *pcomm.Block {
+		env, err := putils.CreateSignedConfirmation(chainID, &mockcrypto.LocalSigner{Identity: []byte("coordinator")},
+			&cross.Confirmation{CrossTxId: "tx1", Outcome: outcome})
+		assert.NoError(t, err)
+		block := pcomm.NewBlock(seqNum, nil)
+		block.Data.Data = [][]byte{putils.MarshalOrPanic(env)}
+		block.Header.DataHash = block.Data.Hash()
+		return block
+	}
+	stateResponse := func(block *pcomm.Block) proto.ReceivedMessage {
+		msg, _ := (&proto.GossipMessage{
+			Nonce:   1,
+			Tag:     proto.GossipMessage_CHAN_OR_ORG,
+			Channel: []byte(chainID),
+			Content: &proto.GossipMessage_StateResponse{StateResponse: &proto.RemoteStateResponse{
+				Payloads: []*proto.Payload{{SeqNum: block.Header.Number, Data: putils.MarshalOrPanic(block)}},
+			}},
+		}).NoopSign()
+		received := new(receivedMessageMock)
+		received.On("GetGossipMessage").Return(msg)
+		return received
+	}
+
+	// a confirmation block whose confirmation was replaced is rejected
+	tampered := confirmationBlock(6, cross.Outcome_FAILURE)
+	tampered.Header = confirmationBlock(6, cross.Outcome_SUCCESS).Header
+	_, err := st.handleStateResponse(stateResponse(tampered))
+	assert.Error(t, err)
+	assert.Equal(t, 0, st.payloads.Size())
+
+	// a verified confirmation block is buffered
+	max, err := st.handleStateResponse(stateResponse(confirmationBlock(7, cross.Outcome_SUCCESS)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), max)
+	assert.Equal(t, 1, st.payloads.Size())
+}
@@ -33,7 +33,10 @@ type Receiver interface {
 	// `pending` indicates if there are still messages pending in the receiver. It
 	// is useful for Kafka orderer to determine the `LastOffsetPersisted` of block.
 	Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool)
-	OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) //NEW add
+	// NEW add
+	// OrderedCrosschain should be invoked for confirmations of cross transactions, in place of Ordered.
+	// Confirmations are batched as regular messages, but never share a batch with other messages.
+	OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool)
 	// Cut returns the current batch and starts a new one
 	Cut() []*cb.Envelope
 }
@@ -42,6 +45,7 @@ type receiver struct {
 	sharedConfigFetcher   OrdererConfigFetcher
 	pendingBatch          []*cb.Envelope
 	pendingBatchSizeBytes uint32
+	pendingConfirmations  bool //NEW add  true when the pending batch holds confirmations
 }
 
 // NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager
@@ -68,12 +72,30 @@ func NewReceiverImpl(sharedConfigFetcher OrdererConfigFetcher) Receiver {
 //
 // Note that messageBatches can not be greater than 2.
 func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
+	return r.ordered(msg, false) //NEW add
+}
+
+// NEW add  HuBinmei
+// OrderedCrosschain batches a confirmation, like Ordered does any other message. The peers process
+// the confirmations of a block in order, but apart from regular transactions, hence a pending batch
+// of regular messages is cut ahead of a confirmation, and a pending batch of confirmations is cut
+// ahead of a regular message. The lengths of messageBatches are the ones documented on Ordered.
+func (r *receiver) OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
+	return r.ordered(msg, true)
+}
+
+func (r *receiver) ordered(msg *cb.Envelope, confirmation bool) (messageBatches [][]*cb.Envelope, pending bool) {
 	ordererConfig, ok := r.sharedConfigFetcher.OrdererConfig()
 	if !ok {
 		logger.Panicf("Could not retrieve orderer config to query batch parameters, block cutting is not possible")
 	}
 	batchSize := ordererConfig.BatchSize()
 
+	if len(r.pendingBatch) > 0 && r.pendingConfirmations != confirmation { //NEW add
+		logger.Debugf("Cutting the pending batch of %d message(s), which may not be mixed with the current message", len(r.pendingBatch))
+		messageBatches = append(messageBatches, r.Cut())
+	} //NEW end
+
 	messageSizeBytes := messageSizeBytes(msg)
 	if messageSizeBytes > batchSize.PreferredMaxBytes {
 		logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, batchSize.PreferredMaxBytes)
@@ -102,6 +124,7 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, p
 	logger.Debugf("Enqueuing message into batch")
 	r.pendingBatch = append(r.pendingBatch, msg)
 	r.pendingBatchSizeBytes += messageSizeBytes
+	r.pendingConfirmations = confirmation //NEW add
 	pending = true
 
 	if uint32(len(r.pendingBatch)) >= batchSize.MaxMessageCount {
@@ -114,37 +137,12 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, p
 	return
 }
 
-//NEW add  HuBinmei
-func (r *receiver) OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
-
-	messageSizeBytes := messageSizeBytes(msg)
-
-	logger.Debugf("Enqueuing message into batch")
-	r.pendingBatch = append(r.pendingBatch, msg)
-	r.pendingBatchSizeBytes += messageSizeBytes  //调整缓存交易消息列表的消息字节数
-	pending = true
-
-	logger.Debugf("缓存交易消息列表消息数为：%d", len(r.pendingBatch))
-
-	//若存在缓存交易消息列表，则切割出批量交易集合
-	if len(r.pendingBatch) > 0 {
-		messageBatch := r.Cut()  //切割
-		messageBatches = append(messageBatches, messageBatch)
-	}
-
-	logger.Debugf("缓存交易消息列表消息数为：%d", len(r.pendingBatch))
-
-	// create new batch with single message
-	messageBatches = append(messageBatches, []*cb.Envelope{msg})
-
-	return
-}
-
 // Cut returns the current batch and starts a new one
 func (r *receiver) Cut() []*cb.Envelope {
 	batch := r.pendingBatch
 	r.pendingBatch = nil
 	r.pendingBatchSizeBytes = 0
+	r.pendingConfirmations = false //NEW add
 	return batch
 }
 
@@ -137,6 +137,60 @@ func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 	}
 }
 
+func TestOrderedCrosschain(t *testing.T) {
+	mockConfig := &mock.OrdererConfig{}
+	mockConfig.BatchSizeReturns(&ab.BatchSize{
+		MaxMessageCount:   3,
+		AbsoluteMaxBytes:  1000,
+		PreferredMaxBytes: 100,
+	})
+
+	mockConfigFetcher := &mock.OrdererConfigFetcher{}
+	mockConfigFetcher.OrdererConfigReturns(mockConfig, true)
+
+	r := NewReceiverImpl(mockConfigFetcher)
+	crossTx := &cb.Envelope{Payload: []byte("CROSS"), CrossInfo: []byte("multiCross")}
+	conf := &cb.Envelope{Payload: []byte("cross1_succ"), CrossInfo: []byte("confirmation")}
+
+	// cross transactions are batched along with regular messages
+	batches, pending := r.Ordered(tx)
+	assert.Nil(t, batches, "Should not have cut a batch")
+	assert.True(t, pending, "Should have enqueued message into batch")
+	batches, pending = r.Ordered(crossTx)
+	assert.Nil(t, batches, "Should not have cut a batch")
+	assert.True(t, pending, "Should have enqueued message into batch")
+
+	// the regular messages are cut ahead of a confirmation, which starts a new batch
+	batches, pending = r.OrderedCrosschain(conf)
+	assert.Equal(t, [][]*cb.Envelope{{tx, crossTx}}, batches)
+	assert.True(t, pending, "Should have enqueued confirmation into batch")
+
+	// confirmations are batched together up to the message count
+	batches, pending = r.OrderedCrosschain(conf)
+	assert.Nil(t, batches, "Should not have cut a batch")
+	assert.True(t, pending, "Should have enqueued confirmation into batch")
+	batches, pending = r.OrderedCrosschain(conf)
+	assert.Equal(t, [][]*cb.Envelope{{conf, conf, conf}}, batches)
+	assert.False(t, pending, "Should not have pending messages in receiver")
+
+	// the confirmations are cut ahead of a regular message
+	_, pending = r.OrderedCrosschain(conf)
+	assert.True(t, pending, "Should have enqueued confirmation into batch")
+	batches, pending = r.Ordered(tx)
+	assert.Equal(t, [][]*cb.Envelope{{conf}}, batches)
+	assert.True(t, pending, "Should have enqueued message into batch")
+	assert.Equal(t, []*cb.Envelope{tx}, r.Cut())
+
+	// a confirmation larger than the preferred batch size is isolated
+	largeConf := &cb.Envelope{Payload: make([]byte, 101), CrossInfo: []byte("confirmation")}
+	_, pending = r.Ordered(tx)
+	assert.True(t, pending, "Should have enqueued message into batch")
+	batches, pending = r.OrderedCrosschain(largeConf)
+	assert.Equal(t, [][]*cb.Envelope{{tx}, {largeConf}}, batches)
+	assert.False(t, pending, "Should not have pending messages in receiver")
+	assert.Nil(t, r.Cut(), "Should have left the receiver empty")
+}
+
 func TestPanicOnMissingConfig(t *testing.T) {
 	mockConfigFetcher := &mock.OrdererConfigFetcher{}
 	r := NewReceiverImpl(mockConfigFetcher)
@@ -16,8 +16,6 @@ import (
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
 	"github.com/pkg/errors"
-
-"fmt"
 )
 
 const pkgLogID = "orderer/common/broadcast"
@@ -78,33 +76,21 @@ func NewHandlerImpl(sm ChannelSupportRegistrar) Handler {
 }
 
 // Handle starts a service thread for a given gRPC connection and services the broadcast connection
-func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error { //AtomicBroadcast_BroadcastServer是interface
-	fmt.Println("orderer/common/broadcast/broadcast.go Handle()")
+func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 	addr := util.ExtractRemoteAddress(srv.Context())
 	logger.Debugf("Starting new broadcast loop for %s", addr)
 	for {
-	//	crossOverMsg, _ := srv.RecvM() // NEW add
-	//	fmt.Println(crossOverMsg)// NEW add
-
-		msg, err := srv.Recv() // AtomicBroadcast_BroadcastServer.Recv() (*common.Envelope, error)
-		fmt.Println("Orderer!!! orderer/common/broadcast/broadcast.go  Handle()")
-		//fmt.Println("msg(Envelope)", msg) //NEW add  ok
-		if msg != nil{
-			fmt.Println("msg.CrossInfo = ", string(msg.CrossInfo))  //NEW add
-		}
-fmt.Println("标记1")
+		msg, err := srv.Recv()
 		if err == io.EOF {
 			logger.Debugf("Received EOF from %s, hangup", addr)
 			return nil
 		}
-		fmt.Println("标记2")
 		if err != nil {
 			logger.Warningf("Error reading from %s: %s", addr, err)
 			return err
 		}
-		fmt.Println("Orderer/common/broadcast/broadcast.go  Handle() 开始BroadcastChannelSupport")
+
 		chdr, isConfig, processor, err := bh.sm.BroadcastChannelSupport(msg)
-		fmt.Println("broadcast.go Handle 结束BroadcastChannelSupport chid = ", chdr.ChannelId)
 		if err != nil {
 			channelID := "<malformed_header>"
 			if chdr != nil {
@@ -113,32 +99,27 @@ fmt.Println("标记1")
 			logger.Warningf("[channel: %s] Could not get message processor for serving %s: %s", channelID, addr, err)
 			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST, Info: err.Error()})
 		}
-		fmt.Println("Orderer/common/broadcast/broadcast.go  Handle() 结束BroadcastChannelSupport  开始WaitReady()")
 
 		if err = processor.WaitReady(); err != nil {
 			logger.Warningf("[channel: %s] Rejecting broadcast of message from %s with SERVICE_UNAVAILABLE: rejected by Consenter: %s", chdr.ChannelId, addr, err)
 			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
 		}
-		//fmt.Println("orderer/common/broadcast/broadcast.go Handle() isConfig = ", isConfig)
+
 		if !isConfig {
-			fmt.Println("orderer/common/broadcast/broadcast.go Handle() !isConfig")
-		//	logger.Debugf("[channel: %s] Broadcast is processing normal message from %s with txid '%s' of type %s", chdr.ChannelId, addr, chdr.TxId, cb.HeaderType_name[chdr.Type])
-			logger.Debugf("[channel: %s] Broadcast is processing normal message from %s with txid ", chdr.ChannelId, addr)
-fmt.Println("broadcast标记1")
+			logger.Debugf("[channel: %s] Broadcast is processing normal message from %s with txid '%s' of type %s", chdr.ChannelId, addr, chdr.TxId, cb.HeaderType_name[chdr.Type])
+
 			configSeq, err := processor.ProcessNormalMsg(msg)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s because of error: %s", chdr.ChannelId, addr, err)
 				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
 			}
-			fmt.Println("broadcast标记2")
+
 			err = processor.Order(msg, configSeq)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s with SERVICE_UNAVAILABLE: rejected by Order: %s", chdr.ChannelId, addr, err)
 				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
 			}
-			fmt.Println("broadcast标记3")
 		} else { // isConfig
-			fmt.Println("orderer/common/broadcast/broadcast.go Handle() isConfig")
 			logger.Debugf("[channel: %s] Broadcast is processing config update message from %s", chdr.ChannelId, addr)
 
 			config, configSeq, err := processor.ProcessConfigUpdateMsg(msg)
@@ -1,18 +0,0 @@
-package cross
-/*
-import (
-	"fmt"
-	"encoding/json"
-	cb "github.com/hyperledger/fabric/protos/common"
-)
-
-func ByteToCOM(b []byte) cb.CrossOverMsg {
-	com := cb.CrossOverMsg{}
-	err := json.Unmarshal(b, com)
-	if err!=nil{
-		return nil
-	}
-	fmt.Println(m)
-	return com
-}
-*/
\ No newline at end of file
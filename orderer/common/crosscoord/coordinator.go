@@ -0,0 +1,483 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscoord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+const pkgLogID = "orderer/common/crosscoord"
+
+var logger = flogging.MustGetLogger(pkgLogID)
+
+// Broadcaster sends envelopes to the ordering service
+type Broadcaster interface {
+	// Broadcast sends the envelope to the ordering service and waits for it to be accepted
+	Broadcast(env *cb.Envelope) error
+}
+
+// Lease bounds the time a cross transaction prepared on a channel waits for the votes of the other
+// participants. Once the lease expired, the coordinator decides the failure of the transaction, which
+// rolls back its writes and releases its locks on the participants. A zero field sets no bound.
+type Lease struct {
+	// Blocks is the number of blocks committed on the channel after the block preparing the transaction
+	Blocks uint64
+	// Time is the time elapsed since the coordinator processed the block preparing the transaction
+	Time time.Duration
+}
+
+// Coordinator drives the two-phase commit of cross transactions. The prepare phase
+// is the commit of a cross transaction on each participant channel, whose outcome
+// is read from the validation flags of the committed blocks. Once every participant
+// voted, the coordinator decides the outcome, records it in its decision log, and
+// broadcasts a signed confirmation to every participant until it sees it committed.
+// A transaction still missing votes when its lease on a participant expires fails,
+// as does a transaction deadlocked with others over the keys they hold on the channels.
+//
+// The coordinator processes committed blocks, that is blocks delivered by a peer
+// once validated, as it needs their validation flags.
+type Coordinator struct {
+	lock        sync.Mutex
+	log         DecisionLog
+	signer      crypto.LocalSigner
+	broadcaster Broadcaster
+	txs         map[string]*TxRecord
+	leases      map[string]Lease
+	// waits holds the lock waits reported on each channel, as the transactions each transaction waits for
+	waits map[string]map[string][]string
+	now   func() time.Time
+}
+
+// New creates a coordinator recovering its state from the given decision log
+func New(log DecisionLog, signer crypto.LocalSigner, broadcaster Broadcaster) (*Coordinator, error) {
+	recs, err := log.TxRecords()
+	if err != nil {
+		return nil, err
+	}
+	c := &Coordinator{
+		log:         log,
+		signer:      signer,
+		broadcaster: broadcaster,
+		txs:         make(map[string]*TxRecord),
+		leases:      make(map[string]Lease),
+		waits:       make(map[string]map[string][]string),
+		now:         time.Now,
+	}
+	for _, rec := range recs {
+		c.txs[rec.CrossTxID] = rec
+	}
+	logger.Infof("Recovered %d cross transactions from the decision log", len(recs))
+	return c, nil
+}
+
+// SetLease sets the lease of the transactions prepared on the given channel. The lease set
+// for the empty channel ID applies to the channels without a lease of their own.
+func (c *Coordinator) SetLease(channelID string, lease Lease) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.leases[channelID] = lease
+}
+
+func (c *Coordinator) lease(channelID string) Lease {
+	if lease, ok := c.leases[channelID]; ok {
+		return lease
+	}
+	return c.leases[""]
+}
+
+// Height returns the number of the next block of the channel to be processed
+func (c *Coordinator) Height(channelID string) (uint64, error) {
+	return c.log.Height(channelID)
+}
+
+// Pending returns the records of the cross transactions that are not completed,
+// sorted by cross transaction ID
+func (c *Coordinator) Pending() []*TxRecord {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var recs []*TxRecord
+	for _, rec := range c.txs {
+		recs = append(recs, rec.clone())
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].CrossTxID < recs[j].CrossTxID })
+	return recs
+}
+
+// ProcessBlock processes a committed block of the given channel. The votes and confirmations
+// it carries, and the decisions they lead to, are recorded in the decision log before the
+// decided confirmations are broadcast. Blocks below the height of the channel are ignored,
+// so that blocks may be delivered again after a restart.
+func (c *Coordinator) ProcessBlock(channelID string, block *cb.Block) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	height, err := c.log.Height(channelID)
+	if err != nil {
+		return err
+	}
+	if block.Header.Number < height {
+		logger.Debugf("[%s] Skipping block [%d] below height [%d]", channelID, block.Header.Number, height)
+		return nil
+	}
+	if block.Header.Number > height {
+		return errors.Errorf("[%s] expected block [%d] but got block [%d]", channelID, height, block.Header.Number)
+	}
+
+	changed, resends, err := c.scanBlock(channelID, block)
+	if err != nil {
+		return err
+	}
+
+	var puts, decided []*TxRecord
+	var deletes []string
+	for _, rec := range changed {
+		if !rec.Decided() && len(rec.Votes) == len(rec.Channels) {
+			decide(rec)
+			decided = append(decided, rec)
+		}
+	}
+	expired := c.expireBlockLeases(channelID, block.Header.Number, changed)
+	decided = append(decided, expired...)
+	for _, rec := range expired {
+		if !containsRecord(changed, rec) {
+			changed = append(changed, rec)
+		}
+	}
+	for _, rec := range changed {
+		if rec.Decided() && len(rec.Confirmed) == len(rec.Channels) {
+			deletes = append(deletes, rec.CrossTxID)
+			continue
+		}
+		puts = append(puts, rec)
+	}
+
+	if err := c.log.Commit(channelID, block.Header.Number+1, puts, deletes); err != nil {
+		return err
+	}
+	for _, rec := range puts {
+		c.txs[rec.CrossTxID] = rec
+	}
+	for _, crossTxID := range deletes {
+		logger.Infof("Cross transaction [%s] completed", crossTxID)
+		delete(c.txs, crossTxID)
+	}
+
+	for _, rec := range decided {
+		logger.Infof("Decided outcome %s for cross transaction [%s] on channels %v", rec.Decision, rec.CrossTxID, rec.Channels)
+		c.broadcastConfirmations(rec)
+	}
+	for _, rec := range resends {
+		logger.Infof("[%s] Confirming again outcome %s of cross transaction [%s] to a late vote", channelID, rec.Decision, rec.CrossTxID)
+		c.broadcastConfirmation(rec, channelID)
+	}
+	return nil
+}
+
+// expireBlockLeases decides the failure of the undecided transactions whose lease in blocks on the
+// channel expires with the given block. The records of the given list are updated in place, the
+// others are copied. It returns the expired records.
+func (c *Coordinator) expireBlockLeases(channelID string, blockNum uint64, changed []*TxRecord) []*TxRecord {
+	lease := c.lease(channelID)
+	if lease.Blocks == 0 {
+		return nil
+	}
+	recs := append([]*TxRecord(nil), changed...)
+	for _, rec := range c.sortedTxs() {
+		if !containsRecord(changed, rec) {
+			recs = append(recs, rec.clone())
+		}
+	}
+	var expired []*TxRecord
+	for _, rec := range recs {
+		prep, ok := rec.Prepared[channelID]
+		if rec.Decided() || !ok || blockNum < prep.Block+lease.Blocks {
+			continue
+		}
+		expire(rec, fmt.Sprintf("lease of %d blocks expired on channel %s", lease.Blocks, channelID))
+		expired = append(expired, rec)
+	}
+	return expired
+}
+
+// ExpireLeases decides the failure of the undecided transactions whose lease in time expired
+// on any of the channels they were prepared on, and broadcasts their confirmations
+func (c *Coordinator) ExpireLeases() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.now()
+	var expired []*TxRecord
+	for _, rec := range c.sortedTxs() {
+		if rec.Decided() {
+			continue
+		}
+		for _, ch := range rec.Channels {
+			prep, ok := rec.Prepared[ch]
+			lease := c.lease(ch)
+			if !ok || lease.Time == 0 || now.Before(prep.Time.Add(lease.Time)) {
+				continue
+			}
+			rec = rec.clone()
+			expire(rec, fmt.Sprintf("lease of %s expired on channel %s", lease.Time, ch))
+			expired = append(expired, rec)
+			break
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return c.commitDecisions(expired)
+}
+
+// commitDecisions records the given decided records in the decision log, leaving the heights of the channels
+// unchanged, and broadcasts their confirmations. The caller is expected to hold the lock.
+func (c *Coordinator) commitDecisions(recs []*TxRecord) error {
+	if err := c.log.Commit("", 0, recs, nil); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		c.txs[rec.CrossTxID] = rec
+	}
+	for _, rec := range recs {
+		c.broadcastConfirmations(rec)
+	}
+	return nil
+}
+
+// sortedTxs returns the records of the coordinator sorted by cross transaction ID. The caller is expected to hold the lock.
+func (c *Coordinator) sortedTxs() []*TxRecord {
+	recs := make([]*TxRecord, 0, len(c.txs))
+	for _, rec := range c.txs {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].CrossTxID < recs[j].CrossTxID })
+	return recs
+}
+
+// scanBlock returns copies of the records updated by the votes and confirmations of the block, along with the
+// decided records still unconfirmed on the channel that the block votes on, whose confirmation is to be broadcast
+// to the channel again. A confirmation counts once committed as valid, or as a duplicate when the channel already
+// committed or aborted the transaction. Any other invalid confirmation, such as one reaching a channel before the
+// transaction is prepared there, leaves the transaction undecided on the channel.
+func (c *Coordinator) scanBlock(channelID string, block *cb.Block) ([]*TxRecord, []*TxRecord, error) {
+	var txsFilter ledgerUtil.TxValidationFlags
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txsFilter = ledgerUtil.TxValidationFlags(block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	changed := make(map[string]*TxRecord)
+	var order []string
+	var resends []*TxRecord
+	record := func(crossTxID string) *TxRecord {
+		if rec, ok := changed[crossTxID]; ok {
+			return rec
+		}
+		rec, ok := c.txs[crossTxID]
+		if !ok {
+			return nil
+		}
+		rec = rec.clone()
+		changed[crossTxID] = rec
+		order = append(order, crossTxID)
+		return rec
+	}
+
+	for i, data := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(data)
+		if err != nil {
+			logger.Warningf("[%s] Skipping malformed transaction [%d] of block [%d]: %s", channelID, i, block.Header.Number, err)
+			continue
+		}
+		info, err := utils.UnmarshalCrossInfo(env.CrossInfo)
+		if err != nil {
+			logger.Warningf("[%s] Skipping transaction [%d] of block [%d]: %s", channelID, i, block.Header.Number, err)
+			continue
+		}
+
+		switch info.Mode {
+		case cross.CrossMode_SINGLE_CROSS, cross.CrossMode_MULTI_CROSS:
+			if len(txsFilter) <= i {
+				return nil, nil, errUnvalidatedBlock(channelID, block)
+			}
+			crossTxID, channels, err := crossTxParticipants(channelID, env, info)
+			if err != nil {
+				logger.Warningf("[%s] Skipping transaction [%d] of block [%d]: %s", channelID, i, block.Header.Number, err)
+				continue
+			}
+			rec := record(crossTxID)
+			if rec == nil {
+				rec = &TxRecord{CrossTxID: crossTxID, Channels: channels, Votes: map[string]bool{}, Prepared: map[string]*Preparation{}, Confirmed: map[string]bool{}}
+				changed[crossTxID] = rec
+				order = append(order, crossTxID)
+			}
+			if !contains(rec.Channels, channelID) {
+				logger.Warningf("[%s] Ignoring vote on cross transaction [%s] from a channel which is not a participant", channelID, crossTxID)
+				continue
+			}
+			if rec.Decided() {
+				logger.Warningf("[%s] Ignoring vote on cross transaction [%s], whose outcome is already decided", channelID, crossTxID)
+				if !rec.Confirmed[channelID] && !containsRecord(resends, rec) {
+					resends = append(resends, rec)
+				}
+				continue
+			}
+			rec.Votes[channelID] = txsFilter.IsValid(i)
+			rec.Prepared[channelID] = &Preparation{Block: block.Header.Number, Time: c.now().UTC()}
+			logger.Debugf("[%s] Cross transaction [%s] prepared=%t", channelID, crossTxID, rec.Votes[channelID])
+
+		case cross.CrossMode_CONFIRMATION:
+			if len(txsFilter) <= i {
+				return nil, nil, errUnvalidatedBlock(channelID, block)
+			}
+			conf, err := utils.GetConfirmation(env)
+			if err != nil {
+				logger.Warningf("[%s] Skipping confirmation [%d] of block [%d]: %s", channelID, i, block.Header.Number, err)
+				continue
+			}
+			if flag := txsFilter.Flag(i); flag != pb.TxValidationCode_VALID && flag != pb.TxValidationCode_DUPLICATE_CONFIRMATION {
+				logger.Warningf("[%s] Confirmation of cross transaction [%s] invalidated in block [%d]: %s", channelID, conf.CrossTxId, block.Header.Number, flag)
+				continue
+			}
+			if rec := record(conf.CrossTxId); rec != nil && rec.Decided() && conf.Outcome == rec.Decision {
+				rec.Confirmed[channelID] = true
+			}
+		}
+	}
+
+	recs := make([]*TxRecord, 0, len(order))
+	for _, crossTxID := range order {
+		recs = append(recs, changed[crossTxID])
+	}
+	var unconfirmed []*TxRecord
+	for _, rec := range resends {
+		if !rec.Confirmed[channelID] {
+			unconfirmed = append(unconfirmed, rec)
+		}
+	}
+	return recs, unconfirmed, nil
+}
+
+func errUnvalidatedBlock(channelID string, block *cb.Block) error {
+	return errors.Errorf("[%s] block [%d] carries no validation flags, it must be delivered by a peer", channelID, block.Header.Number)
+}
+
+// crossTxParticipants returns the ID and the participant channels of a cross transaction.
+// Without explicit cross information, the transaction ID is the global ID and the channel
+// the only participant.
+func crossTxParticipants(channelID string, env *cb.Envelope, info *cross.CrossInfo) (string, []string, error) {
+	crossTxID := info.CrossTxId
+	if crossTxID == "" {
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil {
+			return "", nil, err
+		}
+		crossTxID = chdr.TxId
+	}
+	if crossTxID == "" {
+		return "", nil, errors.New("cross transaction has no ID")
+	}
+	channels := info.Channels
+	if len(channels) == 0 {
+		channels = []string{channelID}
+	}
+	channels = append([]string(nil), channels...)
+	sort.Strings(channels)
+	return crossTxID, channels, nil
+}
+
+// decide sets the outcome of a transaction every participant voted on: success if the
+// transaction was committed as valid on every channel, failure otherwise
+func decide(rec *TxRecord) {
+	var failed []string
+	for _, ch := range rec.Channels {
+		if !rec.Votes[ch] {
+			failed = append(failed, ch)
+		}
+	}
+	if len(failed) == 0 {
+		rec.Decision = cross.Outcome_SUCCESS
+		return
+	}
+	rec.Decision = cross.Outcome_FAILURE
+	rec.Reason = "invalidated on channels " + strings.Join(failed, ",")
+}
+
+// expire sets the failure of a transaction whose lease expired
+func expire(rec *TxRecord, reason string) {
+	logger.Warningf("Lease of cross transaction [%s] expired with votes from %d of its %d participants: %s",
+		rec.CrossTxID, len(rec.Votes), len(rec.Channels), reason)
+	rec.Decision = cross.Outcome_FAILURE
+	rec.Reason = reason
+}
+
+// ResendPending broadcasts again the confirmations of the decided transactions
+// which are not yet committed on every participant channel
+func (c *Coordinator) ResendPending() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, rec := range c.txs {
+		if rec.Decided() {
+			c.broadcastConfirmations(rec)
+		}
+	}
+}
+
+func (c *Coordinator) broadcastConfirmations(rec *TxRecord) {
+	for _, ch := range rec.Channels {
+		if rec.Confirmed[ch] {
+			continue
+		}
+		c.broadcastConfirmation(rec, ch)
+	}
+}
+
+func (c *Coordinator) broadcastConfirmation(rec *TxRecord, channelID string) {
+	conf := &cross.Confirmation{
+		CrossTxId: rec.CrossTxID,
+		Channels:  rec.Channels,
+		Outcome:   rec.Decision,
+		Reason:    rec.Reason,
+	}
+	env, err := utils.CreateSignedConfirmation(channelID, c.signer, conf)
+	if err != nil {
+		logger.Errorf("[%s] Failed creating confirmation of cross transaction [%s]: %s", channelID, rec.CrossTxID, err)
+		return
+	}
+	if err := c.broadcaster.Broadcast(env); err != nil {
+		logger.Warningf("[%s] Failed broadcasting confirmation of cross transaction [%s], will retry: %s", channelID, rec.CrossTxID, err)
+	}
+}
+
+func containsRecord(recs []*TxRecord, rec *TxRecord) bool {
+	for _, r := range recs {
+		if r.CrossTxID == rec.CrossTxID {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(channels []string, channelID string) bool {
+	for _, ch := range channels {
+		if ch == channelID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,407 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscoord
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBroadcaster struct {
+	err  error
+	envs []*cb.Envelope
+}
+
+func (mb *mockBroadcaster) Broadcast(env *cb.Envelope) error {
+	if mb.err != nil {
+		return mb.err
+	}
+	mb.envs = append(mb.envs, env)
+	return nil
+}
+
+// confirmations returns the channel and the outcome of the broadcast confirmations, and resets them
+func (mb *mockBroadcaster) confirmations(t *testing.T) map[string]cross.Outcome {
+	res := make(map[string]cross.Outcome)
+	for _, env := range mb.envs {
+		chdr, err := utils.ChannelHeader(env)
+		assert.NoError(t, err)
+		conf, err := utils.GetConfirmation(env)
+		assert.NoError(t, err)
+		res[chdr.ChannelId] = conf.Outcome
+	}
+	mb.envs = nil
+	return res
+}
+
+type testChain struct {
+	channelID string
+	height    uint64
+}
+
+func (tc *testChain) nextBlock(envs []*cb.Envelope, codes ...pb.TxValidationCode) *cb.Block {
+	block := cb.NewBlock(tc.height, nil)
+	tc.height++
+	flags := ledgerUtil.NewTxValidationFlagsSetValue(len(envs), pb.TxValidationCode_VALID)
+	for i, env := range envs {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(env))
+		if i < len(codes) {
+			flags.SetFlag(i, codes[i])
+		}
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+	return block
+}
+
+func crossTx(channelID, crossTxID string, channels ...string) *cb.Envelope {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, channelID, 0)
+	chdr.TxId = crossTxID
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+		}),
+		CrossInfo: utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: crossTxID, Channels: channels}),
+	}
+}
+
+var testTime = time.Date(2018, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestCoordinator(t *testing.T, dir string, broadcaster Broadcaster) (*Coordinator, DecisionLog) {
+	log := NewDecisionLog(dir)
+	c, err := New(log, mockcrypto.FakeLocalSigner, broadcaster)
+	assert.NoError(t, err)
+	c.now = func() time.Time { return testTime }
+	return c, log
+}
+
+func TestCoordinatorCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// a local transaction and the first vote do not lead to any decision
+	local := crossTx("ch1", "local")
+	local.CrossInfo = nil
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{local, crossTx("ch1", "tx1", "ch1", "ch2")})))
+	assert.Empty(t, mb.envs)
+	pending := c.Pending()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, &TxRecord{
+		CrossTxID: "tx1",
+		Channels:  []string{"ch1", "ch2"},
+		Votes:     map[string]bool{"ch1": true},
+		Prepared:  map[string]*Preparation{"ch1": {Block: 0, Time: testTime}},
+		Confirmed: map[string]bool{},
+	}, pending[0])
+
+	// the last vote decides the outcome, which is confirmed to every participant
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch2", "ch1")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_SUCCESS, "ch2": cross.Outcome_SUCCESS}, mb.confirmations(t))
+	assert.Equal(t, cross.Outcome_SUCCESS, c.Pending()[0].Decision)
+
+	// the transaction is completed once its confirmation is committed on every participant
+	conf1, _ := utils.CreateSignedConfirmation("ch1", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_SUCCESS})
+	conf2, _ := utils.CreateSignedConfirmation("ch2", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_SUCCESS})
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{conf1}, pb.TxValidationCode_VALID)))
+	assert.Len(t, c.Pending(), 1)
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{conf2}, pb.TxValidationCode_VALID)))
+	assert.Empty(t, c.Pending())
+	recs, err := log.TxRecords()
+	assert.NoError(t, err)
+	assert.Empty(t, recs)
+
+	height, err := c.Height("ch1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+}
+
+func TestCoordinatorAbort(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")}, pb.TxValidationCode_MVCC_READ_CONFLICT)))
+	assert.Empty(t, mb.envs)
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch1", "ch2")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_FAILURE, "ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	rec := c.Pending()[0]
+	assert.Equal(t, cross.Outcome_FAILURE, rec.Decision)
+	assert.Equal(t, "invalidated on channels ch1", rec.Reason)
+
+	// a late vote does not change the decision
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch1", "ch2")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	assert.Equal(t, rec, c.Pending()[0])
+}
+
+func TestCoordinatorInvalidConfirmation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	c.SetLease("ch1", Lease{Blocks: 1})
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// tx1 expires on ch1 before being prepared on ch2
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")})))
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock(nil)))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_FAILURE, "ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+
+	// the confirmation invalidated on ch2 does not count, nor does a confirmation of another outcome
+	conf1, _ := utils.CreateSignedConfirmation("ch1", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE})
+	conf2, _ := utils.CreateSignedConfirmation("ch2", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE})
+	success2, _ := utils.CreateSignedConfirmation("ch2", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_SUCCESS})
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{conf1})))
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{conf2, success2}, pb.TxValidationCode_INVALID_OTHER_REASON)))
+	assert.Equal(t, map[string]bool{"ch1": true}, c.Pending()[0].Confirmed)
+
+	// the late prepare on ch2 is confirmed again, and the transaction completes once its confirmation is committed
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch1", "ch2")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{conf2})))
+	assert.Empty(t, c.Pending())
+
+	// a confirmation invalidated as a duplicate finds the transaction completed on the channel
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx2", "ch1")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_SUCCESS}, mb.confirmations(t))
+	dup, _ := utils.CreateSignedConfirmation("ch1", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx2", Outcome: cross.Outcome_SUCCESS})
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{dup}, pb.TxValidationCode_DUPLICATE_CONFIRMATION)))
+	assert.Empty(t, c.Pending())
+
+	// confirmations are only read from validated blocks
+	block := cb.NewBlock(ch1.height, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(conf1)}
+	err = c.ProcessBlock("ch1", block)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "carries no validation flags")
+}
+
+func TestCoordinatorRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{err: fmt.Errorf("orderer unavailable")}
+	c, log := newTestCoordinator(t, dir, mb)
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// the decision is logged even though the confirmations cannot be broadcast
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")})))
+	block := ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch1", "ch2")})
+	assert.NoError(t, c.ProcessBlock("ch2", block))
+	assert.Empty(t, mb.envs)
+
+	// the coordinator crashes and recovers its decision from the log
+	log.Close()
+	mb.err = nil
+	c, log = newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	assert.Len(t, c.Pending(), 1)
+	assert.Equal(t, cross.Outcome_SUCCESS, c.Pending()[0].Decision)
+
+	// blocks delivered again are skipped, and blocks must be processed in order
+	assert.NoError(t, c.ProcessBlock("ch2", block))
+	assert.Empty(t, mb.envs)
+	assert.Error(t, c.ProcessBlock("ch2", cb.NewBlock(5, nil)))
+
+	c.ResendPending()
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_SUCCESS, "ch2": cross.Outcome_SUCCESS}, mb.confirmations(t))
+
+	// a confirmation committed on a channel is not resent there
+	conf, _ := utils.CreateSignedConfirmation("ch1", mockcrypto.FakeLocalSigner, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_SUCCESS})
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{conf})))
+	c.ResendPending()
+	assert.Equal(t, map[string]cross.Outcome{"ch2": cross.Outcome_SUCCESS}, mb.confirmations(t))
+}
+
+func TestCoordinatorBlockLease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	c.SetLease("", Lease{Blocks: 2})
+	c.SetLease("ch2", Lease{})
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// tx1 is prepared on ch1 but never on ch2, tx2 is prepared on ch2 but never on ch1
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")})))
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx2", "ch1", "ch2")})))
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock(nil)))
+	assert.Empty(t, mb.envs)
+
+	// the lease of tx1 expires with the second block committed on ch1 after its preparation
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock(nil)))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_FAILURE, "ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	pending := c.Pending()
+	assert.Len(t, pending, 2)
+	assert.Equal(t, cross.Outcome_FAILURE, pending[0].Decision)
+	assert.Equal(t, "lease of 2 blocks expired on channel ch1", pending[0].Reason)
+
+	// the transactions prepared on ch2 do not expire
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock(nil)))
+	}
+	assert.Empty(t, mb.envs)
+	assert.False(t, c.Pending()[1].Decided())
+
+	// the decision survives a restart
+	log.Close()
+	c, log = newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	assert.Equal(t, pending[0], c.Pending()[0])
+}
+
+func TestCoordinatorTimeLease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	c.SetLease("ch1", Lease{Time: time.Minute})
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")})))
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx2", "ch1", "ch2")})))
+	assert.NoError(t, c.ExpireLeases())
+	assert.Empty(t, mb.envs)
+
+	c.now = func() time.Time { return testTime.Add(time.Minute) }
+	assert.NoError(t, c.ExpireLeases())
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_FAILURE, "ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	pending := c.Pending()
+	assert.Equal(t, "lease of 1m0s expired on channel ch1", pending[0].Reason)
+	assert.False(t, pending[1].Decided())
+
+	// an expired transaction is not expired again, and the heights are left unchanged
+	assert.NoError(t, c.ExpireLeases())
+	assert.Empty(t, mb.envs)
+	height, err := c.Height("ch1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+	recs, err := log.TxRecords()
+	assert.NoError(t, err)
+	assert.Equal(t, cross.Outcome_FAILURE, recs[0].Decision)
+
+	// a late vote does not change the decision, which is confirmed again to the channel
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx1", "ch1", "ch2")})))
+	assert.Equal(t, map[string]cross.Outcome{"ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	assert.Equal(t, pending[0], c.Pending()[0])
+}
+
+func TestCoordinatorUnvalidatedBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c, log := newTestCoordinator(t, dir, &mockBroadcaster{})
+	defer log.Close()
+
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(crossTx("ch1", "tx1", "ch1", "ch2"))}
+	err = c.ProcessBlock("ch1", block)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "carries no validation flags")
+	height, err := c.Height("ch1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), height)
+}
+
+func TestCoordinatorDeadlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// tx1 is prepared on ch1 and tx2 on ch2, each one holding the keys the other one accesses on the other channel
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2")})))
+	c.now = func() time.Time { return testTime.Add(time.Second) }
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx2", "ch1", "ch2")})))
+
+	// waits without a cycle, or involving unknown transactions, do not abort any transaction
+	assert.NoError(t, c.ReportWaits("ch2", []*cross.CrossLockWait{{CrossTxId: "tx1", Holders: []string{"tx2"}}}))
+	assert.NoError(t, c.ReportWaits("ch1", []*cross.CrossLockWait{
+		{CrossTxId: "tx3", Holders: []string{"tx1"}},
+		{CrossTxId: "tx1", Holders: []string{"tx1"}},
+	}))
+	assert.Empty(t, mb.envs)
+
+	// tx2, prepared last, is aborted to break the cycle
+	assert.NoError(t, c.ReportWaits("ch1", []*cross.CrossLockWait{{CrossTxId: "tx2", Holders: []string{"tx1"}}}))
+	assert.Equal(t, map[string]cross.Outcome{"ch1": cross.Outcome_FAILURE, "ch2": cross.Outcome_FAILURE}, mb.confirmations(t))
+	pending := c.Pending()
+	assert.False(t, pending[0].Decided())
+	assert.Equal(t, cross.Outcome_FAILURE, pending[1].Decision)
+	assert.Equal(t, "deadlock with cross transactions tx1", pending[1].Reason)
+	recs, err := log.TxRecords()
+	assert.NoError(t, err)
+	assert.Equal(t, cross.Outcome_FAILURE, recs[1].Decision)
+	height, err := c.Height("ch1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	// the waits of a decided transaction are ignored
+	assert.NoError(t, c.ReportWaits("ch1", []*cross.CrossLockWait{{CrossTxId: "tx2", Holders: []string{"tx1"}}}))
+	assert.Empty(t, mb.envs)
+	assert.False(t, c.Pending()[0].Decided())
+}
+
+func TestCoordinatorDeadlockVictims(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crosscoord")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mb := &mockBroadcaster{}
+	c, log := newTestCoordinator(t, dir, mb)
+	defer log.Close()
+	ch1, ch2 := &testChain{channelID: "ch1"}, &testChain{channelID: "ch2"}
+
+	// two cycles on the same channels, among transactions prepared at the same time
+	assert.NoError(t, c.ProcessBlock("ch1", ch1.nextBlock([]*cb.Envelope{crossTx("ch1", "tx1", "ch1", "ch2"), crossTx("ch1", "tx3", "ch1", "ch2")})))
+	assert.NoError(t, c.ProcessBlock("ch2", ch2.nextBlock([]*cb.Envelope{crossTx("ch2", "tx2", "ch1", "ch2"), crossTx("ch2", "tx4", "ch1", "ch2")})))
+	assert.NoError(t, c.ReportWaits("ch2", []*cross.CrossLockWait{
+		{CrossTxId: "tx1", Holders: []string{"tx2"}},
+		{CrossTxId: "tx3", Holders: []string{"tx4"}},
+	}))
+	assert.NoError(t, c.ReportWaits("ch1", []*cross.CrossLockWait{
+		{CrossTxId: "tx2", Holders: []string{"tx1"}},
+		{CrossTxId: "tx4", Holders: []string{"tx3"}},
+	}))
+	assert.Len(t, mb.envs, 4)
+	mb.envs = nil
+	var aborted []string
+	for _, rec := range c.Pending() {
+		if rec.Decided() {
+			aborted = append(aborted, rec.CrossTxID)
+		}
+	}
+	// the transaction with the greatest ID is aborted in each cycle
+	assert.Equal(t, []string{"tx2", "tx4"}, aborted)
+}
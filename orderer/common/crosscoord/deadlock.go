@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscoord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+)
+
+// ReportWaits replaces the lock waits reported by the endorsers of the given channel, which make the edges of
+// the wait-for graph of the coordinator: a cross transaction whose proposals access keys held by other cross
+// transactions waits for them. A transaction prepared on one channel and waiting on another for a transaction
+// which waits for it in turn never gets the votes it needs, and neither does the other one. Each cycle of the
+// graph among the undecided transactions is broken by deciding the failure of a victim, the transaction of the
+// cycle prepared last, ties broken by the greatest ID, whose confirmations are broadcast as for any failure.
+func (c *Coordinator) ReportWaits(channelID string, waits []*cross.CrossLockWait) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	edges := make(map[string][]string)
+	for _, w := range waits {
+		for _, holder := range w.Holders {
+			if holder != w.CrossTxId {
+				edges[w.CrossTxId] = append(edges[w.CrossTxId], holder)
+			}
+		}
+	}
+	c.waits[channelID] = edges
+
+	var victims []*TxRecord
+	graph := c.waitForGraph()
+	for cycle := findCycle(graph); cycle != nil; cycle = findCycle(graph) {
+		victim := c.deadlockVictim(cycle)
+		rec := c.txs[victim].clone()
+		var others []string
+		for _, crossTxID := range cycle {
+			if crossTxID != victim {
+				others = append(others, crossTxID)
+			}
+		}
+		sort.Strings(others)
+		logger.Warningf("Cross transaction [%s] is deadlocked with cross transactions %v, aborting it", victim, others)
+		rec.Decision = cross.Outcome_FAILURE
+		rec.Reason = fmt.Sprintf("deadlock with cross transactions %s", strings.Join(others, ","))
+		victims = append(victims, rec)
+		delete(graph, victim)
+	}
+	if len(victims) == 0 {
+		return nil
+	}
+	return c.commitDecisions(victims)
+}
+
+// waitForGraph returns the edges reported on every channel between the undecided transactions of the coordinator,
+// as sorted lists of the transactions each transaction waits for. The caller is expected to hold the lock.
+func (c *Coordinator) waitForGraph() map[string][]string {
+	undecided := func(crossTxID string) bool {
+		rec, ok := c.txs[crossTxID]
+		return ok && !rec.Decided()
+	}
+	sets := make(map[string]map[string]struct{})
+	for _, edges := range c.waits {
+		for waiter, holders := range edges {
+			if !undecided(waiter) {
+				continue
+			}
+			for _, holder := range holders {
+				if !undecided(holder) {
+					continue
+				}
+				if sets[waiter] == nil {
+					sets[waiter] = make(map[string]struct{})
+				}
+				sets[waiter][holder] = struct{}{}
+			}
+		}
+	}
+	graph := make(map[string][]string, len(sets))
+	for waiter, holders := range sets {
+		for holder := range holders {
+			graph[waiter] = append(graph[waiter], holder)
+		}
+		sort.Strings(graph[waiter])
+	}
+	return graph
+}
+
+// findCycle returns the transactions of the first cycle found in the given graph, walking the transactions
+// in the order of their IDs, or nil if the graph has none. Edges to transactions removed from the graph are
+// ignored.
+func findCycle(graph map[string][]string) []string {
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(node string) []string
+	visit = func(node string) []string {
+		state[node] = onPath
+		path = append(path, node)
+		for _, next := range graph[node] {
+			if _, ok := graph[next]; !ok {
+				continue
+			}
+			switch state[next] {
+			case onPath:
+				for i, n := range path {
+					if n == next {
+						return append([]string(nil), path[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = done
+		return nil
+	}
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			if cycle := visit(node); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// deadlockVictim returns the transaction of the cycle prepared last on any of its channels, the one with the
+// greatest ID among the ones prepared last. The caller is expected to hold the lock.
+func (c *Coordinator) deadlockVictim(cycle []string) string {
+	var victim string
+	var victimRec *TxRecord
+	for _, crossTxID := range cycle {
+		rec := c.txs[crossTxID]
+		if victimRec == nil {
+			victim, victimRec = crossTxID, rec
+			continue
+		}
+		last, victimLast := rec.lastPrepared(), victimRec.lastPrepared()
+		if last.After(victimLast) || (last.Equal(victimLast) && crossTxID > victim) {
+			victim, victimRec = crossTxID, rec
+		}
+	}
+	return victim
+}
+
+// lastPrepared returns the time the coordinator processed the last preparation of the transaction
+func (rec *TxRecord) lastPrepared() time.Time {
+	var last time.Time
+	for _, prep := range rec.Prepared {
+		if prep.Time.After(last) {
+			last = prep.Time
+		}
+	}
+	return last
+}
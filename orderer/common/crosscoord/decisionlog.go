@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crosscoord
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var (
+	txKeyPrefix     = []byte("tx~")
+	txKeyEnd        = []byte("tx\x7f")
+	heightKeyPrefix = []byte("height~")
+)
+
+// TxRecord is the state of a global cross transaction kept in the decision log
+type TxRecord struct {
+	CrossTxID string   `json:"crossTxID"`
+	Channels  []string `json:"channels"`
+	// Votes holds the prepare outcome of the participant channels, true if the
+	// transaction was committed as valid on the channel
+	Votes map[string]bool `json:"votes,omitempty"`
+	// Prepared holds when the transaction was prepared on the participant channels which voted
+	Prepared map[string]*Preparation `json:"prepared,omitempty"`
+	// Decision is the outcome decided by the coordinator, UNKNOWN until every participant voted
+	Decision cross.Outcome `json:"decision,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+	// Confirmed holds the participant channels on which the confirmation was committed
+	Confirmed map[string]bool `json:"confirmed,omitempty"`
+}
+
+// Preparation records the block of a channel in which a transaction was prepared,
+// and the time the coordinator processed it
+type Preparation struct {
+	Block uint64    `json:"block"`
+	Time  time.Time `json:"time"`
+}
+
+// Decided returns true if the coordinator decided the outcome of the transaction
+func (rec *TxRecord) Decided() bool {
+	return rec.Decision != cross.Outcome_UNKNOWN
+}
+
+func (rec *TxRecord) clone() *TxRecord {
+	c := *rec
+	c.Channels = append([]string(nil), rec.Channels...)
+	c.Votes = make(map[string]bool, len(rec.Votes))
+	for ch, v := range rec.Votes {
+		c.Votes[ch] = v
+	}
+	c.Prepared = make(map[string]*Preparation, len(rec.Prepared))
+	for ch, p := range rec.Prepared {
+		prep := *p
+		c.Prepared[ch] = &prep
+	}
+	c.Confirmed = make(map[string]bool, len(rec.Confirmed))
+	for ch, v := range rec.Confirmed {
+		c.Confirmed[ch] = v
+	}
+	return &c
+}
+
+// DecisionLog durably records the state of the coordinator
+type DecisionLog interface {
+	// Commit atomically persists the given records, removes the records of the given
+	// transactions, and sets the height up to which the blocks of the channel were processed,
+	// unless the channel is empty. The changes must be durable when Commit returns.
+	Commit(channelID string, height uint64, puts []*TxRecord, deletes []string) error
+	// TxRecords returns all the records of the log
+	TxRecords() ([]*TxRecord, error)
+	// Height returns the height up to which the blocks of the channel were processed
+	Height(channelID string) (uint64, error)
+	// Close releases the resources of the log
+	Close()
+}
+
+type levelDBDecisionLog struct {
+	db *leveldbhelper.DB
+}
+
+// NewDecisionLog opens the decision log stored in the given directory
+func NewDecisionLog(dir string) DecisionLog {
+	db := leveldbhelper.CreateDB(&leveldbhelper.Conf{DBPath: dir})
+	db.Open()
+	return &levelDBDecisionLog{db: db}
+}
+
+func (l *levelDBDecisionLog) Commit(channelID string, height uint64, puts []*TxRecord, deletes []string) error {
+	batch := &leveldb.Batch{}
+	for _, rec := range puts {
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal record of cross transaction [%s]", rec.CrossTxID)
+		}
+		batch.Put(txKey(rec.CrossTxID), b)
+	}
+	for _, crossTxID := range deletes {
+		batch.Delete(txKey(crossTxID))
+	}
+	if channelID != "" {
+		heightBytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(heightBytes, height)
+		batch.Put(heightKey(channelID), heightBytes)
+	}
+	return errors.Wrap(l.db.WriteBatch(batch, true), "failed to write to the decision log")
+}
+
+func (l *levelDBDecisionLog) TxRecords() ([]*TxRecord, error) {
+	itr := l.db.GetIterator(txKeyPrefix, txKeyEnd)
+	defer itr.Release()
+	var recs []*TxRecord
+	for itr.Next() {
+		rec := &TxRecord{}
+		if err := json.Unmarshal(itr.Value(), rec); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal cross transaction record")
+		}
+		recs = append(recs, rec)
+	}
+	return recs, errors.Wrap(itr.Error(), "failed to read the decision log")
+}
+
+func (l *levelDBDecisionLog) Height(channelID string) (uint64, error) {
+	b, err := l.db.Get(heightKey(channelID))
+	if err != nil || b == nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func (l *levelDBDecisionLog) Close() {
+	l.db.Close()
+}
+
+func txKey(crossTxID string) []byte {
+	return append(append([]byte{}, txKeyPrefix...), crossTxID...)
+}
+
+func heightKey(channelID string) []byte {
+	return append(append([]byte{}, heightKeyPrefix...), channelID...)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"bytes"
+
+	"github.com/hyperledger/fabric/common/policies"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// ErrMalformedConfirmation is returned for confirmation envelopes which do not carry
+// a well formed outcome of a cross transaction
+var ErrMalformedConfirmation = errors.New("malformed confirmation")
+
+// CrossInfoFilter authorizes envelopes according to their cross information.
+// Confirmations of cross transactions may roll back the state of the peers, so
+// they must be well formed, and both the envelope and the confirmation must be signed
+// by an identity satisfying the cross coordinators policy, while any other envelope is
+// checked against the writers policy.
+type CrossInfoFilter struct {
+	writers      *SigFilter
+	coordinators *SigFilter
+}
+
+// NewCrossInfoFilter creates a new cross information filter
+func NewCrossInfoFilter(support SigFilterSupport) *CrossInfoFilter {
+	return &CrossInfoFilter{
+		writers:      NewSigFilter(policies.ChannelWriters, support),
+		coordinators: NewSigFilter(policies.ChannelCrossCoordinators, support),
+	}
+}
+
+// Apply rejects envelopes with an unknown cross information, cross transactions naming
+// another transaction, malformed confirmations, and envelopes which do not satisfy the
+// policy that applies to them
+func (cf *CrossInfoFilter) Apply(message *cb.Envelope) error {
+	info, err := utils.UnmarshalCrossInfo(message.CrossInfo)
+	if err != nil {
+		return errors.WithMessage(err, "invalid cross info")
+	}
+	if info.Mode != cross.CrossMode_CONFIRMATION && info.CrossTxId != "" {
+		chdr, err := utils.ChannelHeader(message)
+		if err != nil {
+			return errors.WithMessage(err, "invalid cross transaction")
+		}
+		if err := utils.CheckCrossTxID(chdr.TxId, info); err != nil {
+			return errors.WithMessage(err, "invalid cross info")
+		}
+	}
+	if info.Mode != cross.CrossMode_CONFIRMATION {
+		return cf.writers.Apply(message)
+	}
+	conf, err := checkConfirmation(message, info)
+	if err != nil {
+		return err
+	}
+	if err := cf.coordinators.Apply(message); err != nil {
+		return err
+	}
+	return cf.checkCoordinatorSignature(conf)
+}
+
+// checkCoordinatorSignature checks the signature of a confirmation by its coordinator against the cross
+// coordinators policy. A confirmation which is not signed by its coordinator is rejected.
+func (cf *CrossInfoFilter) checkCoordinatorSignature(conf *cross.Confirmation) error {
+	signedData, err := utils.ConfirmationAsSignedData(conf)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(ErrPermissionDenied), err.Error())
+	}
+	policy, ok := cf.coordinators.support.PolicyManager().GetPolicy(cf.coordinators.policyName)
+	if !ok {
+		return errors.Errorf("could not find policy %s", cf.coordinators.policyName)
+	}
+	if err := policy.Evaluate(signedData); err != nil {
+		return errors.Wrap(errors.WithStack(ErrPermissionDenied), "invalid signature of the coordinator: "+err.Error())
+	}
+	return nil
+}
+
+// checkConfirmation checks that a confirmation is a signed endorser transaction whose data is the
+// outcome of the cross transaction named in its cross info, and whose txid is derived from it. It returns the
+// confirmation carried by the envelope.
+func checkConfirmation(message *cb.Envelope, info *cross.CrossInfo) (*cross.Confirmation, error) {
+	payload, err := utils.UnmarshalPayload(message.Payload)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, err.Error())
+	}
+	if payload.Header == nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, "missing header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, err.Error())
+	}
+	if chdr.Type != int32(cb.HeaderType_ENDORSER_TRANSACTION) {
+		return nil, errors.Wrapf(ErrMalformedConfirmation, "header type %s is not an endorser transaction", cb.HeaderType(chdr.Type))
+	}
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, err.Error())
+	}
+	if len(shdr.Creator) == 0 || len(message.Signature) == 0 {
+		return nil, errors.Wrap(errors.WithStack(ErrPermissionDenied), "confirmation is not signed")
+	}
+	conf, err := utils.UnmarshalConfirmation(payload.Data)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, err.Error())
+	}
+	if info.CrossTxId != "" && info.CrossTxId != conf.CrossTxId {
+		return nil, errors.Wrapf(ErrMalformedConfirmation, "cross info names cross transaction [%s] but confirmation is for [%s]", info.CrossTxId, conf.CrossTxId)
+	}
+	if err := utils.CheckConfirmationTxID(chdr.TxId, conf); err != nil {
+		return nil, errors.Wrap(ErrMalformedConfirmation, err.Error())
+	}
+	if len(conf.Coordinator) != 0 && !bytes.Equal(conf.Coordinator, shdr.Creator) {
+		return nil, errors.Wrap(errors.WithStack(ErrPermissionDenied), "confirmation is not signed by its coordinator")
+	}
+	return conf, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeConfirmation(headerType cb.HeaderType, creator []byte, data string, signature []byte) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader:   utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(headerType), ChannelId: testChannelID}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator}),
+			},
+			Data: []byte(data),
+		}),
+		Signature: signature,
+		CrossInfo: []byte("confirmation"),
+	}
+}
+
+func makeValidConfirmation() *cb.Envelope {
+	conf := &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE, Coordinator: []byte("coordinator"),
+		CoordinatorSignature: []byte("signature")}
+	return makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), string(utils.MarshalOrPanic(conf)), []byte("signature"))
+}
+
+func newCrossFilterSupport(writersErr, coordinatorsErr error) *mockchannelconfig.Resources {
+	return &mockchannelconfig.Resources{
+		PolicyManagerVal: &mockpolicies.Manager{
+			PolicyMap: map[string]policies.Policy{
+				policies.ChannelWriters:           &mockpolicies.Policy{Err: writersErr},
+				policies.ChannelCrossCoordinators: &mockpolicies.Policy{Err: coordinatorsErr},
+			},
+		},
+	}
+}
+
+func TestCrossInfoFilterNormalMsg(t *testing.T) {
+	for _, crossInfo := range [][]byte{
+		nil,
+		[]byte("local"),
+		[]byte("singleCross"),
+		[]byte("multiCross"),
+		utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, Channels: []string{testChannelID}}),
+	} {
+		env := makeEnvelope()
+		env.CrossInfo = crossInfo
+		assert.NoError(t, NewCrossInfoFilter(newCrossFilterSupport(nil, fmt.Errorf("not a coordinator"))).Apply(env))
+
+		err := NewCrossInfoFilter(newCrossFilterSupport(fmt.Errorf("not a writer"), nil)).Apply(env)
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
+	}
+}
+
+func TestCrossInfoFilterCrossTxID(t *testing.T) {
+	env := makeEnvelope()
+	payload := utils.UnmarshalPayloadOrPanic(env.Payload)
+	payload.Header.ChannelHeader = utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_ENDORSER_TRANSACTION), ChannelId: testChannelID, TxId: "tx1"})
+	env.Payload = utils.MarshalOrPanic(payload)
+	support := newCrossFilterSupport(nil, nil)
+
+	env.CrossInfo = utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "tx1"})
+	assert.NoError(t, NewCrossInfoFilter(support).Apply(env))
+
+	// the ID of a cross transaction is its txid
+	env.CrossInfo = utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_SINGLE_CROSS, CrossTxId: "global"})
+	err := NewCrossInfoFilter(support).Apply(env)
+	assert.Error(t, err)
+	assert.Regexp(t, "invalid cross info", err.Error())
+}
+
+func TestCrossInfoFilterUnknown(t *testing.T) {
+	env := makeValidConfirmation()
+	env.CrossInfo = []byte("txid_fail")
+	err := NewCrossInfoFilter(newCrossFilterSupport(nil, nil)).Apply(env)
+	assert.Error(t, err)
+	assert.Regexp(t, "invalid cross info", err.Error())
+}
+
+func TestCrossInfoFilterConfirmation(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		err := NewCrossInfoFilter(newCrossFilterSupport(fmt.Errorf("not a writer"), nil)).Apply(makeValidConfirmation())
+		assert.NoError(t, err)
+	})
+
+	t.Run("ValidTyped", func(t *testing.T) {
+		conf := &cross.Confirmation{CrossTxId: "tx_1", Outcome: cross.Outcome_FAILURE, Coordinator: []byte("coordinator"),
+			CoordinatorSignature: []byte("signature")}
+		env := makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), string(utils.MarshalOrPanic(conf)), []byte("signature"))
+		env.CrossInfo = utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_CONFIRMATION, CrossTxId: "tx_1"})
+		assert.NoError(t, NewCrossInfoFilter(newCrossFilterSupport(nil, nil)).Apply(env))
+	})
+
+	t.Run("ValidCreated", func(t *testing.T) {
+		conf := &cross.Confirmation{CrossTxId: "tx_1", Channels: []string{testChannelID}, Outcome: cross.Outcome_SUCCESS}
+		env, err := utils.CreateSignedConfirmation(testChannelID, mockcrypto.FakeLocalSigner, conf)
+		assert.NoError(t, err)
+		assert.NoError(t, NewCrossInfoFilter(newCrossFilterSupport(nil, nil)).Apply(env))
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		err := NewCrossInfoFilter(newCrossFilterSupport(nil, fmt.Errorf("not a coordinator"))).Apply(makeValidConfirmation())
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
+	})
+
+	t.Run("MissingPolicy", func(t *testing.T) {
+		mpm := &mockchannelconfig.Resources{
+			PolicyManagerVal: &mockpolicies.Manager{
+				PolicyMap: map[string]policies.Policy{policies.ChannelWriters: &mockpolicies.Policy{}},
+			},
+		}
+		err := NewCrossInfoFilter(mpm).Apply(makeValidConfirmation())
+		assert.Error(t, err)
+		assert.Regexp(t, "could not find policy", err.Error())
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		support := newCrossFilterSupport(nil, nil)
+		env := makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), "tx1_fail", nil)
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(NewCrossInfoFilter(support).Apply(env)))
+
+		env = makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, nil, "tx1_fail", []byte("signature"))
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(NewCrossInfoFilter(support).Apply(env)))
+
+		// a legacy confirmation carries no signature of its coordinator
+		env = makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), "tx1_fail", []byte("signature"))
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(NewCrossInfoFilter(support).Apply(env)))
+
+		// the envelope must be signed by the coordinator named in the confirmation
+		conf := &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE, Coordinator: []byte("coordinator")}
+		env = makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("someone else"), string(utils.MarshalOrPanic(conf)), []byte("signature"))
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(NewCrossInfoFilter(support).Apply(env)))
+	})
+
+	t.Run("CoordinatorSignature", func(t *testing.T) {
+		policy := &signaturePolicy{}
+		support := &mockchannelconfig.Resources{
+			PolicyManagerVal: &mockpolicies.Manager{
+				PolicyMap: map[string]policies.Policy{policies.ChannelWriters: &mockpolicies.Policy{}, policies.ChannelCrossCoordinators: policy},
+			},
+		}
+		for name, conf := range map[string]*cross.Confirmation{
+			"Unsigned":        {CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE},
+			"Missing":         {CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE, Coordinator: []byte("coordinator")},
+			"NoCoordinator":   {CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE, CoordinatorSignature: []byte("signature")},
+			"NotACoordinator": {CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE, Coordinator: []byte("coordinator"), CoordinatorSignature: []byte("forged")},
+		} {
+			creator := conf.Coordinator
+			if creator == nil {
+				creator = []byte("coordinator")
+			}
+			env := makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, creator, string(utils.MarshalOrPanic(conf)), []byte("signature"))
+			err := NewCrossInfoFilter(support).Apply(env)
+			assert.Equal(t, ErrPermissionDenied, errors.Cause(err), "%s should be rejected", name)
+		}
+
+		// the policy is evaluated against the signature of the confirmation by its coordinator
+		conf := &cross.Confirmation{CrossTxId: "tx_1", Channels: []string{testChannelID}, Outcome: cross.Outcome_SUCCESS}
+		env, err := utils.CreateSignedConfirmation(testChannelID, mockcrypto.FakeLocalSigner, conf)
+		assert.NoError(t, err)
+		policy.evaluated = nil
+		assert.NoError(t, NewCrossInfoFilter(support).Apply(env))
+		signed, err := utils.GetConfirmation(env)
+		assert.NoError(t, err)
+		expected, err := utils.ConfirmationAsSignedData(signed)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, policy.evaluated[1])
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		support := newCrossFilterSupport(nil, nil)
+		mismatched := makeValidConfirmation()
+		mismatched.CrossInfo = utils.MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_CONFIRMATION, CrossTxId: "tx2"})
+		// the txid of a confirmation is derived from its cross transaction
+		otherTxID := makeValidConfirmation()
+		payload := utils.UnmarshalPayloadOrPanic(otherTxID.Payload)
+		payload.Header.ChannelHeader = utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
+			ChannelId: testChannelID,
+			TxId:      utils.ComputeConfirmationTxID("tx2"),
+		})
+		otherTxID.Payload = utils.MarshalOrPanic(payload)
+		for name, env := range map[string]*cb.Envelope{
+			"BadPayload":    {Payload: []byte("garbage"), Signature: []byte("signature"), CrossInfo: []byte("confirmation")},
+			"MissingHeader": {Payload: utils.MarshalOrPanic(&cb.Payload{Data: []byte("tx1_fail")}), Signature: []byte("signature"), CrossInfo: []byte("confirmation")},
+			"ConfigUpdate":  makeConfirmation(cb.HeaderType_CONFIG_UPDATE, []byte("coordinator"), "tx1_fail", []byte("signature")),
+			"NoTxID":        makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), "_fail", []byte("signature")),
+			"NoOutcome":     makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), "tx1", []byte("signature")),
+			"BadOutcome":    makeConfirmation(cb.HeaderType_ENDORSER_TRANSACTION, []byte("coordinator"), "tx1_abort", []byte("signature")),
+			"OtherTx":       mismatched,
+			"OtherTxID":     otherTxID,
+		} {
+			err := NewCrossInfoFilter(support).Apply(env)
+			assert.Equal(t, ErrMalformedConfirmation, errors.Cause(err), "%s should be rejected as malformed", name)
+		}
+	})
+}
+
+func TestProcessNormalMsgConfirmation(t *testing.T) {
+	ms := &mockSystemChannelFilterSupport{SequenceVal: 7}
+	filters := NewRuleSet([]Rule{EmptyRejectRule, NewCrossInfoFilter(newCrossFilterSupport(nil, fmt.Errorf("not a coordinator")))})
+
+	_, err := NewStandardChannel(ms, filters).ProcessNormalMsg(makeValidConfirmation())
+	assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
+
+	env := makeValidConfirmation()
+	env.Payload = nil
+	_, err = NewStandardChannel(ms, filters).ProcessNormalMsg(env)
+	assert.Equal(t, ErrEmptyMessage, errors.Cause(err))
+}
+
+// signaturePolicy records the signed data it evaluates and rejects the forged signatures
+type signaturePolicy struct {
+	evaluated [][]*cb.SignedData
+}
+
+func (p *signaturePolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	p.evaluated = append(p.evaluated, signatureSet)
+	for _, sd := range signatureSet {
+		if string(sd.Signature) == "forged" {
+			return fmt.Errorf("invalid signature")
+		}
+	}
+	return nil
+}
@@ -7,10 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msgprocessor
 
 import (
-	"fmt"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/crypto"
-	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -55,7 +53,7 @@ func CreateStandardChannelFilters(filterSupport channelconfig.Resources) *RuleSe
 		EmptyRejectRule,
 		NewExpirationRejectRule(filterSupport),
 		NewSizeFilter(ordererConfig),
-		NewSigFilter(policies.ChannelWriters, filterSupport),
+		NewCrossInfoFilter(filterSupport),
 	})
 }
 
@@ -78,18 +76,8 @@ func (s *StandardChannel) ClassifyMsg(chdr *cb.ChannelHeader) Classification {
 // ProcessNormalMsg will check the validity of a message based on the current configuration.  It returns the current
 // configuration sequence number and nil on success, or an error if the message is not valid
 func (s *StandardChannel) ProcessNormalMsg(env *cb.Envelope) (configSeq uint64, err error) {
-	fmt.Println("orderer/common/msgprocessor/standardchannel.go ProcessNormalMsg()")
 	configSeq = s.support.Sequence()
-	fmt.Println("ProcessNormalMsg标记1")
-	//NEW add
-	crossmsg := string(env.CrossInfo)
-	if crossmsg != "local" && crossmsg != "singleCross" && crossmsg != "multiCross" && crossmsg != ""{
-		fmt.Println("ProcessNormalMsg标记2 confirmation")
-		err = nil
-		return
-	}//NEW end
 	err = s.filters.Apply(env)
-	fmt.Println("ProcessNormalMsg标记2")
 	return
 }
 
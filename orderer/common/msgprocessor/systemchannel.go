@@ -12,7 +12,6 @@ import (
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/crypto"
-	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 
@@ -31,25 +30,6 @@ type SystemChannel struct {
 	templator ChannelConfigTemplator
 }
 
-//NEW add
-func GetChannelIDFromEnvelope(msg *cb.Envelope) (string, error){
-	crossmsg := string(msg.CrossInfo)
-	var channelID string
-	if crossmsg != "local" && crossmsg != "singleCross" && crossmsg != "multiCross" && crossmsg != "" { //confirmation
-		payload, err := utils.UnmarshalPayload(msg.Payload)
-		if err != nil {
-			panic(fmt.Errorf("ord/commo/mulch/regs...go  Error unmarshaling data to envelope: %s", err))
-		}
-		channelID = string(payload.Data)
-		return channelID, nil
-	}
-	channelID, err := utils.ChannelID(msg)
-	if err != nil {
-		return "", err
-	}
-	return channelID, nil
-}
-
 // NewSystemChannel creates a new system channel message processor.
 func NewSystemChannel(support StandardChannelSupport, templator ChannelConfigTemplator, filters *RuleSet) *SystemChannel {
 	logger.Debugf("Creating system channel msg processor for channel %s", support.ChainID())
@@ -69,7 +49,7 @@ func CreateSystemChannelFilters(chainCreator ChainCreator, ledgerResources chann
 		EmptyRejectRule,
 		NewExpirationRejectRule(ledgerResources),
 		NewSizeFilter(ordererConfig),
-		NewSigFilter(policies.ChannelWriters, ledgerResources),
+		NewCrossInfoFilter(ledgerResources),
 		NewSystemChannelFilter(ledgerResources, chainCreator),
 	})
 }
@@ -77,9 +57,7 @@ func CreateSystemChannelFilters(chainCreator ChainCreator, ledgerResources chann
 // ProcessNormalMsg handles normal messages, rejecting them if they are not bound for the system channel ID
 // with ErrChannelDoesNotExist.
 func (s *SystemChannel) ProcessNormalMsg(msg *cb.Envelope) (configSeq uint64, err error) {
-	fmt.Println("orderer/common/msgprocessor/systemchannel.go ProcessNormalMsg()")
-	channelID, err := utils.ChannelID(msg)  //原
-//	channelID, err := GetChannelIDFromEnvelope(msg)
+	channelID, err := utils.ChannelID(msg)
 	if err != nil {
 		return 0, err
 	}
@@ -212,7 +212,6 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 			panic(fmt.Errorf("ord/commo/mulch/regs...go  Error unmarshaling data to envelope: %s", err))
 		}
 		channelID = string(payload.Data)
-		fmt.Println("orderer/common/multichannel/registrar.go BroadcastChannelSupport() channelID = ", channelID)
 		isConfig := false
 		cs, ok := r.chains[channelID]
 		if !ok {
@@ -220,26 +219,22 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 		}
 		return &cb.ChannelHeader{ChannelId: channelID}, isConfig, cs, nil
 	} //NEW end*/
-	fmt.Println("orderer/common/multichannel/registrar.go BroadcastChannelSupport() 开始 取chdr")
 		chdr, err := utils.ChannelHeader(msg)
 		if err != nil {
 			return nil, false, nil, fmt.Errorf("could not determine channel ID: %s", err)
 		}
-	fmt.Println("orderer/common/multichannel/registrar.go BroadcastChannelSupport() 开始 取chdr结束 chid=", string(chdr.ChannelId))
 		cs, ok := r.chains[chdr.ChannelId]
 		if !ok {
 			cs = r.systemChannel
 		}
 
 		isConfig := false
-		if string(msg.CrossInfo) != "confirmation" { // NEW add line
-			switch cs.ClassifyMsg(chdr) {
-			case msgprocessor.ConfigUpdateMsg:
-				isConfig = true
-			case msgprocessor.ConfigMsg:
-				return chdr, false, nil, errors.New("message is of type that cannot be processed directly")
-			default:
-			}
+		switch cs.ClassifyMsg(chdr) {
+		case msgprocessor.ConfigUpdateMsg:
+			isConfig = true
+		case msgprocessor.ConfigMsg:
+			return chdr, false, nil, errors.New("message is of type that cannot be processed directly")
+		default:
 		}
 
 
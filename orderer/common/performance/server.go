@@ -7,7 +7,6 @@ SPDX-License-Identifier: Apache-2.0
 package performance
 
 import (
-	"fmt"
 	"io"
 	"sync"
 
@@ -110,7 +109,6 @@ func (server *BenchmarkServer) RegisterService(s ab.AtomicBroadcastServer) {
 // CreateBroadcastClient creates a broadcast client of this server
 func (server *BenchmarkServer) CreateBroadcastClient() *BroadcastClient {
 	client := &BroadcastClient{
-		requestMsgChan: make(chan *cb.CrossOverMsg), //NEW add
 		requestChan:  make(chan *cb.Envelope),
 		responseChan: make(chan *ab.BroadcastResponse),
 		errChan:      make(chan error),
@@ -128,8 +126,6 @@ type BroadcastClient struct {
 	requestChan  chan *cb.Envelope
 	responseChan chan *ab.BroadcastResponse
 	errChan      chan error
-
-	requestMsgChan chan *cb.CrossOverMsg // NEW add
 }
 
 func (BroadcastClient) Context() context.Context {
@@ -169,17 +165,6 @@ func (bc *BroadcastClient) Recv() (*cb.Envelope, error) {
 	if !ok {
 		return msg, io.EOF
 	}
-	fmt.Println("orderer/common/performance/server.go (BroadcastClient)Recv() msg= ", msg)
-	return msg, nil
-}
-
-//NEW add
-// Recv implements AtomicBroadcast_BroadcastServer interface
-func (bc *BroadcastClient) RecvM() (*cb.CrossOverMsg, error) {
-	msg, ok := <-bc.requestMsgChan
-	if !ok {
-		return msg, io.EOF
-	}
 	return msg, nil
 }
 
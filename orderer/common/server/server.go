@@ -111,7 +111,6 @@ type broadcastMsgTracer struct {
 
 func (bmt *broadcastMsgTracer) Recv() (*cb.Envelope, error) {
 	msg, err := bmt.AtomicBroadcast_BroadcastServer.Recv()
-	fmt.Println("orderer/common/server/server.go (bmt *broadcastMsgTracer)Recv() msg= (envelope)", msg)
 	if traceDir := bmt.debug.BroadcastTraceDir; traceDir != "" {
 		bmt.trace(bmt.debug.BroadcastTraceDir, msg, err)
 	}
@@ -133,16 +132,13 @@ func (dmt *deliverMsgTracer) Recv() (*cb.Envelope, error) {
 
 // Broadcast receives a stream of messages from a client for ordering
 func (s *server) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
-	fmt.Println("orderer/common/server/server.go Broadcast()")
 	logger.Debugf("Starting new Broadcast handler")
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("orderer/common/server/server.go Broadcast() defer.recover")
 			logger.Criticalf("Broadcast client triggered panic: %s\n%s", r, debug.Stack())
 		}
 		logger.Debugf("Closing Broadcast stream")
 	}()
-	fmt.Println("orderer/common/server/server.go Broadcast() 标记")
 	return s.bh.Handle(&broadcastMsgTracer{
 		AtomicBroadcast_BroadcastServer: srv,
 		msgTracer: msgTracer{
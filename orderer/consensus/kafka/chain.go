@@ -547,8 +547,19 @@ func (chain *chainImpl) processRegular(regularMessage *ab.KafkaMessageRegular, r
 	//   should be the same as current `lastOriginalOffsetProcessed`
 	// - if the message is re-validated and re-ordered, this value should be the `OriginalOffset` of that
 	//   Kafka message, so that `lastOriginalOffsetProcessed` is advanced
+	//
+	// NEW add: confirmations are batched apart from the other messages, as the solo consenter does, while
+	// cross transactions are batched as regular messages. The decision depends on the messages only, so that
+	// the same blocks are cut when the messages are replayed from Kafka after a restart.
 	commitNormalMsg := func(message *cb.Envelope, newOffset int64) {
-		batches, pending := chain.BlockCutter().Ordered(message)
+		var batches [][]*cb.Envelope
+		var pending bool
+		if utils.IsConfirmation(message) {
+			logger.Debugf("[channel: %s] Batching confirmation apart from regular messages", chain.ChainID())
+			batches, pending = chain.BlockCutter().OrderedCrosschain(message)
+		} else {
+			batches, pending = chain.BlockCutter().Ordered(message)
+		} //NEW end
 		logger.Debugf("[channel: %s] Ordering results: items in batch = %d, pending = %v", chain.ChainID(), len(batches), pending)
 		if len(batches) == 0 {
 			// If no block is cut, we update the `lastOriginalOffsetProcessed`, start the timer if necessary and return
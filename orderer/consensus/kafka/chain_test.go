@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -1861,6 +1862,129 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 				assert.Equal(t, uint64(1), counts[indexRecvPass], "Expected 2 message received and unmarshaled")
 				assert.Equal(t, uint64(1), counts[indexProcessRegularPass], "Expected 1 REGULAR message processed")
 			})
+
+			// This test sends a normal tx, a cross tx, two confirmations and a normal tx. The cross tx
+			// should share a block with the normal tx, the confirmations should be batched apart from
+			// the other messages, and replaying the same messages, as a restarted orderer does, should
+			// cut the very same blocks.
+			t.Run("ReceiveCrossTxAndConfirmationsAndBatchApart", func(t *testing.T) {
+				if testing.Short() {
+					t.Skip("Skipping test in short mode")
+				}
+
+				normalEnv := newMockEnvelope("fooMessage")
+				crossEnv := newMockEnvelope("crossMessage")
+				crossEnv.CrossInfo = []byte("multiCross")
+				confEnv1 := newMockEnvelope("crossMessage1_succ")
+				confEnv1.CrossInfo = []byte("confirmation")
+				confEnv2 := newMockEnvelope("crossMessage2_fail")
+				confEnv2.CrossInfo = []byte("confirmation")
+
+				processCrossMessages := func() ([]*cb.Block, []int64) {
+					errorChan := make(chan struct{})
+					close(errorChan)
+					haltChan := make(chan struct{})
+
+					lastCutBlockNumber := uint64(3)
+
+					mockSupport := &mockmultichannel.ConsenterSupport{
+						Blocks:         make(chan *cb.Block), // WriteBlock will post here
+						BlockCutterVal: mockblockcutter.NewReceiver(),
+						ChainIDVal:     mockChannel.topic(),
+						HeightVal:      lastCutBlockNumber, // Incremented during the WriteBlock call
+						SharedConfigVal: &mockconfig.Orderer{
+							BatchTimeoutVal: longTimeout,
+							CapabilitiesVal: &mockconfig.OrdererCapabilities{
+								ResubmissionVal: true,
+							},
+						},
+						SequenceVal: uint64(0),
+					}
+					defer close(mockSupport.BlockCutterVal.Block)
+
+					bareMinimumChain := &chainImpl{
+						parentConsumer:  mockParentConsumer,
+						channelConsumer: mockChannelConsumer,
+
+						channel:                     mockChannel,
+						ConsenterSupport:            mockSupport,
+						lastCutBlockNumber:          lastCutBlockNumber,
+						lastOriginalOffsetProcessed: lastOriginalOffsetProcessed,
+
+						errorChan:                      errorChan,
+						haltChan:                       haltChan,
+						doneProcessingMessagesToBlocks: make(chan struct{}),
+					}
+
+					var counts []uint64
+					done := make(chan struct{})
+
+					go func() {
+						counts, err = bareMinimumChain.processMessagesToBlocks()
+						done <- struct{}{}
+					}()
+
+					receiveBlock := func() *cb.Block {
+						select {
+						case block := <-mockSupport.Blocks: // Let the `mockConsenterSupport.WriteBlock` proceed
+							return block
+						case <-time.After(shortTimeout):
+							logger.Fatalf("Did not receive a block from the blockcutter as expected")
+						}
+						return nil
+					}
+
+					var blocks []*cb.Block
+					var offsets []int64
+
+					yield := func(env *cb.Envelope) {
+						offsets = append(offsets, mpc.HighWaterMarkOffset())
+						mpc.YieldMessage(newMockConsumerMessage(newNormalMessage(utils.MarshalOrPanic(env), uint64(0), int64(0))))
+						mockSupport.BlockCutterVal.Block <- struct{}{} // Let the `mockblockcutter` call return
+					}
+
+					// The cross message is enqueued along with the normal message
+					yield(normalEnv)
+					yield(crossEnv)
+
+					// The first confirmation cuts the pending batch, the second one is batched along
+					yield(confEnv1)
+					blocks = append(blocks, receiveBlock())
+					yield(confEnv2)
+
+					// The normal message cuts the batch of confirmations
+					yield(normalEnv)
+					blocks = append(blocks, receiveBlock())
+
+					logger.Debug("Closing haltChan to exit the infinite for-loop")
+					close(haltChan) // Identical to chain.Halt()
+					logger.Debug("haltChan closed")
+					<-done
+
+					assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+					assert.Equal(t, uint64(5), counts[indexRecvPass], "Expected 5 messages received and unmarshaled")
+					assert.Equal(t, uint64(5), counts[indexProcessRegularPass], "Expected 5 REGULAR messages processed")
+					assert.Equal(t, lastCutBlockNumber+2, bareMinimumChain.lastCutBlockNumber, "Expected lastCutBlockNumber to be bumped up by two")
+					assert.Equal(t, []*cb.Envelope{normalEnv}, mockSupport.BlockCutterVal.CurBatch, "Expected the last normal message pending in the blockcutter")
+					return blocks, offsets
+				}
+
+				blocks, offsets := processCrossMessages()
+				require.Len(t, blocks, 2)
+				assert.Equal(t, [][]byte{utils.MarshalOrPanic(normalEnv), utils.MarshalOrPanic(crossEnv)}, blocks[0].Data.Data,
+					"Expected the cross message to share a block with the normal message")
+				assert.Equal(t, [][]byte{utils.MarshalOrPanic(confEnv1), utils.MarshalOrPanic(confEnv2)}, blocks[1].Data.Data,
+					"Expected the confirmations to be batched together")
+				// Each block persists the offset of its last message
+				assert.Equal(t, offsets[1], extractEncodedOffset(blocks[0].GetMetadata().Metadata[cb.BlockMetadataIndex_ORDERER]), "Expected encoded offset in block 0 to be %d", offsets[1])
+				assert.Equal(t, offsets[3], extractEncodedOffset(blocks[1].GetMetadata().Metadata[cb.BlockMetadataIndex_ORDERER]), "Expected encoded offset in block 1 to be %d", offsets[3])
+
+				replayedBlocks, _ := processCrossMessages()
+				require.Len(t, replayedBlocks, 2)
+				for i := range blocks {
+					assert.Equal(t, blocks[i].Data.Data, replayedBlocks[i].Data.Data, "Expected the replay to cut the same block %d", i)
+				}
+			})
 		})
 
 		// This ensures regular kafka messages of type CONFIG are handled properly
@@ -3375,6 +3499,11 @@ func (r *mockReceiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelop
 	return args.Get(0).([][]*cb.Envelope), args.Bool(1)
 }
 
+func (r *mockReceiver) OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
+	args := r.Called(msg)
+	return args.Get(0).([][]*cb.Envelope), args.Bool(1)
+}
+
 func (r *mockReceiver) Cut() []*cb.Envelope {
 	args := r.Called()
 	return args.Get(0).([]*cb.Envelope)
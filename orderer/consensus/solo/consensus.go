@@ -23,6 +23,8 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 )
 
@@ -131,21 +133,18 @@ func (ch *chain) main() {
 				if msg.configSeq < seq {
 					_, err = ch.support.ProcessNormalMsg(msg.normalMsg)
 					if err != nil {
-						fmt.Println("orderer/consensus/solo/consensus.go main().ProcessNormalMsg err!=nil")
 						logger.Warningf("Discarding bad normal message: %s", err)
 						continue
 					}
 				}
 				//batches, _ := ch.support.BlockCutter().Ordered(msg.normalMsg) //原版
 				//New add update  上为原版
-				if string(msg.normalMsg.CrossInfo) == "singleCross" || string(msg.normalMsg.CrossInfo) == "multiCross" { //NEW add
-					fmt.Println("orderer/consensus/solo/consensus.go main() 🥇进OrderedCrosschain CrossInfo=", msg.normalMsg.CrossInfo)
-					batches, _ = ch.support.BlockCutter().OrderedCrosschain(msg.normalMsg)
-				} else if string(msg.normalMsg.CrossInfo) == "confirmation"{ //confiramtion
-					fmt.Println("orderer/consensus/solo/consensus.go main() 🥇进Ordered (confirmation) CrossInfo=", msg.normalMsg.CrossInfo)
+				mode, _ := utils.GetCrossMode(msg.normalMsg) //NEW add
+				// cross transactions are batched as regular messages, confirmations apart from them
+				if mode == cross.CrossMode_CONFIRMATION { //confiramtion
+					logger.Debugf("Ordering confirmation on channel %s", ch.support.ChainID())
 					batches, _ = ch.support.BlockCutter().OrderedCrosschain(msg.normalMsg)
 				}else {
-					fmt.Println("orderer/consensus/solo/consensus.go main() 🥇进Ordered CrossInfo=", msg.normalMsg.CrossInfo)
 					//添加消息到缓存交易消息列表，并按出块规则切割成批量交易集合列表
 					batches, _ = ch.support.BlockCutter().Ordered(msg.normalMsg)
 				} //NEW end
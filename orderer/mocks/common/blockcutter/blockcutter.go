@@ -19,6 +19,7 @@ package blockcutter
 import (
 	"github.com/hyperledger/fabric/common/flogging"
 	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 )
 
@@ -49,9 +50,31 @@ type Receiver struct {
 	Block chan struct{}
 }
 
-//NEW add
-func (mbc *Receiver) OrderedCrosschain(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
-	return nil, nil
+// NEW add
+// OrderedCrosschain cuts the current batch if it holds regular messages, as the real receiver does, and
+// appends the confirmation to the batch, which is cut when CutNext is set. It blocks reading from Block on return.
+func (mbc *Receiver) OrderedCrosschain(env *cb.Envelope) ([][]*cb.Envelope, bool) {
+	defer func() {
+		<-mbc.Block
+	}()
+
+	var res [][]*cb.Envelope
+	if len(mbc.CurBatch) > 0 && !utils.IsConfirmation(mbc.CurBatch[0]) {
+		logger.Debugf("Receiver: Cutting regular batch ahead of confirmation")
+		res = append(res, mbc.CurBatch)
+		mbc.CurBatch = nil
+	}
+	mbc.CurBatch = append(mbc.CurBatch, env)
+
+	if mbc.CutNext {
+		logger.Debugf("Receiver: Returning confirmation batch")
+		res = append(res, mbc.CurBatch)
+		mbc.CurBatch = nil
+		return res, false
+	}
+
+	logger.Debugf("Appending confirmation to batch")
+	return res, true
 }
 
 // NewReceiver returns the mock blockcutter.Receiver implementation
@@ -70,6 +93,13 @@ func (mbc *Receiver) Ordered(env *cb.Envelope) ([][]*cb.Envelope, bool) {
 		<-mbc.Block
 	}()
 
+	if len(mbc.CurBatch) > 0 && utils.IsConfirmation(mbc.CurBatch[0]) { //NEW add
+		logger.Debugf("Receiver: Returning confirmation batch and appending newest env")
+		res := [][]*cb.Envelope{mbc.CurBatch}
+		mbc.CurBatch = []*cb.Envelope{env}
+		return res, true
+	} //NEW end
+
 	if mbc.IsolatedTx {
 		logger.Debugf("Receiver: Returning dual batch")
 		res := [][]*cb.Envelope{mbc.CurBatch, {env}}
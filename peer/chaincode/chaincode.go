@@ -68,6 +68,9 @@ var (
 	connectionProfile     string
 	waitForEvent          bool
 	waitForEventTimeout   time.Duration
+	crossMode             string   // NEW add
+	crossTxID             string   // NEW add
+	crossChannels         []string // NEW add
 )
 
 var chaincodeCmd = &cobra.Command{
@@ -123,6 +126,14 @@ func resetFlags() {
 		fmt.Sprint("Whether to wait for the event from each peer's deliver filtered service signifying that the 'invoke' transaction has been committed successfully"))
 	flags.DurationVar(&waitForEventTimeout, "waitForEventTimeout", 30*time.Second,
 		fmt.Sprint("Time to wait for the event from each peer's deliver filtered service signifying that the 'invoke' transaction has been committed successfully"))
+	//NEW add
+	flags.StringVar(&crossMode, "crossMode", crossModeLocal,
+		fmt.Sprint("The cross-chain mode of the transaction: local, single or multi. The keys accessed by a single or multi cross transaction stay locked until its confirmation"))
+	flags.StringVar(&crossTxID, "crossTxID", "",
+		fmt.Sprint("A name of the cross transaction chosen by the client and given to its invocation on every participant channel, from which the nonce of the proposal is derived, so that the invocations share the transaction ID, which is the ID of the cross transaction. Defaults to a random nonce"))
+	flags.StringSliceVar(&crossChannels, "crossChannels", nil,
+		fmt.Sprint("The participant channels of a multi cross transaction, including the channel of the invocation, coordinated until each of them votes for the transaction"))
+	//NEW end
 }
 
 func attachFlags(cmd *cobra.Command, names []string) {
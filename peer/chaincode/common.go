@@ -446,8 +446,6 @@ func ChaincodeInvokeOrQuery(
 	deliverClients []api.PeerDeliverClient,
 	bc common.BroadcastClient,
 ) (*pb.ProposalResponse, error) {
-//NEW add print
-	fmt.Println("这里是fabric/peer/chaincode/common.go   ChaincodeInvokeOrQuery()")
 	// Build the ChaincodeInvocationSpec message
 	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
 
@@ -469,12 +467,26 @@ func ChaincodeInvokeOrQuery(
 		}
 	}
 
-	prop, txid, err := putils.CreateChaincodeProposalWithTxIDAndTransient(pcommon.HeaderType_ENDORSER_TRANSACTION, cID, invocation, creator, txID, tMap)
+	//NEW add
+	crossInfo, err := getCrossInfo(cID)
+	if err != nil {
+		return nil, err
+	}
+	var prop *pb.Proposal
+	var txid string
+	if crossInfo != nil && txID == "" {
+		prop, txid, err = createCrossProposal(cID, invocation, creator, tMap)
+	} else {
+		prop, txid, err = putils.CreateChaincodeProposalWithTxIDAndTransient(pcommon.HeaderType_ENDORSER_TRANSACTION, cID, invocation, creator, txID, tMap)
+	}
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("error creating proposal for %s", funcName))
 	}
-
-	signedProp, err := putils.GetSignedProposal(prop, signer)
+	if crossInfo != nil {
+		logger.Infof("Cross transaction ID: %s", txid)
+	}
+	//NEW end
+	signedProp, err := putils.GetSignedCrossProposal(prop, signer, crossInfo)
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("error creating signed proposal for %s", funcName))
 	}
@@ -501,9 +513,8 @@ func ChaincodeInvokeOrQuery(
 				return proposalResp, nil
 			}
 			// assemble a signed transaction (it's an Envelope message)
-			env, err := putils.CreateSignedTx(prop, signer, responses...)  //封装成envelope
+			env, err := putils.CreateSignedCrossTx(prop, signer, crossInfo, responses...)  //封装成envelope
 			if err != nil {
-				fmt.Println("peer/chaincode/common.go ChaincodeInvokeOrQuery() 检查proposalResponses&构建signedTx报error")
 				return proposalResp, errors.WithMessage(err, "could not assemble transaction")
 			}
 			var dg *deliverGroup
@@ -521,7 +532,6 @@ func ChaincodeInvokeOrQuery(
 				}
 			}
 
-			fmt.Println("peer/chaincode/common.go ChaincodeInvokeOrQuery  现在send the envelope for ordering")
 			// send the envelope for ordering
 			if err = bc.Send(env); err != nil {  //！！!这里send给orderer
 				return proposalResp, errors.WithMessage(err, fmt.Sprintf("error sending transaction for %s", funcName))
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric/common/util"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+const crossModeLocal = "local"
+
+// crossModes maps the values of the crossMode flag to their mode
+var crossModes = map[string]cross.CrossMode{
+	crossModeLocal: cross.CrossMode_LOCAL,
+	"single":       cross.CrossMode_SINGLE_CROSS,
+	"multi":        cross.CrossMode_MULTI_CROSS,
+}
+
+// getCrossInfo returns the cross info of a transaction on the given channel set by the crossMode and
+// crossChannels flags, or nil for a local transaction. The ID of a cross transaction is its txid.
+// A multi cross transaction spans the given channel and at least another one.
+func getCrossInfo(channelID string) (*cross.CrossInfo, error) {
+	mode, ok := crossModes[crossMode]
+	if !ok {
+		return nil, errors.Errorf("invalid cross mode '%s', expecting local, single or multi", crossMode)
+	}
+	if mode != cross.CrossMode_MULTI_CROSS {
+		if len(crossChannels) > 0 {
+			return nil, errors.New("the participant channels are given for a multi cross transaction only")
+		}
+		if mode == cross.CrossMode_LOCAL {
+			if crossTxID != "" {
+				return nil, errors.New("the name of a cross transaction is given for a cross transaction only")
+			}
+			return nil, nil
+		}
+		return &cross.CrossInfo{Mode: mode}, nil
+	}
+
+	channels := make(map[string]struct{})
+	for _, ch := range crossChannels {
+		if ch == "" {
+			return nil, errors.New("empty participant channel")
+		}
+		if _, ok := channels[ch]; ok {
+			return nil, errors.Errorf("participant channel %s given twice", ch)
+		}
+		channels[ch] = struct{}{}
+	}
+	if len(channels) < 2 {
+		return nil, errors.New("a multi cross transaction spans at least two participant channels, given by the crossChannels flag")
+	}
+	if _, ok := channels[channelID]; !ok {
+		return nil, errors.Errorf("the participant channels of the cross transaction do not include channel %s", channelID)
+	}
+	return &cross.CrossInfo{Mode: mode, Channels: crossChannels}, nil
+}
+
+// createCrossProposal creates the proposal of a cross transaction. Given the name of the cross transaction, the
+// nonce of the proposal is derived from it, so that the proposals of the cross transaction on its channels, made
+// by the same client, share the txid, which is the ID of the cross transaction.
+func createCrossProposal(cID string, invocation *pb.ChaincodeInvocationSpec, creator []byte, tMap map[string][]byte) (*pb.Proposal, string, error) {
+	if crossTxID == "" {
+		return putils.CreateChaincodeProposalWithTxIDAndTransient(pcommon.HeaderType_ENDORSER_TRANSACTION, cID, invocation, creator, "", tMap)
+	}
+	nonce := util.ComputeSHA256([]byte(crossTxID))
+	txid, err := putils.ComputeProposalTxID(nonce, creator)
+	if err != nil {
+		return nil, "", err
+	}
+	return putils.CreateChaincodeProposalWithTxIDNonceAndTransient(txid, pcommon.HeaderType_ENDORSER_TRANSACTION, cID, invocation, nonce, creator, tMap)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestInvokeCmdCrossMode(t *testing.T) {
+	defer resetFlags()
+	InitMSP()
+
+	mockCF, err := getMockChaincodeCmdFactory()
+	assert.NoError(t, err, "Error getting mock chaincode command factory")
+	endorser := &recordingEndorserClient{EndorserClient: mockCF.EndorserClients[0]}
+	broadcaster := &recordingBroadcastClient{BroadcastClient: mockCF.BroadcastClient}
+	mockCF.EndorserClients = []pb.EndorserClient{endorser}
+	mockCF.BroadcastClient = broadcaster
+
+	invoke := func(args ...string) error {
+		resetFlags()
+		cmd := invokeCmd(mockCF)
+		addFlags(cmd)
+		cmd.SetArgs(append([]string{"-n", "example02", "-c", "{\"Args\": [\"invoke\",\"a\",\"b\",\"10\"]}", "-C", "mychannel"}, args...))
+		return cmd.Execute()
+	}
+
+	// a local transaction carries no cross info
+	assert.NoError(t, invoke())
+	assert.Nil(t, endorser.signedProp.CrossMsgBytes)
+	assert.Nil(t, broadcaster.env.CrossInfo)
+
+	// the ID of a cross transaction is its txid
+	assert.NoError(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel,otherchannel"))
+	info, err := utils.UnmarshalCrossInfo(endorser.signedProp.CrossMsgBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, cross.CrossMode_MULTI_CROSS, info.Mode)
+	assert.Empty(t, info.CrossTxId)
+	assert.Equal(t, []string{"mychannel", "otherchannel"}, info.Channels)
+	assert.Equal(t, endorser.signedProp.CrossMsgBytes, broadcaster.env.CrossInfo)
+
+	// a multi cross transaction spans the channel of the invocation and at least another one
+	assert.Error(t, invoke("--crossMode", "multi"))
+	assert.Error(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel"))
+	assert.Error(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel,mychannel"))
+	assert.Error(t, invoke("--crossMode", "multi", "--crossChannels", "otherchannel,thirdchannel"))
+	assert.Error(t, invoke("--crossMode", "single", "--crossChannels", "mychannel,otherchannel"))
+
+	// the invocations of a cross transaction given its name share the txid
+	txID := func() string {
+		prop, err := utils.GetProposal(endorser.signedProp.ProposalBytes)
+		assert.NoError(t, err)
+		hdr, err := utils.GetHeader(prop.Header)
+		assert.NoError(t, err)
+		chdr, err := utils.UnmarshalChannelHeader(hdr.ChannelHeader)
+		assert.NoError(t, err)
+		return chdr.TxId
+	}
+	assert.NoError(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel,otherchannel", "--crossTxID", "xfer-42"))
+	first := txID()
+	assert.NoError(t, invoke("--crossMode", "multi", "--crossChannels", "otherchannel,mychannel", "--crossTxID", "xfer-42"))
+	assert.Equal(t, first, txID())
+	assert.NoError(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel,otherchannel", "--crossTxID", "xfer-43"))
+	assert.NotEqual(t, first, txID())
+	assert.NoError(t, invoke("--crossMode", "multi", "--crossChannels", "mychannel,otherchannel"))
+	assert.NotEqual(t, first, txID())
+
+	assert.NoError(t, invoke("--crossMode", "single"))
+	info, err = utils.UnmarshalCrossInfo(broadcaster.env.CrossInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, cross.CrossMode_SINGLE_CROSS, info.Mode)
+	assert.Empty(t, info.CrossTxId)
+
+	assert.Error(t, invoke("--crossMode", "confirmation"))
+	assert.Error(t, invoke("--crossTxID", "xfer-42"))
+}
+
+type recordingEndorserClient struct {
+	pb.EndorserClient
+	signedProp *pb.SignedProposal
+}
+
+func (r *recordingEndorserClient) ProcessProposal(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.ProposalResponse, error) {
+	r.signedProp = in
+	return r.EndorserClient.ProcessProposal(ctx, in, opts...)
+}
+
+type recordingBroadcastClient struct {
+	common.BroadcastClient
+	env *cb.Envelope
+}
+
+func (r *recordingBroadcastClient) Send(env *cb.Envelope) error {
+	r.env = env
+	return r.BroadcastClient.Send(env)
+}
@@ -35,6 +35,9 @@ func invokeCmd(cf *ChaincodeCmdFactory) *cobra.Command {
 		"connectionProfile",
 		"waitForEvent",
 		"waitForEventTimeout",
+		"crossMode",     // NEW add
+		"crossTxID",     // NEW add
+		"crossChannels", // NEW add
 	}
 	attachFlags(chaincodeInvokeCmd, flagList)
 
@@ -45,6 +48,11 @@ func chaincodeInvoke(cmd *cobra.Command, cf *ChaincodeCmdFactory) error {
 	if channelID == "" {
 		return errors.New("The required parameter 'channelID' is empty. Rerun the command with -C flag")
 	}
+	//NEW add
+	if _, err := getCrossInfo(channelID); err != nil {
+		return err
+	}
+	//NEW end
 	// Parsing of the command line is done so silence cmd usage
 	cmd.SilenceUsage = true
 
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clicross
+
+import (
+	"fmt"
+
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// outcomes maps the values of the outcome flag to their outcome
+var outcomes = map[string]crosspb.Outcome{
+	"success": crosspb.Outcome_SUCCESS,
+	"failure": crosspb.Outcome_FAILURE,
+}
+
+func confirmCmd(cf *CrossCmdFactory) *cobra.Command {
+	confirmCmd := &cobra.Command{
+		Use:   "confirm",
+		Short: "Confirm the outcome of a cross transaction.",
+		Long: "Confirm the outcome of a cross transaction on each of its channels, which releases the keys it holds, " +
+			"and rolls them back on failure. The confirmation is signed by the local identity as the coordinator, " +
+			"which must satisfy the cross coordinators policy of the channels. Requires '--crossTxID', '--channels' and '--outcome'.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return confirm(cmd, cf)
+		},
+	}
+	flagList := []string{
+		"crossTxID",
+		"channels",
+		"outcome",
+		"reason",
+	}
+	attachFlags(confirmCmd, flagList)
+
+	return confirmCmd
+}
+
+func confirm(cmd *cobra.Command, cf *CrossCmdFactory) error {
+	if crossTxID == "" {
+		return errors.New("Must supply the cross transaction ID")
+	}
+	if len(channels) == 0 {
+		return errors.New("Must supply the channels of the cross transaction")
+	}
+	o, ok := outcomes[outcome]
+	if !ok {
+		return errors.Errorf("invalid outcome '%s', expecting success or failure", outcome)
+	}
+	// Parsing of the command line is done so silence cmd usage
+	cmd.SilenceUsage = true
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(false, true)
+		if err != nil {
+			return err
+		}
+		defer cf.BroadcastClient.Close()
+	}
+
+	conf := &crosspb.Confirmation{CrossTxId: crossTxID, Channels: channels, Outcome: o, Reason: reason}
+	for _, ch := range channels {
+		env, err := utils.CreateSignedConfirmation(ch, cf.ConfirmationSigner, conf)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error creating the confirmation for channel %s", ch))
+		}
+		if err = cf.BroadcastClient.Send(env); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error sending the confirmation to channel %s", ch))
+		}
+		logger.Infof("Sent the confirmation of cross transaction [%s] to channel %s", crossTxID, ch)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clicross
+
+import (
+	"errors"
+	"testing"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingBroadcastClient struct {
+	envs []*cb.Envelope
+	err  error
+}
+
+func (r *recordingBroadcastClient) Send(env *cb.Envelope) error {
+	r.envs = append(r.envs, env)
+	return r.err
+}
+
+func (r *recordingBroadcastClient) Close() error {
+	return nil
+}
+
+func TestConfirm(t *testing.T) {
+	defer resetFlags()
+
+	bc := &recordingBroadcastClient{}
+	mockCF := &CrossCmdFactory{
+		ConfirmationSigner: &mockcrypto.LocalSigner{Identity: []byte("coordinator")},
+		BroadcastClient:    bc,
+	}
+	confirm := func(args ...string) error {
+		resetFlags()
+		cmd := confirmCmd(mockCF)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	assert.NoError(t, confirm("--crossTxID", "tx1", "--channels", "ch1,ch2", "--outcome", "failure", "--reason", "timeout"))
+	assert.Len(t, bc.envs, 2)
+	for i, ch := range []string{"ch1", "ch2"} {
+		assert.True(t, utils.IsConfirmation(bc.envs[i]))
+		chdr, err := utils.ChannelHeader(bc.envs[i])
+		assert.NoError(t, err)
+		assert.Equal(t, ch, chdr.ChannelId)
+		conf, err := utils.GetConfirmation(bc.envs[i])
+		assert.NoError(t, err)
+		assert.Equal(t, "tx1", conf.CrossTxId)
+		assert.Equal(t, []string{"ch1", "ch2"}, conf.Channels)
+		assert.Equal(t, crosspb.Outcome_FAILURE, conf.Outcome)
+		assert.Equal(t, "timeout", conf.Reason)
+		assert.Equal(t, []byte("coordinator"), conf.Coordinator)
+	}
+
+	assert.Error(t, confirm("--channels", "ch1", "--outcome", "success"))
+	assert.Error(t, confirm("--crossTxID", "tx1", "--outcome", "success"))
+	assert.Error(t, confirm("--crossTxID", "tx1", "--channels", "ch1"))
+	assert.Error(t, confirm("--crossTxID", "tx1", "--channels", "ch1", "--outcome", "aborted"))
+
+	bc.err = errors.New("service unavailable")
+	assert.Error(t, confirm("--crossTxID", "tx1", "--channels", "ch1", "--outcome", "success"))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clicross
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	crossFuncName = "cross"
+	crossCmdDes   = "Operate cross transactions: confirm|status."
+)
+
+var logger = flogging.MustGetLogger("cli/cross")
+
+// Cross-related variables.
+var (
+	channelID string
+	crossTxID string
+	channels  []string
+	outcome   string
+	reason    string
+)
+
+// Cmd returns the cobra command for Cross
+func Cmd(cf *CrossCmdFactory) *cobra.Command {
+	common.AddOrdererFlags(crossCmd)
+
+	crossCmd.AddCommand(confirmCmd(cf))
+	crossCmd.AddCommand(statusCmd(cf))
+
+	return crossCmd
+}
+
+var crossCmd = &cobra.Command{
+	Use:              crossFuncName,
+	Short:            fmt.Sprint(crossCmdDes),
+	Long:             fmt.Sprint(crossCmdDes),
+	PersistentPreRun: common.SetOrdererEnv,
+}
+
+var flags *pflag.FlagSet
+
+func init() {
+	resetFlags()
+}
+
+// Explicitly define a method to facilitate tests
+func resetFlags() {
+	flags = &pflag.FlagSet{}
+
+	flags.StringVarP(&channelID, "channelID", "C", "", "The channel on which this command should be executed")
+	flags.StringVar(&crossTxID, "crossTxID", "", "The global ID of the cross transaction")
+	flags.StringSliceVar(&channels, "channels", nil, "The channels taking part in the cross transaction, each of which gets the confirmation")
+	flags.StringVar(&outcome, "outcome", "", "The outcome of the cross transaction: success or failure")
+	flags.StringVar(&reason, "reason", "", "Why the outcome was decided, typically set on failure")
+}
+
+func attachFlags(cmd *cobra.Command, names []string) {
+	cmdFlags := cmd.Flags()
+	for _, name := range names {
+		if flag := flags.Lookup(name); flag != nil {
+			cmdFlags.AddFlag(flag)
+		} else {
+			logger.Fatalf("Could not find flag '%s' to attach to command '%s'", name, cmd.Name())
+		}
+	}
+}
+
+// CrossCmdFactory holds the clients used by CrossCmd
+type CrossCmdFactory struct {
+	EndorserClient pb.EndorserClient
+	Signer         msp.SigningIdentity
+	// ConfirmationSigner signs the confirmations as their coordinator
+	ConfirmationSigner crypto.LocalSigner
+	BroadcastClient    common.BroadcastClient
+}
+
+// InitCmdFactory init the CrossCmdFactory with clients to endorser and orderer according to params
+func InitCmdFactory(isEndorserRequired, isOrdererRequired bool) (*CrossCmdFactory, error) {
+	var err error
+	cf := &CrossCmdFactory{ConfirmationSigner: localmsp.NewSigner()}
+
+	cf.Signer, err = common.GetDefaultSignerFnc()
+	if err != nil {
+		return nil, errors.WithMessage(err, "error getting default signer")
+	}
+
+	if isEndorserRequired {
+		cf.EndorserClient, err = common.GetEndorserClientFnc(common.UndefinedParamValue, common.UndefinedParamValue)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error getting endorser client for cross")
+		}
+	}
+
+	if isOrdererRequired {
+		if len(strings.Split(common.OrderingEndpoint, ":")) != 2 {
+			return nil, errors.Errorf("ordering service endpoint %s is not valid or missing", common.OrderingEndpoint)
+		}
+		cf.BroadcastClient, err = common.GetBroadcastClientFnc()
+		if err != nil {
+			return nil, errors.WithMessage(err, "error getting broadcast client")
+		}
+	}
+	return cf, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clicross
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	cb "github.com/hyperledger/fabric/protos/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func statusCmd(cf *CrossCmdFactory) *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Get the status of a cross transaction on a channel.",
+		Long:  "Get the status of a cross transaction on a channel from the peer, along with the keys it holds while prepared. Requires '-C' and '--crossTxID'.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return status(cmd, cf)
+		},
+	}
+	flagList := []string{
+		"channelID",
+		"crossTxID",
+	}
+	attachFlags(statusCmd, flagList)
+
+	return statusCmd
+}
+
+func getCrossTxStatus(cf *CrossCmdFactory) (*crosspb.CrossTxStatus, error) {
+	invocation := &pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
+			ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+			Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(qscc.GetCrossTxStatus), []byte(channelID), []byte(crossTxID)}},
+		},
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot serialize the signer")
+	}
+	prop, _, err := utils.CreateProposalFromCIS(cb.HeaderType_ENDORSER_TRANSACTION, "", invocation, creator)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot create proposal")
+	}
+
+	signedProp, err := utils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot create signed proposal")
+	}
+
+	proposalResp, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed sending proposal")
+	}
+
+	if proposalResp.Response == nil || proposalResp.Response.Status != 200 {
+		return nil, errors.Errorf("received bad response, status %d: %s", proposalResp.Response.GetStatus(), proposalResp.Response.GetMessage())
+	}
+
+	status := &crosspb.CrossTxStatus{}
+	if err = proto.Unmarshal(proposalResp.Response.Payload, status); err != nil {
+		return nil, errors.Wrap(err, "cannot read qscc response")
+	}
+	return status, nil
+}
+
+func status(cmd *cobra.Command, cf *CrossCmdFactory) error {
+	if channelID == "" {
+		return errors.New("Must supply channel ID")
+	}
+	if crossTxID == "" {
+		return errors.New("Must supply the cross transaction ID")
+	}
+	// Parsing of the command line is done so silence cmd usage
+	cmd.SilenceUsage = true
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := getCrossTxStatus(cf)
+	if err != nil {
+		return err
+	}
+	jsonStr, err := (&jsonpb.Marshaler{}).MarshalToString(status)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal the cross transaction status")
+	}
+
+	fmt.Printf("Cross transaction status: %s\n", jsonStr)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clicross
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/hyperledger/fabric/peer/common"
+	crosspb "github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	defer resetFlags()
+	assert.NoError(t, msptesttools.LoadMSPSetupForTesting())
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err)
+
+	mockPayload, err := proto.Marshal(&crosspb.CrossTxStatus{CrossTxId: "tx1", State: crosspb.CrossTxState_PREPARED, PreparedBlock: 5})
+	assert.NoError(t, err)
+	mockCF := &CrossCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(&pb.ProposalResponse{Response: &pb.Response{Status: 200, Payload: mockPayload}}, nil),
+		Signer:         signer,
+	}
+	status := func(args ...string) error {
+		resetFlags()
+		cmd := statusCmd(mockCF)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	assert.NoError(t, status("-C", "ch1", "--crossTxID", "tx1"))
+	assert.Error(t, status("--crossTxID", "tx1"))
+	assert.Error(t, status("-C", "ch1"))
+
+	mockCF.EndorserClient = common.GetMockEndorserClient(&pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "no ledger"}}, nil)
+	assert.Error(t, status("-C", "ch1", "--crossTxID", "tx1"))
+}
@@ -0,0 +1,537 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/pkg/errors"
+)
+
+var logger = flogging.MustGetLogger("cross")
+
+var lockMgr = NewLockManager()
+
+// GetLockManager returns the lock manager shared by the endorser, the chaincode
+// handler and the committer of this peer
+func GetLockManager() *LockManager {
+	return lockMgr
+}
+
+// LockManager keeps track of the in-flight cross transactions of every channel,
+// the keys they hold locked and the original values of the keys they wrote.
+// Keys are scoped by channel and namespace, so the same key of two different
+// chaincodes (or of two different channels) never block each other. A key only
+// read by cross transactions is held in shared mode, and does not block other
+// readers, whereas a written key is held in exclusive mode.
+// All methods are safe for concurrent use.
+type LockManager struct {
+	lock     sync.RWMutex
+	channels map[string]*channelLocks
+}
+
+// channelLocks holds the lock table of a single channel
+type channelLocks struct {
+	txs   map[string]*crossTx
+	locks *LockTable
+	// store, when set, persists every change made to the lock table
+	store Store
+	// waiting holds the transactions waiting for the release of cross transactions
+	waiting *waitQueue
+	// waitsFor holds the conflicts reported for cross transactions, by ID
+	waitsFor map[string]*lockWait
+}
+
+// crossTx holds the state of a single in-flight cross transaction
+type crossTx struct {
+	// registered is the time the transaction was registered on this peer
+	registered time.Time
+	// prepared is true once the transaction was committed on the channel, in preparedBlock
+	prepared      bool
+	preparedBlock uint64
+	keys          map[statedb.CompositeKey]LockMode
+	// ranges holds the ranges of keys read by the transaction through range queries
+	ranges []RangeLock
+	// undo holds the original value of every key written by the transaction, in
+	// the order of the first write to the key
+	undo      []statedb.KeyOrigVal
+	undoIndex map[statedb.CompositeKey]struct{}
+}
+
+// NewLockManager constructs an empty LockManager
+func NewLockManager() *LockManager {
+	return &LockManager{channels: make(map[string]*channelLocks)}
+}
+
+func newChannelLocks() *channelLocks {
+	return &channelLocks{
+		txs:      make(map[string]*crossTx),
+		locks:    NewLockTable(),
+		waiting:  &waitQueue{},
+		waitsFor: make(map[string]*lockWait),
+	}
+}
+
+func newCrossTx() *crossTx {
+	return &crossTx{
+		keys:      make(map[statedb.CompositeKey]LockMode),
+		undoIndex: make(map[statedb.CompositeKey]struct{}),
+	}
+}
+
+// clone returns a copy of the transaction which can be updated without affecting the transaction
+func (tx *crossTx) clone() *crossTx {
+	c := newCrossTx()
+	c.registered, c.prepared, c.preparedBlock = tx.registered, tx.prepared, tx.preparedBlock
+	for ck, mode := range tx.keys {
+		c.keys[ck] = mode
+	}
+	for ck := range tx.undoIndex {
+		c.undoIndex[ck] = struct{}{}
+	}
+	c.undo = append(c.undo, tx.undo...)
+	c.ranges = append(c.ranges, tx.ranges...)
+	return c
+}
+
+// getChannel returns the lock table of the given channel. The caller is expected to hold the write lock
+// when create is true.
+func (m *LockManager) getChannel(channelID string, create bool) *channelLocks {
+	ch, ok := m.channels[channelID]
+	if !ok && create {
+		ch = newChannelLocks()
+		m.channels[channelID] = ch
+	}
+	return ch
+}
+
+// SetStore attaches a persistent store to the lock table of the given channel.
+// The in-memory lock table of the channel is replaced by the content of the store,
+// which is how the locks and the undo log of in-flight cross transactions are
+// recovered upon a peer restart.
+func (m *LockManager) SetStore(channelID string, store Store) error {
+	recs, err := store.GetTxRecords()
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("failed to load the cross lock table of channel [%s]", channelID))
+	}
+	ch := newChannelLocks()
+	ch.store = store
+	for _, rec := range recs {
+		tx := fromRecord(rec)
+		ch.txs[rec.TxID] = tx
+		for ck, mode := range tx.keys {
+			ch.locks.Grant(rec.TxID, ck, mode)
+		}
+		for _, r := range tx.ranges {
+			ch.locks.GrantRange(rec.TxID, r)
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if prev, ok := m.channels[channelID]; ok {
+		ch.waiting, ch.waitsFor = prev.waiting, prev.waitsFor
+	}
+	m.channels[channelID] = ch
+	if len(recs) > 0 {
+		logger.Infof("[%s] Recovered %d in-flight cross transaction(s) holding %d key(s)", channelID, len(ch.txs), ch.locks.Len())
+	}
+	return nil
+}
+
+// persist writes the state of the given transaction to the store of the channel, if any.
+// The caller is expected to hold the write lock.
+func (ch *channelLocks) persist(txID string, tx *crossTx) error {
+	if ch.store == nil {
+		return nil
+	}
+	return ch.store.PutTxRecord(tx.toRecord(txID))
+}
+
+// RegisterTx marks the given transaction as a cross transaction on the given channel.
+// Registering an already registered transaction is a no-op.
+func (m *LockManager) RegisterTx(channelID, txID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ch := m.getChannel(channelID, true)
+	if _, ok := ch.txs[txID]; ok {
+		return nil
+	}
+	tx := newCrossTx()
+	tx.registered = time.Now()
+	if err := ch.persist(txID, tx); err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("failed to register cross transaction [%s]", txID))
+	}
+	ch.txs[txID] = tx
+	logger.Debugf("[%s] Registered cross transaction [%s]", channelID, txID)
+	return nil
+}
+
+// IsCrossTx returns true if the given transaction is a registered cross transaction
+func (m *LockManager) IsCrossTx(channelID, txID string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return false
+	}
+	_, ok := ch.txs[txID]
+	return ok
+}
+
+// IsLocked returns true if the given key is held by a cross transaction in a mode which does not allow
+// accessing the key in the given mode, that is if the key is to be written and is held in any mode, or
+// if the key is to be read and is held in exclusive mode
+func (m *LockManager) IsLocked(channelID, ns, key string, mode LockMode) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return false
+	}
+	_, locked := ch.locks.Conflicts("", statedb.CompositeKey{Namespace: ns, Key: key}, mode)
+	return locked
+}
+
+// CheckKey reports whether the given transaction is a cross transaction and whether the given key is held
+// by a cross transaction other than the given one in a mode which does not allow accessing it in the given mode
+func (m *LockManager) CheckKey(channelID, txID, ns, key string, mode LockMode) (isCross bool, isLocked bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return false, false
+	}
+	_, isCross = ch.txs[txID]
+	_, isLocked = ch.locks.Conflicts(txID, statedb.CompositeKey{Namespace: ns, Key: key}, mode)
+	return isCross, isLocked
+}
+
+// CheckRange returns true if a key of the given range is held in exclusive mode by a cross transaction
+// other than the given one
+func (m *LockManager) CheckRange(channelID, txID string, r RangeLock) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return false
+	}
+	_, isLocked := ch.locks.ConflictsRange(txID, r)
+	return isLocked
+}
+
+// LockRead locks a key read by the given cross transaction in shared mode, unless the transaction already
+// holds it. It returns false if the transaction is not registered or if the key is held by another
+// transaction in exclusive mode.
+func (m *LockManager) LockRead(channelID, txID, ns, key string) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ck := statedb.CompositeKey{Namespace: ns, Key: key}
+	ch, tx, ok := m.acquire(channelID, txID, ck, SharedLock)
+	if !ok {
+		return false, nil
+	}
+	if _, held := tx.keys[ck]; held {
+		return true, nil
+	}
+	tx.keys[ck] = SharedLock
+	if err := ch.persist(txID, tx); err != nil {
+		delete(tx.keys, ck)
+		return false, errors.WithMessage(err, fmt.Sprintf("failed to lock key [%s:%s] for cross transaction [%s]", ns, key, txID))
+	}
+	ch.locks.Grant(txID, ck, SharedLock)
+	return true, nil
+}
+
+// LockWrite locks a key written by the given cross transaction in exclusive mode and records
+// the original value of the key so that it can be restored should the transaction fail.
+// Only the value seen by the first write of the transaction is recorded. A key the transaction
+// holds in shared mode is upgraded, provided no other transaction holds it. It returns false
+// if the transaction is not registered or if the key is held by another transaction.
+func (m *LockManager) LockWrite(channelID, txID, ns, key string, origVal []byte) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ck := statedb.CompositeKey{Namespace: ns, Key: key}
+	ch, tx, ok := m.acquire(channelID, txID, ck, ExclusiveLock)
+	if !ok {
+		return false, nil
+	}
+	prevMode, held := tx.keys[ck]
+	_, recorded := tx.undoIndex[ck]
+	if held && prevMode == ExclusiveLock && recorded {
+		return true, nil
+	}
+	tx.keys[ck] = ExclusiveLock
+	if !recorded {
+		tx.undoIndex[ck] = struct{}{}
+		tx.undo = append(tx.undo, statedb.KeyOrigVal{Namespace: ns, Key: key, OriginalVersionedValue: origVal})
+	}
+	if err := ch.persist(txID, tx); err != nil {
+		if held {
+			tx.keys[ck] = prevMode
+		} else {
+			delete(tx.keys, ck)
+		}
+		if !recorded {
+			delete(tx.undoIndex, ck)
+			tx.undo = tx.undo[:len(tx.undo)-1]
+		}
+		return false, errors.WithMessage(err, fmt.Sprintf("failed to lock key [%s:%s] for cross transaction [%s]", ns, key, txID))
+	}
+	ch.locks.Grant(txID, ck, ExclusiveLock)
+	return true, nil
+}
+
+// acquire checks whether the key can be held by the transaction in the given mode. The caller is expected to hold the write lock.
+func (m *LockManager) acquire(channelID, txID string, ck statedb.CompositeKey, mode LockMode) (*channelLocks, *crossTx, bool) {
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil, nil, false
+	}
+	tx, ok := ch.txs[txID]
+	if !ok {
+		return nil, nil, false
+	}
+	if holder, locked := ch.locks.Conflicts(txID, ck, mode); locked {
+		logger.Debugf("[%s] Key [%s:%s] requested in %s mode by cross transaction [%s] is held by [%s]", channelID, ck.Namespace, ck.Key, mode, txID, holder)
+		return nil, nil, false
+	}
+	return ch, tx, true
+}
+
+// PrepareTx records that the given transaction was committed on the channel in the given block, holding
+// the given keys locked in the given modes, and the given ranges locked, until its confirmation. A key the
+// transaction already holds is upgraded to exclusive mode if so requested, and never downgraded. The undo log of the transaction is
+// extended with the given original values, except for the keys whose original value is already recorded.
+// Preparing is derived from the committed blocks only, so that every peer of the channel reaches the same
+// lock table, and preparing the same transaction again, as the recovery of the ledger does, is a no-op.
+// It fails, leaving the lock table unchanged, if a key is held by another transaction in a conflicting mode.
+func (m *LockManager) PrepareTx(channelID, txID string, blockNum uint64, keys []KeyLock, ranges []RangeLock, undo []statedb.KeyOrigVal) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ch := m.getChannel(channelID, true)
+	for _, kl := range keys {
+		if holder, locked := ch.locks.Conflicts(txID, kl.CompositeKey, kl.Mode); locked {
+			return errors.Errorf("key [%s:%s] of cross transaction [%s] prepared in block [%d] is held by [%s]",
+				kl.Namespace, kl.Key, txID, blockNum, holder)
+		}
+	}
+	for _, r := range ranges {
+		if holder, locked := ch.locks.ConflictsRange(txID, r); locked {
+			return errors.Errorf("range [%s:%s-%s] of cross transaction [%s] prepared in block [%d] holds a key held by [%s]",
+				r.Namespace, r.StartKey, r.EndKey, txID, blockNum, holder)
+		}
+	}
+	tx := newCrossTx()
+	if prev, ok := ch.txs[txID]; ok {
+		tx = prev.clone()
+	} else {
+		tx.registered = time.Now()
+	}
+	if !tx.prepared {
+		tx.prepared, tx.preparedBlock = true, blockNum
+	}
+	for _, kl := range keys {
+		if mode, held := tx.keys[kl.CompositeKey]; !held || mode != ExclusiveLock {
+			tx.keys[kl.CompositeKey] = kl.Mode
+		}
+	}
+	for _, r := range ranges {
+		if !tx.holdsRange(r) {
+			tx.ranges = append(tx.ranges, r)
+		}
+	}
+	for _, kv := range undo {
+		ck := undoKey(kv)
+		if _, recorded := tx.undoIndex[ck]; !recorded {
+			tx.undoIndex[ck] = struct{}{}
+			tx.undo = append(tx.undo, kv)
+		}
+	}
+	if err := ch.persist(txID, tx); err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("failed to prepare cross transaction [%s]", txID))
+	}
+	ch.txs[txID] = tx
+	for ck, mode := range tx.keys {
+		ch.locks.Grant(txID, ck, mode)
+	}
+	for _, r := range tx.ranges {
+		ch.locks.GrantRange(txID, r)
+	}
+	ch.releaseWaits(txID, false)
+	logger.Debugf("[%s] Cross transaction [%s] prepared in block [%d] holding %d key(s) and %d range(s)",
+		channelID, txID, blockNum, len(tx.keys), len(tx.ranges))
+	return nil
+}
+
+func (tx *crossTx) holdsRange(r RangeLock) bool {
+	for _, held := range tx.ranges {
+		if held == r {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrepared returns true if the given transaction was committed on the channel and waits for its confirmation
+func (m *LockManager) IsPrepared(channelID, txID string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return false
+	}
+	tx, ok := ch.txs[txID]
+	return ok && tx.prepared
+}
+
+// RollbackKVs returns the original values of the keys written by the given transaction
+func (m *LockManager) RollbackKVs(channelID, txID string) []statedb.KeyOrigVal {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	tx, ok := ch.txs[txID]
+	if !ok {
+		return nil
+	}
+	kvs := make([]statedb.KeyOrigVal, len(tx.undo))
+	copy(kvs, tx.undo)
+	return kvs
+}
+
+// Release unlocks all the keys held by the given transaction and forgets about the transaction
+func (m *LockManager) Release(channelID, txID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	tx, ok := ch.txs[txID]
+	if !ok {
+		return nil
+	}
+	if ch.store != nil {
+		if err := ch.store.DeleteTxRecord(txID); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("failed to release cross transaction [%s]", txID))
+		}
+	}
+	for ck := range tx.keys {
+		ch.locks.Release(txID, ck)
+	}
+	ch.locks.ReleaseRanges(txID)
+	delete(ch.txs, txID)
+	ch.waiting.released(txID)
+	ch.releaseWaits(txID, true)
+	logger.Debugf("[%s] Released cross transaction [%s] and its %d key(s)", channelID, txID, len(tx.keys))
+	return nil
+}
+
+// LockedKeys returns the keys currently locked on the given channel, sorted by namespace and key
+func (m *LockManager) LockedKeys(channelID string) []statedb.CompositeKey {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	return ch.locks.Keys()
+}
+
+func sortCompositeKeys(keys []statedb.CompositeKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Key < keys[j].Key
+	})
+}
+
+func sortKeyLocks(keys []KeyLock) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Key < keys[j].Key
+	})
+}
+
+// PendingTxs returns the ids of the in-flight cross transactions of the given channel, sorted
+func (m *LockManager) PendingTxs(channelID string) []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	txIDs := make([]string, 0, len(ch.txs))
+	for txID := range ch.txs {
+		txIDs = append(txIDs, txID)
+	}
+	sort.Strings(txIDs)
+	return txIDs
+}
+
+// TxInfo describes a cross transaction of the lock table
+type TxInfo struct {
+	TxID          string
+	Prepared      bool
+	PreparedBlock uint64
+	// Keys are the keys held by the transaction along with their mode, sorted by namespace and key
+	Keys []KeyLock
+	// Ranges are the ranges of keys held by the transaction
+	Ranges []RangeLock
+}
+
+func (tx *crossTx) info(txID string) *TxInfo {
+	info := &TxInfo{TxID: txID, Prepared: tx.prepared, PreparedBlock: tx.preparedBlock}
+	for ck, mode := range tx.keys {
+		info.Keys = append(info.Keys, KeyLock{CompositeKey: ck, Mode: mode})
+	}
+	sortKeyLocks(info.Keys)
+	info.Ranges = append(info.Ranges, tx.ranges...)
+	return info
+}
+
+// GetTxInfo returns the description of the given transaction, false if the transaction is not in the lock table
+func (m *LockManager) GetTxInfo(channelID, txID string) (*TxInfo, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil, false
+	}
+	tx, ok := ch.txs[txID]
+	if !ok {
+		return nil, false
+	}
+	return tx.info(txID), true
+}
+
+// GetTxInfos returns the description of the in-flight cross transactions of the given channel, sorted by ID
+func (m *LockManager) GetTxInfos(channelID string) []*TxInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	infos := make([]*TxInfo, 0, len(ch.txs))
+	for txID, tx := range ch.txs {
+		infos = append(infos, tx.info(txID))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].TxID < infos[j].TxID })
+	return infos
+}
@@ -0,0 +1,459 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockManagerRegisterTx(t *testing.T) {
+	m := NewLockManager()
+	assert.False(t, m.IsCrossTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.True(t, m.IsCrossTx("ch1", "tx1"))
+	assert.False(t, m.IsCrossTx("ch2", "tx1"))
+	assert.Equal(t, []string{"tx1"}, m.PendingTxs("ch1"))
+	assert.Nil(t, m.PendingTxs("ch2"))
+}
+
+func TestLockManagerLocking(t *testing.T) {
+	m := NewLockManager()
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx2"))
+
+	// an unregistered transaction cannot lock
+	assertLock(t, false)(m.LockRead("ch1", "tx3", "cc1", "a"))
+
+	assertLock(t, true)(m.LockRead("ch1", "tx1", "cc1", "a"))
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "b", []byte("orig-b")))
+	// a read key may still be read, a written key may not
+	assert.False(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", ExclusiveLock))
+	assert.True(t, m.IsLocked("ch1", "cc1", "b", SharedLock))
+	assert.True(t, m.IsLocked("ch1", "cc1", "b", ExclusiveLock))
+
+	// the owner does not see its own keys as locked
+	isCross, isLocked := m.CheckKey("ch1", "tx1", "cc1", "b", ExclusiveLock)
+	assert.True(t, isCross)
+	assert.False(t, isLocked)
+
+	// another cross transaction does
+	isCross, isLocked = m.CheckKey("ch1", "tx2", "cc1", "a", ExclusiveLock)
+	assert.True(t, isCross)
+	assert.True(t, isLocked)
+	assertLock(t, false)(m.LockWrite("ch1", "tx2", "cc1", "a", nil))
+	assertLock(t, false)(m.LockRead("ch1", "tx2", "cc1", "b"))
+
+	// and so does a local transaction
+	isCross, isLocked = m.CheckKey("ch1", "local", "cc1", "b", SharedLock)
+	assert.False(t, isCross)
+	assert.True(t, isLocked)
+
+	// the same key in another namespace or another channel is not locked
+	assert.False(t, m.IsLocked("ch1", "cc2", "a", ExclusiveLock))
+	assert.False(t, m.IsLocked("ch2", "cc1", "a", ExclusiveLock))
+	assertLock(t, true)(m.LockRead("ch1", "tx2", "cc2", "a"))
+
+	assert.Equal(t, []statedb.CompositeKey{
+		{Namespace: "cc1", Key: "a"},
+		{Namespace: "cc1", Key: "b"},
+		{Namespace: "cc2", Key: "a"},
+	}, m.LockedKeys("ch1"))
+}
+
+func TestLockManagerSharedLocks(t *testing.T) {
+	m := NewLockManager()
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx2"))
+
+	// readers share a key
+	assertLock(t, true)(m.LockRead("ch1", "tx1", "cc1", "a"))
+	assertLock(t, true)(m.LockRead("ch1", "tx2", "cc1", "a"))
+	isCross, isLocked := m.CheckKey("ch1", "tx2", "cc1", "a", SharedLock)
+	assert.True(t, isCross)
+	assert.False(t, isLocked)
+
+	// neither of them may upgrade while the other holds the key
+	assertLock(t, false)(m.LockWrite("ch1", "tx1", "cc1", "a", []byte("orig-a")))
+	assert.Empty(t, m.RollbackKVs("ch1", "tx1"))
+
+	// the last reader may
+	assert.NoError(t, m.Release("ch1", "tx2"))
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "a", []byte("orig-a")))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+	info, _ := m.GetTxInfo("ch1", "tx1")
+	assert.Equal(t, []KeyLock{{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "a"}, Mode: ExclusiveLock}}, info.Keys)
+
+	// a read after a write does not downgrade the key
+	assertLock(t, true)(m.LockRead("ch1", "tx1", "cc1", "a"))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+
+	assert.NoError(t, m.Release("ch1", "tx1"))
+	assert.Empty(t, m.LockedKeys("ch1"))
+}
+
+func TestLockManagerRollbackKVs(t *testing.T) {
+	m := NewLockManager()
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "a", []byte("orig-a")))
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "b", nil))
+	// only the value seen by the first write is kept
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "a", []byte("intermediate-a")))
+
+	assert.Equal(t, []statedb.KeyOrigVal{
+		{Namespace: "cc1", Key: "a", OriginalVersionedValue: []byte("orig-a")},
+		{Namespace: "cc1", Key: "b"},
+	}, m.RollbackKVs("ch1", "tx1"))
+	assert.Nil(t, m.RollbackKVs("ch1", "tx2"))
+	assert.Nil(t, m.RollbackKVs("ch2", "tx1"))
+}
+
+func TestLockManagerRelease(t *testing.T) {
+	m := NewLockManager()
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx2"))
+	assertLock(t, true)(m.LockRead("ch1", "tx1", "cc1", "a"))
+	assertLock(t, true)(m.LockWrite("ch1", "tx2", "cc1", "b", nil))
+
+	assert.NoError(t, m.Release("ch1", "tx1"))
+	assert.NoError(t, m.Release("ch1", "unknown"))
+	assert.NoError(t, m.Release("ch2", "tx1"))
+	assert.False(t, m.IsCrossTx("ch1", "tx1"))
+	assert.False(t, m.IsLocked("ch1", "cc1", "a", ExclusiveLock))
+	assert.True(t, m.IsLocked("ch1", "cc1", "b", SharedLock))
+	assert.Nil(t, m.RollbackKVs("ch1", "tx1"))
+
+	// a released key can be taken by another transaction
+	assertLock(t, true)(m.LockRead("ch1", "tx2", "cc1", "a"))
+}
+
+func TestLockManagerConcurrentAccess(t *testing.T) {
+	m := NewLockManager()
+	channels := []string{"ch1", "ch2"}
+	numTxs := 50
+	numKeys := 20
+
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		for i := 0; i < numTxs; i++ {
+			wg.Add(1)
+			go func(ch string, txID string) {
+				defer wg.Done()
+				assert.NoError(t, m.RegisterTx(ch, txID))
+				for k := 0; k < numKeys; k++ {
+					key := fmt.Sprintf("key%d", k)
+					if isCross, isLocked := m.CheckKey(ch, txID, "cc", key, ExclusiveLock); isCross && !isLocked {
+						if k%2 == 0 {
+							_, err := m.LockRead(ch, txID, "cc", key)
+							assert.NoError(t, err)
+						} else {
+							_, err := m.LockWrite(ch, txID, "cc", key, []byte(txID))
+							assert.NoError(t, err)
+						}
+					}
+					m.IsLocked(ch, "cc", key, SharedLock)
+				}
+				m.RollbackKVs(ch, txID)
+				m.LockedKeys(ch)
+				m.PendingTxs(ch)
+				assert.NoError(t, m.Release(ch, txID))
+			}(ch, fmt.Sprintf("tx%d", i))
+		}
+	}
+	wg.Wait()
+
+	for _, ch := range channels {
+		assert.Empty(t, m.LockedKeys(ch))
+		assert.Empty(t, m.PendingTxs(ch))
+	}
+}
+
+func TestLockManagerPrepareTx(t *testing.T) {
+	m := NewLockManager()
+	store := newMemStore()
+	assert.NoError(t, m.SetStore("ch1", store))
+	keyA, keyB := statedb.CompositeKey{Namespace: "cc1", Key: "a"}, statedb.CompositeKey{Namespace: "cc1", Key: "b"}
+	keys := []KeyLock{{CompositeKey: keyA, Mode: SharedLock}, {CompositeKey: keyB, Mode: ExclusiveLock}}
+	undo := []statedb.KeyOrigVal{{Namespace: "cc1", Key: "b", OriginalVersionedValue: []byte("orig-b")}}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, keys, nil, undo))
+	assert.True(t, m.IsCrossTx("ch1", "tx1"))
+	assert.True(t, m.IsPrepared("ch1", "tx1"))
+	assert.False(t, m.IsPrepared("ch1", "tx2"))
+	assert.Equal(t, []statedb.CompositeKey{keyA, keyB}, m.LockedKeys("ch1"))
+	assert.Equal(t, undo, m.RollbackKVs("ch1", "tx1"))
+
+	// preparing again, as a recovery does, changes nothing
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, keys, nil,
+		[]statedb.KeyOrigVal{{Namespace: "cc1", Key: "b", OriginalVersionedValue: []byte("other-b")}}))
+	assert.Equal(t, []statedb.CompositeKey{keyA, keyB}, m.LockedKeys("ch1"))
+	assert.Equal(t, undo, m.RollbackKVs("ch1", "tx1"))
+
+	// a transaction cannot be prepared while another one holds one of its keys in a conflicting mode
+	keyC := statedb.CompositeKey{Namespace: "cc1", Key: "c"}
+	err := m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyC, Mode: ExclusiveLock}, {CompositeKey: keyA, Mode: ExclusiveLock}}, nil, nil)
+	assert.EqualError(t, err, "key [cc1:a] of cross transaction [tx2] prepared in block [6] is held by [tx1]")
+	err = m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyC, Mode: ExclusiveLock}, {CompositeKey: keyB, Mode: SharedLock}}, nil, nil)
+	assert.EqualError(t, err, "key [cc1:b] of cross transaction [tx2] prepared in block [6] is held by [tx1]")
+	assert.False(t, m.IsCrossTx("ch1", "tx2"))
+	assert.False(t, m.IsLocked("ch1", "cc1", "c", SharedLock))
+
+	// but can share a key read by both
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyA, Mode: SharedLock}}, nil, nil))
+	assert.False(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", ExclusiveLock))
+
+	// the prepared transactions survive a restart, along with the mode of their keys
+	restarted := NewLockManager()
+	assert.NoError(t, restarted.SetStore("ch1", store))
+	assert.True(t, restarted.IsPrepared("ch1", "tx1"))
+	assert.Equal(t, []statedb.CompositeKey{keyA, keyB}, restarted.LockedKeys("ch1"))
+	assert.Equal(t, undo, restarted.RollbackKVs("ch1", "tx1"))
+	info, _ := restarted.GetTxInfo("ch1", "tx1")
+	assert.Equal(t, keys, info.Keys)
+	_, isLocked := restarted.CheckKey("ch1", "tx3", "cc1", "a", SharedLock)
+	assert.False(t, isLocked)
+	rec, err := UnmarshalTxRecord(store.recs["tx1"])
+	assert.NoError(t, err)
+	assert.True(t, rec.Prepared)
+	assert.Equal(t, uint64(5), rec.PreparedBlock)
+	assert.Equal(t, []statedb.CompositeKey{keyB}, rec.Keys)
+	assert.Equal(t, []statedb.CompositeKey{keyA}, rec.ReadKeys)
+
+	store.err = errors.New("store failure")
+	assert.Error(t, restarted.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyC}}, nil, nil))
+	assert.Error(t, restarted.PrepareTx("ch1", "tx3", 6, []KeyLock{{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "d"}}}, nil, nil))
+	assert.Equal(t, []statedb.CompositeKey{keyA, keyB}, restarted.LockedKeys("ch1"))
+	assert.False(t, restarted.IsCrossTx("ch1", "tx3"))
+}
+
+func TestLockManagerCollectionUndo(t *testing.T) {
+	m := NewLockManager()
+	store := newMemStore()
+	assert.NoError(t, m.SetStore("ch1", store))
+	hashB := []byte("hash-b")
+	keys := []KeyLock{
+		{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "b"}, Mode: ExclusiveLock},
+		{CompositeKey: CollectionKey("cc1", "coll1", hashB), Mode: ExclusiveLock},
+	}
+	// the public key, the hash of the key of the collection and the key of the collection are restored apart
+	undo := []statedb.KeyOrigVal{
+		{Namespace: "cc1", Key: "b", OriginalVersionedValue: []byte("orig-b")},
+		{Namespace: "cc1", Collection: "coll1", KeyHash: hashB, OriginalVersionedValue: []byte("orig-hash-b")},
+		{Namespace: "cc1", Collection: "coll1", Key: "b", OriginalVersionedValue: []byte("orig-pvt-b")},
+	}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, keys, nil, undo))
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, keys, nil, undo))
+	assert.Equal(t, undo, m.RollbackKVs("ch1", "tx1"))
+
+	// and survive a restart
+	m = NewLockManager()
+	assert.NoError(t, m.SetStore("ch1", store))
+	assert.Equal(t, undo, m.RollbackKVs("ch1", "tx1"))
+}
+
+func TestLockManagerPrepareTxUpgrade(t *testing.T) {
+	m := NewLockManager()
+	keyA := statedb.CompositeKey{Namespace: "cc1", Key: "a"}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: SharedLock}}, nil, nil))
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 5, []KeyLock{{CompositeKey: keyA, Mode: SharedLock}}, nil, nil))
+
+	// a shared key is upgraded only by its single holder
+	assert.Error(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: ExclusiveLock}}, nil, nil))
+	assert.NoError(t, m.Release("ch1", "tx2"))
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: ExclusiveLock}}, nil, nil))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+
+	// and is never downgraded
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: SharedLock}}, nil, nil))
+	info, _ := m.GetTxInfo("ch1", "tx1")
+	assert.Equal(t, []KeyLock{{CompositeKey: keyA, Mode: ExclusiveLock}}, info.Keys)
+}
+
+func TestLockManagerRangeLocks(t *testing.T) {
+	m := NewLockManager()
+	store := newMemStore()
+	assert.NoError(t, m.SetStore("ch1", store))
+	keyB := statedb.CompositeKey{Namespace: "cc1", Key: "b"}
+	rangeAC := RangeLock{Namespace: "cc1", StartKey: "a", EndKey: "c"}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, nil, []RangeLock{rangeAC}, nil))
+
+	// no other transaction may write in the range, but any may read in it
+	assert.True(t, m.IsLocked("ch1", "cc1", "b", ExclusiveLock))
+	assert.False(t, m.IsLocked("ch1", "cc1", "b", SharedLock))
+	assert.False(t, m.IsLocked("ch1", "cc1", "c", ExclusiveLock))
+	assert.False(t, m.IsLocked("ch1", "cc2", "b", ExclusiveLock))
+	_, isLocked := m.CheckKey("ch1", "tx1", "cc1", "b", ExclusiveLock)
+	assert.False(t, isLocked)
+	err := m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyB, Mode: ExclusiveLock}}, nil, nil)
+	assert.EqualError(t, err, "key [cc1:b] of cross transaction [tx2] prepared in block [6] is held by [tx1]")
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyB, Mode: SharedLock}}, []RangeLock{rangeAC}, nil))
+
+	// a range holding a key written by another transaction cannot be locked
+	assert.NoError(t, m.PrepareTx("ch1", "tx3", 7, []KeyLock{{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "x"}}}, nil, nil))
+	rangeWZ := RangeLock{Namespace: "cc1", StartKey: "w", EndKey: "x", EndInclusive: true}
+	assert.True(t, m.CheckRange("ch1", "tx4", rangeWZ))
+	assert.False(t, m.CheckRange("ch1", "tx3", rangeWZ))
+	assert.False(t, m.CheckRange("ch1", "tx4", rangeAC))
+	err = m.PrepareTx("ch1", "tx4", 8, nil, []RangeLock{rangeWZ}, nil)
+	assert.EqualError(t, err, "range [cc1:w-x] of cross transaction [tx4] prepared in block [8] holds a key held by [tx3]")
+
+	// the ranges survive a restart
+	restarted := NewLockManager()
+	assert.NoError(t, restarted.SetStore("ch1", store))
+	info, _ := restarted.GetTxInfo("ch1", "tx1")
+	assert.Equal(t, []RangeLock{rangeAC}, info.Ranges)
+	assert.True(t, restarted.IsLocked("ch1", "cc1", "b", ExclusiveLock))
+
+	// and are released along with the keys
+	assert.NoError(t, restarted.Release("ch1", "tx1"))
+	assert.True(t, restarted.IsLocked("ch1", "cc1", "a", ExclusiveLock))
+	assert.NoError(t, restarted.Release("ch1", "tx2"))
+	assert.False(t, restarted.IsLocked("ch1", "cc1", "a", ExclusiveLock))
+}
+
+func TestLockManagerLegacyRecord(t *testing.T) {
+	// records written by earlier versions hold all their keys in exclusive mode
+	store := newMemStore()
+	store.recs["tx1"] = []byte(`{"txid":"tx1","prepared":true,"preparedBlock":3,"keys":[{"Namespace":"cc1","Key":"a"}]}`)
+	m := NewLockManager()
+	assert.NoError(t, m.SetStore("ch1", store))
+	assert.True(t, m.IsPrepared("ch1", "tx1"))
+	assert.True(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+}
+
+func TestLockManagerTxInfo(t *testing.T) {
+	m := NewLockManager()
+	_, ok := m.GetTxInfo("ch1", "tx1")
+	assert.False(t, ok)
+	assert.Nil(t, m.GetTxInfos("ch1"))
+
+	keyA, keyB, keyZ := statedb.CompositeKey{Namespace: "cc1", Key: "a"}, statedb.CompositeKey{Namespace: "cc1", Key: "b"},
+		statedb.CompositeKey{Namespace: "cc0", Key: "z"}
+	keys := []KeyLock{{CompositeKey: keyB}, {CompositeKey: keyA, Mode: SharedLock}, {CompositeKey: keyZ}}
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 7, keys, nil, nil))
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	info, ok := m.GetTxInfo("ch1", "tx2")
+	assert.True(t, ok)
+	assert.Equal(t, &TxInfo{TxID: "tx2", Prepared: true, PreparedBlock: 7, Keys: []KeyLock{
+		{CompositeKey: keyZ, Mode: ExclusiveLock}, {CompositeKey: keyA, Mode: SharedLock}, {CompositeKey: keyB, Mode: ExclusiveLock}}}, info)
+	assert.Equal(t, []*TxInfo{{TxID: "tx1"}, info}, m.GetTxInfos("ch1"))
+	assert.Empty(t, m.GetTxInfos("ch2"))
+}
+
+func TestGetLockManager(t *testing.T) {
+	assert.NotNil(t, GetLockManager())
+	assert.True(t, GetLockManager() == GetLockManager())
+}
+
+func TestLockManagerStore(t *testing.T) {
+	store := newMemStore()
+	m := NewLockManager()
+	assert.NoError(t, m.SetStore("ch1", store))
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+	assert.NoError(t, m.RegisterTx("ch1", "tx2"))
+	assertLock(t, true)(m.LockRead("ch1", "tx1", "cc1", "a"))
+	assertLock(t, true)(m.LockWrite("ch1", "tx1", "cc1", "b", []byte("orig-b")))
+	assertLock(t, true)(m.LockWrite("ch1", "tx2", "cc1", "c", nil))
+	assert.NoError(t, m.Release("ch1", "tx2"))
+	assert.Len(t, store.recs, 1)
+
+	// a restarted peer recovers the lock table from the store
+	restarted := NewLockManager()
+	assert.NoError(t, restarted.SetStore("ch1", store))
+	assert.True(t, restarted.IsCrossTx("ch1", "tx1"))
+	assert.False(t, restarted.IsCrossTx("ch1", "tx2"))
+	assert.Equal(t, m.LockedKeys("ch1"), restarted.LockedKeys("ch1"))
+	assert.Equal(t, m.RollbackKVs("ch1", "tx1"), restarted.RollbackKVs("ch1", "tx1"))
+	isCross, isLocked := restarted.CheckKey("ch1", "tx3", "cc1", "b", SharedLock)
+	assert.False(t, isCross)
+	assert.True(t, isLocked)
+
+	assert.NoError(t, restarted.Release("ch1", "tx1"))
+	assert.Empty(t, store.recs)
+}
+
+func TestLockManagerStoreErrors(t *testing.T) {
+	store := newMemStore()
+	m := NewLockManager()
+	assert.NoError(t, m.SetStore("ch1", store))
+	assert.NoError(t, m.RegisterTx("ch1", "tx1"))
+
+	store.err = errors.New("store failure")
+	assert.Error(t, m.RegisterTx("ch1", "tx2"))
+	assert.False(t, m.IsCrossTx("ch1", "tx2"))
+	acquired, err := m.LockWrite("ch1", "tx1", "cc1", "a", []byte("orig-a"))
+	assert.Error(t, err)
+	assert.False(t, acquired)
+	assert.False(t, m.IsLocked("ch1", "cc1", "a", SharedLock))
+	assert.Empty(t, m.RollbackKVs("ch1", "tx1"))
+	acquired, err = m.LockRead("ch1", "tx1", "cc1", "a")
+	assert.Error(t, err)
+	assert.False(t, acquired)
+	assert.Error(t, m.Release("ch1", "tx1"))
+	assert.True(t, m.IsCrossTx("ch1", "tx1"))
+
+	assert.Error(t, NewLockManager().SetStore("ch1", store))
+}
+
+// assertLock returns a function checking the result of LockRead or LockWrite
+func assertLock(t *testing.T, expected bool) func(bool, error) {
+	return func(acquired bool, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, expected, acquired)
+	}
+}
+
+type memStore struct {
+	recs map[string][]byte
+	err  error
+}
+
+func newMemStore() *memStore {
+	return &memStore{recs: make(map[string][]byte)}
+}
+
+func (s *memStore) PutTxRecord(rec *TxRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	b, err := MarshalTxRecord(rec)
+	if err != nil {
+		return err
+	}
+	s.recs[rec.TxID] = b
+	return nil
+}
+
+func (s *memStore) DeleteTxRecord(txID string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.recs, txID)
+	return nil
+}
+
+func (s *memStore) GetTxRecords() ([]*TxRecord, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var recs []*TxRecord
+	for _, b := range s.recs {
+		rec, err := UnmarshalTxRecord(b)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
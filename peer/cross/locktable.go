@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+)
+
+// LockMode is the mode in which a cross transaction holds a key
+type LockMode int
+
+const (
+	// ExclusiveLock is held on a key the transaction wrote. A single transaction may hold a key in
+	// exclusive mode, and no other transaction may read or write it.
+	ExclusiveLock LockMode = iota
+	// SharedLock is held on a key the transaction only read. Any number of transactions may hold
+	// a key in shared mode, and transactions not holding the key may still read it.
+	SharedLock
+)
+
+func (mode LockMode) String() string {
+	if mode == ExclusiveLock {
+		return "exclusive"
+	}
+	return "shared"
+}
+
+// KeyLock is a key along with the mode in which it is to be locked
+type KeyLock struct {
+	statedb.CompositeKey
+	Mode LockMode
+}
+
+// RangeLock is a range of keys of a namespace read by a transaction through a range query. It is held in
+// shared mode: other transactions may read the keys of the range, but may not write any of them, including
+// the keys not present in the state, so that the range still holds the same keys once the lock is released.
+type RangeLock struct {
+	Namespace string
+	StartKey  string
+	// EndKey is excluded from the range, unless EndInclusive is set. An empty EndKey, not included,
+	// stands for the end of the namespace.
+	EndKey       string
+	EndInclusive bool
+}
+
+// Contains returns true if the given key falls in the range
+func (r RangeLock) Contains(ck statedb.CompositeKey) bool {
+	if ck.Namespace != r.Namespace || ck.Key < r.StartKey {
+		return false
+	}
+	if r.EndInclusive {
+		return ck.Key <= r.EndKey
+	}
+	return r.EndKey == "" || ck.Key < r.EndKey
+}
+
+// LockTable holds the modes in which keys are locked by transactions, along with the ranges of keys
+// they locked. A transaction holding a key in shared mode upgrades it to exclusive mode once it is the
+// only holder of the key. A LockTable is not safe for concurrent use.
+type LockTable struct {
+	keys   map[statedb.CompositeKey]*keyHolders
+	ranges map[string][]RangeLock
+}
+
+// keyHolders holds the transactions locking a key, either an exclusive holder or shared holders
+type keyHolders struct {
+	exclusive string
+	shared    map[string]struct{}
+}
+
+// NewLockTable constructs an empty LockTable
+func NewLockTable() *LockTable {
+	return &LockTable{
+		keys:   make(map[statedb.CompositeKey]*keyHolders),
+		ranges: make(map[string][]RangeLock),
+	}
+}
+
+// Conflicts returns true, along with one of the holders, if the given key is held by a transaction
+// other than the given one in a mode incompatible with the given mode. A key falling in a range held
+// by another transaction may only be locked in shared mode.
+func (t *LockTable) Conflicts(txID string, ck statedb.CompositeKey, mode LockMode) (string, bool) {
+	if h, ok := t.keys[ck]; ok {
+		if h.exclusive != "" && h.exclusive != txID {
+			return h.exclusive, true
+		}
+		if mode == ExclusiveLock {
+			for holder := range h.shared {
+				if holder != txID {
+					return holder, true
+				}
+			}
+		}
+	}
+	if mode == ExclusiveLock {
+		for holder, ranges := range t.ranges {
+			if holder == txID {
+				continue
+			}
+			for _, r := range ranges {
+				if r.Contains(ck) {
+					return holder, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// ConflictsRange returns true, along with the holder, if a key of the given range is held in exclusive
+// mode by a transaction other than the given one
+func (t *LockTable) ConflictsRange(txID string, r RangeLock) (string, bool) {
+	for ck, h := range t.keys {
+		if h.exclusive != "" && h.exclusive != txID && r.Contains(ck) {
+			return h.exclusive, true
+		}
+	}
+	return "", false
+}
+
+// GrantRange locks the given range for the given transaction, which is expected not to conflict
+func (t *LockTable) GrantRange(txID string, r RangeLock) {
+	for _, held := range t.ranges[txID] {
+		if held == r {
+			return
+		}
+	}
+	t.ranges[txID] = append(t.ranges[txID], r)
+}
+
+// ReleaseRanges unlocks all the ranges held by the given transaction
+func (t *LockTable) ReleaseRanges(txID string) {
+	delete(t.ranges, txID)
+}
+
+// Grant locks the given key for the given transaction, which is expected not to conflict,
+// upgrading a shared lock of the transaction to an exclusive one if so requested
+func (t *LockTable) Grant(txID string, ck statedb.CompositeKey, mode LockMode) {
+	h, ok := t.keys[ck]
+	if !ok {
+		h = &keyHolders{shared: make(map[string]struct{})}
+		t.keys[ck] = h
+	}
+	if h.exclusive == txID {
+		return
+	}
+	if mode == ExclusiveLock {
+		delete(h.shared, txID)
+		h.exclusive = txID
+		return
+	}
+	h.shared[txID] = struct{}{}
+}
+
+// Release unlocks the given key for the given transaction
+func (t *LockTable) Release(txID string, ck statedb.CompositeKey) {
+	h, ok := t.keys[ck]
+	if !ok {
+		return
+	}
+	if h.exclusive == txID {
+		h.exclusive = ""
+	}
+	delete(h.shared, txID)
+	if h.exclusive == "" && len(h.shared) == 0 {
+		delete(t.keys, ck)
+	}
+}
+
+// IsLocked returns true if the given key is held by any transaction, in any mode
+func (t *LockTable) IsLocked(ck statedb.CompositeKey) bool {
+	_, ok := t.keys[ck]
+	return ok
+}
+
+// Keys returns the locked keys, sorted by namespace and key
+func (t *LockTable) Keys() []statedb.CompositeKey {
+	keys := make([]statedb.CompositeKey, 0, len(t.keys))
+	for ck := range t.keys {
+		keys = append(keys, ck)
+	}
+	sortCompositeKeys(keys)
+	return keys
+}
+
+// Len returns the number of locked keys
+func (t *LockTable) Len() int {
+	return len(t.keys)
+}
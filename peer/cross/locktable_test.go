@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockTable(t *testing.T) {
+	table := NewLockTable()
+	keyA, keyB := statedb.CompositeKey{Namespace: "cc1", Key: "a"}, statedb.CompositeKey{Namespace: "cc1", Key: "b"}
+
+	table.Grant("tx1", keyA, SharedLock)
+	table.Grant("tx2", keyA, SharedLock)
+	table.Grant("tx1", keyB, ExclusiveLock)
+	assert.Equal(t, 2, table.Len())
+	assert.Equal(t, []statedb.CompositeKey{keyA, keyB}, table.Keys())
+
+	_, conflict := table.Conflicts("tx3", keyA, SharedLock)
+	assert.False(t, conflict)
+	holder, conflict := table.Conflicts("tx3", keyB, SharedLock)
+	assert.True(t, conflict)
+	assert.Equal(t, "tx1", holder)
+	holder, conflict = table.Conflicts("tx1", keyA, ExclusiveLock)
+	assert.True(t, conflict)
+	assert.Equal(t, "tx2", holder)
+
+	// the single holder of a shared key upgrades it
+	table.Release("tx2", keyA)
+	_, conflict = table.Conflicts("tx1", keyA, ExclusiveLock)
+	assert.False(t, conflict)
+	table.Grant("tx1", keyA, ExclusiveLock)
+	table.Grant("tx1", keyA, SharedLock)
+	_, conflict = table.Conflicts("tx2", keyA, SharedLock)
+	assert.True(t, conflict)
+
+	table.Release("tx1", keyA)
+	table.Release("tx1", keyB)
+	table.Release("tx1", keyB)
+	assert.False(t, table.IsLocked(keyA))
+	assert.Equal(t, 0, table.Len())
+}
+
+func TestLockTableRanges(t *testing.T) {
+	table := NewLockTable()
+	keyB, keyD := statedb.CompositeKey{Namespace: "cc1", Key: "b"}, statedb.CompositeKey{Namespace: "cc1", Key: "d"}
+	table.GrantRange("tx1", RangeLock{Namespace: "cc1", StartKey: "a", EndKey: "c"})
+	table.GrantRange("tx1", RangeLock{Namespace: "cc1", StartKey: "a", EndKey: "c"})
+	assert.Len(t, table.ranges["tx1"], 1)
+
+	holder, conflict := table.Conflicts("tx2", keyB, ExclusiveLock)
+	assert.True(t, conflict)
+	assert.Equal(t, "tx1", holder)
+	_, conflict = table.Conflicts("tx2", keyB, SharedLock)
+	assert.False(t, conflict)
+	_, conflict = table.Conflicts("tx1", keyB, ExclusiveLock)
+	assert.False(t, conflict)
+	_, conflict = table.Conflicts("tx2", keyD, ExclusiveLock)
+	assert.False(t, conflict)
+
+	table.Grant("tx2", keyD, ExclusiveLock)
+	holder, conflict = table.ConflictsRange("tx1", RangeLock{Namespace: "cc1", StartKey: "c"})
+	assert.True(t, conflict)
+	assert.Equal(t, "tx2", holder)
+	_, conflict = table.ConflictsRange("tx2", RangeLock{Namespace: "cc1", StartKey: "c"})
+	assert.False(t, conflict)
+	_, conflict = table.ConflictsRange("tx1", RangeLock{Namespace: "cc1", StartKey: "a", EndKey: "d"})
+	assert.False(t, conflict)
+
+	table.ReleaseRanges("tx1")
+	_, conflict = table.Conflicts("tx2", keyB, ExclusiveLock)
+	assert.False(t, conflict)
+}
+
+func TestRangeLockContains(t *testing.T) {
+	ck := func(ns, key string) statedb.CompositeKey { return statedb.CompositeKey{Namespace: ns, Key: key} }
+	r := RangeLock{Namespace: "cc1", StartKey: "b", EndKey: "d"}
+	assert.False(t, r.Contains(ck("cc1", "a")))
+	assert.True(t, r.Contains(ck("cc1", "b")))
+	assert.True(t, r.Contains(ck("cc1", "c~suffix")))
+	assert.False(t, r.Contains(ck("cc1", "d")))
+	assert.False(t, r.Contains(ck("cc2", "c")))
+
+	r.EndInclusive = true
+	assert.True(t, r.Contains(ck("cc1", "d")))
+	assert.False(t, r.Contains(ck("cc1", "d0")))
+
+	// an empty end key stands for the end of the namespace
+	r = RangeLock{Namespace: "cc1", StartKey: "b"}
+	assert.True(t, r.Contains(ck("cc1", "zzz")))
+	assert.False(t, r.Contains(ck("cc1", "a")))
+}
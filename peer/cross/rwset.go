@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+)
+
+// lsccNamespace holds the chaincode definitions, which every invocation reads. Its keys are never
+// locked, otherwise a cross transaction would block every transaction of the chaincode it invokes.
+const lsccNamespace = "lscc"
+
+// CollectionKey returns the key under which the lock table holds a key of a private data collection, which
+// is the hash of the key in the namespace of the hashed data of the collection. Every peer of the channel,
+// member of the collection or not, holds the hashed data, hence the same locks.
+func CollectionKey(ns, coll string, keyHash []byte) statedb.CompositeKey {
+	return statedb.CompositeKey{Namespace: privacyenabledstate.DeriveHashedDataNs(ns, coll), Key: string(keyHash)}
+}
+
+// LockKeys returns the keys read or written by the given transaction, sorted by namespace and key, along
+// with the mode they are locked in: shared for the keys only read and exclusive for the keys written. The keys
+// of private data collections are given by CollectionKey. These are the keys a committed cross transaction
+// holds locked until its confirmation, and the keys a transaction may not access in a conflicting mode while
+// another cross transaction holds them.
+func LockKeys(txRWSet *rwsetutil.TxRwSet) []KeyLock {
+	index := make(map[statedb.CompositeKey]int)
+	var keys []KeyLock
+	add := func(ck statedb.CompositeKey, mode LockMode) {
+		if i, ok := index[ck]; ok {
+			if mode == ExclusiveLock {
+				keys[i].Mode = ExclusiveLock
+			}
+			return
+		}
+		index[ck] = len(keys)
+		keys = append(keys, KeyLock{CompositeKey: ck, Mode: mode})
+	}
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		ns := nsRWSet.NameSpace
+		if ns == lsccNamespace {
+			continue
+		}
+		if nsRWSet.KvRwSet != nil {
+			for _, kvRead := range nsRWSet.KvRwSet.Reads {
+				add(statedb.CompositeKey{Namespace: ns, Key: kvRead.Key}, SharedLock)
+			}
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				add(statedb.CompositeKey{Namespace: ns, Key: kvWrite.Key}, ExclusiveLock)
+			}
+		}
+		for _, collHashedRWSet := range nsRWSet.CollHashedRwSets {
+			if collHashedRWSet.HashedRwSet == nil {
+				continue
+			}
+			coll := collHashedRWSet.CollectionName
+			for _, hashedRead := range collHashedRWSet.HashedRwSet.HashedReads {
+				add(CollectionKey(ns, coll, hashedRead.KeyHash), SharedLock)
+			}
+			for _, hashedWrite := range collHashedRWSet.HashedRwSet.HashedWrites {
+				add(CollectionKey(ns, coll, hashedWrite.KeyHash), ExclusiveLock)
+			}
+		}
+	}
+	sortKeyLocks(keys)
+	return keys
+}
+
+// LockRanges returns the ranges of public keys read by the given transaction through range queries, which
+// include the queries by partial composite key. A range ends with the last key read, unless the query was
+// iterated to its end. These are the ranges a committed cross transaction holds locked until its confirmation,
+// and the ranges in which a transaction may not write while another cross transaction holds them.
+func LockRanges(txRWSet *rwsetutil.TxRwSet) []RangeLock {
+	var ranges []RangeLock
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		if nsRWSet.NameSpace == lsccNamespace || nsRWSet.KvRwSet == nil {
+			continue
+		}
+		for _, rqi := range nsRWSet.KvRwSet.RangeQueriesInfo {
+			r := RangeLock{Namespace: nsRWSet.NameSpace, StartKey: rqi.StartKey, EndKey: rqi.EndKey, EndInclusive: !rqi.ItrExhausted}
+			duplicate := false
+			for _, prev := range ranges {
+				duplicate = duplicate || prev == r
+			}
+			if !duplicate {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return ranges
+}
+
+// undoKey returns the key identifying the given entry of an undo log. The entries restoring the hash of a key of
+// a collection are identified as the key is locked, and the entries restoring the key itself by the key in the
+// namespace of the private data of the collection.
+func undoKey(kv statedb.KeyOrigVal) statedb.CompositeKey {
+	switch {
+	case kv.Collection == "":
+		return statedb.CompositeKey{Namespace: kv.Namespace, Key: kv.Key}
+	case kv.KeyHash != nil:
+		return CollectionKey(kv.Namespace, kv.Collection, kv.KeyHash)
+	default:
+		return statedb.CompositeKey{Namespace: privacyenabledstate.DerivePvtDataNs(kv.Namespace, kv.Collection), Key: kv.Key}
+	}
+}
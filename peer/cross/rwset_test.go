@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockKeys(t *testing.T) {
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToReadSet("lscc", "cc2", nil)
+	builder.AddToReadSet("cc2", "b", nil)
+	builder.AddToWriteSet("cc2", "b", []byte("value-b"))
+	builder.AddToReadSet("cc1", "z", nil)
+	builder.AddToWriteSet("cc1", "a", nil)
+	txRWSet := builder.GetTxReadWriteSet(nil)
+
+	// a key both read and written is locked in exclusive mode
+	assert.Equal(t, []KeyLock{
+		{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "a"}, Mode: ExclusiveLock},
+		{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "z"}, Mode: SharedLock},
+		{CompositeKey: statedb.CompositeKey{Namespace: "cc2", Key: "b"}, Mode: ExclusiveLock},
+	}, LockKeys(txRWSet))
+	assert.Empty(t, LockKeys(&rwsetutil.TxRwSet{}))
+}
+
+func TestLockKeysOfCollections(t *testing.T) {
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToReadSet("cc1", "a", nil)
+	builder.AddToHashedReadSet("cc1", "coll1", "a", nil)
+	builder.AddToHashedReadSet("cc1", "coll2", "b", nil)
+	builder.AddToPvtAndHashedWriteSet("cc1", "coll2", "b", []byte("value-b"))
+	txRWSet := builder.GetTxReadWriteSet(nil)
+
+	// a key of a collection is held by its hash, apart from the public key of the same name
+	hashA, hashB := util.ComputeStringHash("a"), util.ComputeStringHash("b")
+	expected := []KeyLock{
+		{CompositeKey: statedb.CompositeKey{Namespace: "cc1", Key: "a"}, Mode: SharedLock},
+		{CompositeKey: CollectionKey("cc1", "coll1", hashA), Mode: SharedLock},
+		{CompositeKey: CollectionKey("cc1", "coll2", hashB), Mode: ExclusiveLock},
+	}
+	assert.Equal(t, expected, LockKeys(txRWSet))
+	assert.Equal(t, statedb.CompositeKey{Namespace: "cc1$$hcoll2", Key: string(hashB)}, CollectionKey("cc1", "coll2", hashB))
+}
+
+func TestLockRanges(t *testing.T) {
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToRangeQuerySet("lscc", &kvrwset.RangeQueryInfo{StartKey: "a", EndKey: "z", ItrExhausted: true})
+	builder.AddToRangeQuerySet("cc1", &kvrwset.RangeQueryInfo{StartKey: "a", EndKey: "m", ItrExhausted: true})
+	builder.AddToRangeQuerySet("cc1", &kvrwset.RangeQueryInfo{StartKey: "a", EndKey: "m", ItrExhausted: true})
+	builder.AddToRangeQuerySet("cc2", &kvrwset.RangeQueryInfo{StartKey: "k", EndKey: "k5", ItrExhausted: false})
+	txRWSet := builder.GetTxReadWriteSet(nil)
+
+	// a range not iterated to its end ends with the last key read
+	assert.Equal(t, []RangeLock{
+		{Namespace: "cc1", StartKey: "a", EndKey: "m"},
+		{Namespace: "cc2", StartKey: "k", EndKey: "k5", EndInclusive: true},
+	}, LockRanges(txRWSet))
+	assert.Empty(t, LockRanges(&rwsetutil.TxRwSet{}))
+}
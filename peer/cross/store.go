@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/pkg/errors"
+)
+
+// TxRecord is the persisted state of an in-flight cross transaction
+type TxRecord struct {
+	TxID          string    `json:"txid"`
+	Registered    time.Time `json:"registered"`
+	Prepared      bool      `json:"prepared,omitempty"`
+	PreparedBlock uint64    `json:"preparedBlock,omitempty"`
+	// Keys are the keys held in exclusive mode, which are all the keys held in records written by earlier versions
+	Keys []statedb.CompositeKey `json:"keys,omitempty"`
+	// ReadKeys are the keys held in shared mode
+	ReadKeys []statedb.CompositeKey `json:"readKeys,omitempty"`
+	Ranges   []RangeLock            `json:"ranges,omitempty"`
+	Undo     []statedb.KeyOrigVal   `json:"undo,omitempty"`
+}
+
+// Store persists the lock table of a channel so that the locks and the undo
+// log of the in-flight cross transactions survive a peer restart
+type Store interface {
+	// PutTxRecord persists the given record, replacing any previous record of the same transaction
+	PutTxRecord(rec *TxRecord) error
+	// DeleteTxRecord removes the record of the given transaction
+	DeleteTxRecord(txID string) error
+	// GetTxRecords returns all the persisted records
+	GetTxRecords() ([]*TxRecord, error)
+}
+
+// MarshalTxRecord encodes a TxRecord for storage
+func MarshalTxRecord(rec *TxRecord) ([]byte, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal record of cross transaction [%s]", rec.TxID)
+	}
+	return b, nil
+}
+
+// UnmarshalTxRecord decodes a TxRecord encoded by MarshalTxRecord
+func UnmarshalTxRecord(b []byte) (*TxRecord, error) {
+	rec := &TxRecord{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cross transaction record")
+	}
+	return rec, nil
+}
+
+// toRecord builds the persisted form of the transaction
+func (tx *crossTx) toRecord(txID string) *TxRecord {
+	rec := &TxRecord{TxID: txID, Registered: tx.registered, Prepared: tx.prepared, PreparedBlock: tx.preparedBlock}
+	for ck, mode := range tx.keys {
+		if mode == SharedLock {
+			rec.ReadKeys = append(rec.ReadKeys, ck)
+		} else {
+			rec.Keys = append(rec.Keys, ck)
+		}
+	}
+	sortCompositeKeys(rec.Keys)
+	sortCompositeKeys(rec.ReadKeys)
+	rec.Ranges = append(rec.Ranges, tx.ranges...)
+	rec.Undo = append(rec.Undo, tx.undo...)
+	return rec
+}
+
+// fromRecord rebuilds an in-flight transaction from its persisted form
+func fromRecord(rec *TxRecord) *crossTx {
+	tx := newCrossTx()
+	tx.registered, tx.prepared, tx.preparedBlock = rec.Registered, rec.Prepared, rec.PreparedBlock
+	if tx.registered.IsZero() {
+		// records written by earlier versions carry no registration time
+		tx.registered = time.Now()
+	}
+	for _, ck := range rec.ReadKeys {
+		tx.keys[ck] = SharedLock
+	}
+	for _, ck := range rec.Keys {
+		tx.keys[ck] = ExclusiveLock
+	}
+	tx.ranges = append(tx.ranges, rec.Ranges...)
+	for _, kv := range rec.Undo {
+		tx.undoIndex[undoKey(kv)] = struct{}{}
+		tx.undo = append(tx.undo, kv)
+	}
+	return tx
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"sort"
+	"time"
+)
+
+// conflictRetention is how long a lock conflict reported for a cross transaction is kept, unless the transaction
+// gets prepared on the channel or the holders are released earlier. A proposal still refused or waiting for
+// the holders reports the conflict again, so that only the conflicts of abandoned proposals expire.
+const conflictRetention = time.Minute
+
+// LockWait is a cross transaction whose proposals access keys held by other cross transactions on a channel.
+// The lock waits of the channels make the edges of the wait-for graph of the coordinator of the transactions.
+type LockWait struct {
+	TxID string
+	// Holders are the cross transactions holding the keys, sorted
+	Holders []string
+	// Since is the time the conflict was first reported
+	Since time.Time
+}
+
+// lockWait holds the conflicts reported for a cross transaction on a channel
+type lockWait struct {
+	holders map[string]struct{}
+	since   time.Time
+	// reported is the time the conflict was last reported
+	reported time.Time
+}
+
+// ReportConflict records that a proposal of the given cross transaction accesses keys held by the given cross
+// transactions, whether the proposal waits for their release or is refused. Conflicts of local transactions,
+// which hold no key, are not recorded.
+func (m *LockManager) ReportConflict(channelID, txID string, holders []string) {
+	if txID == "" {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ch := m.getChannel(channelID, true)
+	now := time.Now()
+	for waiter, w := range ch.waitsFor {
+		if now.Sub(w.reported) > conflictRetention {
+			delete(ch.waitsFor, waiter)
+		}
+	}
+	for _, holder := range holders {
+		if holder == txID {
+			continue
+		}
+		w, ok := ch.waitsFor[txID]
+		if !ok {
+			w = &lockWait{holders: make(map[string]struct{}), since: now}
+			ch.waitsFor[txID] = w
+		}
+		w.holders[holder] = struct{}{}
+		w.reported = now
+	}
+}
+
+// releaseWaits forgets the conflicts of the given transaction, which is prepared or released on the channel,
+// along with the conflicts with the transaction if it is released. The caller is expected to hold the write lock.
+func (ch *channelLocks) releaseWaits(txID string, released bool) {
+	delete(ch.waitsFor, txID)
+	if !released {
+		return
+	}
+	for waiter, w := range ch.waitsFor {
+		delete(w.holders, txID)
+		if len(w.holders) == 0 {
+			delete(ch.waitsFor, waiter)
+		}
+	}
+}
+
+// LockWaits returns the cross transactions of the given channel whose proposals access keys held by other
+// cross transactions, as reported in the last minute, sorted by ID
+func (m *LockManager) LockWaits(channelID string) []LockWait {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	var waits []LockWait
+	for txID, w := range ch.waitsFor {
+		if time.Since(w.reported) > conflictRetention {
+			continue
+		}
+		wait := LockWait{TxID: txID, Since: w.since}
+		for holder := range w.holders {
+			wait.Holders = append(wait.Holders, holder)
+		}
+		sort.Strings(wait.Holders)
+		waits = append(waits, wait)
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i].TxID < waits[j].TxID })
+	return waits
+}
@@ -0,0 +1,64 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockManagerLockWaits(t *testing.T) {
+	m := NewLockManager()
+	keyA, keyB := statedb.CompositeKey{Namespace: "cc1", Key: "a"}, statedb.CompositeKey{Namespace: "cc1", Key: "b"}
+	// tx1 and tx2 hold keys in opposite order on the two channels
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: ExclusiveLock}}, nil, nil))
+	assert.NoError(t, m.PrepareTx("ch2", "tx2", 7, []KeyLock{{CompositeKey: keyB, Mode: ExclusiveLock}}, nil, nil))
+	assert.Nil(t, m.LockWaits("ch1"))
+	assert.Nil(t, m.LockWaits("ch3"))
+
+	m.ReportConflict("ch1", "tx2", []string{"tx1"})
+	m.ReportConflict("ch2", "tx1", []string{"tx2", "tx1"})
+	m.ReportConflict("ch2", "tx3", []string{"tx2"})
+	m.ReportConflict("ch2", "tx1", []string{"tx0"})
+	// conflicts of local transactions are not recorded
+	m.ReportConflict("ch1", "", []string{"tx1"})
+
+	waits := m.LockWaits("ch1")
+	assert.Len(t, waits, 1)
+	assert.Equal(t, "tx2", waits[0].TxID)
+	assert.Equal(t, []string{"tx1"}, waits[0].Holders)
+	assert.False(t, waits[0].Since.IsZero())
+	waits = m.LockWaits("ch2")
+	assert.Len(t, waits, 2)
+	assert.Equal(t, "tx1", waits[0].TxID)
+	assert.Equal(t, []string{"tx0", "tx2"}, waits[0].Holders)
+	assert.Equal(t, "tx3", waits[1].TxID)
+
+	// the conflicts of a transaction are forgotten once it is prepared
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 8, nil, nil, nil))
+	assert.Nil(t, m.LockWaits("ch1"))
+
+	// and the conflicts with a transaction once it is released
+	assert.NoError(t, m.Release("ch2", "tx2"))
+	waits = m.LockWaits("ch2")
+	assert.Len(t, waits, 1)
+	assert.Equal(t, "tx1", waits[0].TxID)
+	assert.Equal(t, []string{"tx0"}, waits[0].Holders)
+
+	// conflicts not reported again expire
+	m.lock.Lock()
+	m.channels["ch2"].waitsFor["tx1"].reported = time.Now().Add(-2 * conflictRetention)
+	m.lock.Unlock()
+	assert.Nil(t, m.LockWaits("ch2"))
+	m.ReportConflict("ch2", "tx4", []string{"tx1"})
+	m.lock.RLock()
+	assert.Len(t, m.channels["ch2"].waitsFor, 1)
+	m.lock.RUnlock()
+}
@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// Blocker is a cross transaction holding keys accessed by another transaction in a conflicting mode
+type Blocker struct {
+	TxID          string
+	PreparedBlock uint64
+	// Since is the time the transaction was prepared on this peer
+	Since time.Time
+}
+
+// Blockers returns the cross transactions of the channel, other than the given transaction, holding keys read
+// or written by the given simulation results in a conflicting mode, or keys in the ranges it read. They are
+// ordered as the first conflicting key each of them holds, followed by the holders of keys in the ranges.
+func (m *LockManager) Blockers(channelID, txID string, txRWSet *rwsetutil.TxRwSet) []Blocker {
+	keys := LockKeys(txRWSet)
+	// the keys returned by range queries are not reads of the results, but are checked as reads all the same
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		if nsRWSet.NameSpace == lsccNamespace || nsRWSet.KvRwSet == nil {
+			continue
+		}
+		for _, rqi := range nsRWSet.KvRwSet.RangeQueriesInfo {
+			for _, kvRead := range rqi.GetRawReads().GetKvReads() {
+				keys = append(keys, KeyLock{CompositeKey: statedb.CompositeKey{Namespace: nsRWSet.NameSpace, Key: kvRead.Key}, Mode: SharedLock})
+			}
+		}
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ch := m.getChannel(channelID, false)
+	if ch == nil {
+		return nil
+	}
+	var blockers []Blocker
+	found := make(map[string]struct{})
+	add := func(holder string) {
+		if _, ok := found[holder]; ok {
+			return
+		}
+		found[holder] = struct{}{}
+		tx := ch.txs[holder]
+		blockers = append(blockers, Blocker{TxID: holder, PreparedBlock: tx.preparedBlock, Since: tx.registered})
+	}
+	for _, kl := range keys {
+		if holder, locked := ch.locks.Conflicts(txID, kl.CompositeKey, kl.Mode); locked {
+			add(holder)
+		}
+	}
+	for _, r := range LockRanges(txRWSet) {
+		if holder, locked := ch.locks.ConflictsRange(txID, r); locked {
+			add(holder)
+		}
+	}
+	return blockers
+}
+
+// waiter is a transaction waiting for the release of the cross transactions holding the keys it accesses
+type waiter struct {
+	// blockers are the transactions the waiter waits for, any cross transaction of the channel if empty
+	blockers map[string]struct{}
+	ready    bool
+	// turn is closed when the waiter gets its turn
+	turn chan struct{}
+}
+
+// waitQueue holds the transactions waiting for the release of cross transactions on a channel, in their order
+// of arrival. A waiter is ready once one of the transactions it waits for is released, and gets its turn after the
+// waiters ahead of it which are ready. It keeps the turn until it is done, so that the waiters released together
+// access the released keys one at a time, in their order of arrival.
+type waitQueue struct {
+	waiters  []*waiter
+	turnHeld bool
+}
+
+// released makes ready the waiters waiting for the given transaction
+func (q *waitQueue) released(txID string) {
+	for _, w := range q.waiters {
+		if _, ok := w.blockers[txID]; ok || len(w.blockers) == 0 {
+			w.ready = true
+		}
+	}
+	q.schedule()
+}
+
+// schedule gives the turn to the first ready waiter, unless a waiter already holds it
+func (q *waitQueue) schedule() {
+	if q.turnHeld {
+		return
+	}
+	for i, w := range q.waiters {
+		if w.ready {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			q.turnHeld = true
+			close(w.turn)
+			return
+		}
+	}
+}
+
+// remove removes the given waiter from the queue, returning false if the waiter already got its turn
+func (q *waitQueue) remove(w *waiter) bool {
+	for i, queued := range q.waiters {
+		if queued == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *waitQueue) endTurn() {
+	q.turnHeld = false
+	q.schedule()
+}
+
+// WaitRelease waits until one of the given cross transactions of the channel is released, or any cross transaction
+// of the channel when none is given, and until the waiters which arrived earlier and are released as well are done.
+// It does not wait for a release if one of the given transactions is not held anymore, or if no transaction is held
+// when none is given. It fails once the given context is done. The returned function ends the turn of the caller,
+// which is expected to call it once done accessing the released keys.
+func (m *LockManager) WaitRelease(ctx context.Context, channelID string, txIDs []string) (func(), error) {
+	m.lock.Lock()
+	ch := m.getChannel(channelID, true)
+	q := ch.waiting
+	w := &waiter{blockers: make(map[string]struct{}), ready: len(txIDs) == 0 && len(ch.txs) == 0, turn: make(chan struct{})}
+	for _, txID := range txIDs {
+		w.blockers[txID] = struct{}{}
+		if _, held := ch.txs[txID]; !held {
+			w.ready = true
+		}
+	}
+	q.waiters = append(q.waiters, w)
+	q.schedule()
+	m.lock.Unlock()
+
+	select {
+	case <-w.turn:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				m.lock.Lock()
+				defer m.lock.Unlock()
+				q.endTurn()
+			})
+		}, nil
+	case <-ctx.Done():
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if !q.remove(w) {
+			// the turn was given meanwhile
+			q.endTurn()
+		}
+		return nil, errors.WithMessage(ctx.Err(), fmt.Sprintf("gave up waiting for the release of cross transactions %v", txIDs))
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cross
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestLockManagerBlockers(t *testing.T) {
+	m := NewLockManager()
+	keyA, keyB := statedb.CompositeKey{Namespace: "cc1", Key: "a"}, statedb.CompositeKey{Namespace: "cc1", Key: "b"}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: SharedLock}}, nil, nil))
+	assert.NoError(t, m.PrepareTx("ch1", "tx2", 6, []KeyLock{{CompositeKey: keyB, Mode: ExclusiveLock}}, nil, nil))
+	assert.NoError(t, m.PrepareTx("ch1", "tx3", 6, nil, []RangeLock{{Namespace: "cc2", StartKey: "k", EndKey: "m"}}, nil))
+	info, _ := m.GetTxInfo("ch1", "tx2")
+	assert.Equal(t, uint64(6), info.PreparedBlock)
+
+	// reading a key held in shared mode does not conflict
+	builder := rwsetutil.NewRWSetBuilder()
+	builder.AddToReadSet("cc1", "a", nil)
+	assert.Empty(t, m.Blockers("ch1", "local", builder.GetTxReadWriteSet(nil)))
+
+	builder = rwsetutil.NewRWSetBuilder()
+	builder.AddToWriteSet("cc1", "a", nil)
+	builder.AddToWriteSet("cc2", "l", nil)
+	builder.AddToRangeQuerySet("cc1", &kvrwset.RangeQueryInfo{StartKey: "", EndKey: "", ItrExhausted: true})
+	builder.AddToRangeQuerySet("cc3", &kvrwset.RangeQueryInfo{StartKey: "a", EndKey: "c", ItrExhausted: true,
+		ReadsInfo: &kvrwset.RangeQueryInfo_RawReads{RawReads: &kvrwset.QueryReads{KvReads: []*kvrwset.KVRead{{Key: "b"}}}}})
+	blockers := m.Blockers("ch1", "local", builder.GetTxReadWriteSet(nil))
+	assert.Len(t, blockers, 3)
+	assert.Equal(t, "tx1", blockers[0].TxID)
+	assert.Equal(t, uint64(5), blockers[0].PreparedBlock)
+	assert.False(t, blockers[0].Since.IsZero())
+	assert.Equal(t, "tx3", blockers[1].TxID)
+	// tx2 holds a key of the range read
+	assert.Equal(t, "tx2", blockers[2].TxID)
+	assert.Nil(t, m.Blockers("ch2", "local", builder.GetTxReadWriteSet(nil)))
+}
+
+func TestLockManagerWaitRelease(t *testing.T) {
+	m := NewLockManager()
+	keyA := statedb.CompositeKey{Namespace: "cc1", Key: "a"}
+	assert.NoError(t, m.PrepareTx("ch1", "tx1", 5, []KeyLock{{CompositeKey: keyA, Mode: ExclusiveLock}}, nil, nil))
+
+	// a transaction already released is not waited for
+	done, err := m.WaitRelease(context.Background(), "ch1", []string{"tx0", "tx1"})
+	assert.NoError(t, err)
+	done()
+	// nor are the transactions of a channel holding none
+	done, err = m.WaitRelease(context.Background(), "ch2", nil)
+	assert.NoError(t, err)
+	done()
+
+	// the wait is bounded by the context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = m.WaitRelease(ctx, "ch1", []string{"tx1"})
+	assert.Error(t, err)
+
+	// the waiters released together get their turn in their order of arrival, one at a time
+	turns := make(chan int, 3)
+	dones := make(chan func(), 3)
+	for i := 0; i < 3; i++ {
+		txIDs := []string{"tx1"}
+		if i == 2 {
+			txIDs = nil
+		}
+		go func(i int) {
+			done, err := m.WaitRelease(context.Background(), "ch1", txIDs)
+			assert.NoError(t, err)
+			turns <- i
+			dones <- done
+		}(i)
+		waitForWaiters(t, m, "ch1", i+1)
+	}
+	select {
+	case <-turns:
+		t.Fatal("no waiter is expected to get its turn before the release")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.NoError(t, m.Release("ch1", "tx1"))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, <-turns)
+		select {
+		case <-turns:
+			t.Fatal("a single waiter is expected to hold the turn")
+		case <-time.After(10 * time.Millisecond):
+		}
+		done := <-dones
+		done()
+		// ending a turn twice does not end the turn of the next waiter
+		done()
+	}
+}
+
+func waitForWaiters(t *testing.T, m *LockManager, channelID string, n int) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		m.lock.RLock()
+		waiting := len(m.channels[channelID].waiting.waiters)
+		m.lock.RUnlock()
+		if waiting == n {
+			return
+		}
+	}
+	t.Fatalf("expected %d waiters", n)
+}
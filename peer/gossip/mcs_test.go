@@ -35,6 +35,7 @@ import (
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/gossip/mocks"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
 	pmsp "github.com/hyperledger/fabric/protos/msp"
 	protospeer "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -241,6 +242,11 @@ func mockBlock(t *testing.T, channel string, seqNum uint64, localSigner crypto.L
 		block.Header.DataHash = block.Data.Hash()
 	}
 
+	return signMockBlock(t, block, localSigner)
+}
+
+// signMockBlock adds the signature of the given signer to the block, and returns the marshaled block along with the signed message
+func signMockBlock(t *testing.T, block *common.Block, localSigner crypto.LocalSigner) ([]byte, []byte) {
 	// Add signer's signature to the block
 	shdr, err := localSigner.NewSignatureHeader()
 	assert.NoError(t, err, "Failed generating signature header")
@@ -270,6 +276,56 @@ func mockBlock(t *testing.T, channel string, seqNum uint64, localSigner crypto.L
 	return blockRaw, msg
 }
 
+func TestVerifyConfirmationBlock(t *testing.T) {
+	aliceSigner := &mockscrypto.LocalSigner{Identity: []byte("Alice")}
+	bobSigner := &mockscrypto.LocalSigner{Identity: []byte("Bob")}
+	aliceDeserializer := &mocks.IdentityDeserializer{[]byte("Alice"), nil, mock.Mock{}}
+	policyManagerGetter := &mocks.ChannelPolicyManagerGetterWithManager{
+		map[string]policies.Manager{
+			"C": &mocks.ChannelPolicyManager{&mocks.Policy{aliceDeserializer}},
+		},
+	}
+	msgCryptoService := NewMCS(
+		policyManagerGetter,
+		aliceSigner,
+		&mocks.DeserializersManager{
+			LocalDeserializer:    aliceDeserializer,
+			ChannelDeserializers: map[string]msp.IdentityDeserializer{},
+		},
+	)
+
+	confirmationBlock := func(outcome cross.Outcome) *common.Block {
+		env, err := utils.CreateSignedConfirmation("C", aliceSigner, &cross.Confirmation{CrossTxId: "tx1", Channels: []string{"C"}, Outcome: outcome})
+		assert.NoError(t, err)
+		block := common.NewBlock(42, nil)
+		block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+		block.Header.DataHash = block.Data.Hash()
+		return block
+	}
+
+	// - A confirmation block signed by the orderer is verified like any other block
+	block := confirmationBlock(cross.Outcome_SUCCESS)
+	blockRaw, msg := signMockBlock(t, block, aliceSigner)
+	aliceDeserializer.Msg = msg
+	assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, blockRaw))
+
+	// - The confirmation is replaced by another one, leaving the header and the signature unchanged
+	tampered := confirmationBlock(cross.Outcome_FAILURE)
+	tampered.Header = block.Header
+	tampered.Metadata = block.Metadata
+	err := msgCryptoService.VerifyBlock([]byte("C"), 42, utils.MarshalOrPanic(tampered))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Header.DataHash is different from Hash(block.Data)")
+
+	// - The data hash of the header is updated as well
+	tampered.Header = &common.BlockHeader{Number: 42, DataHash: tampered.Data.Hash()}
+	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, utils.MarshalOrPanic(tampered)))
+
+	// - The block is signed by an identity which does not satisfy the block validation policy
+	tamperedRaw, _ := signMockBlock(t, tampered, bobSigner)
+	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, tamperedRaw))
+}
+
 func TestExpiration(t *testing.T) {
 	expirationDate := time.Now().Add(time.Minute)
 	id1 := &pmsp.SerializedIdentity{
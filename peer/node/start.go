@@ -274,6 +274,12 @@ func serve(args []string) error {
 	})
 	endorserSupport.PluginEndorser = pluginEndorser
 	serverEndorser := endorser.NewEndorserServer(privDataDist, endorserSupport)
+	//NEW add
+	if viper.GetBool("peer.cross.lockWait.enabled") {
+		serverEndorser.CrossLockWait.Timeout = viper.GetDuration("peer.cross.lockWait.timeout")
+	}
+	serverEndorser.CrossLockWait.Lease = viper.GetDuration("peer.cross.lease")
+	//NEW end
 	auth := authHandler.ChainFilters(serverEndorser, authFilters...)
 	// Register the Endorser server
 	pb.RegisterEndorserServer(peerServer.Server(), auth)
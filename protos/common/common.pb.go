@@ -103,7 +103,8 @@ const (
 	BlockMetadataIndex_LAST_CONFIG         BlockMetadataIndex = 1
 	BlockMetadataIndex_TRANSACTIONS_FILTER BlockMetadataIndex = 2
 	BlockMetadataIndex_ORDERER             BlockMetadataIndex = 3
-	BlockMetadataIndex_CROSSINFO           BlockMetadataIndex = 4 //NEW add
+	// Deprecated: the cross-chain classification of a block is derived from its envelopes
+	BlockMetadataIndex_CROSSINFO BlockMetadataIndex = 4 //NEW add
 
 )
 
@@ -400,11 +401,6 @@ func (m *Envelope) GetCrossInfo() []byte{
 	return nil
 }
 
-//NEW add
-type CrossOverMsg struct{
-	Txid string `protobuf:"string,1,opt,name=txid,proto3" json:"txid,omitempty"`
-	IsSucc bool `protobuf:"bool,2,opt,name=issucc,proto3" json:"isSucc,omitempty"`
-}
 
 // This is finalized block structure to be shared among the orderer and peer
 // Note that the BlockHeader chains to the previous BlockHeader, and the BlockData hash is embedded
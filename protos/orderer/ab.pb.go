@@ -553,8 +553,6 @@ type AtomicBroadcast_BroadcastServer interface {
 	Send(*BroadcastResponse) error
 	Recv() (*common.Envelope, error)
 	grpc.ServerStream
-
-	RecvM() (*common.CrossOverMsg, error) // NEW add
 }
 
 type atomicBroadcastBroadcastServer struct {
@@ -574,16 +572,6 @@ func (x *atomicBroadcastBroadcastServer) Recv() (*common.Envelope, error) {
 	return m, nil
 }
 
-// NEW add
-func (x *atomicBroadcastBroadcastServer) RecvM() (*common.CrossOverMsg, error) {
-	m := new(common.CrossOverMsg) 
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	fmt.Println("Orderer了！！这是fabric/protos/orderer/ab.pb.go RecvM() m(common.CrossOverMsg)=", m)	
-	return m, nil
-}
-
 func _AtomicBroadcast_Deliver_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(AtomicBroadcastServer).Deliver(&atomicBroadcastDeliverServer{stream})
 }
@@ -58,6 +58,7 @@ It has these top-level messages:
 	SignedEvent
 	Event
 	DeliverResponse
+	CrossOutcomeEvent
 	PeerID
 	PeerEndpoint
 	SignedProposal
@@ -41,6 +41,7 @@ const (
 	ChaincodeMessage_KEEPALIVE           ChaincodeMessage_Type = 18
 	ChaincodeMessage_GET_HISTORY_FOR_KEY ChaincodeMessage_Type = 19
 	ChaincodeMessage_CROSS_KEY_LOCKED	 ChaincodeMessage_Type = 20 //New add
+	ChaincodeMessage_IS_KEY_CROSS_LOCKED ChaincodeMessage_Type = 21 //NEW add
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -64,6 +65,7 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	18: "KEEPALIVE",
 	19: "GET_HISTORY_FOR_KEY",
 	20: "CROSS_KEY_LOCKED",  //New add
+	21: "IS_KEY_CROSS_LOCKED", //NEW add
 }
 var ChaincodeMessage_Type_value = map[string]int32{
 	"UNDEFINED":           0,
@@ -86,6 +88,7 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"KEEPALIVE":           18,
 	"GET_HISTORY_FOR_KEY": 19,
 	"CROSS_KEY_LOCKED":    20,  //New add
+	"IS_KEY_CROSS_LOCKED": 21, //NEW add
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -515,57 +518,58 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/chaincode_shim.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 825 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x95, 0x51, 0x6f, 0xe2, 0x46,
-	0x10, 0xc7, 0x8f, 0x00, 0xc1, 0x0c, 0x09, 0xec, 0x6d, 0xae, 0xa9, 0x0f, 0xe9, 0x5a, 0x8a, 0xfa,
-	0x40, 0x5f, 0xa0, 0xa5, 0x7d, 0xe8, 0xc3, 0x49, 0x15, 0xc1, 0x1b, 0x62, 0x85, 0xd8, 0xdc, 0xda,
-	0x39, 0x1d, 0x7d, 0xb1, 0x1c, 0xbc, 0x67, 0xac, 0x1a, 0xaf, 0x6b, 0x2f, 0xa7, 0xf3, 0x67, 0xe8,
-	0x07, 0xeb, 0xd7, 0xaa, 0xd6, 0xc6, 0x84, 0x23, 0x8a, 0x4e, 0xea, 0x13, 0xfe, 0xcf, 0xfc, 0x66,
-	0xe6, 0x3f, 0xd6, 0xb2, 0x86, 0xd7, 0x31, 0x63, 0xc9, 0x68, 0xb5, 0x76, 0x83, 0x68, 0xc5, 0x3d,
-	0xe6, 0xa4, 0xeb, 0x60, 0x33, 0x8c, 0x13, 0x2e, 0x38, 0x3e, 0xcd, 0x7f, 0xd2, 0x6e, 0xf7, 0x08,
-	0x61, 0x9f, 0x58, 0x24, 0x0a, 0xa6, 0x7b, 0x91, 0xe7, 0xe2, 0x84, 0xc7, 0x3c, 0x75, 0xc3, 0x5d,
-	0xf0, 0x7b, 0x9f, 0x73, 0x3f, 0x64, 0xa3, 0x5c, 0x3d, 0x6c, 0x3f, 0x8e, 0x44, 0xb0, 0x61, 0xa9,
-	0x70, 0x37, 0x71, 0x01, 0xf4, 0xff, 0xa9, 0x03, 0x9a, 0x96, 0xfd, 0xee, 0x58, 0x9a, 0xba, 0x3e,
-	0xc3, 0xbf, 0x40, 0x4d, 0x64, 0x31, 0x53, 0x2b, 0xbd, 0xca, 0xa0, 0x3d, 0x7e, 0x53, 0xa0, 0xe9,
-	0xf0, 0x98, 0x1b, 0xda, 0x59, 0xcc, 0x68, 0x8e, 0xe2, 0xdf, 0xa1, 0xb9, 0x6f, 0xad, 0x9e, 0xf4,
-	0x2a, 0x83, 0xd6, 0xb8, 0x3b, 0x2c, 0x86, 0x0f, 0xcb, 0xe1, 0x43, 0xbb, 0x24, 0xe8, 0x23, 0x8c,
-	0x55, 0x68, 0xc4, 0x6e, 0x16, 0x72, 0xd7, 0x53, 0xab, 0xbd, 0xca, 0xe0, 0x8c, 0x96, 0x12, 0x63,
-	0xa8, 0x89, 0xcf, 0x81, 0xa7, 0xd6, 0x7a, 0x95, 0x41, 0x93, 0xe6, 0xcf, 0x78, 0x0c, 0x4a, 0xb9,
-	0xa2, 0x5a, 0xcf, 0xc7, 0x5c, 0x96, 0xf6, 0xac, 0xc0, 0x8f, 0x98, 0xb7, 0xd8, 0x65, 0xe9, 0x9e,
-	0xc3, 0x7f, 0x40, 0xe7, 0xe8, 0x95, 0xa9, 0xa7, 0x5f, 0x96, 0xee, 0x37, 0x23, 0x32, 0x4b, 0xdb,
-	0xab, 0x2f, 0x34, 0x7e, 0x03, 0xb0, 0x5a, 0xbb, 0x51, 0xc4, 0x42, 0x27, 0xf0, 0xd4, 0x46, 0x6e,
-	0xa7, 0xb9, 0x8b, 0xe8, 0x5e, 0xff, 0xdf, 0x13, 0xa8, 0xc9, 0x57, 0x81, 0xcf, 0xa1, 0x79, 0x6f,
-	0x68, 0xe4, 0x5a, 0x37, 0x88, 0x86, 0x5e, 0xe0, 0x33, 0x50, 0x28, 0x99, 0xe9, 0x96, 0x4d, 0x28,
-	0xaa, 0xe0, 0x36, 0x40, 0xa9, 0x88, 0x86, 0x4e, 0xb0, 0x02, 0x35, 0xdd, 0xd0, 0x6d, 0x54, 0xc5,
-	0x4d, 0xa8, 0x53, 0x32, 0xd1, 0x96, 0xa8, 0x86, 0x3b, 0xd0, 0xb2, 0xe9, 0xc4, 0xb0, 0x26, 0x53,
-	0x5b, 0x37, 0x0d, 0x54, 0x97, 0x2d, 0xa7, 0xe6, 0xdd, 0x62, 0x4e, 0x6c, 0xa2, 0xa1, 0x53, 0x89,
-	0x12, 0x4a, 0x4d, 0x8a, 0x1a, 0x32, 0x33, 0x23, 0xb6, 0x63, 0xd9, 0x13, 0x9b, 0x20, 0x45, 0xca,
-	0xc5, 0x7d, 0x29, 0x9b, 0x52, 0x6a, 0x64, 0xbe, 0x93, 0x80, 0x5f, 0x01, 0xd2, 0x8d, 0xf7, 0xe6,
-	0x2d, 0x71, 0xa6, 0x37, 0x13, 0xdd, 0x98, 0x9a, 0x1a, 0x41, 0xad, 0xc2, 0xa0, 0xb5, 0x30, 0x0d,
-	0x8b, 0xa0, 0x73, 0x7c, 0x09, 0x78, 0xdf, 0xd0, 0xb9, 0x5a, 0x3a, 0x74, 0x62, 0xcc, 0x08, 0x6a,
-	0xcb, 0x5a, 0x19, 0x7f, 0x77, 0x4f, 0xe8, 0xd2, 0xa1, 0xc4, 0xba, 0x9f, 0xdb, 0xa8, 0x23, 0xa3,
-	0x45, 0xa4, 0xe0, 0x0d, 0xf2, 0xc1, 0x46, 0x08, 0x7f, 0x03, 0x2f, 0x0f, 0xa3, 0xd3, 0xb9, 0x69,
-	0x11, 0xf4, 0x52, 0xba, 0xb9, 0x25, 0x64, 0x31, 0x99, 0xeb, 0xef, 0x09, 0xc2, 0xf8, 0x5b, 0xb8,
-	0x90, 0x1d, 0x6f, 0x74, 0xcb, 0x36, 0xe9, 0xd2, 0xb9, 0x36, 0xa9, 0x73, 0x4b, 0x96, 0xe8, 0xa2,
-	0xff, 0x16, 0x94, 0x19, 0x13, 0x96, 0x70, 0x05, 0xc3, 0x08, 0xaa, 0x7f, 0xb1, 0x2c, 0x3f, 0x83,
-	0x4d, 0x2a, 0x1f, 0xf1, 0x77, 0x00, 0x2b, 0x1e, 0x86, 0x6c, 0x25, 0x02, 0x1e, 0xe5, 0x87, 0xac,
-	0x49, 0x0f, 0x22, 0x7d, 0x0a, 0xca, 0x62, 0xfb, 0x6c, 0xf5, 0x2b, 0xa8, 0x7f, 0x72, 0xc3, 0x2d,
-	0xcb, 0x0b, 0xcf, 0x68, 0x21, 0x8e, 0x7a, 0x56, 0x9f, 0xf4, 0x7c, 0x0b, 0x8a, 0xc6, 0xc2, 0xff,
-	0xeb, 0x88, 0x41, 0xa7, 0xdc, 0xe7, 0x2a, 0xa3, 0x6e, 0xe4, 0x33, 0xdc, 0x05, 0x25, 0x15, 0x6e,
-	0x22, 0x6e, 0xf7, 0x9d, 0xf6, 0x1a, 0x5f, 0xc2, 0x29, 0x8b, 0x3c, 0x99, 0x29, 0x5a, 0xed, 0xd4,
-	0x57, 0x4d, 0x5e, 0x43, 0x7b, 0xc6, 0xc4, 0xbb, 0x2d, 0x4b, 0x32, 0xca, 0xd2, 0x6d, 0x28, 0xe4,
-	0xb2, 0x7f, 0x4b, 0xb9, 0x1b, 0x51, 0x88, 0xaf, 0xda, 0xfd, 0x11, 0xd0, 0x8c, 0x89, 0x9b, 0x20,
-	0x15, 0x3c, 0xc9, 0xae, 0x79, 0x22, 0x67, 0x3f, 0x59, 0xba, 0xdf, 0x83, 0x76, 0x3e, 0x2a, 0x5f,
-	0xcb, 0x60, 0x9f, 0x05, 0x6e, 0xc3, 0x49, 0xe0, 0xed, 0x90, 0x93, 0xc0, 0xeb, 0xff, 0x00, 0x9d,
-	0x47, 0x62, 0x1a, 0xf2, 0x94, 0x3d, 0x41, 0x7e, 0x03, 0x74, 0xe0, 0xf7, 0x2a, 0x13, 0x2c, 0xc5,
-	0x3d, 0x68, 0x25, 0x8f, 0x32, 0x87, 0xcf, 0xe8, 0x61, 0xa8, 0x1f, 0xc1, 0x79, 0x59, 0x15, 0xf3,
-	0x28, 0x65, 0x78, 0x0c, 0x8d, 0x22, 0x2f, 0xf1, 0xea, 0xa0, 0x35, 0x56, 0xcb, 0xbf, 0xf4, 0x71,
-	0x77, 0x5a, 0x82, 0xf8, 0x35, 0x28, 0x6b, 0x37, 0x75, 0x36, 0x3c, 0x29, 0xce, 0x82, 0x42, 0x1b,
-	0x6b, 0x37, 0xbd, 0xe3, 0x49, 0xe9, 0xb2, 0x5a, 0xba, 0x1c, 0x7f, 0x38, 0xb8, 0x1c, 0xad, 0x6d,
-	0x1c, 0xf3, 0x44, 0x60, 0x0d, 0x14, 0xca, 0xfc, 0x20, 0x15, 0x2c, 0xc1, 0xea, 0x73, 0x57, 0x63,
-	0xf7, 0xd9, 0x4c, 0xff, 0xc5, 0xa0, 0xf2, 0x73, 0xe5, 0xca, 0x84, 0x3e, 0x4f, 0xfc, 0xe1, 0x3a,
-	0x8b, 0x59, 0x12, 0x32, 0xcf, 0x67, 0xc9, 0xf0, 0xa3, 0xfb, 0x90, 0x04, 0xab, 0xb2, 0x4e, 0xde,
-	0xe6, 0x7f, 0xfe, 0xe4, 0x07, 0x62, 0xbd, 0x7d, 0x18, 0xae, 0xf8, 0x66, 0x74, 0x80, 0x8e, 0x0a,
-	0xb4, 0xb8, 0xd5, 0xd3, 0x91, 0x44, 0x1f, 0x8a, 0x4f, 0xc4, 0xaf, 0xff, 0x05, 0x00, 0x00, 0xff,
-	0xff, 0x18, 0x6a, 0x79, 0xe7, 0x46, 0x06, 0x00, 0x00,
+	// 840 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x95, 0x5f, 0x6f, 0xe2, 0x46,
+	0x17, 0xc6, 0x97, 0x7f, 0xc1, 0x1c, 0x12, 0x98, 0x9d, 0xec, 0xe6, 0xf5, 0x22, 0xed, 0x5b, 0x8a,
+	0x7a, 0x41, 0x6f, 0xa0, 0xa5, 0xbd, 0xe8, 0xc5, 0x4a, 0x15, 0xc1, 0x13, 0x62, 0x41, 0x6c, 0x76,
+	0xec, 0xac, 0x96, 0xde, 0x58, 0x0e, 0x9e, 0x05, 0xab, 0xc6, 0xe3, 0xda, 0xc3, 0x6a, 0xfd, 0x7d,
+	0xfa, 0xc1, 0xfa, 0x51, 0xaa, 0xb1, 0x31, 0x61, 0x89, 0xa2, 0x95, 0x7a, 0x95, 0x79, 0xce, 0xf9,
+	0x9d, 0x73, 0x9e, 0x63, 0x4d, 0x06, 0x78, 0x13, 0x31, 0x16, 0x0f, 0x57, 0x1b, 0xd7, 0x0f, 0x57,
+	0xdc, 0x63, 0x4e, 0xb2, 0xf1, 0xb7, 0x83, 0x28, 0xe6, 0x82, 0xe3, 0xb3, 0xec, 0x4f, 0xd2, 0xe9,
+	0x9c, 0x20, 0xec, 0x33, 0x0b, 0x45, 0xce, 0x74, 0x2e, 0xb3, 0x5c, 0x14, 0xf3, 0x88, 0x27, 0x6e,
+	0xb0, 0x0f, 0x7e, 0xb7, 0xe6, 0x7c, 0x1d, 0xb0, 0x61, 0xa6, 0x1e, 0x76, 0x9f, 0x86, 0xc2, 0xdf,
+	0xb2, 0x44, 0xb8, 0xdb, 0x28, 0x07, 0x7a, 0x7f, 0xd7, 0x00, 0x4d, 0x8a, 0x7e, 0x77, 0x2c, 0x49,
+	0xdc, 0x35, 0xc3, 0x3f, 0x43, 0x55, 0xa4, 0x11, 0x53, 0x4b, 0xdd, 0x52, 0xbf, 0x35, 0x7a, 0x9b,
+	0xa3, 0xc9, 0xe0, 0x94, 0x1b, 0xd8, 0x69, 0xc4, 0x68, 0x86, 0xe2, 0xdf, 0xa0, 0x71, 0x68, 0xad,
+	0x96, 0xbb, 0xa5, 0x7e, 0x73, 0xd4, 0x19, 0xe4, 0xc3, 0x07, 0xc5, 0xf0, 0x81, 0x5d, 0x10, 0xf4,
+	0x11, 0xc6, 0x2a, 0xd4, 0x23, 0x37, 0x0d, 0xb8, 0xeb, 0xa9, 0x95, 0x6e, 0xa9, 0x7f, 0x4e, 0x0b,
+	0x89, 0x31, 0x54, 0xc5, 0x17, 0xdf, 0x53, 0xab, 0xdd, 0x52, 0xbf, 0x41, 0xb3, 0x33, 0x1e, 0x81,
+	0x52, 0xac, 0xa8, 0xd6, 0xb2, 0x31, 0x57, 0x85, 0x3d, 0xcb, 0x5f, 0x87, 0xcc, 0x5b, 0xec, 0xb3,
+	0xf4, 0xc0, 0xe1, 0xdf, 0xa1, 0x7d, 0xf2, 0xc9, 0xd4, 0xb3, 0xaf, 0x4b, 0x0f, 0x9b, 0x11, 0x99,
+	0xa5, 0xad, 0xd5, 0x57, 0x1a, 0xbf, 0x05, 0x58, 0x6d, 0xdc, 0x30, 0x64, 0x81, 0xe3, 0x7b, 0x6a,
+	0x3d, 0xb3, 0xd3, 0xd8, 0x47, 0x74, 0xaf, 0xf7, 0x4f, 0x19, 0xaa, 0xf2, 0x53, 0xe0, 0x0b, 0x68,
+	0xdc, 0x1b, 0x1a, 0xb9, 0xd1, 0x0d, 0xa2, 0xa1, 0x17, 0xf8, 0x1c, 0x14, 0x4a, 0xa6, 0xba, 0x65,
+	0x13, 0x8a, 0x4a, 0xb8, 0x05, 0x50, 0x28, 0xa2, 0xa1, 0x32, 0x56, 0xa0, 0xaa, 0x1b, 0xba, 0x8d,
+	0x2a, 0xb8, 0x01, 0x35, 0x4a, 0xc6, 0xda, 0x12, 0x55, 0x71, 0x1b, 0x9a, 0x36, 0x1d, 0x1b, 0xd6,
+	0x78, 0x62, 0xeb, 0xa6, 0x81, 0x6a, 0xb2, 0xe5, 0xc4, 0xbc, 0x5b, 0xcc, 0x89, 0x4d, 0x34, 0x74,
+	0x26, 0x51, 0x42, 0xa9, 0x49, 0x51, 0x5d, 0x66, 0xa6, 0xc4, 0x76, 0x2c, 0x7b, 0x6c, 0x13, 0xa4,
+	0x48, 0xb9, 0xb8, 0x2f, 0x64, 0x43, 0x4a, 0x8d, 0xcc, 0xf7, 0x12, 0xf0, 0x2b, 0x40, 0xba, 0xf1,
+	0xc1, 0x9c, 0x11, 0x67, 0x72, 0x3b, 0xd6, 0x8d, 0x89, 0xa9, 0x11, 0xd4, 0xcc, 0x0d, 0x5a, 0x0b,
+	0xd3, 0xb0, 0x08, 0xba, 0xc0, 0x57, 0x80, 0x0f, 0x0d, 0x9d, 0xeb, 0xa5, 0x43, 0xc7, 0xc6, 0x94,
+	0xa0, 0x96, 0xac, 0x95, 0xf1, 0xf7, 0xf7, 0x84, 0x2e, 0x1d, 0x4a, 0xac, 0xfb, 0xb9, 0x8d, 0xda,
+	0x32, 0x9a, 0x47, 0x72, 0xde, 0x20, 0x1f, 0x6d, 0x84, 0xf0, 0x6b, 0x78, 0x79, 0x1c, 0x9d, 0xcc,
+	0x4d, 0x8b, 0xa0, 0x97, 0xd2, 0xcd, 0x8c, 0x90, 0xc5, 0x78, 0xae, 0x7f, 0x20, 0x08, 0xe3, 0xff,
+	0xc1, 0xa5, 0xec, 0x78, 0xab, 0x5b, 0xb6, 0x49, 0x97, 0xce, 0x8d, 0x49, 0x9d, 0x19, 0x59, 0xa2,
+	0x4b, 0x99, 0xd0, 0x2d, 0x79, 0x76, 0x26, 0xd4, 0xb4, 0x2c, 0x67, 0x6e, 0x4e, 0x66, 0x44, 0x43,
+	0xaf, 0x7b, 0xef, 0x40, 0x99, 0x32, 0x61, 0x09, 0x57, 0x30, 0x8c, 0xa0, 0xf2, 0x27, 0x4b, 0xb3,
+	0xcb, 0xd9, 0xa0, 0xf2, 0x88, 0xff, 0x0f, 0xb0, 0xe2, 0x41, 0xc0, 0x56, 0xc2, 0xe7, 0x61, 0x76,
+	0xfb, 0x1a, 0xf4, 0x28, 0xd2, 0xa3, 0xa0, 0x2c, 0x76, 0xcf, 0x56, 0xbf, 0x82, 0xda, 0x67, 0x37,
+	0xd8, 0xb1, 0xac, 0xf0, 0x9c, 0xe6, 0xe2, 0xa4, 0x67, 0xe5, 0x49, 0xcf, 0x77, 0xa0, 0x68, 0x2c,
+	0xf8, 0xaf, 0x8e, 0x18, 0xb4, 0x8b, 0x7d, 0xae, 0x53, 0xea, 0x86, 0x6b, 0x86, 0x3b, 0xa0, 0x24,
+	0xc2, 0x8d, 0xc5, 0xec, 0xd0, 0xe9, 0xa0, 0xf1, 0x15, 0x9c, 0xb1, 0xd0, 0x93, 0x99, 0xbc, 0xd5,
+	0x5e, 0x7d, 0xd3, 0xe4, 0x0d, 0xb4, 0xa6, 0x4c, 0xbc, 0xdf, 0xb1, 0x38, 0xa5, 0x2c, 0xd9, 0x05,
+	0x42, 0x2e, 0xfb, 0x97, 0x94, 0xfb, 0x11, 0xb9, 0xf8, 0xa6, 0xdd, 0x1f, 0x00, 0x4d, 0x99, 0xb8,
+	0xf5, 0x13, 0xc1, 0xe3, 0xf4, 0x86, 0xc7, 0x72, 0xf6, 0x93, 0xa5, 0x7b, 0x5d, 0x68, 0x65, 0xa3,
+	0xb2, 0xb5, 0x0c, 0xf6, 0x45, 0xe0, 0x16, 0x94, 0x7d, 0x6f, 0x8f, 0x94, 0x7d, 0xaf, 0xf7, 0x3d,
+	0xb4, 0x1f, 0x89, 0x49, 0xc0, 0x13, 0xf6, 0x04, 0xf9, 0x15, 0xd0, 0x91, 0xdf, 0xeb, 0x54, 0xb0,
+	0x04, 0x77, 0xa1, 0x19, 0x3f, 0xca, 0x0c, 0x3e, 0xa7, 0xc7, 0xa1, 0x5e, 0x08, 0x17, 0x45, 0x55,
+	0xc4, 0xc3, 0x84, 0xe1, 0x11, 0xd4, 0xf3, 0xbc, 0xc4, 0x2b, 0xfd, 0xe6, 0x48, 0x2d, 0xfe, 0xd7,
+	0x4f, 0xbb, 0xd3, 0x02, 0xc4, 0x6f, 0x40, 0xd9, 0xb8, 0x89, 0xb3, 0xe5, 0x71, 0x7e, 0x17, 0x14,
+	0x5a, 0xdf, 0xb8, 0xc9, 0x1d, 0x8f, 0x0b, 0x97, 0x95, 0xc2, 0xe5, 0xe8, 0xe3, 0xd1, 0xab, 0x69,
+	0xed, 0xa2, 0x88, 0xc7, 0x02, 0x6b, 0xa0, 0x50, 0xb6, 0xf6, 0x13, 0xc1, 0x62, 0xac, 0x3e, 0xf7,
+	0x66, 0x76, 0x9e, 0xcd, 0xf4, 0x5e, 0xf4, 0x4b, 0x3f, 0x95, 0xae, 0x4d, 0xe8, 0xf1, 0x78, 0x3d,
+	0xd8, 0xa4, 0x11, 0x8b, 0x03, 0xe6, 0xad, 0x59, 0x3c, 0xf8, 0xe4, 0x3e, 0xc4, 0xfe, 0xaa, 0xa8,
+	0x93, 0xcf, 0xfc, 0x1f, 0x3f, 0xae, 0x7d, 0xb1, 0xd9, 0x3d, 0x0c, 0x56, 0x7c, 0x3b, 0x3c, 0x42,
+	0x87, 0x39, 0x9a, 0x3f, 0xf7, 0xc9, 0x50, 0xa2, 0x0f, 0xf9, 0x6f, 0xc7, 0x2f, 0xff, 0x0e, 0x00,
+	0x60, 0x6c, 0xfe, 0x5f, 0x5f, 0x06, 0x00, 0x00,
 }
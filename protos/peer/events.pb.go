@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import common "github.com/hyperledger/fabric/protos/common"
+import cross "github.com/hyperledger/fabric/protos/ledger/cross"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
@@ -288,6 +289,7 @@ type FilteredTransaction struct {
 	TxValidationCode TxValidationCode  `protobuf:"varint,3,opt,name=tx_validation_code,json=txValidationCode,enum=protos.TxValidationCode" json:"tx_validation_code,omitempty"`
 	// Types that are valid to be assigned to Data:
 	//	*FilteredTransaction_TransactionActions
+	//	*FilteredTransaction_CrossOutcome
 	Data isFilteredTransaction_Data `protobuf_oneof:"Data"`
 }
 
@@ -301,8 +303,12 @@ type isFilteredTransaction_Data interface{ isFilteredTransaction_Data() }
 type FilteredTransaction_TransactionActions struct {
 	TransactionActions *FilteredTransactionActions `protobuf:"bytes,4,opt,name=transaction_actions,json=transactionActions,oneof"`
 }
+type FilteredTransaction_CrossOutcome struct {
+	CrossOutcome *cross.CrossOutcome `protobuf:"bytes,5,opt,name=cross_outcome,json=crossOutcome,oneof"`
+}
 
 func (*FilteredTransaction_TransactionActions) isFilteredTransaction_Data() {}
+func (*FilteredTransaction_CrossOutcome) isFilteredTransaction_Data()       {}
 
 func (m *FilteredTransaction) GetData() isFilteredTransaction_Data {
 	if m != nil {
@@ -339,10 +345,18 @@ func (m *FilteredTransaction) GetTransactionActions() *FilteredTransactionAction
 	return nil
 }
 
+func (m *FilteredTransaction) GetCrossOutcome() *cross.CrossOutcome {
+	if x, ok := m.GetData().(*FilteredTransaction_CrossOutcome); ok {
+		return x.CrossOutcome
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*FilteredTransaction) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _FilteredTransaction_OneofMarshaler, _FilteredTransaction_OneofUnmarshaler, _FilteredTransaction_OneofSizer, []interface{}{
 		(*FilteredTransaction_TransactionActions)(nil),
+		(*FilteredTransaction_CrossOutcome)(nil),
 	}
 }
 
@@ -355,6 +369,11 @@ func _FilteredTransaction_OneofMarshaler(msg proto.Message, b *proto.Buffer) err
 		if err := b.EncodeMessage(x.TransactionActions); err != nil {
 			return err
 		}
+	case *FilteredTransaction_CrossOutcome:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.CrossOutcome); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("FilteredTransaction.Data has unexpected type %T", x)
@@ -373,6 +392,14 @@ func _FilteredTransaction_OneofUnmarshaler(msg proto.Message, tag, wire int, b *
 		err := b.DecodeMessage(msg)
 		m.Data = &FilteredTransaction_TransactionActions{msg}
 		return true, err
+	case 5: // Data.cross_outcome
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(cross.CrossOutcome)
+		err := b.DecodeMessage(msg)
+		m.Data = &FilteredTransaction_CrossOutcome{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -387,6 +414,11 @@ func _FilteredTransaction_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(4<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *FilteredTransaction_CrossOutcome:
+		s := proto.Size(x.CrossOutcome)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -458,8 +490,8 @@ func (m *SignedEvent) GetEventBytes() []byte {
 }
 
 // Event is used by
-//  - consumers (adapters) to send Register
-//  - producer to advertise supported types and events
+//   - consumers (adapters) to send Register
+//   - producer to advertise supported types and events
 type Event struct {
 	// Types that are valid to be assigned to Event:
 	//	*Event_Register
@@ -737,6 +769,7 @@ type DeliverResponse struct {
 	//	*DeliverResponse_Status
 	//	*DeliverResponse_Block
 	//	*DeliverResponse_FilteredBlock
+	//	*DeliverResponse_CrossOutcomeEvent
 	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
 }
 
@@ -756,10 +789,14 @@ type DeliverResponse_Block struct {
 type DeliverResponse_FilteredBlock struct {
 	FilteredBlock *FilteredBlock `protobuf:"bytes,3,opt,name=filtered_block,json=filteredBlock,oneof"`
 }
+type DeliverResponse_CrossOutcomeEvent struct {
+	CrossOutcomeEvent *CrossOutcomeEvent `protobuf:"bytes,4,opt,name=cross_outcome_event,json=crossOutcomeEvent,oneof"`
+}
 
-func (*DeliverResponse_Status) isDeliverResponse_Type()        {}
-func (*DeliverResponse_Block) isDeliverResponse_Type()         {}
-func (*DeliverResponse_FilteredBlock) isDeliverResponse_Type() {}
+func (*DeliverResponse_Status) isDeliverResponse_Type()            {}
+func (*DeliverResponse_Block) isDeliverResponse_Type()             {}
+func (*DeliverResponse_FilteredBlock) isDeliverResponse_Type()     {}
+func (*DeliverResponse_CrossOutcomeEvent) isDeliverResponse_Type() {}
 
 func (m *DeliverResponse) GetType() isDeliverResponse_Type {
 	if m != nil {
@@ -789,12 +826,20 @@ func (m *DeliverResponse) GetFilteredBlock() *FilteredBlock {
 	return nil
 }
 
+func (m *DeliverResponse) GetCrossOutcomeEvent() *CrossOutcomeEvent {
+	if x, ok := m.GetType().(*DeliverResponse_CrossOutcomeEvent); ok {
+		return x.CrossOutcomeEvent
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
 		(*DeliverResponse_Status)(nil),
 		(*DeliverResponse_Block)(nil),
 		(*DeliverResponse_FilteredBlock)(nil),
+		(*DeliverResponse_CrossOutcomeEvent)(nil),
 	}
 }
 
@@ -815,6 +860,11 @@ func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.FilteredBlock); err != nil {
 			return err
 		}
+	case *DeliverResponse_CrossOutcomeEvent:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.CrossOutcomeEvent); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
@@ -848,6 +898,14 @@ func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &DeliverResponse_FilteredBlock{msg}
 		return true, err
+	case 4: // Type.cross_outcome_event
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(CrossOutcomeEvent)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_CrossOutcomeEvent{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -870,6 +928,11 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *DeliverResponse_CrossOutcomeEvent:
+		s := proto.Size(x.CrossOutcomeEvent)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -877,6 +940,40 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// CrossOutcomeEvent is sent by the Deliver service after a block carrying confirmations, and
+// tells the outcome of the cross transactions confirmed by the block
+type CrossOutcomeEvent struct {
+	ChannelId     string                `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Number        uint64                `protobuf:"varint,2,opt,name=number" json:"number,omitempty"`
+	CrossOutcomes []*cross.CrossOutcome `protobuf:"bytes,3,rep,name=cross_outcomes,json=crossOutcomes" json:"cross_outcomes,omitempty"`
+}
+
+func (m *CrossOutcomeEvent) Reset()                    { *m = CrossOutcomeEvent{} }
+func (m *CrossOutcomeEvent) String() string            { return proto.CompactTextString(m) }
+func (*CrossOutcomeEvent) ProtoMessage()               {}
+func (*CrossOutcomeEvent) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{12} }
+
+func (m *CrossOutcomeEvent) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *CrossOutcomeEvent) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *CrossOutcomeEvent) GetCrossOutcomes() []*cross.CrossOutcome {
+	if m != nil {
+		return m.CrossOutcomes
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ChaincodeReg)(nil), "protos.ChaincodeReg")
 	proto.RegisterType((*Interest)(nil), "protos.Interest")
@@ -890,6 +987,7 @@ func init() {
 	proto.RegisterType((*SignedEvent)(nil), "protos.SignedEvent")
 	proto.RegisterType((*Event)(nil), "protos.Event")
 	proto.RegisterType((*DeliverResponse)(nil), "protos.DeliverResponse")
+	proto.RegisterType((*CrossOutcomeEvent)(nil), "protos.CrossOutcomeEvent")
 	proto.RegisterEnum("protos.EventType", EventType_name, EventType_value)
 }
 
@@ -1171,69 +1269,74 @@ var _Deliver_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/events.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 1009 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xdd, 0x72, 0xdb, 0x44,
-	0x14, 0xb6, 0x62, 0xc7, 0xb1, 0x8e, 0xe3, 0xd4, 0xd9, 0xb4, 0xa9, 0xc6, 0x05, 0x5a, 0xc4, 0xc0,
-	0x04, 0x2e, 0xec, 0x60, 0x3a, 0x0c, 0xd3, 0x0b, 0x98, 0xf8, 0x27, 0xc8, 0x34, 0x4d, 0x32, 0x1b,
-	0x87, 0x8b, 0x5e, 0xa0, 0x59, 0xcb, 0x6b, 0x59, 0xad, 0x2c, 0x79, 0x76, 0xd7, 0x99, 0xe4, 0x11,
-	0x78, 0x03, 0xde, 0x80, 0x19, 0x5e, 0x85, 0x17, 0xe2, 0x92, 0xd1, 0x6a, 0x57, 0x52, 0x6c, 0xda,
-	0x21, 0x57, 0xf6, 0xf9, 0xfb, 0xf6, 0xfc, 0x7c, 0x67, 0x57, 0xb0, 0xbf, 0xa4, 0x94, 0x75, 0xe8,
-	0x0d, 0x8d, 0x04, 0x6f, 0x2f, 0x59, 0x2c, 0x62, 0x54, 0x95, 0x3f, 0xbc, 0x75, 0xe0, 0xc5, 0x8b,
-	0x45, 0x1c, 0x75, 0xd2, 0x9f, 0xd4, 0xd8, 0x7a, 0xee, 0xc7, 0xb1, 0x1f, 0xd2, 0x8e, 0x94, 0x26,
-	0xab, 0x59, 0x47, 0x04, 0x0b, 0xca, 0x05, 0x59, 0x2c, 0x95, 0x43, 0x4b, 0x02, 0x7a, 0x73, 0x12,
-	0x44, 0x5e, 0x3c, 0xa5, 0xae, 0x84, 0x56, 0xb6, 0x43, 0x69, 0x13, 0x8c, 0x44, 0x9c, 0x78, 0x22,
-	0xd0, 0xa0, 0xf6, 0x25, 0xec, 0xf6, 0x75, 0x00, 0xa6, 0x3e, 0xfa, 0x1c, 0x76, 0x73, 0x80, 0x60,
-	0x6a, 0x19, 0x2f, 0x8c, 0x23, 0x13, 0xd7, 0x33, 0xdd, 0x68, 0x8a, 0x3e, 0x05, 0x90, 0xc8, 0x6e,
-	0x44, 0x16, 0xd4, 0xda, 0x92, 0x0e, 0xa6, 0xd4, 0x9c, 0x93, 0x05, 0xb5, 0xff, 0x34, 0xa0, 0x36,
-	0x8a, 0x04, 0x65, 0x94, 0x0b, 0x74, 0xac, 0x7d, 0xc5, 0xdd, 0x92, 0x4a, 0xb0, 0xbd, 0xee, 0x7e,
-	0x7a, 0x34, 0x6f, 0x0f, 0x13, 0xcb, 0xf8, 0x6e, 0x49, 0x55, 0x78, 0xf2, 0x17, 0x0d, 0x00, 0xe5,
-	0x09, 0x30, 0xea, 0xbb, 0x41, 0x34, 0x8b, 0xe5, 0x29, 0xf5, 0xee, 0x63, 0x1d, 0x59, 0x4c, 0xd9,
-	0x29, 0xe1, 0xa6, 0x57, 0x90, 0x47, 0xd1, 0x2c, 0x46, 0x16, 0xec, 0x48, 0xdd, 0x68, 0x60, 0x95,
-	0x65, 0x82, 0x5a, 0xec, 0x99, 0xb0, 0xa3, 0x9c, 0xec, 0x97, 0x50, 0xc3, 0xd4, 0x0f, 0xb8, 0xa0,
-	0x0c, 0x1d, 0x41, 0x35, 0x9d, 0x84, 0x65, 0xbc, 0x28, 0x1f, 0xd5, 0xbb, 0x4d, 0x7d, 0x94, 0x2e,
-	0x05, 0x2b, 0xbb, 0xfd, 0x06, 0x4c, 0x4c, 0xdf, 0x51, 0xd9, 0x44, 0xf4, 0x05, 0x6c, 0x89, 0x5b,
-	0x59, 0x57, 0xbd, 0x7b, 0xa0, 0x43, 0xc6, 0x79, 0x97, 0xf1, 0x96, 0xb8, 0x45, 0xcf, 0xc0, 0xa4,
-	0x8c, 0xc5, 0xcc, 0x5d, 0x70, 0x5f, 0xf5, 0xab, 0x26, 0x15, 0x6f, 0xb8, 0x6f, 0x7f, 0x0f, 0x70,
-	0x1d, 0xb1, 0x87, 0xa7, 0xf1, 0x87, 0x01, 0x8d, 0xd3, 0x20, 0x4c, 0xb4, 0xd3, 0x5e, 0x18, 0x7b,
-	0xef, 0x93, 0xb9, 0x78, 0x73, 0x12, 0x45, 0x34, 0xcc, 0x07, 0x67, 0x2a, 0xcd, 0x68, 0x8a, 0x0e,
-	0xa1, 0x1a, 0xad, 0x16, 0x13, 0xca, 0x64, 0x0a, 0x15, 0xac, 0x24, 0x74, 0x09, 0x4f, 0x66, 0x0a,
-	0xc7, 0x2d, 0xf0, 0x83, 0x5b, 0x15, 0x99, 0xc1, 0x33, 0x9d, 0x81, 0x3e, 0xac, 0x58, 0xdd, 0xe3,
-	0xd9, 0xa6, 0x92, 0xdb, 0xff, 0x18, 0x70, 0xf0, 0x1f, 0xde, 0x08, 0x41, 0x45, 0xdc, 0x66, 0xa9,
-	0xc9, 0xff, 0xe8, 0x2b, 0xa8, 0x48, 0x6a, 0x6c, 0x49, 0x6a, 0xa0, 0xb6, 0x62, 0xbc, 0x43, 0xc9,
-	0x94, 0x32, 0xc9, 0x0d, 0x69, 0x47, 0xa7, 0x80, 0xc4, 0xad, 0x7b, 0x43, 0xc2, 0x60, 0x4a, 0x12,
-	0x30, 0x37, 0x99, 0xb6, 0x9c, 0xed, 0x5e, 0xd7, 0xca, 0x1a, 0x7f, 0xfb, 0x6b, 0xe6, 0xd0, 0x4f,
-	0xd8, 0xd0, 0x14, 0x6b, 0x1a, 0x74, 0x0d, 0x07, 0x85, 0x22, 0xdd, 0xbc, 0xd6, 0x64, 0x82, 0xf6,
-	0x47, 0x6a, 0x3d, 0x49, 0x3d, 0x9d, 0x12, 0x46, 0x62, 0x43, 0xdb, 0xab, 0x42, 0x65, 0x40, 0x04,
-	0xb1, 0xdf, 0x41, 0xeb, 0xc3, 0xb1, 0xe8, 0x0c, 0xf6, 0x73, 0x6e, 0xeb, 0xa3, 0xd3, 0x41, 0x3f,
-	0x5f, 0x3f, 0x3a, 0xa3, 0x78, 0x1a, 0x5c, 0xe0, 0xb8, 0x42, 0xb3, 0xdf, 0xc2, 0xd3, 0x0f, 0x38,
-	0xa3, 0x9f, 0xe0, 0xd1, 0xda, 0x35, 0xa0, 0x38, 0x7a, 0xb8, 0xb1, 0x41, 0x72, 0x09, 0xf1, 0x9e,
-	0x77, 0x4f, 0xb6, 0x5f, 0x43, 0xfd, 0x2a, 0xf0, 0x23, 0x3a, 0x95, 0x22, 0xfa, 0x04, 0x4c, 0x1e,
-	0xf8, 0x11, 0x11, 0x2b, 0x96, 0x6e, 0xf1, 0x2e, 0xce, 0x15, 0xe8, 0x33, 0xb5, 0xe4, 0xbd, 0x3b,
-	0x41, 0xb9, 0x9c, 0xe4, 0x2e, 0x2e, 0x68, 0xec, 0xbf, 0xcb, 0xb0, 0x9d, 0xe2, 0xb4, 0xa1, 0xa6,
-	0xa9, 0xae, 0x12, 0xca, 0x08, 0xae, 0x37, 0xd1, 0x29, 0xe1, 0xcc, 0x07, 0x7d, 0x09, 0xdb, 0x93,
-	0x84, 0xdb, 0x6a, 0xff, 0x1b, 0x9a, 0x1e, 0x92, 0xf0, 0x4e, 0x09, 0xa7, 0x56, 0x74, 0xb2, 0x59,
-	0x6e, 0xf9, 0x63, 0xe5, 0x3a, 0xa5, 0xf5, 0x82, 0xd1, 0xb7, 0x60, 0x32, 0xbd, 0xd5, 0x8a, 0x0d,
-	0xfb, 0x79, 0x6a, 0xca, 0xe0, 0x94, 0x70, 0xee, 0x85, 0x5e, 0x02, 0xac, 0xb2, 0xcd, 0xb5, 0xb6,
-	0x65, 0x0c, 0xd2, 0x31, 0xf9, 0x4e, 0x3b, 0x25, 0x5c, 0xf0, 0x43, 0x3f, 0xc2, 0x5e, 0xb6, 0x6e,
-	0x69, 0x6d, 0x3b, 0x32, 0xf2, 0xc9, 0x3a, 0x01, 0x74, 0x8d, 0x8d, 0xd9, 0xbd, 0x2d, 0x4f, 0x6e,
-	0x36, 0x46, 0x89, 0x88, 0x99, 0x55, 0x95, 0x9d, 0xd6, 0x22, 0xfa, 0x01, 0xcc, 0xec, 0x45, 0xb0,
-	0x6a, 0x12, 0xb4, 0xd5, 0x4e, 0xdf, 0x8c, 0xb6, 0x7e, 0x33, 0xda, 0x63, 0xed, 0x81, 0x73, 0x67,
-	0x64, 0x43, 0x43, 0x84, 0xdc, 0xf5, 0x28, 0x13, 0xee, 0x9c, 0xf0, 0xb9, 0x65, 0x4a, 0xe4, 0xba,
-	0x08, 0x79, 0x9f, 0x32, 0xe1, 0x10, 0x3e, 0xef, 0xed, 0xa8, 0x19, 0xda, 0x7f, 0x19, 0xf0, 0x68,
-	0x40, 0xc3, 0xe0, 0x86, 0x32, 0x4c, 0xf9, 0x32, 0x8e, 0x38, 0x4d, 0xae, 0x2d, 0x2e, 0x88, 0x58,
-	0x71, 0x75, 0xc5, 0xef, 0xe9, 0x41, 0x5d, 0x49, 0xad, 0x53, 0xc2, 0xca, 0xfe, 0x7f, 0x27, 0xba,
-	0xd9, 0xa5, 0xf2, 0x43, 0xba, 0x94, 0xec, 0x63, 0x72, 0x79, 0x7c, 0x73, 0x0d, 0x66, 0xf6, 0xca,
-	0xa0, 0x5d, 0xa8, 0xe1, 0xe1, 0xcf, 0xa3, 0xab, 0xf1, 0x10, 0x37, 0x4b, 0xc8, 0x84, 0xed, 0xde,
-	0xd9, 0x45, 0xff, 0x75, 0xd3, 0x40, 0x0d, 0x30, 0xfb, 0xce, 0xc9, 0xe8, 0xbc, 0x7f, 0x31, 0x18,
-	0x36, 0xb7, 0x12, 0x11, 0x0f, 0x7f, 0x19, 0xf6, 0xc7, 0xa3, 0x8b, 0xf3, 0x66, 0x19, 0xed, 0x43,
-	0xe3, 0x74, 0x74, 0x36, 0x1e, 0xe2, 0xe1, 0x20, 0x0d, 0xa8, 0x74, 0x5f, 0x41, 0x55, 0xc2, 0x72,
-	0x74, 0x0c, 0x95, 0xfe, 0x9c, 0x08, 0x94, 0x5d, 0xfe, 0x85, 0xb5, 0x69, 0x35, 0xee, 0xbd, 0x74,
-	0x76, 0xe9, 0xc8, 0x38, 0x36, 0xba, 0xbf, 0x1b, 0xb0, 0xa3, 0xfa, 0x87, 0x5e, 0xe5, 0x7f, 0x9b,
-	0xba, 0x13, 0xc3, 0xe8, 0x86, 0x86, 0xf1, 0x92, 0xb6, 0x9e, 0xea, 0xe8, 0xb5, 0x6e, 0xa7, 0x38,
-	0xa8, 0x97, 0x8d, 0x41, 0xf7, 0xe2, 0xc1, 0x18, 0xbd, 0xdf, 0xc0, 0x8e, 0x99, 0xdf, 0x9e, 0xdf,
-	0x2d, 0x29, 0x0b, 0xe9, 0xd4, 0xa7, 0xac, 0x3d, 0x23, 0x13, 0x16, 0x78, 0x3a, 0x2c, 0xf9, 0x6a,
-	0xe8, 0x35, 0xd2, 0x5a, 0x2f, 0x89, 0xf7, 0x9e, 0xf8, 0xf4, 0xed, 0xd7, 0x7e, 0x20, 0xe6, 0xab,
-	0x49, 0x72, 0x56, 0xa7, 0x10, 0xd9, 0x49, 0x23, 0xd3, 0xcf, 0x13, 0xde, 0x49, 0x22, 0x27, 0xe9,
-	0xf7, 0xcc, 0x77, 0xff, 0x06, 0x00, 0x00, 0xff, 0xff, 0x7f, 0xfc, 0x9e, 0x06, 0xeb, 0x08, 0x00,
-	0x00,
+	// 1094 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0x5b, 0x72, 0xe3, 0x44,
+	0x17, 0xf6, 0x2d, 0x8e, 0x75, 0x7c, 0x19, 0xbb, 0x33, 0x17, 0xfd, 0x9e, 0x1f, 0x26, 0x88, 0x82,
+	0x0a, 0x3c, 0xd8, 0xc1, 0x4c, 0x51, 0x54, 0x1e, 0xa0, 0xe2, 0x4b, 0x90, 0x49, 0x26, 0x49, 0x75,
+	0x1c, 0x1e, 0xe6, 0x01, 0x97, 0x2c, 0xb7, 0x65, 0xcd, 0xd8, 0x92, 0xab, 0xbb, 0x9d, 0x4a, 0x36,
+	0x40, 0x15, 0x3b, 0x60, 0x07, 0x6c, 0x80, 0x5d, 0xb0, 0x20, 0x5e, 0x29, 0xf5, 0x45, 0x52, 0x6c,
+	0x32, 0x45, 0x78, 0x91, 0xd4, 0xe7, 0xd6, 0xe7, 0xf4, 0xf9, 0xbe, 0xd3, 0x82, 0xc6, 0x8a, 0x10,
+	0xda, 0x26, 0x37, 0x24, 0xe0, 0xac, 0xb5, 0xa2, 0x21, 0x0f, 0x51, 0x51, 0xbc, 0x58, 0x73, 0xcf,
+	0x0d, 0x97, 0xcb, 0x30, 0x68, 0xcb, 0x97, 0x54, 0x36, 0x5f, 0x79, 0x61, 0xe8, 0x2d, 0x48, 0x5b,
+	0xac, 0x26, 0xeb, 0x59, 0x9b, 0xfb, 0x4b, 0xc2, 0xb8, 0xb3, 0x5c, 0x29, 0x83, 0xa6, 0x08, 0xe8,
+	0xce, 0x1d, 0x3f, 0x70, 0xc3, 0x29, 0x19, 0x8b, 0xd0, 0x4a, 0xf7, 0x5c, 0xe8, 0x38, 0x75, 0x02,
+	0xe6, 0xb8, 0xdc, 0x8f, 0x83, 0x9a, 0x0b, 0x32, 0xf5, 0x22, 0x2f, 0x1a, 0x32, 0x26, 0x9f, 0x52,
+	0x63, 0x5d, 0x42, 0xa5, 0xa7, 0x43, 0x61, 0xe2, 0xa1, 0x4f, 0xa0, 0x92, 0x84, 0xf6, 0xa7, 0x66,
+	0x76, 0x3f, 0x7b, 0x60, 0xe0, 0x72, 0x2c, 0x1b, 0x4e, 0xd1, 0x47, 0x00, 0x62, 0xcf, 0x71, 0xe0,
+	0x2c, 0x89, 0x99, 0x13, 0x06, 0x86, 0x90, 0x9c, 0x3b, 0x4b, 0x62, 0xfd, 0x9e, 0x85, 0xd2, 0x30,
+	0xe0, 0x84, 0x12, 0xc6, 0xd1, 0xa1, 0xb6, 0xe5, 0x77, 0x2b, 0x22, 0x82, 0xd5, 0x3a, 0x0d, 0xb9,
+	0x35, 0x6b, 0x0d, 0x22, 0xcd, 0xe8, 0x6e, 0x45, 0x94, 0x7b, 0xf4, 0x89, 0xfa, 0x80, 0x92, 0x04,
+	0x28, 0xf1, 0xc6, 0x7e, 0x30, 0x0b, 0xc5, 0x2e, 0xe5, 0xce, 0x53, 0xed, 0x99, 0x4e, 0xd9, 0xce,
+	0xe0, 0xba, 0x9b, 0x5a, 0x0f, 0x83, 0x59, 0x88, 0x4c, 0xd8, 0x15, 0xb2, 0x61, 0xdf, 0xcc, 0x8b,
+	0x04, 0xf5, 0xb2, 0x6b, 0xc0, 0xae, 0x32, 0xb2, 0x5e, 0x43, 0x09, 0x13, 0xcf, 0x67, 0x9c, 0x50,
+	0x74, 0x00, 0x45, 0xd9, 0x23, 0x33, 0xbb, 0x9f, 0x3f, 0x28, 0x77, 0xea, 0x7a, 0x2b, 0x5d, 0x0a,
+	0x56, 0x7a, 0xeb, 0x0d, 0x18, 0x98, 0xbc, 0x23, 0xe2, 0x78, 0xd1, 0xa7, 0x90, 0xe3, 0xb7, 0xa2,
+	0xae, 0x72, 0x67, 0x4f, 0xbb, 0x8c, 0x92, 0xf3, 0xc7, 0x39, 0x7e, 0x8b, 0x5e, 0x82, 0x41, 0x28,
+	0x0d, 0xe9, 0x78, 0xc9, 0x3c, 0x75, 0x5e, 0x25, 0x21, 0x78, 0xc3, 0x3c, 0xeb, 0x1b, 0x80, 0xeb,
+	0x80, 0x3e, 0x3e, 0x8d, 0xdf, 0xb2, 0x50, 0x3d, 0xf1, 0x17, 0x91, 0x74, 0xda, 0x5d, 0x84, 0xee,
+	0xfb, 0xa8, 0x2f, 0xee, 0xdc, 0x09, 0x02, 0xb2, 0x48, 0x1a, 0x67, 0x28, 0xc9, 0x70, 0x8a, 0x9e,
+	0x43, 0x31, 0x58, 0x2f, 0x27, 0x84, 0x8a, 0x14, 0x0a, 0x58, 0xad, 0xd0, 0x25, 0x3c, 0x9b, 0xa9,
+	0x38, 0xe3, 0x14, 0x72, 0x98, 0x59, 0x10, 0x19, 0xbc, 0xd4, 0x19, 0xe8, 0xcd, 0xd2, 0xd5, 0x3d,
+	0x9d, 0x6d, 0x0b, 0x99, 0xf5, 0x47, 0x0e, 0xf6, 0xfe, 0xc1, 0x1a, 0x21, 0x28, 0xf0, 0xdb, 0x38,
+	0x35, 0xf1, 0x8d, 0x3e, 0x87, 0x82, 0x80, 0x46, 0x4e, 0x40, 0x03, 0xb5, 0x14, 0x17, 0x6c, 0xe2,
+	0x4c, 0x09, 0x15, 0xd8, 0x10, 0x7a, 0x74, 0x02, 0x88, 0xdf, 0x8e, 0x6f, 0x9c, 0x85, 0x3f, 0x75,
+	0xa2, 0x60, 0xe3, 0xa8, 0xdb, 0xa2, 0xb7, 0xb5, 0x8e, 0x19, 0x1f, 0xfc, 0xed, 0x4f, 0xb1, 0x41,
+	0x2f, 0x42, 0x43, 0x9d, 0x6f, 0x48, 0xd0, 0x35, 0xec, 0xa5, 0x8a, 0x1c, 0x27, 0xb5, 0x46, 0x1d,
+	0xb4, 0x3e, 0x50, 0xeb, 0xb1, 0xb4, 0xb4, 0x33, 0x18, 0xf1, 0x2d, 0x29, 0x3a, 0x82, 0xaa, 0x60,
+	0xd5, 0x38, 0x5c, 0x73, 0x37, 0x5c, 0x12, 0x73, 0x47, 0x41, 0x42, 0x48, 0x5b, 0xbd, 0xe8, 0x79,
+	0x21, 0x55, 0x76, 0x06, 0x57, 0xdc, 0xd4, 0xba, 0x5b, 0x84, 0x42, 0xdf, 0xe1, 0x8e, 0xf5, 0x0e,
+	0x9a, 0x0f, 0xef, 0x8b, 0xce, 0xa0, 0x91, 0xf0, 0x42, 0xa7, 0x2d, 0x41, 0xf2, 0x6a, 0x33, 0xed,
+	0x98, 0x1e, 0xd2, 0x39, 0xc5, 0x0f, 0x15, 0xcd, 0x7a, 0x0b, 0x2f, 0x1e, 0x30, 0x46, 0xdf, 0xc3,
+	0x93, 0x8d, 0xe1, 0xa2, 0xf0, 0xfd, 0x7c, 0x8b, 0x7d, 0x82, 0xc0, 0xb8, 0xe6, 0xde, 0x5b, 0x5b,
+	0xa7, 0x50, 0xbe, 0xf2, 0xbd, 0x80, 0x4c, 0xc5, 0x12, 0xfd, 0x1f, 0x0c, 0xe6, 0x7b, 0x81, 0xc3,
+	0xd7, 0x54, 0x4e, 0x80, 0x0a, 0x4e, 0x04, 0xe8, 0x63, 0x35, 0x20, 0xba, 0x77, 0x9c, 0x30, 0x81,
+	0x82, 0x0a, 0x4e, 0x49, 0xac, 0x3f, 0xf3, 0xb0, 0x23, 0xe3, 0xb4, 0xa0, 0xa4, 0x69, 0xa2, 0x12,
+	0x8a, 0xc9, 0xa1, 0x59, 0x6c, 0x67, 0x70, 0x6c, 0x83, 0x3e, 0x83, 0x9d, 0x49, 0xc4, 0x0b, 0x35,
+	0x3b, 0xaa, 0x1a, 0x5a, 0x82, 0x2c, 0x76, 0x06, 0x4b, 0x2d, 0x3a, 0xde, 0x2e, 0x37, 0xff, 0xa1,
+	0x72, 0xed, 0xcc, 0x66, 0xc1, 0xe8, 0x2b, 0x30, 0xa8, 0x9e, 0x08, 0x0a, 0x49, 0x8d, 0x24, 0x35,
+	0xa5, 0xb0, 0x33, 0x38, 0xb1, 0x42, 0xaf, 0x01, 0xd6, 0x31, 0xeb, 0x15, 0x58, 0x90, 0xf6, 0x49,
+	0xe6, 0x81, 0x9d, 0xc1, 0x29, 0x3b, 0xf4, 0x1d, 0xd4, 0x62, 0xaa, 0xca, 0xda, 0x76, 0x85, 0xe7,
+	0xb3, 0x4d, 0x00, 0xe8, 0x1a, 0xab, 0xb3, 0xb4, 0x40, 0x4c, 0x45, 0x4a, 0x1c, 0x1e, 0x52, 0xb3,
+	0x28, 0x4e, 0x5a, 0x2f, 0xd1, 0xb7, 0x60, 0xc4, 0xf7, 0x8c, 0x59, 0x12, 0x41, 0x9b, 0x2d, 0x79,
+	0x13, 0xb5, 0xf4, 0x4d, 0xd4, 0x1a, 0x69, 0x0b, 0x9c, 0x18, 0x23, 0x0b, 0xaa, 0x7c, 0xc1, 0xc6,
+	0x2e, 0xa1, 0x7c, 0x3c, 0x77, 0xd8, 0xdc, 0x34, 0x44, 0xe4, 0x32, 0x5f, 0xb0, 0x1e, 0xa1, 0xdc,
+	0x76, 0xd8, 0xbc, 0xbb, 0xab, 0x7a, 0x68, 0xfd, 0x95, 0x85, 0x27, 0x7d, 0xb2, 0xf0, 0x6f, 0x08,
+	0xc5, 0x84, 0xad, 0xc2, 0x80, 0x91, 0x68, 0xe4, 0x31, 0xee, 0xf0, 0x35, 0x53, 0xd7, 0x43, 0x4d,
+	0x37, 0xea, 0x4a, 0x48, 0xed, 0x0c, 0x56, 0xfa, 0x7f, 0xdb, 0xd1, 0xed, 0x53, 0xca, 0x3f, 0xea,
+	0x94, 0x4e, 0x61, 0xef, 0x1e, 0x97, 0x15, 0x2a, 0x64, 0x63, 0xff, 0x17, 0xa3, 0x22, 0x45, 0x61,
+	0x0d, 0x8c, 0x86, 0xbb, 0x29, 0x8c, 0xc8, 0x1d, 0x4d, 0x31, 0xeb, 0x97, 0x2c, 0x34, 0xb6, 0x5c,
+	0xfe, 0xeb, 0xc8, 0x3e, 0x82, 0xda, 0xbd, 0x0c, 0x99, 0x99, 0xdf, 0xcf, 0x3f, 0x30, 0x6e, 0x70,
+	0x35, 0x9d, 0x14, 0xfb, 0xf2, 0x1a, 0x8c, 0xf8, 0xde, 0x45, 0x15, 0x28, 0xe1, 0xc1, 0x0f, 0xc3,
+	0xab, 0xd1, 0x00, 0xd7, 0x33, 0xc8, 0x80, 0x9d, 0xee, 0xd9, 0x45, 0xef, 0xb4, 0x9e, 0x45, 0x55,
+	0x30, 0x7a, 0xf6, 0xf1, 0xf0, 0xbc, 0x77, 0xd1, 0x1f, 0xd4, 0x73, 0xd1, 0x12, 0x0f, 0x7e, 0x1c,
+	0xf4, 0x46, 0xc3, 0x8b, 0xf3, 0x7a, 0x1e, 0x35, 0xa0, 0x7a, 0x32, 0x3c, 0x1b, 0x0d, 0xf0, 0xa0,
+	0x2f, 0x1d, 0x0a, 0x9d, 0x23, 0x28, 0x8a, 0xb0, 0x0c, 0x1d, 0x42, 0xa1, 0x37, 0x77, 0x38, 0x8a,
+	0xaf, 0xc3, 0xd4, 0x30, 0x68, 0x56, 0xef, 0xdd, 0xfd, 0x56, 0xe6, 0x20, 0x7b, 0x98, 0xed, 0xfc,
+	0x9a, 0x85, 0x5d, 0x85, 0x0a, 0x74, 0x94, 0x7c, 0xd6, 0x75, 0x7f, 0x07, 0xc1, 0x0d, 0x59, 0x84,
+	0x2b, 0xd2, 0x7c, 0xa1, 0xbd, 0x37, 0x30, 0x24, 0xe3, 0xa0, 0x6e, 0x0c, 0x2e, 0xdd, 0xe1, 0x47,
+	0xc7, 0xe8, 0xfe, 0x0c, 0x56, 0x48, 0xbd, 0xd6, 0xfc, 0x6e, 0x45, 0xa8, 0xfc, 0x69, 0x6a, 0xcd,
+	0x9c, 0x09, 0xf5, 0x5d, 0xed, 0xb6, 0x22, 0x84, 0x76, 0xab, 0xb2, 0xd6, 0x4b, 0xc7, 0x7d, 0xef,
+	0x78, 0xe4, 0xed, 0x17, 0x9e, 0xcf, 0xe7, 0xeb, 0x49, 0xb4, 0x57, 0x3b, 0xe5, 0xd9, 0x96, 0x9e,
+	0xf2, 0x57, 0x8e, 0xb5, 0x23, 0xcf, 0x89, 0xfc, 0xf7, 0xfb, 0xfa, 0xef, 0x01, 0x00, 0x6f, 0x9a,
+	0x8d, 0x81, 0x17, 0x0a, 0x00, 0x00,
 }
@@ -76,6 +76,13 @@ func (m *SignedProposal) GetSignature() []byte {
 	return nil
 }
 
+func (m *SignedProposal) GetCrossMsgBytes() []byte {
+	if m != nil {
+		return m.CrossMsgBytes
+	}
+	return nil
+}
+
 // A Proposal is sent to an endorser for endorsement.  The proposal contains:
 // 1. A header which should be unmarshaled to a Header message.  Note that
 //    Header is both the header of a Proposal and of a Transaction, in that i)
@@ -42,6 +42,8 @@ const (
 	TxValidationCode_BAD_RWSET                    TxValidationCode = 22
 	TxValidationCode_ILLEGAL_WRITESET             TxValidationCode = 23
 	TxValidationCode_INVALID_WRITESET             TxValidationCode = 24
+	TxValidationCode_CROSS_LOCK_CONFLICT          TxValidationCode = 25
+	TxValidationCode_DUPLICATE_CONFIRMATION       TxValidationCode = 26
 	TxValidationCode_NOT_VALIDATED                TxValidationCode = 254
 	TxValidationCode_INVALID_OTHER_REASON         TxValidationCode = 255
 )
@@ -72,6 +74,8 @@ var TxValidationCode_name = map[int32]string{
 	22:  "BAD_RWSET",
 	23:  "ILLEGAL_WRITESET",
 	24:  "INVALID_WRITESET",
+	25:  "CROSS_LOCK_CONFLICT",
+	26:  "DUPLICATE_CONFIRMATION",
 	254: "NOT_VALIDATED",
 	255: "INVALID_OTHER_REASON",
 }
@@ -101,6 +105,8 @@ var TxValidationCode_value = map[string]int32{
 	"BAD_RWSET":                    22,
 	"ILLEGAL_WRITESET":             23,
 	"INVALID_WRITESET":             24,
+	"CROSS_LOCK_CONFLICT":          25,
+	"DUPLICATE_CONFIRMATION":       26,
 	"NOT_VALIDATED":                254,
 	"INVALID_OTHER_REASON":         255,
 }
@@ -314,59 +320,60 @@ func init() {
 func init() { proto.RegisterFile("peer/transaction.proto", fileDescriptor12) }
 
 var fileDescriptor12 = []byte{
-	// 849 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0x5d, 0x6f, 0x22, 0x37,
-	0x14, 0x2d, 0xd9, 0x26, 0x69, 0x2e, 0xf9, 0x30, 0x86, 0x10, 0x82, 0xa2, 0xee, 0x8a, 0x87, 0x6a,
-	0xdb, 0x4a, 0x20, 0x65, 0x1f, 0x2a, 0x55, 0x7d, 0x31, 0x33, 0x4e, 0x18, 0x75, 0xb0, 0x47, 0x1e,
-	0x43, 0x48, 0x1f, 0x6a, 0x0d, 0xe0, 0x25, 0xa8, 0x30, 0x83, 0x66, 0xc8, 0xaa, 0x79, 0xed, 0x0f,
-	0x68, 0x5f, 0xfa, 0x7b, 0xdb, 0xca, 0xf3, 0x01, 0x24, 0xe9, 0xbe, 0x30, 0xf8, 0xdc, 0xe3, 0x7b,
-	0xce, 0xbd, 0xd7, 0xba, 0x50, 0x5f, 0x69, 0x1d, 0x77, 0xd6, 0x71, 0x10, 0x26, 0xc1, 0x64, 0x3d,
-	0x8f, 0xc2, 0xf6, 0x2a, 0x8e, 0xd6, 0x11, 0x3e, 0x48, 0x3f, 0x49, 0xf3, 0xed, 0x2c, 0x8a, 0x66,
-	0x0b, 0xdd, 0x49, 0x8f, 0xe3, 0xc7, 0x8f, 0x9d, 0xf5, 0x7c, 0xa9, 0x93, 0x75, 0xb0, 0x5c, 0x65,
-	0xc4, 0xe6, 0x55, 0x9a, 0x60, 0x15, 0x47, 0xab, 0x28, 0x09, 0x16, 0x2a, 0xd6, 0xc9, 0x2a, 0x0a,
-	0x13, 0x9d, 0x47, 0xab, 0x93, 0x68, 0xb9, 0x8c, 0xc2, 0x4e, 0xf6, 0xc9, 0xc0, 0xd6, 0xaf, 0x50,
-	0xf1, 0xe7, 0xb3, 0x50, 0x4f, 0xe5, 0x56, 0x16, 0x7f, 0x0f, 0x95, 0x1d, 0x17, 0x6a, 0xfc, 0xb4,
-	0xd6, 0x49, 0xa3, 0xf4, 0xae, 0xf4, 0xfe, 0x58, 0xa0, 0x9d, 0x40, 0xd7, 0xe0, 0xf8, 0x0a, 0x8e,
-	0x92, 0xf9, 0x2c, 0x0c, 0xd6, 0x8f, 0xb1, 0x6e, 0xec, 0xa5, 0xa4, 0x2d, 0xd0, 0xfa, 0xa3, 0x04,
-	0x35, 0x2f, 0x8e, 0x26, 0x3a, 0x49, 0x9e, 0x6b, 0x74, 0xa1, 0xba, 0x93, 0x8a, 0x86, 0x9f, 0xf4,
-	0x22, 0x5a, 0xe9, 0x54, 0xa5, 0x7c, 0x8d, 0xda, 0xb9, 0xc9, 0x02, 0x17, 0xff, 0x47, 0xc6, 0xdf,
-	0xc0, 0xe9, 0xa7, 0x60, 0x31, 0x9f, 0x06, 0x06, 0xb5, 0xa2, 0x69, 0xa6, 0xbf, 0x2f, 0x5e, 0xa0,
-	0xad, 0x2e, 0x94, 0x77, 0xa5, 0x3f, 0xc0, 0x61, 0xf6, 0xcf, 0x14, 0xf5, 0xe6, 0x7d, 0xf9, 0xfa,
-	0x32, 0x6b, 0x46, 0xd2, 0xde, 0x61, 0x91, 0xf4, 0x57, 0x14, 0xcc, 0x16, 0x85, 0xca, 0xab, 0x28,
-	0xae, 0xc3, 0xc1, 0x83, 0x0e, 0xa6, 0x3a, 0xce, 0xbb, 0x93, 0x9f, 0x70, 0x03, 0x0e, 0x57, 0xc1,
-	0xd3, 0x22, 0x0a, 0xa6, 0x79, 0x47, 0x8a, 0x63, 0xeb, 0xaf, 0x12, 0xd4, 0xad, 0x87, 0x60, 0x1e,
-	0x4e, 0xa2, 0xa9, 0xce, 0xb2, 0x78, 0x59, 0x08, 0xff, 0x04, 0xcd, 0x49, 0x11, 0x51, 0x9b, 0x21,
-	0x16, 0x79, 0x32, 0x81, 0xc6, 0x86, 0xe1, 0xe5, 0x84, 0xe2, 0xf6, 0x0f, 0x70, 0x90, 0x59, 0x4b,
-	0x15, 0xcb, 0xd7, 0x6f, 0x8b, 0x9a, 0x36, 0x6a, 0x34, 0x9c, 0x46, 0x71, 0xa2, 0xa7, 0x79, 0x65,
-	0x39, 0xbd, 0xf5, 0x67, 0x09, 0x2e, 0x3e, 0xc3, 0xc1, 0x3f, 0xc2, 0xe5, 0xab, 0xd7, 0xf4, 0xc2,
-	0xd1, 0x45, 0x41, 0x10, 0x79, 0x7c, 0x6b, 0xe8, 0x58, 0x67, 0xd9, 0x96, 0x3a, 0x5c, 0x27, 0x8d,
-	0xbd, 0xb4, 0xd5, 0xd5, 0xc2, 0x16, 0xdd, 0xc6, 0xc4, 0x33, 0xe2, 0x77, 0x7f, 0xef, 0x03, 0x92,
-	0xbf, 0x0f, 0x9f, 0x8d, 0x10, 0x1f, 0xc1, 0xfe, 0x90, 0xb8, 0x8e, 0x8d, 0xbe, 0xc0, 0x08, 0x8e,
-	0x99, 0xe3, 0x2a, 0xca, 0x86, 0xd4, 0xe5, 0x1e, 0x45, 0x25, 0x7c, 0x06, 0xe5, 0x2e, 0xb1, 0x95,
-	0x47, 0xee, 0x5d, 0x4e, 0x6c, 0xb4, 0x87, 0xcf, 0xa1, 0x62, 0x00, 0x8b, 0xf7, 0xfb, 0x9c, 0xa9,
-	0x1e, 0x25, 0x36, 0x15, 0xe8, 0x0d, 0xbe, 0x84, 0xf3, 0x14, 0x16, 0x94, 0x48, 0x2e, 0x94, 0xef,
-	0xdc, 0x32, 0x22, 0x07, 0x82, 0xa2, 0x2f, 0xf1, 0x3b, 0xb8, 0x72, 0x58, 0xaa, 0xa0, 0x28, 0xb3,
-	0xb9, 0xf0, 0xa9, 0x50, 0x52, 0x10, 0xe6, 0x13, 0x4b, 0x3a, 0x9c, 0xa1, 0x7d, 0xfc, 0x35, 0x34,
-	0x0b, 0x86, 0xc5, 0xd9, 0x8d, 0x73, 0xfb, 0x2c, 0x7e, 0x80, 0x9b, 0x50, 0x1f, 0x30, 0x7f, 0xe0,
-	0x79, 0x5c, 0x48, 0x6a, 0x2b, 0x39, 0xda, 0xf8, 0x39, 0x2c, 0xfc, 0x78, 0x82, 0x7b, 0xdc, 0x27,
-	0xae, 0x92, 0x23, 0xc7, 0x46, 0x5f, 0x61, 0x0c, 0xa7, 0xf6, 0xc0, 0x73, 0x1d, 0x8b, 0x48, 0x9a,
-	0x61, 0x47, 0x46, 0x26, 0x37, 0xd0, 0xa7, 0x4c, 0x2a, 0x8f, 0xbb, 0x8e, 0x75, 0xaf, 0x6e, 0x88,
-	0xe3, 0x1a, 0xa3, 0x80, 0xeb, 0x80, 0xfb, 0x43, 0xcb, 0x52, 0x82, 0x92, 0xcc, 0x88, 0xeb, 0x58,
-	0x12, 0x95, 0x4d, 0x6d, 0x5e, 0x8f, 0x30, 0xc9, 0xfb, 0x2f, 0x42, 0xc7, 0xb8, 0x0a, 0x67, 0x03,
-	0xf6, 0x33, 0xe3, 0x77, 0xcc, 0xb8, 0x92, 0xf7, 0x1e, 0x45, 0x27, 0xc6, 0xae, 0x24, 0xe2, 0x96,
-	0x4a, 0x65, 0xf5, 0x88, 0xc3, 0x14, 0xe3, 0x52, 0xdd, 0xf0, 0x01, 0xb3, 0xd1, 0x29, 0xae, 0x01,
-	0xea, 0x13, 0xe1, 0xf7, 0x52, 0xa7, 0x8a, 0x0a, 0xc1, 0x05, 0x3a, 0x2b, 0xfa, 0x2e, 0x47, 0x79,
-	0xc9, 0xc8, 0x94, 0x45, 0x47, 0x9e, 0x23, 0xa8, 0x9d, 0x25, 0xb1, 0xb8, 0x4d, 0x51, 0xc5, 0x94,
-	0xb0, 0x39, 0xaa, 0x21, 0x15, 0xbe, 0xc3, 0xd9, 0xd6, 0x0f, 0xc6, 0x0d, 0xa8, 0x99, 0x6e, 0x64,
-	0x63, 0x51, 0x74, 0x24, 0x29, 0x33, 0x14, 0x54, 0x35, 0xc5, 0xa5, 0x03, 0xea, 0x11, 0xc6, 0xa8,
-	0x5b, 0x0c, 0xae, 0x56, 0xdc, 0x10, 0xd4, 0xf7, 0x38, 0xf3, 0xe9, 0xa6, 0xb3, 0xe7, 0xf8, 0x04,
-	0x8e, 0xd2, 0xc8, 0x9d, 0x4f, 0x25, 0xaa, 0x1b, 0xe7, 0x8e, 0xeb, 0xd2, 0x5b, 0xe2, 0xaa, 0x3b,
-	0xe1, 0x48, 0x6a, 0xd0, 0x8b, 0x14, 0xcd, 0x47, 0xb7, 0x41, 0x1b, 0x18, 0xc3, 0x89, 0x29, 0x3a,
-	0xc5, 0x89, 0xa4, 0x36, 0xfa, 0xa7, 0x84, 0x2f, 0xa1, 0x56, 0x30, 0xb9, 0xec, 0x51, 0x61, 0x7a,
-	0xe9, 0x73, 0x86, 0xfe, 0x2d, 0x75, 0x27, 0xd0, 0x8a, 0xe2, 0x59, 0xfb, 0xe1, 0x69, 0xa5, 0xe3,
-	0x85, 0x9e, 0xce, 0x74, 0xdc, 0xfe, 0x18, 0x8c, 0xe3, 0xf9, 0xa4, 0x78, 0xd1, 0x66, 0xf9, 0x76,
-	0xf1, 0xce, 0x92, 0xf0, 0x82, 0xc9, 0x6f, 0xc1, 0x4c, 0xff, 0xf2, 0xed, 0x6c, 0xbe, 0x7e, 0x78,
-	0x1c, 0x9b, 0x9d, 0xd6, 0xd9, 0xb9, 0xde, 0xc9, 0xae, 0x67, 0xeb, 0x3c, 0xe9, 0x98, 0xeb, 0xe3,
-	0x6c, 0xd5, 0x7f, 0xf8, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x1b, 0x4a, 0x5e, 0xd5, 0x0b, 0x06, 0x00,
-	0x00,
+	// 873 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0x5d, 0x6f, 0xe2, 0x46,
+	0x14, 0x2d, 0xbb, 0x4d, 0xd2, 0x5c, 0xf2, 0x31, 0x0c, 0x84, 0x00, 0x8a, 0xba, 0x2b, 0x1e, 0xaa,
+	0x6d, 0x2b, 0x81, 0x94, 0x7d, 0xa8, 0x54, 0xf5, 0x65, 0xb0, 0x27, 0xc1, 0x5a, 0x33, 0x63, 0x8d,
+	0x07, 0x42, 0xfa, 0xd0, 0x91, 0x81, 0x59, 0x82, 0x0a, 0x36, 0xb2, 0xc9, 0xaa, 0x79, 0xed, 0x0f,
+	0x68, 0x7f, 0x57, 0xff, 0x54, 0x5b, 0x8d, 0x3f, 0x80, 0x64, 0xdb, 0x17, 0xcc, 0x9c, 0x7b, 0xee,
+	0x3d, 0xe7, 0xde, 0x0b, 0x63, 0xa8, 0xaf, 0xb5, 0x8e, 0xbb, 0x9b, 0x38, 0x08, 0x93, 0x60, 0xba,
+	0x59, 0x44, 0x61, 0x67, 0x1d, 0x47, 0x9b, 0x08, 0x1f, 0xa6, 0x8f, 0xa4, 0xf5, 0x66, 0x1e, 0x45,
+	0xf3, 0xa5, 0xee, 0xa6, 0xc7, 0xc9, 0xe3, 0xc7, 0xee, 0x66, 0xb1, 0xd2, 0xc9, 0x26, 0x58, 0xad,
+	0x33, 0x62, 0xeb, 0x2a, 0x2d, 0xb0, 0x8e, 0xa3, 0x75, 0x94, 0x04, 0x4b, 0x15, 0xeb, 0x64, 0x1d,
+	0x85, 0x89, 0xce, 0xa3, 0xd5, 0x69, 0xb4, 0x5a, 0x45, 0x61, 0x37, 0x7b, 0x64, 0x60, 0xfb, 0x17,
+	0xa8, 0xf8, 0x8b, 0x79, 0xa8, 0x67, 0x72, 0x27, 0x8b, 0xbf, 0x87, 0xca, 0x9e, 0x0b, 0x35, 0x79,
+	0xda, 0xe8, 0xa4, 0x51, 0x7a, 0x5b, 0x7a, 0x77, 0x22, 0xd0, 0x5e, 0xa0, 0x67, 0x70, 0x7c, 0x05,
+	0xc7, 0xc9, 0x62, 0x1e, 0x06, 0x9b, 0xc7, 0x58, 0x37, 0x5e, 0xa5, 0xa4, 0x1d, 0xd0, 0xfe, 0xbd,
+	0x04, 0x35, 0x2f, 0x8e, 0xa6, 0x3a, 0x49, 0x9e, 0x6b, 0xf4, 0xa0, 0xba, 0x57, 0x8a, 0x86, 0x9f,
+	0xf4, 0x32, 0x5a, 0xeb, 0x54, 0xa5, 0x7c, 0x8d, 0x3a, 0xb9, 0xc9, 0x02, 0x17, 0xff, 0x45, 0xc6,
+	0xdf, 0xc0, 0xd9, 0xa7, 0x60, 0xb9, 0x98, 0x05, 0x06, 0xb5, 0xa2, 0x59, 0xa6, 0x7f, 0x20, 0x5e,
+	0xa0, 0xed, 0x1e, 0x94, 0xf7, 0xa5, 0xdf, 0xc3, 0x51, 0xf6, 0xcd, 0x34, 0xf5, 0xfa, 0x5d, 0xf9,
+	0xba, 0x99, 0x0d, 0x23, 0xe9, 0xec, 0xb1, 0x48, 0xfa, 0x29, 0x0a, 0x66, 0x9b, 0x42, 0xe5, 0xb3,
+	0x28, 0xae, 0xc3, 0xe1, 0x83, 0x0e, 0x66, 0x3a, 0xce, 0xa7, 0x93, 0x9f, 0x70, 0x03, 0x8e, 0xd6,
+	0xc1, 0xd3, 0x32, 0x0a, 0x66, 0xf9, 0x44, 0x8a, 0x63, 0xfb, 0xcf, 0x12, 0xd4, 0xad, 0x87, 0x60,
+	0x11, 0x4e, 0xa3, 0x99, 0xce, 0xaa, 0x78, 0x59, 0x08, 0xff, 0x04, 0xad, 0x69, 0x11, 0x51, 0xdb,
+	0x25, 0x16, 0x75, 0x32, 0x81, 0xc6, 0x96, 0xe1, 0xe5, 0x84, 0x22, 0xfb, 0x07, 0x38, 0xcc, 0xac,
+	0xa5, 0x8a, 0xe5, 0xeb, 0x37, 0x45, 0x4f, 0x5b, 0x35, 0x1a, 0xce, 0xa2, 0x38, 0xd1, 0xb3, 0xbc,
+	0xb3, 0x9c, 0xde, 0xfe, 0xa3, 0x04, 0x97, 0xff, 0xc3, 0xc1, 0x3f, 0x42, 0xf3, 0xb3, 0x5f, 0xd3,
+	0x0b, 0x47, 0x97, 0x05, 0x41, 0xe4, 0xf1, 0x9d, 0xa1, 0x13, 0x9d, 0x55, 0x5b, 0xe9, 0x70, 0x93,
+	0x34, 0x5e, 0xa5, 0xa3, 0xae, 0x16, 0xb6, 0xe8, 0x2e, 0x26, 0x9e, 0x11, 0xbf, 0xfb, 0xeb, 0x00,
+	0x90, 0xfc, 0x6d, 0xf4, 0x6c, 0x85, 0xf8, 0x18, 0x0e, 0x46, 0xc4, 0x75, 0x6c, 0xf4, 0x05, 0x46,
+	0x70, 0xc2, 0x1c, 0x57, 0x51, 0x36, 0xa2, 0x2e, 0xf7, 0x28, 0x2a, 0xe1, 0x73, 0x28, 0xf7, 0x88,
+	0xad, 0x3c, 0x72, 0xef, 0x72, 0x62, 0xa3, 0x57, 0xf8, 0x02, 0x2a, 0x06, 0xb0, 0xf8, 0x60, 0xc0,
+	0x99, 0xea, 0x53, 0x62, 0x53, 0x81, 0x5e, 0xe3, 0x26, 0x5c, 0xa4, 0xb0, 0xa0, 0x44, 0x72, 0xa1,
+	0x7c, 0xe7, 0x96, 0x11, 0x39, 0x14, 0x14, 0x7d, 0x89, 0xdf, 0xc2, 0x95, 0xc3, 0x52, 0x05, 0x45,
+	0x99, 0xcd, 0x85, 0x4f, 0x85, 0x92, 0x82, 0x30, 0x9f, 0x58, 0xd2, 0xe1, 0x0c, 0x1d, 0xe0, 0xaf,
+	0xa1, 0x55, 0x30, 0x2c, 0xce, 0x6e, 0x9c, 0xdb, 0x67, 0xf1, 0x43, 0xdc, 0x82, 0xfa, 0x90, 0xf9,
+	0x43, 0xcf, 0xe3, 0x42, 0x52, 0x5b, 0xc9, 0xf1, 0xd6, 0xcf, 0x51, 0xe1, 0xc7, 0x13, 0xdc, 0xe3,
+	0x3e, 0x71, 0x95, 0x1c, 0x3b, 0x36, 0xfa, 0x0a, 0x63, 0x38, 0xb3, 0x87, 0x9e, 0xeb, 0x58, 0x44,
+	0xd2, 0x0c, 0x3b, 0x36, 0x32, 0xb9, 0x81, 0x01, 0x65, 0x52, 0x79, 0xdc, 0x75, 0xac, 0x7b, 0x75,
+	0x43, 0x1c, 0xd7, 0x18, 0x05, 0x5c, 0x07, 0x3c, 0x18, 0x59, 0x96, 0x12, 0x94, 0x64, 0x46, 0x5c,
+	0xc7, 0x92, 0xa8, 0x6c, 0x7a, 0xf3, 0xfa, 0x84, 0x49, 0x3e, 0x78, 0x11, 0x3a, 0xc1, 0x55, 0x38,
+	0x1f, 0xb2, 0x0f, 0x8c, 0xdf, 0x31, 0xe3, 0x4a, 0xde, 0x7b, 0x14, 0x9d, 0x1a, 0xbb, 0x92, 0x88,
+	0x5b, 0x2a, 0x95, 0xd5, 0x27, 0x0e, 0x53, 0x8c, 0x4b, 0x75, 0xc3, 0x87, 0xcc, 0x46, 0x67, 0xb8,
+	0x06, 0x68, 0x40, 0x84, 0xdf, 0x4f, 0x9d, 0x2a, 0x2a, 0x04, 0x17, 0xe8, 0xbc, 0x98, 0xbb, 0x1c,
+	0xe7, 0x2d, 0x23, 0xd3, 0x16, 0x1d, 0x7b, 0x8e, 0xa0, 0x76, 0x56, 0xc4, 0xe2, 0x36, 0x45, 0x15,
+	0xd3, 0xc2, 0xf6, 0xa8, 0x46, 0x54, 0xf8, 0x0e, 0x67, 0x3b, 0x3f, 0x18, 0x37, 0xa0, 0x66, 0xa6,
+	0x91, 0xad, 0x45, 0xd1, 0xb1, 0xa4, 0xcc, 0x50, 0x50, 0xd5, 0x34, 0x97, 0x2e, 0xa8, 0x4f, 0x18,
+	0xa3, 0x6e, 0xb1, 0xb8, 0x5a, 0x91, 0x21, 0xa8, 0xef, 0x71, 0xe6, 0xd3, 0xed, 0x64, 0x2f, 0xf0,
+	0x29, 0x1c, 0xa7, 0x91, 0x3b, 0x9f, 0x4a, 0x54, 0x37, 0xce, 0x1d, 0xd7, 0xa5, 0xb7, 0xc4, 0x55,
+	0x77, 0xc2, 0x91, 0xd4, 0xa0, 0x97, 0x29, 0x9a, 0xaf, 0x6e, 0x8b, 0x36, 0xf0, 0x25, 0x54, 0x2d,
+	0xc1, 0x7d, 0x5f, 0xb9, 0xdc, 0xfa, 0xb0, 0xf3, 0xd7, 0x34, 0xa3, 0xd9, 0xad, 0x25, 0xdd, 0xb5,
+	0x18, 0x90, 0xb4, 0xe5, 0x16, 0xc6, 0x70, 0x6a, 0x26, 0x95, 0x16, 0x23, 0x92, 0xda, 0xe8, 0xef,
+	0x12, 0x6e, 0x42, 0xad, 0x28, 0xcf, 0x65, 0x9f, 0x0a, 0xb3, 0x00, 0x9f, 0x33, 0xf4, 0x4f, 0xa9,
+	0x37, 0x85, 0x76, 0x14, 0xcf, 0x3b, 0x0f, 0x4f, 0x6b, 0x1d, 0x2f, 0xf5, 0x6c, 0xae, 0xe3, 0xce,
+	0xc7, 0x60, 0x12, 0x2f, 0xa6, 0xc5, 0xdf, 0xc0, 0xdc, 0xd8, 0x3d, 0xbc, 0x77, 0xb3, 0x78, 0xc1,
+	0xf4, 0xd7, 0x60, 0xae, 0x7f, 0xfe, 0x76, 0xbe, 0xd8, 0x3c, 0x3c, 0x4e, 0xcc, 0x45, 0xd8, 0xdd,
+	0x4b, 0xef, 0x66, 0xe9, 0xd9, 0x3b, 0x20, 0xe9, 0x9a, 0xf4, 0x49, 0xf6, 0x7e, 0x78, 0xff, 0xef,
+	0x00, 0x90, 0x7b, 0xf9, 0x6d, 0x40, 0x06, 0x00, 0x00,
 }
@@ -0,0 +1,309 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+// legacyCrossModes maps the strings formerly carried in the crossinfo field of an envelope to their mode
+var legacyCrossModes = map[string]cross.CrossMode{
+	"local":        cross.CrossMode_LOCAL,
+	"singleCross":  cross.CrossMode_SINGLE_CROSS,
+	"multiCross":   cross.CrossMode_MULTI_CROSS,
+	"confirmation": cross.CrossMode_CONFIRMATION,
+}
+
+// legacyOutcomes maps the suffixes of the former txid_succ and txid_fail confirmations to their outcome
+var legacyOutcomes = map[string]cross.Outcome{
+	"succ": cross.Outcome_SUCCESS,
+	"fail": cross.Outcome_FAILURE,
+}
+
+// UnmarshalCrossInfo decodes the crossinfo field of an envelope. An empty field is a local
+// transaction, and the strings used by earlier versions are decoded into their mode.
+func UnmarshalCrossInfo(b []byte) (*cross.CrossInfo, error) {
+	if len(b) == 0 {
+		return &cross.CrossInfo{Mode: cross.CrossMode_LOCAL}, nil
+	}
+	if mode, ok := legacyCrossModes[string(b)]; ok {
+		return &cross.CrossInfo{Mode: mode}, nil
+	}
+	info := &cross.CrossInfo{}
+	if err := proto.Unmarshal(b, info); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling CrossInfo")
+	}
+	if _, ok := cross.CrossMode_name[int32(info.Mode)]; !ok {
+		return nil, errors.Errorf("unknown cross mode %d", info.Mode)
+	}
+	return info, nil
+}
+
+// GetCrossMode returns the cross-chain mode of an envelope
+func GetCrossMode(env *cb.Envelope) (cross.CrossMode, error) {
+	info, err := UnmarshalCrossInfo(env.CrossInfo)
+	if err != nil {
+		return cross.CrossMode_LOCAL, err
+	}
+	return info.Mode, nil
+}
+
+// GetSignedCrossProposal returns a signed proposal carrying the given cross info, given a Proposal
+// message and a signing identity. A nil cross info leaves the proposal local.
+func GetSignedCrossProposal(prop *peer.Proposal, signer msp.SigningIdentity, info *cross.CrossInfo) (*peer.SignedProposal, error) {
+	signedProp, err := GetSignedProposal(prop, signer)
+	if err != nil {
+		return nil, err
+	}
+	if signedProp.CrossMsgBytes, err = marshalCrossInfo(info); err != nil {
+		return nil, err
+	}
+	return signedProp, nil
+}
+
+// CreateSignedCrossTx assembles an Envelope message carrying the given cross info from proposal,
+// endorsements, and a signer. A nil cross info leaves the transaction local.
+func CreateSignedCrossTx(prop *peer.Proposal, signer msp.SigningIdentity, info *cross.CrossInfo, resps ...*peer.ProposalResponse) (*cb.Envelope, error) {
+	env, err := CreateSignedTx(prop, signer, resps...)
+	if err != nil {
+		return nil, err
+	}
+	if env.CrossInfo, err = marshalCrossInfo(info); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func marshalCrossInfo(info *cross.CrossInfo) ([]byte, error) {
+	if info == nil {
+		return nil, nil
+	}
+	b, err := proto.Marshal(info)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling CrossInfo")
+	}
+	return b, nil
+}
+
+// CheckCrossTxID checks that the cross info of a cross transaction names the transaction itself. The ID of a cross
+// transaction is its txid, which is the txid of its transactions on every participant channel, so that the locks
+// and the state of the transaction on the channels are found by the ID carried by its confirmations. Cross info
+// without an ID names the transaction by its txid.
+func CheckCrossTxID(txID string, info *cross.CrossInfo) error {
+	if info.CrossTxId != "" && info.CrossTxId != txID {
+		return errors.Errorf("cross info names cross transaction [%s] but the txid is %s", info.CrossTxId, txID)
+	}
+	return nil
+}
+
+// IsCrossTx returns true if the envelope is a single or multi-chain cross transaction,
+// which holds the keys it accesses locked until its confirmation
+func IsCrossTx(env *cb.Envelope) bool {
+	mode, err := GetCrossMode(env)
+	return err == nil && (mode == cross.CrossMode_SINGLE_CROSS || mode == cross.CrossMode_MULTI_CROSS)
+}
+
+// IsConfirmation returns true if the envelope is the confirmation of a cross transaction
+func IsConfirmation(env *cb.Envelope) bool {
+	mode, err := GetCrossMode(env)
+	return err == nil && mode == cross.CrossMode_CONFIRMATION
+}
+
+// IsCrossTxBlock returns true if the block carries a cross transaction. As IsConfirmationBlock, the
+// classification is derived from the envelopes of the block, whatever their validation.
+func IsCrossTxBlock(block *cb.Block) bool {
+	if block.Data == nil {
+		return false
+	}
+	for _, envBytes := range block.Data.Data {
+		if env, err := GetEnvelopeFromBlock(envBytes); err == nil && IsCrossTx(env) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConfirmationBlock returns true if the block carries confirmations only, which are committed apart from the
+// other transactions. The classification is derived from the envelopes of the block, which are covered by its
+// data hash and signed by the orderer, so that it is the same on every peer.
+func IsConfirmationBlock(block *cb.Block) bool {
+	if block.Data == nil || len(block.Data.Data) == 0 {
+		return false
+	}
+	for _, envBytes := range block.Data.Data {
+		env, err := GetEnvelopeFromBlock(envBytes)
+		if err != nil || !IsConfirmation(env) {
+			return false
+		}
+	}
+	return true
+}
+
+// legacyCrossInfoMetadata are the classifications of the blocks which earlier versions of the peer wrote at
+// index 4 of the block metadata
+var legacyCrossInfoMetadata = map[string]struct{}{"crosstx": {}, "normal": {}, "confirmation": {}}
+
+// ClearLegacyCrossInfo clears the classification written by earlier versions of the peer at index 4 of the
+// metadata of a block, which is covered by no hash nor signature and is not read anymore, and returns true
+// if the block carried one
+func ClearLegacyCrossInfo(block *cb.Block) bool {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_CROSSINFO) {
+		return false
+	}
+	if _, ok := legacyCrossInfoMetadata[string(block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO])]; !ok {
+		return false
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO] = nil
+	return true
+}
+
+// UnmarshalConfirmation decodes the payload data of a confirmation transaction. The txid_succ
+// and txid_fail strings used by earlier versions are decoded as well, in which case the txid
+// is everything before the last underscore.
+func UnmarshalConfirmation(data []byte) (*cross.Confirmation, error) {
+	if conf, ok := unmarshalLegacyConfirmation(data); ok {
+		return conf, nil
+	}
+	conf := &cross.Confirmation{}
+	if err := proto.Unmarshal(data, conf); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling Confirmation")
+	}
+	if conf.CrossTxId == "" {
+		return nil, errors.New("confirmation carries no cross transaction ID")
+	}
+	if conf.Outcome != cross.Outcome_SUCCESS && conf.Outcome != cross.Outcome_FAILURE {
+		return nil, errors.Errorf("invalid outcome %s of cross transaction [%s]", conf.Outcome, conf.CrossTxId)
+	}
+	return conf, nil
+}
+
+// unmarshalLegacyConfirmation decodes a txid_succ or txid_fail confirmation. A marshaled
+// Confirmation never decodes as such, as its first byte is the tag of the cross_tx_id field.
+func unmarshalLegacyConfirmation(data []byte) (*cross.Confirmation, bool) {
+	for _, c := range data {
+		if c < 0x20 || c > 0x7e {
+			return nil, false
+		}
+	}
+	i := bytes.LastIndexByte(data, '_')
+	if i <= 0 {
+		return nil, false
+	}
+	outcome, ok := legacyOutcomes[string(data[i+1:])]
+	if !ok {
+		return nil, false
+	}
+	return &cross.Confirmation{CrossTxId: string(data[:i]), Outcome: outcome}, true
+}
+
+// GetConfirmation returns the confirmation carried by an envelope
+func GetConfirmation(env *cb.Envelope) (*cross.Confirmation, error) {
+	payload, err := UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalConfirmation(payload.Data)
+}
+
+// ConfirmationSignedBytes returns the bytes of a confirmation which are signed by its coordinator
+func ConfirmationSignedBytes(conf *cross.Confirmation) ([]byte, error) {
+	unsigned := *conf
+	unsigned.CoordinatorSignature = nil
+	b, err := proto.Marshal(&unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling Confirmation")
+	}
+	return b, nil
+}
+
+// ConfirmationAsSignedData returns the signature of a confirmation by its coordinator as signed data, to be checked
+// against the cross coordinators policy. It fails if the confirmation names no coordinator or is not signed.
+func ConfirmationAsSignedData(conf *cross.Confirmation) ([]*cb.SignedData, error) {
+	if len(conf.Coordinator) == 0 {
+		return nil, errors.Errorf("confirmation of cross transaction [%s] is signed by no coordinator", conf.CrossTxId)
+	}
+	if len(conf.CoordinatorSignature) == 0 {
+		return nil, errors.Errorf("confirmation of cross transaction [%s] is not signed by its coordinator", conf.CrossTxId)
+	}
+	signedBytes, err := ConfirmationSignedBytes(conf)
+	if err != nil {
+		return nil, err
+	}
+	return []*cb.SignedData{{Data: signedBytes, Identity: conf.Coordinator, Signature: conf.CoordinatorSignature}}, nil
+}
+
+// ComputeConfirmationTxID returns the txid of the confirmations of the given cross transaction. It is derived
+// from the ID of the cross transaction, which is the txid of the transaction itself, so that the confirmation is
+// indexed in the block store of every channel and a confirmation committed again on a channel is a duplicate.
+func ComputeConfirmationTxID(crossTxID string) string {
+	digest := sha256.Sum256([]byte("confirmation:" + crossTxID))
+	return hex.EncodeToString(digest[:])
+}
+
+// CheckConfirmationTxID checks that the txid of a confirmation is the one derived from its cross transaction.
+// Confirmations created before confirmations had a txid carry none and are accepted, but are not indexed.
+func CheckConfirmationTxID(txID string, conf *cross.Confirmation) error {
+	if txID == "" {
+		return nil
+	}
+	if expected := ComputeConfirmationTxID(conf.CrossTxId); txID != expected {
+		return errors.Errorf("invalid txid %s for the confirmation of cross transaction [%s], expected %s", txID, conf.CrossTxId, expected)
+	}
+	return nil
+}
+
+// CreateSignedConfirmation creates the envelope of a confirmation for the given channel, whose txid is derived
+// from the cross transaction, signed by the coordinator both in the confirmation and on the envelope
+func CreateSignedConfirmation(channelID string, signer crypto.LocalSigner, conf *cross.Confirmation) (*cb.Envelope, error) {
+	shdr, err := signer.NewSignatureHeader()
+	if err != nil {
+		return nil, err
+	}
+	signed := *conf
+	signed.Coordinator = shdr.Creator
+	signedBytes, err := ConfirmationSignedBytes(&signed)
+	if err != nil {
+		return nil, err
+	}
+	if signed.CoordinatorSignature, err = signer.Sign(signedBytes); err != nil {
+		return nil, err
+	}
+
+	data, err := proto.Marshal(&signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling Confirmation")
+	}
+	chdr := MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, channelID, 0)
+	chdr.TxId = ComputeConfirmationTxID(conf.CrossTxId)
+	payloadBytes, err := proto.Marshal(&cb.Payload{Header: MakePayloadHeader(chdr, shdr), Data: data})
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling Payload")
+	}
+	env := &cb.Envelope{Payload: payloadBytes}
+	if env.Signature, err = signer.Sign(payloadBytes); err != nil {
+		return nil, err
+	}
+	env.CrossInfo, err = proto.Marshal(&cross.CrossInfo{
+		Mode:      cross.CrossMode_CONFIRMATION,
+		CrossTxId: conf.CrossTxId,
+		Channels:  conf.Channels,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling CrossInfo")
+	}
+	return env, nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockmsp "github.com/hyperledger/fabric/common/mocks/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/cross"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalCrossInfo(t *testing.T) {
+	for legacy, mode := range map[string]cross.CrossMode{
+		"":             cross.CrossMode_LOCAL,
+		"local":        cross.CrossMode_LOCAL,
+		"singleCross":  cross.CrossMode_SINGLE_CROSS,
+		"multiCross":   cross.CrossMode_MULTI_CROSS,
+		"confirmation": cross.CrossMode_CONFIRMATION,
+	} {
+		info, err := UnmarshalCrossInfo([]byte(legacy))
+		assert.NoError(t, err)
+		assert.Equal(t, mode, info.Mode, "legacy cross info [%s]", legacy)
+	}
+
+	expected := &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "tx_1", Channels: []string{"ch1", "ch2"}}
+	info, err := UnmarshalCrossInfo(MarshalOrPanic(expected))
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(expected, info))
+
+	_, err = UnmarshalCrossInfo([]byte("crossConfirmation"))
+	assert.Error(t, err)
+	_, err = UnmarshalCrossInfo(MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode(42)}))
+	assert.Error(t, err)
+
+	mode, err := GetCrossMode(&cb.Envelope{})
+	assert.NoError(t, err)
+	assert.Equal(t, cross.CrossMode_LOCAL, mode)
+	assert.True(t, IsConfirmation(&cb.Envelope{CrossInfo: []byte("confirmation")}))
+	assert.False(t, IsConfirmation(&cb.Envelope{CrossInfo: []byte("singleCross")}))
+	assert.False(t, IsConfirmation(&cb.Envelope{CrossInfo: []byte("garbage")}))
+	assert.True(t, IsCrossTx(&cb.Envelope{CrossInfo: []byte("singleCross")}))
+	assert.True(t, IsCrossTx(&cb.Envelope{CrossInfo: MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS})}))
+	assert.False(t, IsCrossTx(&cb.Envelope{CrossInfo: []byte("local")}))
+	assert.False(t, IsCrossTx(&cb.Envelope{CrossInfo: []byte("confirmation")}))
+	assert.False(t, IsCrossTx(&cb.Envelope{CrossInfo: []byte("garbage")}))
+}
+
+func TestUnmarshalConfirmation(t *testing.T) {
+	conf, err := UnmarshalConfirmation([]byte("tx_1_succ"))
+	assert.NoError(t, err)
+	assert.Equal(t, &cross.Confirmation{CrossTxId: "tx_1", Outcome: cross.Outcome_SUCCESS}, conf)
+	conf, err = UnmarshalConfirmation([]byte("tx1_fail"))
+	assert.NoError(t, err)
+	assert.Equal(t, &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE}, conf)
+
+	expected := &cross.Confirmation{CrossTxId: "tx_1_fail", Channels: []string{"ch1"}, Outcome: cross.Outcome_SUCCESS}
+	conf, err = UnmarshalConfirmation(MarshalOrPanic(expected))
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(expected, conf))
+
+	for _, invalid := range [][]byte{
+		[]byte("tx1"),
+		[]byte("_fail"),
+		[]byte("tx1_unknown"),
+		nil,
+		MarshalOrPanic(&cross.Confirmation{Outcome: cross.Outcome_FAILURE}),
+		MarshalOrPanic(&cross.Confirmation{CrossTxId: "tx1"}),
+	} {
+		_, err = UnmarshalConfirmation(invalid)
+		assert.Error(t, err, "confirmation [%s] should be rejected", invalid)
+	}
+}
+
+func TestCreateSignedConfirmation(t *testing.T) {
+	conf := &cross.Confirmation{CrossTxId: "tx1", Channels: []string{"ch1", "ch2"}, Outcome: cross.Outcome_FAILURE, Reason: "timeout"}
+	env, err := CreateSignedConfirmation("ch1", mockcrypto.FakeLocalSigner, conf)
+	assert.NoError(t, err)
+	assert.Nil(t, conf.CoordinatorSignature, "the confirmation passed in should not be modified")
+
+	assert.True(t, IsConfirmation(env))
+	info, err := UnmarshalCrossInfo(env.CrossInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "tx1", info.CrossTxId)
+	assert.Equal(t, []string{"ch1", "ch2"}, info.Channels)
+
+	signed, err := GetConfirmation(env)
+	assert.NoError(t, err)
+	assert.Equal(t, conf.Reason, signed.Reason)
+	assert.Equal(t, mockcrypto.FakeLocalSigner.Identity, signed.Coordinator)
+	signedBytes, err := ConfirmationSignedBytes(signed)
+	assert.NoError(t, err)
+	// the mock signer signs a message with the message itself
+	assert.Equal(t, signedBytes, signed.CoordinatorSignature)
+	assert.Equal(t, env.Payload, env.Signature)
+	signedData, err := ConfirmationAsSignedData(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, []*cb.SignedData{{Data: signedBytes, Identity: signed.Coordinator, Signature: signed.CoordinatorSignature}}, signedData)
+
+	payload, err := UnmarshalPayload(env.Payload)
+	assert.NoError(t, err)
+	chdr, err := UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	assert.NoError(t, err)
+	assert.Equal(t, "ch1", chdr.ChannelId)
+	assert.Equal(t, ComputeConfirmationTxID("tx1"), chdr.TxId)
+	assert.NoError(t, CheckConfirmationTxID(chdr.TxId, signed))
+
+	_, err = CreateSignedConfirmation("ch1", badSigner, conf)
+	assert.Error(t, err)
+}
+
+func TestConfirmationAsSignedData(t *testing.T) {
+	// unsigned confirmations are rejected
+	_, err := ConfirmationAsSignedData(&cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_FAILURE})
+	assert.Error(t, err)
+	_, err = ConfirmationAsSignedData(&cross.Confirmation{CrossTxId: "tx1", Coordinator: []byte("coordinator")})
+	assert.Error(t, err)
+	_, err = ConfirmationAsSignedData(&cross.Confirmation{CrossTxId: "tx1", CoordinatorSignature: []byte("signature")})
+	assert.Error(t, err)
+}
+
+func TestCheckCrossTxID(t *testing.T) {
+	assert.NoError(t, CheckCrossTxID("tx1", &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS}))
+	assert.NoError(t, CheckCrossTxID("tx1", &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "tx1"}))
+	assert.Error(t, CheckCrossTxID("tx1", &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "global"}))
+}
+
+func TestConfirmationTxID(t *testing.T) {
+	txID := ComputeConfirmationTxID("tx1")
+	assert.Len(t, txID, 64)
+	assert.Equal(t, txID, ComputeConfirmationTxID("tx1"))
+	assert.NotEqual(t, txID, ComputeConfirmationTxID("tx2"))
+	assert.NotEqual(t, "tx1", txID)
+
+	conf := &cross.Confirmation{CrossTxId: "tx1", Outcome: cross.Outcome_SUCCESS}
+	assert.NoError(t, CheckConfirmationTxID(txID, conf))
+	// legacy confirmations carry no txid
+	assert.NoError(t, CheckConfirmationTxID("", conf))
+	err := CheckConfirmationTxID(ComputeConfirmationTxID("tx2"), conf)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid txid")
+	assert.Error(t, CheckConfirmationTxID("tx1", conf))
+}
+
+func TestCrossBlockClassification(t *testing.T) {
+	newBlock := func(crossInfos ...[]byte) *cb.Block {
+		block := cb.NewBlock(0, nil)
+		for _, crossInfo := range crossInfos {
+			block.Data.Data = append(block.Data.Data, MarshalOrPanic(&cb.Envelope{Payload: []byte("payload"), CrossInfo: crossInfo}))
+		}
+		return block
+	}
+	crossTx := MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "tx1"})
+	confirmation := MarshalOrPanic(&cross.CrossInfo{Mode: cross.CrossMode_CONFIRMATION, CrossTxId: "tx1"})
+
+	for _, test := range []struct {
+		name                   string
+		block                  *cb.Block
+		crossTx, confirmations bool
+	}{
+		{"empty", newBlock(), false, false},
+		{"local", newBlock(nil, []byte("local")), false, false},
+		{"crossTx", newBlock(nil, crossTx), true, false},
+		{"legacyCrossTx", newBlock([]byte("singleCross")), true, false},
+		{"confirmations", newBlock(confirmation, []byte("confirmation")), false, true},
+		{"mixed", newBlock(confirmation, nil), false, false},
+		{"garbage", newBlock([]byte("garbage"), confirmation), false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.crossTx, IsCrossTxBlock(test.block))
+			assert.Equal(t, test.confirmations, IsConfirmationBlock(test.block))
+		})
+	}
+
+	// the metadata of the block is not read
+	block := newBlock(nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO] = []byte("confirmation")
+	assert.False(t, IsConfirmationBlock(block))
+	block.Data.Data = append(block.Data.Data, []byte("garbage"))
+	assert.False(t, IsCrossTxBlock(block))
+}
+
+func TestClearLegacyCrossInfo(t *testing.T) {
+	for _, crossInfo := range []string{"crosstx", "normal", "confirmation"} {
+		block := cb.NewBlock(0, nil)
+		block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO] = []byte(crossInfo)
+		assert.True(t, ClearLegacyCrossInfo(block))
+		assert.Nil(t, block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO])
+		assert.False(t, ClearLegacyCrossInfo(block))
+	}
+
+	// other metadata at the index is kept
+	block := cb.NewBlock(0, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO] = []byte("other")
+	assert.False(t, ClearLegacyCrossInfo(block))
+	assert.Equal(t, []byte("other"), block.Metadata.Metadata[cb.BlockMetadataIndex_CROSSINFO])
+	block.Metadata.Metadata = block.Metadata.Metadata[:cb.BlockMetadataIndex_CROSSINFO]
+	assert.False(t, ClearLegacyCrossInfo(block))
+	assert.False(t, ClearLegacyCrossInfo(&cb.Block{}))
+}
+
+func TestCreateSignedCrossTx(t *testing.T) {
+	signer, err := mockmsp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	creator, err := signer.Serialize()
+	assert.NoError(t, err)
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "cc"}}}
+	prop, _, err := CreateChaincodeProposal(cb.HeaderType_ENDORSER_TRANSACTION, "ch1", cis, creator)
+	assert.NoError(t, err)
+	info := &cross.CrossInfo{Mode: cross.CrossMode_MULTI_CROSS, CrossTxId: "global", Channels: []string{"ch1", "ch2"}}
+
+	signedProp, err := GetSignedCrossProposal(prop, signer, info)
+	assert.NoError(t, err)
+	decoded, err := UnmarshalCrossInfo(signedProp.CrossMsgBytes)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(info, decoded))
+	signedProp, err = GetSignedCrossProposal(prop, signer, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, signedProp.CrossMsgBytes)
+
+	presp, err := CreateProposalResponse(prop.Header, prop.Payload, &pb.Response{Status: 200}, []byte("res"), nil, &pb.ChaincodeID{Name: "cc"}, nil, signer)
+	assert.NoError(t, err)
+	env, err := CreateSignedCrossTx(prop, signer, info, presp)
+	assert.NoError(t, err)
+	assert.True(t, IsCrossTx(env))
+	decoded, err = UnmarshalCrossInfo(env.CrossInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "global", decoded.CrossTxId)
+	env, err = CreateSignedCrossTx(prop, signer, nil, presp)
+	assert.NoError(t, err)
+	assert.False(t, IsCrossTx(env))
+
+	_, err = CreateSignedCrossTx(prop, signer, info)
+	assert.Error(t, err)
+}
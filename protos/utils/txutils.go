@@ -320,9 +320,7 @@ func GetSignedProposal(prop *peer.Proposal, signer msp.SigningIdentity) (*peer.S
 		return nil, err
 	}
 
-// NEW update
 	return &peer.SignedProposal{ProposalBytes: propBytes, Signature: signature}, nil
-//	return &peer.SignedProposal{ProposalBytes: propBytes, Signature: signature, CrossMsgBytes: }, nil
 }
 
 // GetSignedEvent returns a signed event given an Event message and a signing identity
@@ -57,6 +57,19 @@ for x in "peer logging" "peer logging getlevel" "peer logging revertlevels" "pee
 done
 cat docs/wrappers/peer_logging_postscript.md >> $DOC
 
+DOC=docs/source/commands/peercross.md
+cat docs/wrappers/peer_cross_preamble.md > $DOC
+
+for x in "peer cross" "peer cross confirm" "peer cross status"; do
+  echo "" >> $DOC
+  echo "##" $x >> $DOC
+  echo "\`\`\`" >> $DOC
+  .build/bin/${x} --help 1>> $DOC 2>/dev/null
+  echo "\`\`\`" >> $DOC
+  echo "" >> $DOC
+done
+cat docs/wrappers/peer_cross_postscript.md >> $DOC
+
 DOC=docs/source/commands/peernode.md
 cat docs/wrappers/peer_node_preamble.md > $DOC
 